@@ -0,0 +1,113 @@
+package goharvest
+
+import "encoding/xml"
+
+// About holds the about containers of a record. The provenance and rights containers
+// defined by OAI-PMH are parsed; any other container is kept in Raw.
+type About struct {
+	// Raw is the inner XML of all about containers of the record, one after the other
+	Raw []byte `xml:",innerxml"`
+	// Provenance is the provenance container, nil if the record has none
+	Provenance *Provenance `xml:"-"`
+	// Rights is the rights container, nil if the record has none
+	Rights *Rights `xml:"-"`
+}
+
+// UnmarshalXML implements xml.Unmarshaler. A record may have several about elements,
+// which are all decoded into the same About.
+func (a *About) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var container struct {
+		Raw        []byte      `xml:",innerxml"`
+		Provenance *Provenance `xml:"provenance"`
+		Rights     *Rights     `xml:"rights"`
+	}
+	if err := d.DecodeElement(&container, &start); err != nil {
+		return err
+	}
+
+	a.Raw = append(a.Raw, container.Raw...)
+	if container.Provenance != nil {
+		a.Provenance = container.Provenance
+	}
+	if container.Rights != nil {
+		a.Rights = container.Rights
+	}
+	return nil
+}
+
+// Provenance is the OAI provenance container, which aggregators add to the records they
+// re-expose to describe where each record was harvested from
+type Provenance struct {
+	// OriginDescription describes the latest harvest of the record
+	OriginDescription *OriginDescription `xml:"originDescription"`
+}
+
+// OriginDescription describes one harvest of a record. It nests the description of the
+// harvest before it, back to the repository the record originates from.
+type OriginDescription struct {
+	// HarvestDate is when the record was harvested
+	HarvestDate string `xml:"harvestDate,attr"`
+	// Altered reports whether the metadata was changed after the harvest
+	Altered bool `xml:"altered,attr"`
+	// BaseURL is the base URL of the repository the record was harvested from
+	BaseURL string `xml:"baseURL"`
+	// Identifier is the identifier of the record in that repository
+	Identifier string `xml:"identifier"`
+	// Datestamp is the datestamp of the record in that repository
+	Datestamp string `xml:"datestamp"`
+	// MetadataNamespace is the namespace of the metadata format that was harvested
+	MetadataNamespace string `xml:"metadataNamespace"`
+	// OriginDescription describes the harvest that repository got the record from, nil if
+	// the record originates there
+	OriginDescription *OriginDescription `xml:"originDescription"`
+}
+
+// Chain returns the origin descriptions from the latest harvest back to the original
+// repository
+func (p *Provenance) Chain() []*OriginDescription {
+	if p == nil {
+		return nil
+	}
+	var chain []*OriginDescription
+	for origin := p.OriginDescription; origin != nil; origin = origin.OriginDescription {
+		chain = append(chain, origin)
+	}
+	return chain
+}
+
+// Origin returns the description of the repository the record originates from, nil if
+// the provenance is empty
+func (p *Provenance) Origin() *OriginDescription {
+	chain := p.Chain()
+	if len(chain) == 0 {
+		return nil
+	}
+	return chain[len(chain)-1]
+}
+
+// Rights is the OAI rights container, which either references a rights statement or
+// defines it inline
+type Rights struct {
+	// Reference points to the rights statement, nil if it is defined inline
+	Reference *RightsReference `xml:"rightsReference"`
+	// Definition is the inline rights statement, nil if it is referenced
+	Definition *RightsDefinition `xml:"rightsDefinition"`
+}
+
+// RightsReference points to a rights statement, e.g. a Creative Commons license
+type RightsReference struct {
+	Ref string `xml:"ref,attr"`
+}
+
+// RightsDefinition holds an inline rights statement, e.g. Creative Commons RDF
+type RightsDefinition struct {
+	Raw []byte `xml:",innerxml"`
+}
+
+// URL returns the URL of the referenced rights statement, or an empty string
+func (r *Rights) URL() string {
+	if r == nil || r.Reference == nil {
+		return ""
+	}
+	return r.Reference.Ref
+}
@@ -0,0 +1,81 @@
+package goharvest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAboutContainers(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "listrecords_dc_about.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, workers := range []int{0, 4} {
+		resp, err := decodeOAIPMHResponseDC(bytes.NewReader(data), decodeOptions{workers: workers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		records := resp.ListRecords.Records
+		if len(records) != 3 {
+			t.Fatalf("workers=%d: expected 3 records, got %d", workers, len(records))
+		}
+
+		about := records[0].About
+		if about == nil || about.Provenance == nil || about.Rights == nil {
+			t.Fatalf("workers=%d: expected provenance and rights, got %+v", workers, about)
+		}
+		chain := about.Provenance.Chain()
+		if len(chain) != 2 {
+			t.Fatalf("workers=%d: expected a provenance chain of 2, got %d", workers, len(chain))
+		}
+		latest, origin := chain[0], about.Provenance.Origin()
+		if latest.BaseURL != "http://r2.example.org/oai" || latest.Identifier != "oai:r2:klik001" ||
+			latest.Datestamp != "2025-01-20" || latest.HarvestDate != "2025-01-30T14:10:02Z" || !latest.Altered {
+			t.Errorf("workers=%d: unexpected latest origin %+v", workers, latest)
+		}
+		if origin != chain[1] || origin.BaseURL != "http://library.example.org/oai" || origin.Identifier != "oai:library.example.org:5" ||
+			origin.Altered || origin.MetadataNamespace != "http://www.openarchives.org/OAI/2.0/oai_dc/" {
+			t.Errorf("workers=%d: unexpected original origin %+v", workers, origin)
+		}
+		if about.Rights.URL() != "http://creativecommons.org/licenses/by/4.0/" || about.Rights.Definition != nil {
+			t.Errorf("workers=%d: unexpected rights %+v", workers, about.Rights)
+		}
+		// Raw keeps both containers
+		if !strings.Contains(string(about.Raw), "originDescription") || !strings.Contains(string(about.Raw), "rightsReference") {
+			t.Errorf("workers=%d: expected the raw XML of both containers, got %s", workers, about.Raw)
+		}
+
+		rights := records[1].About.Rights
+		if records[1].About.Provenance != nil || rights.URL() != "" || rights.Definition == nil ||
+			!strings.Contains(string(rights.Definition.Raw), "publicdomain/zero") {
+			t.Errorf("workers=%d: unexpected inline rights %+v", workers, records[1].About)
+		}
+		if records[2].About != nil {
+			t.Errorf("workers=%d: expected no about containers, got %+v", workers, records[2].About)
+		}
+	}
+}
+
+func TestHarvestRecordsAbout(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_dc_about.xml"})
+
+	var origins []string
+	err := NewClient(server.URL).HarvestRecords("oai_dc", nil, func(record HarvestedRecord) error {
+		if record.About != nil {
+			if origin := record.About.Provenance.Origin(); origin != nil {
+				origins = append(origins, origin.Identifier)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(origins) != 1 || origins[0] != "oai:library.example.org:5" {
+		t.Errorf("Expected the origin of the first record, got %v", origins)
+	}
+}
@@ -0,0 +1,209 @@
+package goharvest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ArchivedPage describes one raw OAI-PMH response stored by a PageArchive
+type ArchivedPage struct {
+	// Sequence numbers the pages of an archive from 1
+	Sequence int `json:"sequence"`
+	// Verb and Args are the OAI-PMH request (arguments with empty values are omitted)
+	Verb string            `json:"verb"`
+	Args map[string]string `json:"args,omitempty"`
+	// URL is the requested URL
+	URL string `json:"url"`
+	// Fetched is when the response was received
+	Fetched time.Time `json:"fetched"`
+	// File is the name of the stored page, relative to the archive
+	File string `json:"file"`
+	// Size is the uncompressed size of the response in bytes
+	Size int `json:"size"`
+	// SHA256 is the hex SHA-256 digest of the uncompressed response
+	SHA256 string `json:"sha256"`
+}
+
+// PageArchive stores raw OAI-PMH responses. Store fills in Sequence and File.
+type PageArchive interface {
+	Store(page *ArchivedPage, body []byte) error
+}
+
+// WithArchive stores every raw response page in archive before it is parsed,
+// for provenance and offline re-extraction
+func WithArchive(archive PageArchive) ClientOption {
+	return func(c *OAIClient) {
+		c.Archive = archive
+	}
+}
+
+// DirArchiveManifest is the name of the manifest file of a DirArchive
+const DirArchiveManifest = "manifest.jsonl"
+
+// unsafeFileChars matches characters not kept in archived page file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// DirArchive stores gzip-compressed pages in a directory, named by sequence, verb,
+// resumption token (or metadata prefix) and timestamp, and appends one JSON line per page
+// to manifest.jsonl. Reopening a directory continues its sequence.
+type DirArchive struct {
+	Dir string
+
+	mu       sync.Mutex
+	sequence int
+}
+
+// NewDirArchive creates dir if needed and returns an archive writing to it
+func NewDirArchive(dir string) (*DirArchive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	a := &DirArchive{Dir: dir}
+	pages, err := a.Pages()
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) > 0 {
+		a.sequence = pages[len(pages)-1].Sequence
+	}
+
+	return a, nil
+}
+
+// Store writes the page and appends it to the manifest
+func (a *DirArchive) Store(page *ArchivedPage, body []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.sequence++
+	compressed, err := preparePage(page, a.sequence, body)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(a.Dir, page.File), compressed, 0o644); err != nil {
+		return fmt.Errorf("failed to write archived page: %w", err)
+	}
+
+	line, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+	manifest, err := os.OpenFile(filepath.Join(a.Dir, DirArchiveManifest), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open archive manifest: %w", err)
+	}
+	if _, err := manifest.Write(append(line, '\n')); err != nil {
+		manifest.Close()
+		return fmt.Errorf("failed to write archive manifest: %w", err)
+	}
+	return manifest.Close()
+}
+
+// Pages reads the manifest, in archive order
+func (a *DirArchive) Pages() ([]ArchivedPage, error) {
+	f, err := os.Open(filepath.Join(a.Dir, DirArchiveManifest))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var pages []ArchivedPage
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var page ArchivedPage
+		if err := json.Unmarshal(scanner.Bytes(), &page); err != nil {
+			return nil, fmt.Errorf("invalid archive manifest line %d: %w", len(pages)+1, err)
+		}
+		pages = append(pages, page)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// Open returns the decompressed response of an archived page
+func (a *DirArchive) Open(page ArchivedPage) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(a.Dir, page.File))
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read archived page %s: %w", page.File, err)
+	}
+
+	return &gzipFile{Reader: zr, file: f}, nil
+}
+
+// gzipFile closes both the gzip reader and the underlying file
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+// Close closes the reader and the file
+func (g *gzipFile) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+// preparePage numbers and describes a page and returns its gzip-compressed body
+func preparePage(page *ArchivedPage, sequence int, body []byte) ([]byte, error) {
+	page.Sequence = sequence
+	page.File = archiveFileName(page)
+	page.Size = len(body)
+	sum := sha256.Sum256(body)
+	page.SHA256 = hex.EncodeToString(sum[:])
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return compressed.Bytes(), nil
+}
+
+// archiveFileName names a page like "000002-ListRecords-dc-page-2-20251002T100520Z.xml.gz"
+func archiveFileName(page *ArchivedPage) string {
+	label := page.Args["resumptionToken"]
+	if label == "" {
+		label = page.Args["metadataPrefix"]
+	}
+	if label == "" {
+		label = "start"
+	}
+	label = unsafeFileChars.ReplaceAllString(label, "_")
+	if len(label) > 40 {
+		label = label[:40]
+	}
+
+	return fmt.Sprintf("%06d-%s-%s-%s.xml.gz", page.Sequence, page.Verb, label, page.Fetched.UTC().Format("20060102T150405Z"))
+}
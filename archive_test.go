@@ -0,0 +1,76 @@
+package goharvest
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirArchive(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	dir := filepath.Join(t.TempDir(), "pages")
+	archive, err := NewDirArchive(dir)
+	if err != nil {
+		t.Fatalf("NewDirArchive failed: %v", err)
+	}
+
+	client := NewClient(server.URL, WithArchive(archive))
+	count := 0
+	err = client.HarvestRecords("oai_dc", &HarvestOptions{Set: "buku"}, func(record HarvestedRecord) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestRecords failed: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected archived harvest to parse 4 records, got %d", count)
+	}
+
+	pages, err := archive.Pages()
+	if err != nil {
+		t.Fatalf("Pages failed: %v", err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("Expected 2 archived pages, got %d", len(pages))
+	}
+
+	first := pages[0]
+	if first.Sequence != 1 || first.Verb != "ListRecords" || first.Args["metadataPrefix"] != "oai_dc" || first.Args["set"] != "buku" {
+		t.Errorf("Unexpected first page %+v", first)
+	}
+	if !strings.HasPrefix(first.File, "000001-ListRecords-oai_dc-") || !strings.HasSuffix(first.File, ".xml.gz") {
+		t.Errorf("Unexpected file name %q", first.File)
+	}
+	if !strings.HasPrefix(pages[1].File, "000002-ListRecords-dc-page-2-") || pages[1].Args["resumptionToken"] != "dc-page-2" {
+		t.Errorf("Unexpected second page %+v", pages[1])
+	}
+
+	r, err := archive.Open(first)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer r.Close()
+	data, _ := io.ReadAll(r)
+	want, _ := os.ReadFile(filepath.Join("testdata", "listrecords_dc_page1.xml"))
+	if string(data) != string(want) || first.Size != len(want) || len(first.SHA256) != 64 {
+		t.Errorf("Archived page does not match the response (%d bytes)", len(data))
+	}
+
+	reopened, err := NewDirArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &ArchivedPage{Verb: "Identify"}
+	if err := reopened.Store(page, []byte("<OAI-PMH/>")); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if page.Sequence != 3 || !strings.HasPrefix(page.File, "000003-Identify-start-") {
+		t.Errorf("Expected reopened archive to continue the sequence, got %+v", page)
+	}
+}
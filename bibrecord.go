@@ -0,0 +1,514 @@
+package goharvest
+
+import (
+	"regexp"
+	"strings"
+)
+
+// BibType is a format-neutral resource type
+type BibType string
+
+// Resource types used by BibRecord
+const (
+	BibTypeBook     BibType = "book"
+	BibTypeArticle  BibType = "article"
+	BibTypeSerial   BibType = "serial"
+	BibTypeThesis   BibType = "thesis"
+	BibTypeDataset  BibType = "dataset"
+	BibTypeSoftware BibType = "software"
+	BibTypeImage    BibType = "image"
+	BibTypeMap      BibType = "map"
+	BibTypeMusic    BibType = "music"
+	BibTypeSound    BibType = "sound"
+	BibTypeVideo    BibType = "video"
+	BibTypeOther    BibType = "other"
+)
+
+// BibRecord is a format-neutral bibliographic record built from MARC, Dublin Core, MODS or DataCite
+type BibRecord struct {
+	ID                string           `json:"id"`
+	SourceFormat      MetadataFormat   `json:"source_format"`
+	Type              BibType          `json:"type"`
+	Title             string           `json:"title"`
+	Subtitle          string           `json:"subtitle,omitempty"`
+	AlternativeTitles []string         `json:"alternative_titles,omitempty"`
+	Contributors      []BibContributor `json:"contributors"`
+	Identifiers       []BibIdentifier  `json:"identifiers"`
+	Dates             []BibDate        `json:"dates"`
+	Publisher         string           `json:"publisher,omitempty"`
+	PublishPlace      string           `json:"publish_place,omitempty"`
+	Edition           string           `json:"edition,omitempty"`
+	Series            []string         `json:"series,omitempty"`
+	Subjects          []string         `json:"subjects"`
+	Abstract          string           `json:"abstract,omitempty"`
+	Links             []BibLink        `json:"links"`
+	Language          string           `json:"language,omitempty"`
+	Rights            []string         `json:"rights,omitempty"`
+}
+
+// BibContributor is a contributor with its roles
+type BibContributor struct {
+	Name       string   `json:"name"`
+	Roles      []string `json:"roles,omitempty"` // e.g. "author", "editor", "translator"
+	Type       string   `json:"type,omitempty"`  // personal, corporate or meeting
+	Identifier string   `json:"identifier,omitempty"`
+}
+
+// BibIdentifier is a typed identifier such as isbn, issn, doi, handle, url or local
+type BibIdentifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// BibDate is a typed date such as issued, created or copyright
+type BibDate struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// BibLink is a URL related to the record
+type BibLink struct {
+	URL      string `json:"url"`
+	Label    string `json:"label,omitempty"`
+	Relation string `json:"relation,omitempty"`
+}
+
+// yearPattern finds a four-digit year in a free-text date
+var yearPattern = regexp.MustCompile(`(?:^|[^0-9])(1[0-9]{3}|20[0-9]{2})(?:[^0-9]|$)`)
+
+// Year returns the four-digit publication year, preferring the issued date
+func (b *BibRecord) Year() string {
+	for _, date := range b.Dates {
+		if date.Type == "issued" {
+			if year := findYear(date.Value); year != "" {
+				return year
+			}
+		}
+	}
+	for _, date := range b.Dates {
+		if year := findYear(date.Value); year != "" {
+			return year
+		}
+	}
+	return ""
+}
+
+// findYear returns the first four-digit year in s, e.g. "1980" in "c1980."
+func findYear(s string) string {
+	if match := yearPattern.FindStringSubmatch(s); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// Authors returns the contributors with an author or creator role, or all contributors when none has one
+func (b *BibRecord) Authors() []BibContributor {
+	var authors []BibContributor
+	for _, contributor := range b.Contributors {
+		for _, role := range contributor.Roles {
+			if role == "author" || role == "creator" {
+				authors = append(authors, contributor)
+				break
+			}
+		}
+	}
+	if len(authors) == 0 {
+		return b.Contributors
+	}
+	return authors
+}
+
+// FullTitle returns the title followed by the subtitle, e.g. "Bumi manusia: roman"
+func (b *BibRecord) FullTitle() string {
+	if b.Subtitle == "" {
+		return b.Title
+	}
+	return b.Title + ": " + b.Subtitle
+}
+
+// FamilyGiven splits a personal name into family and given parts. Inverted names
+// ("Toer, Pramoedya") split at the comma; direct ones ("Pramoedya Toer") at the last space.
+// Corporate names and single-word names are returned whole as the family part.
+func (c BibContributor) FamilyGiven() (family, given string) {
+	name := strings.TrimSpace(c.Name)
+	if c.Type == string(ContributorCorporate) || c.Type == string(ContributorMeeting) || c.Type == "conference" {
+		return name, ""
+	}
+	if i := strings.Index(name, ","); i >= 0 {
+		return strings.TrimSpace(name[:i]), strings.Trim(strings.TrimSpace(name[i+1:]), ",")
+	}
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		return name[i+1:], name[:i]
+	}
+	return name, ""
+}
+
+// IdentifierValue returns the first identifier of the given type
+func (b *BibRecord) IdentifierValue(idType string) string {
+	for _, id := range b.Identifiers {
+		if id.Type == idType {
+			return id.Value
+		}
+	}
+	return ""
+}
+
+// NewBibRecord converts any supported extractor (MARC, Dublin Core, Qualified DC, ETD-MS, MODS,
+// DataCite) to a BibRecord. It returns false for other formats.
+func NewBibRecord(extractor MetadataExtractor) (*BibRecord, bool) {
+	if record, ok := extractor.(*MARCRecord); ok {
+		return record.ToBibRecord(), record != nil
+	}
+
+	var bib *BibRecord
+	switch metadata := extractor.ExtractMetadata().(type) {
+	case *DCMetadata:
+		bib = metadata.ToBibRecord()
+	case *QDCMetadata:
+		bib = metadata.DCMetadata.ToBibRecord()
+		bib.AlternativeTitles = append(bib.AlternativeTitles, metadata.Alternative...)
+		bib.Abstract = firstNonEmpty(append([]string{bib.Abstract}, metadata.Abstract...))
+	case *EtdmsMetadata:
+		bib = metadata.DCMetadata.ToBibRecord()
+		bib.Type = BibTypeThesis
+		for _, advisor := range metadata.Advisors {
+			bib.Contributors = append(bib.Contributors, BibContributor{Name: advisor, Roles: []string{"thesis advisor"}})
+		}
+	case *ModsMetadata:
+		bib = metadata.ToBibRecord()
+	case *DataCiteMetadata:
+		bib = metadata.ToBibRecord()
+	default:
+		return nil, false
+	}
+	if bib == nil {
+		return nil, false
+	}
+
+	bib.SourceFormat = extractor.GetFormat()
+	return bib, true
+}
+
+// ToBibRecord converts a MARC record to a BibRecord
+func (m *MARCRecord) ToBibRecord() *BibRecord {
+	book := m.ExtractBookMetadata()
+	if book == nil {
+		return nil
+	}
+
+	bib := newBibRecord(FormatMARCXML)
+	bib.ID = book.RecordID
+	bib.Type = m.bibType()
+	bib.Title = trimISBDPunctuation(book.Title)
+	bib.Subtitle = trimISBDPunctuation(book.Subtitle)
+	bib.Publisher = trimISBDPunctuation(book.Publisher)
+	bib.PublishPlace = trimISBDPunctuation(book.PublishPlace)
+	bib.Edition = trimISBDPunctuation(book.Edition)
+	bib.Series = book.Series
+	bib.Abstract = book.Summary
+	bib.Language = m.languageCode()
+	bib.AlternativeTitles = m.Query("246$a$b")
+
+	for _, contributor := range m.Contributors() {
+		roles := contributor.Roles()
+		if len(roles) == 0 && contributor.Main {
+			roles = []string{"author"}
+		}
+		bib.Contributors = append(bib.Contributors, BibContributor{
+			Name:  contributor.Name,
+			Roles: roles,
+			Type:  string(contributor.Type),
+		})
+	}
+
+	for _, id := range m.StandardIdentifiers() {
+		if !id.Canceled && id.Value != "" && id.Type != IdentifierSystem {
+			bib.Identifiers = append(bib.Identifiers, BibIdentifier{Type: string(id.Type), Value: id.Value})
+		}
+	}
+
+	if year := trimISBDPunctuation(book.PublishYear); year != "" {
+		bib.Dates = append(bib.Dates, BibDate{Type: "issued", Value: year})
+	} else if f008, err := m.Parse008(); err == nil && f008.PublicationYear() > 0 {
+		bib.Dates = append(bib.Dates, BibDate{Type: "issued", Value: f008.Date1})
+	}
+	if book.CopyrightDate != "" {
+		bib.Dates = append(bib.Dates, BibDate{Type: "copyright", Value: book.CopyrightDate})
+	}
+
+	for _, subject := range book.Subjects {
+		bib.Subjects = append(bib.Subjects, subject.String())
+	}
+
+	for _, resource := range book.ElectronicResources {
+		bib.Links = append(bib.Links, BibLink{
+			URL:      resource.URL,
+			Label:    firstNonEmpty([]string{resource.LinkText, resource.MaterialSpec}),
+			Relation: resource.Relationship,
+		})
+	}
+
+	return bib
+}
+
+// bibType maps leader/06-07 (and a 502 dissertation note) to a BibType
+func (m *MARCRecord) bibType() BibType {
+	leader, err := m.ParseLeader()
+	if err != nil {
+		// providers that omit the leader are library catalogues, like ExtractBookMetadata assumes
+		return BibTypeBook
+	}
+
+	switch leader.TypeOfRecord {
+	case "a", "t":
+		switch {
+		case len(m.GetAllSubfields("502")) > 0:
+			return BibTypeThesis
+		case leader.BibliographicLevel == BibLevelSerialPart || leader.BibliographicLevel == BibLevelMonographicPart:
+			return BibTypeArticle
+		case leader.IsSerial():
+			return BibTypeSerial
+		}
+		return BibTypeBook
+	case "e", "f":
+		return BibTypeMap
+	case "c", "d", "j":
+		return BibTypeMusic
+	case "i":
+		return BibTypeSound
+	case "g":
+		return BibTypeVideo
+	case "k":
+		return BibTypeImage
+	case "m":
+		return BibTypeSoftware
+	}
+	return BibTypeOther
+}
+
+// ToBibRecord converts extracted Dublin Core metadata to a BibRecord
+func (d *DCMetadata) ToBibRecord() *BibRecord {
+	if d == nil {
+		return nil
+	}
+
+	bib := newBibRecord(FormatOAIDC)
+	bib.Title = firstNonEmpty(d.Title)
+	if len(d.Title) > 1 {
+		bib.AlternativeTitles = d.Title[1:]
+	}
+	bib.Type = bibTypeFromTerms(d.Type...)
+	bib.Publisher = firstNonEmpty(d.Publisher)
+	bib.Subjects = append(bib.Subjects, d.Subject...)
+	bib.Abstract = firstNonEmpty(d.Description)
+	bib.Language = firstNonEmpty(d.Language)
+	bib.Rights = d.Rights
+
+	for _, creator := range d.Creator {
+		bib.Contributors = append(bib.Contributors, BibContributor{Name: creator, Roles: []string{"author"}})
+	}
+	for _, contributor := range d.Contributor {
+		bib.Contributors = append(bib.Contributors, BibContributor{Name: contributor, Roles: []string{"contributor"}})
+	}
+	for _, date := range d.Date {
+		bib.Dates = append(bib.Dates, BibDate{Type: "issued", Value: date})
+	}
+
+	for _, identifier := range d.Identifier {
+		id := classifyIdentifier(identifier)
+		bib.Identifiers = append(bib.Identifiers, id)
+		if id.Type == "url" || id.Type == "doi" || id.Type == "handle" {
+			if url := identifierURL(id); url != "" {
+				bib.Links = append(bib.Links, BibLink{URL: url, Relation: LinkRelationshipResource})
+			}
+		}
+	}
+	if len(bib.Identifiers) > 0 {
+		bib.ID = bib.Identifiers[0].Value
+	}
+
+	return bib
+}
+
+// ToBibRecord converts extracted MODS metadata to a BibRecord
+func (md *ModsMetadata) ToBibRecord() *BibRecord {
+	if md == nil {
+		return nil
+	}
+
+	bib := newBibRecord(FormatMODS)
+	bib.Title = md.Title
+	bib.Subtitle = md.Subtitle
+	bib.AlternativeTitles = md.AlternativeTitles
+	bib.Type = bibTypeFromTerms(append(append([]string{}, md.Genre...), md.TypeOfResource)...)
+	bib.Publisher = md.Publisher
+	bib.PublishPlace = md.PublishPlace
+	bib.Edition = md.Edition
+	bib.Subjects = append(bib.Subjects, md.Subjects...)
+	bib.Abstract = md.Abstract
+	bib.Language = firstNonEmpty(md.Language)
+	bib.Rights = md.AccessCondition
+
+	for _, name := range md.Names {
+		roles := name.Roles
+		if len(roles) == 0 {
+			roles = []string{"author"}
+		}
+		bib.Contributors = append(bib.Contributors, BibContributor{Name: name.Name, Roles: roles, Type: name.Type})
+	}
+	if md.DateIssued != "" {
+		bib.Dates = append(bib.Dates, BibDate{Type: "issued", Value: md.DateIssued})
+	}
+	for _, identifier := range md.Identifiers {
+		idType := strings.ToLower(identifier.Type)
+		if idType == "" {
+			idType = classifyIdentifier(identifier.Value).Type
+		}
+		bib.Identifiers = append(bib.Identifiers, BibIdentifier{Type: idType, Value: strings.TrimSpace(identifier.Value)})
+	}
+	for _, url := range md.URLs {
+		bib.Links = append(bib.Links, BibLink{URL: url, Relation: LinkRelationshipResource})
+	}
+	if len(bib.Identifiers) > 0 {
+		bib.ID = bib.Identifiers[0].Value
+	}
+
+	return bib
+}
+
+// ToBibRecord converts extracted DataCite metadata to a BibRecord
+func (d *DataCiteMetadata) ToBibRecord() *BibRecord {
+	if d == nil {
+		return nil
+	}
+
+	bib := newBibRecord(FormatDataCite)
+	bib.ID = d.DOI
+	bib.Title = d.Title
+	bib.AlternativeTitles = d.AlternativeTitles
+	bib.Type = bibTypeFromTerms(d.ResourceTypeGeneral, d.ResourceType)
+	bib.Publisher = d.Publisher
+	bib.Edition = d.Version
+	bib.Subjects = append(bib.Subjects, d.Subjects...)
+	bib.Abstract = d.Abstract
+	bib.Language = d.Language
+	bib.Rights = d.Rights
+
+	for _, creator := range d.Creators {
+		bib.Contributors = append(bib.Contributors, BibContributor{Name: creator.Name, Roles: []string{"author"}, Identifier: creator.ORCID})
+	}
+	if d.PublicationYear != "" {
+		bib.Dates = append(bib.Dates, BibDate{Type: "issued", Value: d.PublicationYear})
+	}
+	for _, date := range d.Dates {
+		bib.Dates = append(bib.Dates, BibDate{Type: strings.ToLower(date.Type), Value: date.Value})
+	}
+	if d.DOI != "" {
+		bib.Identifiers = append(bib.Identifiers, BibIdentifier{Type: "doi", Value: d.DOI})
+		bib.Links = append(bib.Links, BibLink{URL: "https://doi.org/" + d.DOI, Relation: LinkRelationshipResource})
+	}
+	for _, identifier := range d.AlternateIdentifiers {
+		bib.Identifiers = append(bib.Identifiers, BibIdentifier{Type: strings.ToLower(identifier.Type), Value: identifier.Value})
+	}
+
+	return bib
+}
+
+// newBibRecord returns an empty BibRecord with non-nil slices
+func newBibRecord(format MetadataFormat) *BibRecord {
+	return &BibRecord{
+		SourceFormat: format,
+		Type:         BibTypeOther,
+		Contributors: []BibContributor{},
+		Identifiers:  []BibIdentifier{},
+		Dates:        []BibDate{},
+		Subjects:     []string{},
+		Links:        []BibLink{},
+	}
+}
+
+// bibTypeTerms maps lowercase type vocabulary terms (DCMI, MODS, DataCite, COAR) to BibTypes,
+// checked in order so that specific terms win over generic ones
+var bibTypeTerms = []struct {
+	term    string
+	bibType BibType
+}{
+	{"thesis", BibTypeThesis},
+	{"dissertation", BibTypeThesis},
+	{"skripsi", BibTypeThesis},
+	{"article", BibTypeArticle},
+	{"conference", BibTypeArticle},
+	{"journal", BibTypeSerial},
+	{"periodical", BibTypeSerial},
+	{"serial", BibTypeSerial},
+	{"dataset", BibTypeDataset},
+	{"software", BibTypeSoftware},
+	{"moving image", BibTypeVideo},
+	{"audiovisual", BibTypeVideo},
+	{"video", BibTypeVideo},
+	{"cartographic", BibTypeMap},
+	{"map", BibTypeMap},
+	{"notated music", BibTypeMusic},
+	{"musical", BibTypeMusic},
+	{"sound", BibTypeSound},
+	{"image", BibTypeImage},
+	{"book", BibTypeBook},
+	{"monograph", BibTypeBook},
+	{"text", BibTypeBook},
+}
+
+// bibTypeFromTerms returns the BibType of the first term that matches the vocabulary
+func bibTypeFromTerms(terms ...string) BibType {
+	for _, term := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term == "" {
+			continue
+		}
+		for _, candidate := range bibTypeTerms {
+			if strings.Contains(term, candidate.term) {
+				return candidate.bibType
+			}
+		}
+	}
+	return BibTypeOther
+}
+
+// classifyIdentifier guesses the type of a free-text identifier
+func classifyIdentifier(value string) BibIdentifier {
+	value = strings.TrimSpace(value)
+	lower := strings.ToLower(value)
+
+	switch {
+	case strings.HasPrefix(lower, "doi:"):
+		return BibIdentifier{Type: "doi", Value: strings.TrimSpace(value[4:])}
+	case strings.Contains(lower, "doi.org/"):
+		return BibIdentifier{Type: "doi", Value: value[strings.Index(lower, "doi.org/")+len("doi.org/"):]}
+	case strings.HasPrefix(lower, "10.") && strings.Contains(value, "/"):
+		return BibIdentifier{Type: "doi", Value: value}
+	case strings.Contains(lower, "hdl.handle.net/"):
+		return BibIdentifier{Type: "handle", Value: value[strings.Index(lower, "hdl.handle.net/")+len("hdl.handle.net/"):]}
+	case strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://"):
+		return BibIdentifier{Type: "url", Value: value}
+	case strings.HasPrefix(lower, "urn:isbn:") || strings.HasPrefix(lower, "isbn"):
+		number := strings.TrimLeft(value[strings.Index(lower, "isbn")+len("isbn"):], ": ")
+		return BibIdentifier{Type: "isbn", Value: firstNonEmpty([]string{NormalizeISBN(number), number})}
+	case ValidISBN(value):
+		return BibIdentifier{Type: "isbn", Value: NormalizeISBN(value)}
+	case strings.HasPrefix(lower, "issn") || (ValidISSN(value) && len(strings.Fields(value)) == 1 && strings.Contains(value, "-")):
+		return BibIdentifier{Type: "issn", Value: NormalizeISSN(value)}
+	}
+	return BibIdentifier{Type: "local", Value: value}
+}
+
+// identifierURL returns a resolvable URL for url, doi and handle identifiers
+func identifierURL(id BibIdentifier) string {
+	switch id.Type {
+	case "url":
+		return id.Value
+	case "doi":
+		return "https://doi.org/" + id.Value
+	case "handle":
+		return "https://hdl.handle.net/" + id.Value
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package goharvest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMARCToBibRecord(t *testing.T) {
+	record := &MARCRecord{
+		Leader:        "00000nam a2200000 a 4500",
+		ControlFields: []ControlField{{Tag: "001", Value: "REC-1"}, {Tag: "008", Value: "210315s2020    io a     b   f001 0 ind d"}},
+		DataFields: []DataField{
+			{Tag: "020", Subfields: []Subfield{{Code: "a", Value: "978-0-306-40615-7 (pbk.)"}}},
+			{Tag: "100", Ind1: "1", Subfields: []Subfield{{Code: "a", Value: "Toer, Pramoedya Ananta,"}, {Code: "d", Value: "1925-2006"}}},
+			{Tag: "245", Ind1: "1", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Bumi manusia :"}, {Code: "b", Value: "roman /"}}},
+			{Tag: "264", Ind2: "1", Subfields: []Subfield{{Code: "a", Value: "Jakarta :"}, {Code: "b", Value: "Hasta Mitra,"}, {Code: "c", Value: "1980."}}},
+			{Tag: "650", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Indonesian fiction"}}},
+			{Tag: "700", Ind1: "1", Subfields: []Subfield{{Code: "a", Value: "Lane, Max,"}, {Code: "e", Value: "translator."}}},
+			{Tag: "856", Ind1: "4", Ind2: "0", Subfields: []Subfield{{Code: "u", Value: "http://example.org/1"}}},
+		},
+	}
+
+	bib, ok := NewBibRecord(record)
+	if !ok {
+		t.Fatal("Expected MARC record to convert")
+	}
+	if bib.ID != "REC-1" || bib.Type != BibTypeBook || bib.Title != "Bumi manusia" || bib.Subtitle != "roman" {
+		t.Errorf("Unexpected identity %+v", bib)
+	}
+	if bib.Publisher != "Hasta Mitra" || bib.PublishPlace != "Jakarta" || bib.Year() != "1980" || bib.Language != "ind" {
+		t.Errorf("Unexpected publication %q / %q / %q / %q", bib.Publisher, bib.PublishPlace, bib.Year(), bib.Language)
+	}
+	if authors := bib.Authors(); len(authors) != 1 || authors[0].Name != "Toer, Pramoedya Ananta" {
+		t.Errorf("Unexpected authors %+v", authors)
+	}
+	if len(bib.Contributors) != 2 || bib.Contributors[1].Roles[0] != "translator" {
+		t.Errorf("Unexpected contributors %+v", bib.Contributors)
+	}
+	if bib.IdentifierValue("isbn") != "9780306406157" || len(bib.Subjects) != 1 || len(bib.Links) != 1 {
+		t.Errorf("Unexpected identifiers/subjects/links %+v / %v / %+v", bib.Identifiers, bib.Subjects, bib.Links)
+	}
+}
+
+func TestDCToBibRecord(t *testing.T) {
+	dc := &DCMetadata{
+		Title:      []string{"Skripsi tentang batik", "A thesis on batik"},
+		Creator:    []string{"Sari, Dewi"},
+		Type:       []string{"Thesis", "Text"},
+		Date:       []string{"2019-08-01"},
+		Identifier: []string{"https://doi.org/10.1234/abc", "ISBN 978-0-306-40615-7"},
+		Language:   []string{"ind"},
+	}
+
+	bib := dc.ToBibRecord()
+	if bib.Type != BibTypeThesis || bib.Title != "Skripsi tentang batik" || len(bib.AlternativeTitles) != 1 {
+		t.Errorf("Unexpected DC conversion %+v", bib)
+	}
+	if bib.IdentifierValue("doi") != "10.1234/abc" || bib.IdentifierValue("isbn") != "9780306406157" {
+		t.Errorf("Unexpected identifiers %+v", bib.Identifiers)
+	}
+	if bib.Year() != "2019" || len(bib.Links) != 1 || bib.Links[0].URL != "https://doi.org/10.1234/abc" {
+		t.Errorf("Unexpected year/links %q / %+v", bib.Year(), bib.Links)
+	}
+}
+
+func TestNewBibRecordFromFixtures(t *testing.T) {
+	fixtures := []struct {
+		path  string
+		parse func([]byte) (OAIResponse, error)
+	}{
+		{"testdata/listrecords_dc_page1.xml", func(data []byte) (OAIResponse, error) { return ParseOAIDCXML(data) }},
+		{"testdata/listrecords_qdc.xml", func(data []byte) (OAIResponse, error) { return ParseOAIQDCXML(data) }},
+		{"testdata/listrecords_mods.xml", func(data []byte) (OAIResponse, error) { return ParseOAIMODSXML(data) }},
+		{"testdata/listrecords_datacite.xml", func(data []byte) (OAIResponse, error) { return ParseOAIDataCiteXML(data) }},
+	}
+
+	for _, fixture := range fixtures {
+		data, err := os.ReadFile(fixture.path)
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		resp, err := fixture.parse(data)
+		if err != nil {
+			t.Fatalf("%s: parse failed: %v", fixture.path, err)
+		}
+
+		records := resp.GetRecords()
+		if len(records) == 0 {
+			t.Fatalf("%s: expected records", fixture.path)
+		}
+		for _, record := range records {
+			bib, ok := NewBibRecord(record)
+			if !ok {
+				t.Fatalf("%s: expected %s to convert", fixture.path, record.GetFormat())
+			}
+			if bib.Title == "" || bib.SourceFormat != record.GetFormat() {
+				t.Errorf("%s: unexpected record %+v", fixture.path, bib)
+			}
+		}
+	}
+}
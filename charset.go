@@ -0,0 +1,275 @@
+package goharvest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// windows1252High maps the bytes 0x80-0x9F of Windows-1252; the rest of the upper half
+// matches ISO-8859-1. Undefined bytes map to the C1 control of the same value.
+var windows1252High = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// latin9Changes lists the code points of ISO-8859-15 that differ from ISO-8859-1
+var latin9Changes = map[byte]rune{
+	0xA4: 0x20AC, 0xA6: 0x0160, 0xA8: 0x0161, 0xB4: 0x017D,
+	0xB8: 0x017E, 0xBC: 0x0152, 0xBD: 0x0153, 0xBE: 0x0178,
+}
+
+// charsetTable returns the decoding table of a single-byte charset, or nil for charsets
+// that are already UTF-8 compatible
+func charsetTable(charset string) (*[256]rune, error) {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return nil, nil
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "latin-1", "l1", "cp819",
+		"windows-1252", "cp1252", "x-cp1252":
+		// ISO-8859-1 is decoded as Windows-1252, as browsers do, because servers that
+		// declare it routinely send Windows-1252 punctuation
+		table := latin1Table()
+		for i, r := range windows1252High {
+			table[0x80+i] = r
+		}
+		return table, nil
+	case "iso-8859-15", "iso8859-15", "iso_8859-15", "latin-9", "latin9", "l9":
+		table := latin1Table()
+		for b, r := range latin9Changes {
+			table[b] = r
+		}
+		return table, nil
+	}
+	return nil, fmt.Errorf("unsupported charset %q", charset)
+}
+
+// latin1Table returns the identity mapping of bytes to code points
+func latin1Table() *[256]rune {
+	var table [256]rune
+	for i := range table {
+		table[i] = rune(i)
+	}
+	return &table
+}
+
+// CharsetReader converts input in the named charset to UTF-8. It supports UTF-8, US-ASCII,
+// ISO-8859-1, Windows-1252 and ISO-8859-15, and can be set as xml.Decoder.CharsetReader
+// when decoding harvested XML directly.
+func CharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	table, err := charsetTable(charset)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return input, nil
+	}
+	return &singleByteReader{src: bufio.NewReader(input), table: table}, nil
+}
+
+// singleByteReader decodes a single-byte charset to UTF-8
+type singleByteReader struct {
+	src   *bufio.Reader
+	table *[256]rune
+	// pending holds encoded bytes that did not fit into the last Read
+	pending []byte
+}
+
+// Read implements io.Reader
+func (r *singleByteReader) Read(p []byte) (int, error) {
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+
+	for n < len(p) {
+		b, err := r.src.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+
+		var buf [utf8.UTFMax]byte
+		size := utf8.EncodeRune(buf[:], r.table[b])
+		copied := copy(p[n:], buf[:size])
+		n += copied
+		if copied < size {
+			r.pending = append(r.pending[:0], buf[copied:size]...)
+		}
+	}
+	return n, nil
+}
+
+// xmlDeclarationEncoding matches the encoding pseudo-attribute of an XML declaration
+var xmlDeclarationEncoding = regexp.MustCompile(`encoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// decodeCharset converts a response body to UTF-8. The charset is taken from the XML
+// declaration, falling back to the Content-Type header; the declaration wins because
+// servers often send a default header that does not match the document. A converted
+// document's declaration is rewritten to UTF-8. With scrub set, characters that XML does
+// not allow are removed as well.
+func decodeCharset(body io.ReadCloser, contentType string, scrub bool) (io.ReadCloser, error) {
+	br := bufio.NewReader(body)
+	head, _ := br.Peek(1024)
+
+	// A UTF-8 byte order mark is valid but some servers add it to other encodings too
+	if bytes.HasPrefix(head, []byte("\xEF\xBB\xBF")) {
+		br.Discard(3)
+		head = head[3:]
+	}
+
+	var declaration []byte
+	charset := ""
+	if bytes.HasPrefix(head, []byte("<?xml")) {
+		if end := bytes.Index(head, []byte("?>")); end >= 0 {
+			declaration = head[:end+2]
+			if m := xmlDeclarationEncoding.FindSubmatch(declaration); m != nil {
+				charset = string(m[1])
+			}
+		}
+	}
+	if charset == "" {
+		if _, params, err := mime.ParseMediaType(contentType); err == nil {
+			charset = params["charset"]
+		}
+	}
+
+	table, err := charsetTable(charset)
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	var r io.Reader = br
+	if table != nil {
+		decoded := &singleByteReader{src: br, table: table}
+		if declaration != nil {
+			rewritten := xmlDeclarationEncoding.ReplaceAll(bytes.Clone(declaration), []byte(`encoding="UTF-8"`))
+			br.Discard(len(declaration))
+			r = io.MultiReader(bytes.NewReader(rewritten), decoded)
+		} else {
+			r = decoded
+		}
+	}
+	if scrub {
+		r = &scrubReader{src: r}
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, body}, nil
+}
+
+// isXMLChar reports whether r is allowed in an XML 1.0 document
+func isXMLChar(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\r' ||
+		(r >= 0x20 && r <= 0xD7FF) || (r >= 0xE000 && r <= 0xFFFD) || (r >= 0x10000 && r <= 0x10FFFF)
+}
+
+// maxCharRefLength is the length of the longest character reference, &#x10FFFF;
+const maxCharRefLength = 10
+
+// scrubReader removes characters that XML does not allow from a UTF-8 stream: control
+// characters, references to them such as &#x1;, and U+FFFE/U+FFFF. Invalid UTF-8
+// sequences are replaced with U+FFFD.
+type scrubReader struct {
+	src io.Reader
+	// in holds bytes not yet scrubbed, out scrubbed bytes not yet returned
+	in, out []byte
+	eof     bool
+}
+
+// Read implements io.Reader
+func (s *scrubReader) Read(p []byte) (int, error) {
+	for len(s.out) == 0 {
+		if s.eof && len(s.in) == 0 {
+			return 0, io.EOF
+		}
+		if !s.eof {
+			buf := make([]byte, 32*1024)
+			n, err := s.src.Read(buf)
+			s.in = append(s.in, buf[:n]...)
+			if err == io.EOF {
+				s.eof = true
+			} else if err != nil {
+				return 0, err
+			}
+		}
+		consumed := s.scrub()
+		s.in = s.in[consumed:]
+	}
+
+	n := copy(p, s.out)
+	s.out = s.out[n:]
+	return n, nil
+}
+
+// scrub appends the clean part of in to out and returns the number of bytes consumed.
+// Incomplete runes and character references at the end are kept until more input arrives.
+func (s *scrubReader) scrub() int {
+	in := s.in
+	i := 0
+	for i < len(in) {
+		b := in[i]
+
+		if b == '&' && i+1 < len(in) && in[i+1] == '#' {
+			end := bytes.IndexByte(in[i:min(len(in), i+maxCharRefLength)], ';')
+			if end < 0 && !s.eof && len(in)-i < maxCharRefLength {
+				return i
+			}
+			if end > 0 && !validCharRef(in[i+2:i+end]) {
+				i += end + 1
+				continue
+			}
+			s.out = append(s.out, b)
+			i++
+			continue
+		}
+		if b == '&' && i+1 == len(in) && !s.eof {
+			return i
+		}
+
+		if b < utf8.RuneSelf {
+			if isXMLChar(rune(b)) {
+				s.out = append(s.out, b)
+			}
+			i++
+			continue
+		}
+
+		if !utf8.FullRune(in[i:]) && !s.eof {
+			return i
+		}
+		r, size := utf8.DecodeRune(in[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			s.out = utf8.AppendRune(s.out, utf8.RuneError)
+		case isXMLChar(r):
+			s.out = append(s.out, in[i:i+size]...)
+		}
+		i += size
+	}
+	return i
+}
+
+// validCharRef reports whether the body of a character reference (after "&#") denotes
+// an allowed character. Malformed references are left for the XML parser to report.
+func validCharRef(ref []byte) bool {
+	var n uint64
+	var err error
+	if len(ref) > 0 && (ref[0] == 'x' || ref[0] == 'X') {
+		n, err = strconv.ParseUint(string(ref[1:]), 16, 32)
+	} else {
+		n, err = strconv.ParseUint(string(ref), 10, 32)
+	}
+	return err != nil || isXMLChar(rune(n))
+}
@@ -0,0 +1,145 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCharsetReader(t *testing.T) {
+	tests := []struct {
+		charset string
+		input   string
+		want    string
+	}{
+		{"ISO-8859-1", "Caf\xe9 \x93Perpustakaan\x94 \x80", "Café “Perpustakaan” €"},
+		{"windows-1252", "na\xefve \x96 \x85", "naïve – …"},
+		{"ISO-8859-15", "\xa4 \xbd", "€ œ"},
+		{"UTF-8", "Café", "Café"},
+		{"us-ascii", "plain", "plain"},
+	}
+
+	for _, tt := range tests {
+		r, err := CharsetReader(tt.charset, iotest.HalfReader(strings.NewReader(tt.input)))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.charset, err)
+		}
+		// One-byte reads force multi-byte runes to be split across calls
+		got, err := io.ReadAll(iotest.OneByteReader(r))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.charset, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.charset, got, tt.want)
+		}
+	}
+
+	if _, err := CharsetReader("EBCDIC", strings.NewReader("")); err == nil {
+		t.Error("expected error for unsupported charset")
+	}
+}
+
+func TestDecodeCharset(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+	}{
+		{
+			"declared latin1",
+			"<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<title>Caf\xe9</title>",
+			"text/xml; charset=UTF-8",
+			"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<title>Café</title>",
+		},
+		{
+			"header charset",
+			"<?xml version=\"1.0\"?><title>Caf\xe9</title>",
+			"text/xml; charset=windows-1252",
+			"<?xml version=\"1.0\"?><title>Café</title>",
+		},
+		{
+			"byte order mark",
+			"\xEF\xBB\xBF<?xml version='1.0' encoding='utf-8'?><title>Café</title>",
+			"",
+			"<?xml version='1.0' encoding='utf-8'?><title>Café</title>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := decodeCharset(io.NopCloser(strings.NewReader(tt.body)), tt.contentType, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := io.ReadAll(body)
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+
+			var title string
+			if err := xml.Unmarshal(got, &title); err != nil || title != "Café" {
+				t.Errorf("decoded document does not parse: %q, %v", title, err)
+			}
+		})
+	}
+
+	if _, err := decodeCharset(io.NopCloser(strings.NewReader(`<?xml version="1.0" encoding="Shift_JIS"?><a/>`)), "", false); err == nil {
+		t.Error("expected error for unsupported charset")
+	}
+}
+
+func TestScrubReader(t *testing.T) {
+	input := "<t>a\x01b&#x1;c&#12;d&#233;e&amp;f\xffg\x0bh&#x41;&#xFFFE;i\U0001F600</t>"
+	want := "<t>abcd&#233;e&amp;f�gh&#x41;i\U0001F600</t>"
+
+	got, err := io.ReadAll(&scrubReader{src: iotest.OneByteReader(strings.NewReader(input))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	var text string
+	if err := xml.Unmarshal(got, &text); err != nil {
+		t.Errorf("scrubbed document does not parse: %v", err)
+	}
+}
+
+func TestHarvestLatin1Repository(t *testing.T) {
+	page := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" + strings.Replace(
+		strings.Replace(schemaTestRecord, `<?xml version="1.0" encoding="UTF-8"?>`+"\n", "", 1),
+		"Sistem Informasi Perpustakaan", "Sistem Informasi Perpustakaan \x96 Caf\xe9\x01", 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	harvest := func(client *OAIClient) (string, error) {
+		var title string
+		err := client.HarvestWithOptions("oai_dc", nil, func(response OAIResponse) error {
+			title = response.(*OAIPMHResponseDC).ListRecords.Records[0].Metadata.DC.Title[0]
+			return nil
+		})
+		return title, err
+	}
+
+	if _, err := harvest(NewClient(server.URL)); err == nil {
+		t.Fatal("expected the control character to fail decoding without scrubbing")
+	}
+
+	title, err := harvest(NewClient(server.URL, WithScrubInvalidChars()))
+	if err != nil {
+		t.Fatalf("harvest failed: %v", err)
+	}
+	if title != "Sistem Informasi Perpustakaan – Café" {
+		t.Errorf("unexpected title %q", title)
+	}
+}
@@ -0,0 +1,223 @@
+package goharvest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HarvestState is the persisted progress of a harvest
+type HarvestState struct {
+	MetadataPrefix   string     `json:"metadata_prefix"`
+	Set              string     `json:"set,omitempty"`
+	DateRange        *DateRange `json:"date_range,omitempty"`
+	ResumptionToken  string     `json:"resumption_token"`
+	Cursor           int        `json:"cursor"`
+	CompleteListSize int        `json:"complete_list_size"`
+	PagesHarvested   int        `json:"pages_harvested"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// Checkpointer persists harvest state so interrupted harvests can be resumed
+type Checkpointer interface {
+	// Save stores the state after a page has been processed successfully
+	Save(state *HarvestState) error
+	// Load returns the stored state, or nil if there is none
+	Load() (*HarvestState, error)
+	// Clear removes the stored state once a harvest has completed
+	Clear() error
+}
+
+// ResumeHarvest harvests records like HarvestWithOptions, continuing from the state stored
+// in opts.Checkpointer when available. Progress is saved after each page and cleared when
+// the harvest completes. A stored state for another metadata prefix, set or date range is
+// an error.
+func (c *OAIClient) ResumeHarvest(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	if opts == nil || opts.Checkpointer == nil {
+		return fmt.Errorf("a checkpointer must be provided to resume a harvest")
+	}
+
+	state, err := opts.Checkpointer.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	resumeOpts := *opts
+	if state != nil && state.ResumptionToken != "" {
+		if state.MetadataPrefix != metadataPrefix {
+			return fmt.Errorf("checkpoint is for metadata prefix %s, not %s", state.MetadataPrefix, metadataPrefix)
+		}
+		// The token carries the arguments of the list it was issued for
+		if state.Set != opts.Set {
+			return fmt.Errorf("checkpoint is for set %q, not %q", state.Set, opts.Set)
+		}
+		if !sameDateRange(state.DateRange, opts.DateRange) {
+			return fmt.Errorf("checkpoint is for a different date range")
+		}
+		resumeOpts.resumptionToken = state.ResumptionToken
+		resumeOpts.pagesHarvested = state.PagesHarvested
+	}
+
+	return c.HarvestWithOptions(metadataPrefix, &resumeOpts, callback)
+}
+
+// sameDateRange reports whether two date ranges select the same records, nil selecting
+// all of them
+func sameDateRange(a, b *DateRange) bool {
+	if a == nil {
+		a = &DateRange{}
+	}
+	if b == nil {
+		b = &DateRange{}
+	}
+	return a.From == b.From && a.Until == b.Until && a.FromTime.Equal(b.FromTime) && a.UntilTime.Equal(b.UntilTime)
+}
+
+// saveCheckpoint stores the state of a harvest that continues with token after pages
+// pages, with the cursor of info if known
+func saveCheckpoint(metadataPrefix string, opts *HarvestOptions, token string, info *ResumptionToken, pages int) error {
+	state := &HarvestState{
+		MetadataPrefix:  metadataPrefix,
+		Set:             opts.Set,
+		DateRange:       opts.DateRange,
+		ResumptionToken: token,
+		PagesHarvested:  pages,
+		UpdatedAt:       time.Now().UTC(),
+	}
+
+	if info != nil {
+		state.Cursor = info.Cursor
+		state.CompleteListSize = info.CompleteListSize
+	}
+
+	if err := opts.Checkpointer.Save(state); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// FileCheckpointer stores harvest state as a JSON file
+type FileCheckpointer struct {
+	Path string
+}
+
+// NewFileCheckpointer creates a checkpointer that stores state in the file at path
+func NewFileCheckpointer(path string) *FileCheckpointer {
+	return &FileCheckpointer{Path: path}
+}
+
+// Save writes the state atomically to the checkpoint file
+func (f *FileCheckpointer) Save(state *HarvestState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// Load reads the state from the checkpoint file
+func (f *FileCheckpointer) Load() (*HarvestState, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state HarvestState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// Clear removes the checkpoint file
+func (f *FileCheckpointer) Clear() error {
+	err := os.Remove(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// SQLiteCheckpointer stores harvest state in a SQLite database.
+// The caller provides the *sql.DB, opened with a SQLite driver of their choice.
+type SQLiteCheckpointer struct {
+	DB  *sql.DB
+	Key string
+}
+
+// NewSQLiteCheckpointer creates a checkpointer storing state under key and
+// creates the harvest_checkpoints table if needed
+func NewSQLiteCheckpointer(db *sql.DB, key string) (*SQLiteCheckpointer, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS harvest_checkpoints (
+		key TEXT PRIMARY KEY,
+		state TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint table: %w", err)
+	}
+
+	return &SQLiteCheckpointer{DB: db, Key: key}, nil
+}
+
+// Save upserts the state row for the checkpointer's key
+func (s *SQLiteCheckpointer) Save(state *HarvestState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.Exec(`INSERT INTO harvest_checkpoints (key, state, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET state = excluded.state, updated_at = excluded.updated_at`,
+		s.Key, string(data), state.UpdatedAt)
+	return err
+}
+
+// Load reads the state row for the checkpointer's key
+func (s *SQLiteCheckpointer) Load() (*HarvestState, error) {
+	var data string
+	err := s.DB.QueryRow(`SELECT state FROM harvest_checkpoints WHERE key = ?`, s.Key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state HarvestState
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// Clear deletes the state row for the checkpointer's key
+func (s *SQLiteCheckpointer) Clear() error {
+	_, err := s.DB.Exec(`DELETE FROM harvest_checkpoints WHERE key = ?`, s.Key)
+	return err
+}
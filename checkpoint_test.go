@@ -0,0 +1,128 @@
+package goharvest
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileCheckpointer(t *testing.T) {
+	checkpointer := NewFileCheckpointer(filepath.Join(t.TempDir(), "harvest.json"))
+
+	state, err := checkpointer.Load()
+	if err != nil || state != nil {
+		t.Fatalf("Expected no state before first save, got %v, %v", state, err)
+	}
+
+	saved := &HarvestState{MetadataPrefix: "oai_dc", Set: "theses", ResumptionToken: "token-1", Cursor: 100}
+	if err := checkpointer.Save(saved); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	state, err = checkpointer.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if state.ResumptionToken != "token-1" || state.Cursor != 100 || state.Set != "theses" {
+		t.Errorf("Unexpected loaded state: %+v", state)
+	}
+
+	if err := checkpointer.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if err := checkpointer.Clear(); err != nil {
+		t.Fatalf("Clear on missing file failed: %v", err)
+	}
+}
+
+func TestResumeHarvest(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+	checkpointer := NewFileCheckpointer(filepath.Join(t.TempDir(), "harvest.json"))
+	opts := &HarvestOptions{Set: "theses", Checkpointer: checkpointer}
+
+	// Simulate a crash while processing the second page
+	crash := errors.New("process died")
+	pages := 0
+	err := client.ResumeHarvest("oai_dc", opts, func(OAIResponse) error {
+		pages++
+		if pages == 2 {
+			return crash
+		}
+		return nil
+	})
+	if !errors.Is(err, crash) {
+		t.Fatalf("Expected simulated crash, got %v", err)
+	}
+
+	state, err := checkpointer.Load()
+	if err != nil || state == nil {
+		t.Fatalf("Expected checkpoint after first page, got %v, %v", state, err)
+	}
+	if state.ResumptionToken != "dc-page-2" || state.CompleteListSize != 4 || state.PagesHarvested != 1 {
+		t.Errorf("Unexpected checkpoint state: %+v", state)
+	}
+
+	// Resume continues from the stored token without refetching the first page
+	recordCount := 0
+	err = client.ResumeHarvest("oai_dc", opts, func(response OAIResponse) error {
+		recordCount += len(response.GetRecords())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ResumeHarvest failed: %v", err)
+	}
+	if recordCount != 1 {
+		t.Errorf("Expected 1 record from the second page, got %d", recordCount)
+	}
+
+	queries := server.Queries()
+	if last := queries[len(queries)-1]; last.Get("resumptionToken") != "dc-page-2" {
+		t.Errorf("Expected resumed request with token dc-page-2, got %v", last)
+	}
+
+	state, err = checkpointer.Load()
+	if err != nil || state != nil {
+		t.Errorf("Expected checkpoint to be cleared after completion, got %v, %v", state, err)
+	}
+}
+
+func TestResumeHarvestMismatch(t *testing.T) {
+	checkpointer := NewFileCheckpointer(filepath.Join(t.TempDir(), "harvest.json"))
+	err := checkpointer.Save(&HarvestState{
+		MetadataPrefix:  "oai_dc",
+		Set:             "theses",
+		DateRange:       &DateRange{From: "2025-01-01"},
+		ResumptionToken: "dc-page-2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		opts   HarvestOptions
+	}{
+		{"prefix", "marcxml", HarvestOptions{Set: "theses", DateRange: &DateRange{From: "2025-01-01"}}},
+		{"set", "oai_dc", HarvestOptions{Set: "articles", DateRange: &DateRange{From: "2025-01-01"}}},
+		{"from", "oai_dc", HarvestOptions{Set: "theses", DateRange: &DateRange{From: "2025-02-01"}}},
+		{"until", "oai_dc", HarvestOptions{Set: "theses", DateRange: &DateRange{From: "2025-01-01", Until: "2025-03-01"}}},
+		{"no range", "oai_dc", HarvestOptions{Set: "theses"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The checkpoint is rejected before any request
+			client := NewClient("http://127.0.0.1:0/oai")
+			tt.opts.Checkpointer = checkpointer
+			err := client.ResumeHarvest(tt.prefix, &tt.opts, func(OAIResponse) error { return nil })
+			if err == nil || !strings.Contains(err.Error(), "checkpoint is for") {
+				t.Errorf("Expected the checkpoint to be rejected, got %v", err)
+			}
+		})
+	}
+}
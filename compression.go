@@ -0,0 +1,108 @@
+package goharvest
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// DefaultCompression lists the content codings NewClient requests
+var DefaultCompression = []string{"gzip", "deflate"}
+
+// WithCompression sets the content codings requested with Accept-Encoding, in order of
+// preference. Only gzip and deflate are decoded; call it without arguments to request
+// uncompressed responses.
+func WithCompression(encodings ...string) ClientOption {
+	return func(c *OAIClient) {
+		c.Compression = append([]string{}, encodings...)
+	}
+}
+
+// NegotiateCompression limits the client's compression to the encodings the repository
+// advertises in its Identify response, which is how OAI-PMH announces support. Without
+// advertised encodings the client requests uncompressed responses.
+func (c *OAIClient) NegotiateCompression(ctx context.Context) error {
+	identify, err := c.Identify(ctx)
+	if err != nil {
+		return err
+	}
+
+	var encodings []string
+	for _, encoding := range identify.Compression {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if (encoding == "gzip" || encoding == "deflate") && !slices.Contains(encodings, encoding) {
+			encodings = append(encodings, encoding)
+		}
+	}
+	c.Compression = encodings
+	if encodings == nil {
+		c.Compression = []string{}
+	}
+	return nil
+}
+
+// acceptEncoding returns the Accept-Encoding header for the client's compression, or ""
+// to leave it to the HTTP transport
+func (c *OAIClient) acceptEncoding() string {
+	if c.Compression == nil {
+		return ""
+	}
+	if len(c.Compression) == 0 {
+		return "identity"
+	}
+	return strings.Join(c.Compression, ", ")
+}
+
+// decompressResponse replaces the body of a response sent with a gzip or deflate
+// Content-Encoding by its decompressed form
+func decompressResponse(resp *http.Response) error {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "" || encoding == "identity" {
+		return nil
+	}
+
+	var body io.Reader
+	switch encoding {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return fmt.Errorf("failed to decompress response: %w", err)
+		}
+		body = gz
+	case "deflate":
+		// HTTP deflate is zlib-wrapped, but some servers send raw deflate data
+		br := bufio.NewReader(resp.Body)
+		header, _ := br.Peek(2)
+		if len(header) == 2 && header[0]&0x0F == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				resp.Body.Close()
+				return fmt.Errorf("failed to decompress response: %w", err)
+			}
+			body = zr
+		} else {
+			body = flate.NewReader(br)
+		}
+	default:
+		resp.Body.Close()
+		return fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
@@ -0,0 +1,119 @@
+package goharvest
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// compressingServer serves a fixture with the Content-Encoding chosen by encode
+func compressingServer(t *testing.T, file, encoding string, encode func(io.Writer) io.WriteCloser) (*httptest.Server, func() []string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var accepted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		accepted = append(accepted, r.Header.Get("Accept-Encoding"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		if encode == nil {
+			w.Write(data)
+			return
+		}
+		w.Header().Set("Content-Encoding", encoding)
+		zw := encode(w)
+		zw.Write(data)
+		zw.Close()
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), accepted...)
+	}
+}
+
+func TestCompressedResponses(t *testing.T) {
+	tests := []struct {
+		encoding string
+		encode   func(io.Writer) io.WriteCloser
+	}{
+		{"gzip", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }},
+		{"deflate", func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }},
+		{"deflate", func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		}},
+	}
+
+	for _, tt := range tests {
+		server, accepted := compressingServer(t, "listrecords_dc_page1.xml", tt.encoding, tt.encode)
+
+		count := 0
+		err := NewClient(server.URL).HarvestWithOptions("oai_dc", nil, func(response OAIResponse) error {
+			count += len(response.GetRecords())
+			return ErrStopHarvest
+		})
+		if err != nil {
+			t.Fatalf("%s: harvest failed: %v", tt.encoding, err)
+		}
+		if count == 0 {
+			t.Errorf("%s: no records decoded", tt.encoding)
+		}
+		if got := accepted(); got[0] != "gzip, deflate" {
+			t.Errorf("%s: unexpected Accept-Encoding %q", tt.encoding, got[0])
+		}
+	}
+}
+
+func TestCompressionLimitsApplyAfterDecompression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte("<OAI-PMH>"))
+		zw.Write(bytes.Repeat([]byte(" "), 1<<20))
+		zw.Close()
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, WithMaxResponseSize(64<<10)).Identify(context.Background())
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge, got %v", err)
+	}
+}
+
+func TestNegotiateCompression(t *testing.T) {
+	server, accepted := compressingServer(t, "identify_response.xml", "", nil)
+
+	client := NewClient(server.URL, WithCompression())
+	if err := client.NegotiateCompression(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.Compression) != 1 || client.Compression[0] != "gzip" {
+		t.Errorf("expected the advertised gzip, got %v", client.Compression)
+	}
+	if _, err := client.Identify(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := accepted(); got[0] != "identity" || got[1] != "gzip" {
+		t.Errorf("unexpected Accept-Encoding headers %q", got)
+	}
+}
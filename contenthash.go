@@ -0,0 +1,124 @@
+package goharvest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// HashStore keeps the content hash of each record delivered downstream, so records that
+// did not change can be skipped by the next harvest
+type HashStore interface {
+	// Hash returns the stored hash of the record, or "" if there is none
+	Hash(identifier string) (string, error)
+	// SetHash stores the hash of a record once it has been delivered
+	SetHash(identifier, hash string) error
+}
+
+// ContentHash returns a stable hash of the raw XML metadata of a record. The XML is
+// normalized first, so namespace prefixes, attribute order, whitespace between elements,
+// comments and the encoding of characters do not change the hash. Metadata that is not
+// well-formed XML is hashed as is.
+func ContentHash(raw []byte) string {
+	h := sha256.New()
+	if err := writeCanonicalXML(h, raw); err != nil {
+		h.Reset()
+		h.Write(raw)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordHash returns the content hash of a record; deleted records share one hash
+func recordHash(record HarvestedRecord) string {
+	if record.IsDeleted() {
+		return deletedRecordHash
+	}
+	return ContentHash(record.Raw)
+}
+
+// deletedRecordHash is the content hash of every deleted record
+var deletedRecordHash = func() string {
+	sum := sha256.Sum256([]byte("deleted"))
+	return hex.EncodeToString(sum[:])
+}()
+
+// writeCanonicalXML writes a normalized form of the XML in data to h: elements and
+// attributes by namespace URI and local name, attributes sorted, namespace declarations
+// dropped and text trimmed
+func writeCanonicalXML(h hash.Hash, data []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = true
+
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			attrs := make([]string, 0, len(t.Attr))
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+					continue
+				}
+				attrs = append(attrs, attr.Name.Space+" "+attr.Name.Local+"="+attr.Value)
+			}
+			slices.Sort(attrs)
+			fmt.Fprintf(h, "<%s %s\x00%s\x00", t.Name.Space, t.Name.Local, strings.Join(attrs, "\x00"))
+		case xml.EndElement:
+			h.Write([]byte(">\x00"))
+		case xml.CharData:
+			if text := bytes.TrimSpace(t); len(text) > 0 {
+				h.Write([]byte("\"\x00"))
+				h.Write(text)
+				h.Write([]byte{0})
+			}
+		}
+	}
+}
+
+// MemoryHashStore is a HashStore kept in memory, e.g. for a long-running process or to
+// be filled from and saved to a database in bulk
+type MemoryHashStore struct {
+	mu     sync.RWMutex
+	hashes map[string]string
+}
+
+// NewMemoryHashStore creates an empty MemoryHashStore
+func NewMemoryHashStore() *MemoryHashStore {
+	return &MemoryHashStore{hashes: make(map[string]string)}
+}
+
+// Hash implements HashStore
+func (s *MemoryHashStore) Hash(identifier string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hashes[identifier], nil
+}
+
+// SetHash implements HashStore
+func (s *MemoryHashStore) SetHash(identifier, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hashes[identifier] = hash
+	return nil
+}
+
+// Len returns the number of stored hashes
+func (s *MemoryHashStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.hashes)
+}
@@ -0,0 +1,101 @@
+package goharvest
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestContentHash(t *testing.T) {
+	base := ContentHash([]byte(`<oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title xml:lang="id" type="main">Naskah Kuno</dc:title></oai_dc:dc>`))
+
+	equivalent := []string{
+		// Other prefixes, attribute order and indentation
+		`<d:dc xmlns:d="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:e="http://purl.org/dc/elements/1.1/">
+		  <e:title type="main" xml:lang="id">Naskah Kuno</e:title>
+		</d:dc>`,
+		// Character references and comments
+		`<oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/"><!-- exported --><dc:title xml:lang="id" type="main">Naskah &#75;uno</dc:title></oai_dc:dc>`,
+	}
+	for _, xml := range equivalent {
+		if got := ContentHash([]byte(xml)); got != base {
+			t.Errorf("Expected the same hash for %s", xml)
+		}
+	}
+
+	different := []string{
+		`<oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title xml:lang="id" type="main">Naskah Baru</dc:title></oai_dc:dc>`,
+		`<oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title xml:lang="en" type="main">Naskah Kuno</dc:title></oai_dc:dc>`,
+		`<oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://example.org/"><dc:title xml:lang="id" type="main">Naskah Kuno</dc:title></oai_dc:dc>`,
+	}
+	for _, xml := range different {
+		if got := ContentHash([]byte(xml)); got == base {
+			t.Errorf("Expected a different hash for %s", xml)
+		}
+	}
+
+	malformed := []byte(`<dc><title>unclosed</dc>`)
+	if ContentHash(malformed) != ContentHash(malformed) || ContentHash(malformed) == ContentHash([]byte(`<dc><title>unclosed</title></dc>`)) {
+		t.Error("Expected malformed metadata to be hashed as is")
+	}
+}
+
+func TestHarvestSkipUnchanged(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+	store := NewMemoryHashStore()
+
+	failure := errors.New("index unavailable")
+	err := client.HarvestRecords("oai_dc", &HarvestOptions{SkipUnchanged: store}, func(record HarvestedRecord) error {
+		if record.Hash == "" {
+			t.Errorf("Expected a hash for %s", record.Header.Identifier)
+		}
+		if record.Header.Identifier == "oai:example.org:5" {
+			return failure
+		}
+		return nil
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the callback error, got %v", err)
+	}
+	if store.Len() != 3 {
+		t.Errorf("Expected the hashes of the 3 accepted records, got %d", store.Len())
+	}
+
+	var stats HarvestStats
+	var delivered []string
+	err = client.HarvestRecords("oai_dc", &HarvestOptions{SkipUnchanged: store, Stats: &stats}, func(record HarvestedRecord) error {
+		delivered = append(delivered, record.Header.Identifier)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delivered) != 1 || delivered[0] != "oai:example.org:5" || stats.UnchangedRecords != 3 {
+		t.Errorf("Expected only the record not stored before, got %v and %+v", delivered, stats)
+	}
+}
+
+func TestHarvestContentHash(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+
+	hashes := map[string]string{}
+	err := NewClient(server.URL).HarvestRecords("oai_dc", &HarvestOptions{ContentHash: true}, func(record HarvestedRecord) error {
+		hashes[record.Header.Identifier] = record.Hash
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashes["oai:example.org:2"] != deletedRecordHash {
+		t.Errorf("Expected the deleted record hash, got %q", hashes["oai:example.org:2"])
+	}
+	if len(hashes["oai:example.org:1"]) != 64 || hashes["oai:example.org:1"] == hashes["oai:example.org:4"] {
+		t.Errorf("Unexpected hashes %v", hashes)
+	}
+}
@@ -0,0 +1,464 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DataCite represents a DataCite Metadata Schema resource (kernel-3 and kernel-4)
+type DataCite struct {
+	XMLName              xml.Name                    `xml:"resource"`
+	Identifier           DataCiteIdentifier          `xml:"identifier"`
+	Creators             []DataCiteCreator           `xml:"creators>creator"`
+	Titles               []DataCiteTitle             `xml:"titles>title"`
+	Publisher            string                      `xml:"publisher"`
+	PublicationYear      string                      `xml:"publicationYear"`
+	ResourceType         DataCiteResourceType        `xml:"resourceType"`
+	Subjects             []DataCiteSubject           `xml:"subjects>subject"`
+	Contributors         []DataCiteContributor       `xml:"contributors>contributor"`
+	Dates                []DataCiteDate              `xml:"dates>date"`
+	Language             string                      `xml:"language"`
+	AlternateIdentifiers []DataCiteIdentifier        `xml:"alternateIdentifiers>alternateIdentifier"`
+	RelatedIdentifiers   []DataCiteRelatedIdentifier `xml:"relatedIdentifiers>relatedIdentifier"`
+	Sizes                []string                    `xml:"sizes>size"`
+	Formats              []string                    `xml:"formats>format"`
+	Version              string                      `xml:"version"`
+	RightsList           []DataCiteRights            `xml:"rightsList>rights"`
+	Descriptions         []DataCiteDescription       `xml:"descriptions>description"`
+	GeoLocations         []DataCiteGeoLocation       `xml:"geoLocations>geoLocation"`
+	FundingReferences    []DataCiteFundingReference  `xml:"fundingReferences>fundingReference"`
+}
+
+// DataCiteEnvelope represents the oai_datacite wrapper around a DataCite resource
+type DataCiteEnvelope struct {
+	SchemaVersion    string    `xml:"schemaVersion"`
+	DatacentreSymbol string    `xml:"datacentreSymbol"`
+	Resource         *DataCite `xml:"payload>resource"`
+}
+
+// DataCiteIdentifier represents an identifier or alternateIdentifier element
+type DataCiteIdentifier struct {
+	Type  string `xml:"identifierType,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// DataCiteNameIdentifier represents a nameIdentifier element (ORCID, ISNI, ROR)
+type DataCiteNameIdentifier struct {
+	Scheme    string `xml:"nameIdentifierScheme,attr,omitempty"`
+	SchemeURI string `xml:"schemeURI,attr,omitempty"`
+	Value     string `xml:",chardata"`
+}
+
+// DataCiteCreator represents a creator element
+type DataCiteCreator struct {
+	Name            DataCiteName             `xml:"creatorName"`
+	GivenName       string                   `xml:"givenName"`
+	FamilyName      string                   `xml:"familyName"`
+	NameIdentifiers []DataCiteNameIdentifier `xml:"nameIdentifier"`
+	Affiliations    []string                 `xml:"affiliation"`
+}
+
+// DataCiteContributor represents a contributor element
+type DataCiteContributor struct {
+	Type            string                   `xml:"contributorType,attr,omitempty"`
+	Name            DataCiteName             `xml:"contributorName"`
+	GivenName       string                   `xml:"givenName"`
+	FamilyName      string                   `xml:"familyName"`
+	NameIdentifiers []DataCiteNameIdentifier `xml:"nameIdentifier"`
+	Affiliations    []string                 `xml:"affiliation"`
+}
+
+// DataCiteName represents a creatorName or contributorName element
+type DataCiteName struct {
+	Type  string `xml:"nameType,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// DataCiteTitle represents a title element with its optional titleType
+type DataCiteTitle struct {
+	Type  string `xml:"titleType,attr,omitempty"`
+	Lang  string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// DataCiteResourceType represents the resourceType element
+type DataCiteResourceType struct {
+	General string `xml:"resourceTypeGeneral,attr,omitempty"`
+	Value   string `xml:",chardata"`
+}
+
+// DataCiteSubject represents a subject element
+type DataCiteSubject struct {
+	Scheme string `xml:"subjectScheme,attr,omitempty"`
+	Value  string `xml:",chardata"`
+}
+
+// DataCiteDate represents a date element with its dateType
+type DataCiteDate struct {
+	Type  string `xml:"dateType,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// DataCiteRelatedIdentifier represents a relatedIdentifier element
+type DataCiteRelatedIdentifier struct {
+	Type         string `xml:"relatedIdentifierType,attr,omitempty"`
+	RelationType string `xml:"relationType,attr,omitempty"`
+	Value        string `xml:",chardata"`
+}
+
+// DataCiteRights represents a rights element
+type DataCiteRights struct {
+	URI   string `xml:"rightsURI,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// DataCiteDescription represents a description element with its descriptionType
+type DataCiteDescription struct {
+	Type  string `xml:"descriptionType,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// DataCiteGeoLocation represents a geoLocation element
+type DataCiteGeoLocation struct {
+	Place string            `xml:"geoLocationPlace"`
+	Point *DataCiteGeoPoint `xml:"geoLocationPoint,omitempty"`
+	Box   *DataCiteGeoBox   `xml:"geoLocationBox,omitempty"`
+}
+
+// DataCiteGeoPoint represents a geoLocationPoint element
+type DataCiteGeoPoint struct {
+	Longitude string `xml:"pointLongitude"`
+	Latitude  string `xml:"pointLatitude"`
+}
+
+// DataCiteGeoBox represents a geoLocationBox element
+type DataCiteGeoBox struct {
+	WestLongitude string `xml:"westBoundLongitude"`
+	EastLongitude string `xml:"eastBoundLongitude"`
+	SouthLatitude string `xml:"southBoundLatitude"`
+	NorthLatitude string `xml:"northBoundLatitude"`
+}
+
+// DataCiteFundingReference represents a fundingReference element
+type DataCiteFundingReference struct {
+	FunderName       string             `xml:"funderName"`
+	FunderIdentifier DataCiteIdentifier `xml:"funderIdentifier"`
+	AwardNumber      string             `xml:"awardNumber"`
+	AwardTitle       string             `xml:"awardTitle"`
+}
+
+// ORCID returns the ORCID iD of a creator, if present
+func (c DataCiteCreator) ORCID() string {
+	return findORCID(c.NameIdentifiers)
+}
+
+// findORCID returns the first ORCID value from a list of name identifiers
+func findORCID(ids []DataCiteNameIdentifier) string {
+	for _, id := range ids {
+		if strings.EqualFold(id.Scheme, "ORCID") {
+			value := strings.TrimSpace(id.Value)
+			value = strings.TrimPrefix(value, "https://orcid.org/")
+			return strings.TrimPrefix(value, "http://orcid.org/")
+		}
+	}
+	return ""
+}
+
+// resource returns the DataCite resource from either the bare or oai_datacite form
+func (m MetadataDataCite) resource() *DataCite {
+	if m.DataCite != nil {
+		return m.DataCite
+	}
+	if m.Envelope != nil {
+		return m.Envelope.Resource
+	}
+	return nil
+}
+
+// MetadataDataCite represents the metadata wrapper for DataCite
+type MetadataDataCite struct {
+	DataCite *DataCite         `xml:"resource,omitempty"`
+	Envelope *DataCiteEnvelope `xml:"oai_datacite,omitempty"`
+	Raw      []byte            `xml:",innerxml"`
+}
+
+// RecordDataCite represents an OAI-PMH record with DataCite metadata
+type RecordDataCite struct {
+	Header   Header           `xml:"header"`
+	Metadata MetadataDataCite `xml:"metadata"`
+	About    *About           `xml:"about,omitempty"`
+}
+
+// IsDeleted returns true if the record has been deleted from the repository
+func (r RecordDataCite) IsDeleted() bool {
+	return r.Header.IsDeleted()
+}
+
+// ListRecordsDataCite contains the list of DataCite records from ListRecords verb
+type ListRecordsDataCite struct {
+	Records         []RecordDataCite `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// GetRecordDataCite contains a single DataCite record from GetRecord verb
+type GetRecordDataCite struct {
+	Record RecordDataCite `xml:"record"`
+}
+
+// OAIPMHResponseDataCite represents the OAI-PMH response with DataCite metadata
+type OAIPMHResponseDataCite struct {
+	XMLName      xml.Name             `xml:"OAI-PMH"`
+	ResponseDate string               `xml:"responseDate"`
+	Request      OAIRequest           `xml:"request"`
+	ListRecords  *ListRecordsDataCite `xml:"ListRecords,omitempty"`
+	GetRecord    *GetRecordDataCite   `xml:"GetRecord,omitempty"`
+	Error        *OAIError            `xml:"error,omitempty"`
+}
+
+// DataCiteMetadata represents extracted DataCite metadata
+type DataCiteMetadata struct {
+	DOI                  string                      `json:"doi"`
+	Title                string                      `json:"title"`
+	AlternativeTitles    []string                    `json:"alternative_titles"`
+	Creators             []DataCiteCreatorEntry      `json:"creators"`
+	Publisher            string                      `json:"publisher"`
+	PublicationYear      string                      `json:"publication_year"`
+	ResourceType         string                      `json:"resource_type"`
+	ResourceTypeGeneral  string                      `json:"resource_type_general"`
+	Subjects             []string                    `json:"subjects"`
+	Dates                []DataCiteDate              `json:"dates"`
+	Language             string                      `json:"language"`
+	Version              string                      `json:"version"`
+	Rights               []string                    `json:"rights"`
+	Abstract             string                      `json:"abstract"`
+	Descriptions         []string                    `json:"descriptions"`
+	AlternateIdentifiers []DataCiteIdentifier        `json:"alternate_identifiers"`
+	RelatedIdentifiers   []DataCiteRelatedIdentifier `json:"related_identifiers"`
+	FundingReferences    []DataCiteFundingReference  `json:"funding_references"`
+	GeoLocations         []DataCiteGeoLocation       `json:"geo_locations"`
+}
+
+// DataCiteCreatorEntry is an extracted DataCite creator with its ORCID
+type DataCiteCreatorEntry struct {
+	Name         string   `json:"name"`
+	GivenName    string   `json:"given_name"`
+	FamilyName   string   `json:"family_name"`
+	ORCID        string   `json:"orcid"`
+	Affiliations []string `json:"affiliations"`
+}
+
+// ExtractDataCiteMetadata extracts key fields from a DataCite resource
+func (d *DataCite) ExtractDataCiteMetadata() *DataCiteMetadata {
+	if d == nil {
+		return nil
+	}
+
+	metadata := &DataCiteMetadata{
+		Publisher:            strings.TrimSpace(d.Publisher),
+		PublicationYear:      strings.TrimSpace(d.PublicationYear),
+		ResourceType:         strings.TrimSpace(d.ResourceType.Value),
+		ResourceTypeGeneral:  d.ResourceType.General,
+		AlternativeTitles:    []string{},
+		Creators:             []DataCiteCreatorEntry{},
+		Subjects:             []string{},
+		Dates:                d.Dates,
+		Language:             strings.TrimSpace(d.Language),
+		Version:              strings.TrimSpace(d.Version),
+		Rights:               []string{},
+		Descriptions:         []string{},
+		AlternateIdentifiers: d.AlternateIdentifiers,
+		RelatedIdentifiers:   d.RelatedIdentifiers,
+		FundingReferences:    d.FundingReferences,
+		GeoLocations:         d.GeoLocations,
+	}
+
+	if strings.EqualFold(d.Identifier.Type, "DOI") {
+		metadata.DOI = strings.TrimSpace(d.Identifier.Value)
+	}
+
+	// The first title without titleType is the main title
+	for _, title := range d.Titles {
+		value := strings.TrimSpace(title.Value)
+		if title.Type == "" && metadata.Title == "" {
+			metadata.Title = value
+			continue
+		}
+		if value != "" {
+			metadata.AlternativeTitles = append(metadata.AlternativeTitles, value)
+		}
+	}
+
+	for _, creator := range d.Creators {
+		entry := DataCiteCreatorEntry{
+			Name:         strings.TrimSpace(creator.Name.Value),
+			GivenName:    strings.TrimSpace(creator.GivenName),
+			FamilyName:   strings.TrimSpace(creator.FamilyName),
+			ORCID:        creator.ORCID(),
+			Affiliations: deduplicate(creator.Affiliations),
+		}
+		if entry.Name != "" {
+			metadata.Creators = append(metadata.Creators, entry)
+		}
+	}
+
+	for _, subject := range d.Subjects {
+		metadata.Subjects = append(metadata.Subjects, strings.TrimSpace(subject.Value))
+	}
+	metadata.Subjects = deduplicate(metadata.Subjects)
+
+	for _, rights := range d.RightsList {
+		if value := strings.TrimSpace(rights.Value); value != "" {
+			metadata.Rights = append(metadata.Rights, value)
+		} else if rights.URI != "" {
+			metadata.Rights = append(metadata.Rights, rights.URI)
+		}
+	}
+
+	for _, description := range d.Descriptions {
+		value := strings.TrimSpace(description.Value)
+		if value == "" {
+			continue
+		}
+		if description.Type == "Abstract" && metadata.Abstract == "" {
+			metadata.Abstract = value
+			continue
+		}
+		metadata.Descriptions = append(metadata.Descriptions, value)
+	}
+
+	return metadata
+}
+
+// ExtractAllDataCiteMetadata extracts metadata from all DataCite records in OAI-PMH response
+func (o *OAIPMHResponseDataCite) ExtractAllDataCiteMetadata() []*DataCiteMetadata {
+	var results []*DataCiteMetadata
+
+	for _, extractor := range o.GetRecords() {
+		if metadata := extractor.(*DataCite).ExtractDataCiteMetadata(); metadata != nil {
+			results = append(results, metadata)
+		}
+	}
+
+	return results
+}
+
+// ParseOAIDataCiteXML parses OAI-PMH XML data with DataCite metadata from bytes
+func ParseOAIDataCiteXML(data []byte) (*OAIPMHResponseDataCite, error) {
+	var oaiResp OAIPMHResponseDataCite
+	if err := xml.Unmarshal(data, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, oaiResp.Error
+	}
+
+	return &oaiResp, nil
+}
+
+// decodeOAIPMHResponseDataCite stream-decodes a DataCite ListRecords or GetRecord response
+func decodeOAIPMHResponseDataCite(r io.Reader, page decodeOptions) (*OAIPMHResponseDataCite, error) {
+	env, records, err := decodeResponse(r, page, decodeRecord[RecordDataCite])
+	if err != nil {
+		return nil, err
+	}
+
+	oaiResp := &OAIPMHResponseDataCite{
+		XMLName:      xml.Name{Local: "OAI-PMH"},
+		ResponseDate: env.ResponseDate,
+		Request:      env.Request,
+	}
+
+	switch env.Verb {
+	case "ListRecords":
+		oaiResp.ListRecords = &ListRecordsDataCite{Records: records, ResumptionToken: env.ResumptionToken}
+	case "GetRecord":
+		if len(records) > 0 {
+			oaiResp.GetRecord = &GetRecordDataCite{Record: records[0]}
+		}
+	}
+
+	return oaiResp, nil
+}
+
+// Implement OAIResponse interface for OAIPMHResponseDataCite
+
+// GetRecords returns all records in the response as MetadataExtractor interface
+func (o *OAIPMHResponseDataCite) GetRecords() []MetadataExtractor {
+	var extractors []MetadataExtractor
+
+	for _, record := range o.harvestedRecords() {
+		if record.Metadata != nil {
+			extractors = append(extractors, record.Metadata)
+		}
+	}
+
+	return extractors
+}
+
+// GetResumptionToken returns the resumption token if available
+func (o *OAIPMHResponseDataCite) GetResumptionToken() string {
+	if o.ListRecords != nil && o.ListRecords.ResumptionToken != nil {
+		return o.ListRecords.ResumptionToken.Token
+	}
+	return ""
+}
+
+// GetResumptionTokenInfo returns the full resumption token if available
+func (o *OAIPMHResponseDataCite) GetResumptionTokenInfo() *ResumptionToken {
+	if o.ListRecords != nil {
+		return o.ListRecords.ResumptionToken
+	}
+	return nil
+}
+
+// HasError returns true if the response contains an error
+func (o *OAIPMHResponseDataCite) HasError() bool {
+	return o.Error != nil
+}
+
+// GetError returns the error information
+func (o *OAIPMHResponseDataCite) GetError() *OAIError {
+	return o.Error
+}
+
+// harvestedRecords returns all records in the response with their headers
+func (o *OAIPMHResponseDataCite) harvestedRecords() []HarvestedRecord {
+	var records []RecordDataCite
+	if o.ListRecords != nil {
+		records = o.ListRecords.Records
+	}
+	if o.GetRecord != nil {
+		records = append(records, o.GetRecord.Record)
+	}
+
+	results := make([]HarvestedRecord, 0, len(records))
+	for _, record := range records {
+		harvested := HarvestedRecord{Header: record.Header, Raw: record.Metadata.Raw, About: record.About}
+		if resource := record.Metadata.resource(); resource != nil {
+			harvested.Metadata = resource
+		}
+		results = append(results, harvested)
+	}
+
+	return results
+}
+
+// responseDate returns the responseDate of the response
+func (o *OAIPMHResponseDataCite) responseDate() string {
+	return o.ResponseDate
+}
+
+// Implement MetadataExtractor interface for DataCite
+
+// ExtractMetadata extracts metadata from DataCite record
+func (m *DataCite) ExtractMetadata() interface{} {
+	return m.ExtractDataCiteMetadata()
+}
+
+// GetFormat returns the metadata format type
+func (m *DataCite) GetFormat() MetadataFormat {
+	return FormatDataCite
+}
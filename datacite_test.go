@@ -0,0 +1,99 @@
+package goharvest
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestParseOAIDataCiteXML(t *testing.T) {
+	data, err := os.ReadFile("testdata/listrecords_datacite.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAIDataCiteXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIDataCiteXML failed: %v", err)
+	}
+
+	records := resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if records[0].GetFormat() != FormatDataCite {
+		t.Errorf("Expected format oai_datacite, got %s", records[0].GetFormat())
+	}
+
+	metadata, ok := records[0].ExtractMetadata().(*DataCiteMetadata)
+	if !ok {
+		t.Fatalf("Expected *DataCiteMetadata, got %T", records[0].ExtractMetadata())
+	}
+
+	if metadata.DOI != "10.5281/example.30" {
+		t.Errorf("Unexpected DOI '%s'", metadata.DOI)
+	}
+	if metadata.Title != "Daily rainfall observations for Central Java" || len(metadata.AlternativeTitles) != 1 {
+		t.Errorf("Unexpected titles '%s' / %v", metadata.Title, metadata.AlternativeTitles)
+	}
+	if len(metadata.Creators) != 2 || metadata.Creators[0].ORCID != "0000-0002-1825-0097" || metadata.Creators[1].ORCID != "" {
+		t.Errorf("Unexpected creators %+v", metadata.Creators)
+	}
+	if metadata.ResourceTypeGeneral != "Dataset" || metadata.PublicationYear != "2023" {
+		t.Errorf("Unexpected resource type/year %s / %s", metadata.ResourceTypeGeneral, metadata.PublicationYear)
+	}
+	if len(metadata.RelatedIdentifiers) != 1 || metadata.RelatedIdentifiers[0].RelationType != "IsSupplementTo" {
+		t.Errorf("Unexpected related identifiers %+v", metadata.RelatedIdentifiers)
+	}
+	if len(metadata.FundingReferences) != 1 || metadata.FundingReferences[0].AwardNumber != "ESF-2019-42" {
+		t.Errorf("Unexpected funding references %+v", metadata.FundingReferences)
+	}
+	if len(metadata.GeoLocations) != 1 || metadata.GeoLocations[0].Point == nil || metadata.GeoLocations[0].Point.Latitude != "-7.2" {
+		t.Errorf("Unexpected geo locations %+v", metadata.GeoLocations)
+	}
+	if metadata.Abstract != "Station-level daily rainfall totals." || len(metadata.Descriptions) != 1 {
+		t.Errorf("Unexpected descriptions '%s' / %v", metadata.Abstract, metadata.Descriptions)
+	}
+	if len(metadata.Rights) != 1 || len(metadata.Dates) != 2 {
+		t.Errorf("Unexpected rights/dates %v / %v", metadata.Rights, metadata.Dates)
+	}
+}
+
+func TestUnifiedHarvestDataCite(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_datacite.xml"})
+	client := NewClient(server.URL)
+
+	count := 0
+	err := client.Harvest("oai_datacite", nil, func(response OAIResponse) error {
+		for _, record := range response.GetRecords() {
+			if _, ok := record.ExtractMetadata().(*DataCiteMetadata); ok {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 DataCite record, got %d", count)
+	}
+}
+
+func TestGetRecordDataCiteKernel(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"GetRecord": "getrecord_datacite.xml"})
+
+	resp, err := NewClient(server.URL).GetRecord(context.Background(), "oai:example.org:31", "datacite")
+	if err != nil {
+		t.Fatalf("GetRecord datacite failed: %v", err)
+	}
+
+	records := resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	metadata, ok := records[0].ExtractMetadata().(*DataCiteMetadata)
+	if !ok || metadata.DOI != "10.5281/example.31" || metadata.Title != "Soil samples from Merapi slopes" {
+		t.Errorf("Unexpected DataCite metadata: %+v", records[0].ExtractMetadata())
+	}
+}
@@ -0,0 +1,145 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidDateRange is returned for DateRange values that a repository would reject with
+// badArgument
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// Layouts of the two OAI-PMH datestamp granularities
+const (
+	dayLayout    = "2006-01-02"
+	secondLayout = "2006-01-02T15:04:05Z"
+)
+
+// FormatDatestamp formats t in UTC with the given granularity, GranularityDay or
+// GranularitySecond (the default)
+func FormatDatestamp(t time.Time, granularity string) string {
+	if granularity == GranularityDay {
+		return t.UTC().Format(dayLayout)
+	}
+	return t.UTC().Format(secondLayout)
+}
+
+// WithGranularity sets the repository's datestamp granularity, so that date ranges can be
+// formatted without an Identify request
+func WithGranularity(granularity string) ClientOption {
+	return func(c *OAIClient) {
+		c.Granularity = granularity
+	}
+}
+
+// parseDatestamp parses a from or until value. Day values report day granularity; times
+// in RFC 3339 with any offset report second granularity and are converted to UTC.
+func parseDatestamp(field, value string) (time.Time, string, error) {
+	if t, err := time.Parse(dayLayout, value); err == nil {
+		return t, GranularityDay, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), GranularitySecond, nil
+	}
+	return time.Time{}, "", fmt.Errorf("%w: %s %q is neither YYYY-MM-DD nor YYYY-MM-DDThh:mm:ssZ", ErrInvalidDateRange, field, value)
+}
+
+// granularity returns the repository's datestamp granularity, asking Identify the first
+// time. It returns "" if Identify fails.
+func (c *OAIClient) granularity(ctx context.Context) string {
+	c.granularityMu.Lock()
+	defer c.granularityMu.Unlock()
+
+	if c.Granularity == "" {
+		identify, err := c.Identify(ctx)
+		if err != nil {
+			c.log().Warn("failed to look up the datestamp granularity", "error", err)
+			return ""
+		}
+		c.Granularity = identify.Granularity
+	}
+	return c.Granularity
+}
+
+// Since returns a date range of the records changed at or after t
+func Since(t time.Time) *DateRange {
+	return &DateRange{FromTime: t}
+}
+
+// Between returns a date range of the records changed between from and until, inclusive
+func Between(from, until time.Time) *DateRange {
+	return &DateRange{FromTime: from, UntilTime: until}
+}
+
+// LastNDays returns a date range of the records changed in the last n days
+func LastNDays(n int) *DateRange {
+	return Since(time.Now().AddDate(0, 0, -n))
+}
+
+// dateBound is a parsed from or until value
+type dateBound struct {
+	time        time.Time
+	granularity string
+	raw         string
+}
+
+// parseDateBound parses the string or time form of a bound; ok is false if neither is set
+func parseDateBound(field, value string, t time.Time) (dateBound, bool, error) {
+	switch {
+	case value != "" && !t.IsZero():
+		return dateBound{}, false, fmt.Errorf("%w: both %s %q and a %s time are set", ErrInvalidDateRange, field, value, field)
+	case value != "":
+		parsed, granularity, err := parseDatestamp(field, value)
+		if err != nil {
+			return dateBound{}, false, err
+		}
+		return dateBound{time: parsed, granularity: granularity, raw: value}, true, nil
+	case !t.IsZero():
+		return dateBound{time: t.UTC(), granularity: GranularitySecond}, true, nil
+	}
+	return dateBound{}, false, nil
+}
+
+// dateArguments validates a date range and formats its bounds for the repository. Both
+// bounds get the same granularity as OAI-PMH requires. Day bounds are sent as they are;
+// bounds with a time are sent in UTC, or truncated to their day (widening the range) when
+// the repository only supports day granularity.
+func (c *OAIClient) dateArguments(ctx context.Context, r *DateRange) (string, string, error) {
+	from, hasFrom, err := parseDateBound("from", r.From, r.FromTime)
+	if err != nil {
+		return "", "", err
+	}
+	until, hasUntil, err := parseDateBound("until", r.Until, r.UntilTime)
+	if err != nil {
+		return "", "", err
+	}
+	// A day bound covers the whole day
+	if hasUntil && until.granularity == GranularityDay {
+		until.time = until.time.Add(24*time.Hour - time.Second)
+	}
+	if hasFrom && hasUntil && from.time.After(until.time) {
+		return "", "", fmt.Errorf("%w: from %s is after until %s", ErrInvalidDateRange,
+			from.time.Format(time.RFC3339), until.time.Format(time.RFC3339))
+	}
+
+	// Every repository supports day granularity
+	if from.granularity != GranularitySecond && until.granularity != GranularitySecond {
+		return from.raw, until.raw, nil
+	}
+
+	granularity := GranularitySecond
+	if c.granularity(ctx) == GranularityDay {
+		granularity = GranularityDay
+	}
+
+	var fromArg, untilArg string
+	if hasFrom {
+		fromArg = FormatDatestamp(from.time, granularity)
+	}
+	if hasUntil {
+		untilArg = FormatDatestamp(until.time, granularity)
+	}
+	return fromArg, untilArg, nil
+}
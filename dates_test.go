@@ -0,0 +1,183 @@
+package goharvest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFormatDatestamp(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	ts := time.Date(2025, 1, 1, 3, 4, 5, 0, jakarta)
+
+	if got := FormatDatestamp(ts, GranularitySecond); got != "2024-12-31T20:04:05Z" {
+		t.Errorf("Unexpected second datestamp %q", got)
+	}
+	if got := FormatDatestamp(ts, GranularityDay); got != "2024-12-31" {
+		t.Errorf("Unexpected day datestamp %q", got)
+	}
+	if got := FormatDatestamp(ts, ""); got != "2024-12-31T20:04:05Z" {
+		t.Errorf("Expected second granularity by default, got %q", got)
+	}
+}
+
+func TestDateArguments(t *testing.T) {
+	tests := []struct {
+		name        string
+		granularity string
+		from, until string
+		wantFrom    string
+		wantUntil   string
+		wantErr     bool
+	}{
+		{"days", GranularitySecond, "2025-01-01", "2025-01-31", "2025-01-01", "2025-01-31", false},
+		{"seconds", GranularitySecond, "2025-01-01T10:00:00Z", "", "2025-01-01T10:00:00Z", "", false},
+		{"offset converted to UTC", GranularitySecond, "2025-01-01T00:00:00+07:00", "", "2024-12-31T17:00:00Z", "", false},
+		{"day bound of a seconds range", GranularitySecond, "2025-01-01T10:00:00Z", "2025-01-31", "2025-01-01T10:00:00Z", "2025-01-31T23:59:59Z", false},
+		{"truncated for a day repository", GranularityDay, "2025-01-01T10:00:00Z", "2025-01-31T08:00:00Z", "2025-01-01", "2025-01-31", false},
+		{"same day", GranularityDay, "2025-01-31T10:00:00Z", "2025-01-31", "2025-01-31", "2025-01-31", false},
+		{"slashes", GranularitySecond, "2025/01/01", "", "", "", true},
+		{"no zone", GranularitySecond, "2025-01-01T10:00:00", "", "", "", true},
+		{"from after until", GranularitySecond, "2025-02-01", "2025-01-31", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("http://example.invalid/oai", WithGranularity(tt.granularity))
+			from, until, err := client.dateArguments(context.Background(), &DateRange{From: tt.from, Until: tt.until})
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDateRange) {
+					t.Errorf("Expected ErrInvalidDateRange, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if from != tt.wantFrom || until != tt.wantUntil {
+				t.Errorf("Got from %q until %q, want %q and %q", from, until, tt.wantFrom, tt.wantUntil)
+			}
+		})
+	}
+}
+
+func TestGranularityFromIdentify(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"Identify":              "identify_response.xml",
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	opts := &HarvestOptions{DateRange: &DateRange{From: "2025-01-01T10:00:00Z"}}
+	for i := 0; i < 2; i++ {
+		if err := client.HarvestRecords("oai_dc", opts, func(HarvestedRecord) error { return nil }); err != nil {
+			t.Fatalf("Harvest failed: %v", err)
+		}
+	}
+
+	queries := server.Queries()
+	if len(queries) != 5 || queries[0].Get("verb") != "Identify" {
+		t.Fatalf("Expected a single Identify request before the harvests, got %v", queries)
+	}
+	if from := queries[1].Get("from"); from != "2025-01-01" {
+		t.Errorf("Expected from to be truncated to the day granularity, got %q", from)
+	}
+	if client.Granularity != GranularityDay {
+		t.Errorf("Expected the granularity to be kept, got %q", client.Granularity)
+	}
+}
+
+func TestInvalidDateRangeRejectedLocally(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_dc_page2.xml"})
+
+	opts := &HarvestOptions{DateRange: &DateRange{From: "01/01/2025"}}
+	err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(HarvestedRecord) error { return nil })
+	if !errors.Is(err, ErrInvalidDateRange) {
+		t.Fatalf("Expected ErrInvalidDateRange, got %v", err)
+	}
+	if len(server.Queries()) != 0 {
+		t.Errorf("Expected no requests, got %v", server.Queries())
+	}
+
+	err = NewClient(server.URL).HarvestIdentifiers("oai_dc", &DateRange{Until: "tomorrow"}, "", func(Header) error { return nil })
+	if !errors.Is(err, ErrInvalidDateRange) {
+		t.Errorf("Expected ErrInvalidDateRange from HarvestIdentifiers, got %v", err)
+	}
+}
+
+func TestDateRangeTimes(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	from := time.Date(2025, 3, 1, 6, 30, 0, 0, jakarta)
+	until := time.Date(2025, 3, 31, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		granularity string
+		dateRange   *DateRange
+		wantFrom    string
+		wantUntil   string
+	}{
+		{"since", GranularitySecond, Since(from), "2025-02-28T23:30:00Z", ""},
+		{"between", GranularitySecond, Between(from, until), "2025-02-28T23:30:00Z", "2025-03-31T23:00:00Z"},
+		{"between days", GranularityDay, Between(from, until), "2025-02-28", "2025-03-31"},
+		{"time and day", GranularitySecond, &DateRange{FromTime: from, Until: "2025-03-31"}, "2025-02-28T23:30:00Z", "2025-03-31T23:59:59Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("http://example.invalid/oai", WithGranularity(tt.granularity))
+			gotFrom, gotUntil, err := client.dateArguments(context.Background(), tt.dateRange)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotFrom != tt.wantFrom || gotUntil != tt.wantUntil {
+				t.Errorf("Got from %q until %q, want %q and %q", gotFrom, gotUntil, tt.wantFrom, tt.wantUntil)
+			}
+		})
+	}
+
+	client := NewClient("http://example.invalid/oai", WithGranularity(GranularitySecond))
+	if _, _, err := client.dateArguments(context.Background(), Between(until, from)); !errors.Is(err, ErrInvalidDateRange) {
+		t.Errorf("Expected reversed bounds to fail, got %v", err)
+	}
+	if _, _, err := client.dateArguments(context.Background(), &DateRange{From: "2025-01-01", FromTime: from}); !errors.Is(err, ErrInvalidDateRange) {
+		t.Errorf("Expected From and FromTime together to fail, got %v", err)
+	}
+}
+
+func TestLastNDays(t *testing.T) {
+	before := time.Now()
+	r := LastNDays(7)
+	if r.FromTime.After(before.AddDate(0, 0, -7).Add(time.Minute)) || r.FromTime.Before(before.AddDate(0, 0, -7).Add(-time.Minute)) {
+		t.Errorf("Unexpected start %v", r.FromTime)
+	}
+	if !r.UntilTime.IsZero() || r.From != "" || r.Until != "" {
+		t.Errorf("Expected an open range, got %+v", r)
+	}
+}
+
+func TestDateRangeJSON(t *testing.T) {
+	data, err := json.Marshal(&DateRange{From: "2025-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"From":"2025-01-01","Until":""}` {
+		t.Errorf("Expected unset times to be omitted, got %s", data)
+	}
+
+	from := time.Date(2025, 3, 1, 6, 30, 0, 0, time.UTC)
+	data, err = json.Marshal(Since(from))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded DateRange
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.FromTime.Equal(from) {
+		t.Errorf("Expected %v after a round trip, got %v", from, decoded.FromTime)
+	}
+}
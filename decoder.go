@@ -0,0 +1,290 @@
+package goharvest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// responseEnvelope holds the non-record parts of a streamed OAI-PMH response
+type responseEnvelope struct {
+	ResponseDate    string
+	Request         OAIRequest
+	Verb            string
+	ResumptionToken *ResumptionToken
+	Error           *OAIError
+}
+
+// recordDecodeFunc decodes a single <record> element from the stream
+type recordDecodeFunc[T any] func(d *xml.Decoder, start *xml.StartElement) (T, error)
+
+// decodeRecord decodes a <record> element into a T
+func decodeRecord[T any](d *xml.Decoder, start *xml.StartElement) (T, error) {
+	var record T
+	err := d.DecodeElement(&record, start)
+	return record, err
+}
+
+// decodeOptions configures how decodeResponse reads a page
+type decodeOptions struct {
+	// workers is the number of goroutines decoding records
+	workers int
+	// truncateAt is the offset past which records are skipped instead of decoded (0 for none)
+	truncateAt int64
+	// truncated is called with the number of records kept and dropped from a truncated page
+	truncated func(kept, dropped int)
+}
+
+// decodeResponse streams an OAI-PMH ListRecords or GetRecord response, decoding each
+// <record> element with decode as soon as it is read instead of buffering the whole
+// body. With more than one worker, records are decoded by that many goroutines while the
+// rest of the response is read; they are returned in document order either way.
+func decodeResponse[T any](r io.Reader, page decodeOptions, decode recordDecodeFunc[T]) (*responseEnvelope, []T, error) {
+	workers := page.workers
+	var recorder *recordingReader
+	if workers > 1 {
+		recorder = &recordingReader{r: r}
+		r = recorder
+	}
+	d := xml.NewDecoder(r)
+	env := &responseEnvelope{}
+	depth := 0
+
+	var records []T
+	var pool *extractionPool[T]
+	if recorder != nil {
+		pool = newExtractionPool(workers, decode)
+		defer pool.close()
+	}
+	// namespaces are the declarations in scope of the records, for decoding them apart
+	var namespaces []xml.Attr
+	// dropped counts the records past page.truncateAt
+	dropped := 0
+
+	for {
+		offset := d.InputOffset()
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, &ParseError{Offset: d.InputOffset(), Err: fmt.Errorf("failed to parse XML: %w", err)}
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+
+			var err error
+			switch {
+			case depth == 1:
+				if t.Name.Local != "OAI-PMH" {
+					return nil, nil, &ParseError{Offset: d.InputOffset(), Err: fmt.Errorf("failed to parse XML: unexpected root element <%s>", t.Name.Local)}
+				}
+				namespaces = appendNamespaces(namespaces, t.Attr)
+				continue
+			case depth == 2 && t.Name.Local == "responseDate":
+				err = d.DecodeElement(&env.ResponseDate, &t)
+			case depth == 2 && t.Name.Local == "request":
+				err = d.DecodeElement(&env.Request, &t)
+			case depth == 2 && t.Name.Local == "error":
+				env.Error = &OAIError{}
+				err = d.DecodeElement(env.Error, &t)
+			case depth == 2 && (t.Name.Local == "ListRecords" || t.Name.Local == "GetRecord"):
+				env.Verb = t.Name.Local
+				namespaces = appendNamespaces(namespaces, t.Attr)
+				continue
+			case depth == 3 && env.Verb != "" && t.Name.Local == "record" && page.truncateAt > 0 && offset > page.truncateAt:
+				// The rest of an oversized page is only scanned for its resumption token
+				if err = d.Skip(); err == nil && recorder != nil {
+					recorder.discard(d.InputOffset())
+				}
+				dropped++
+			case depth == 3 && env.Verb != "" && t.Name.Local == "record" && pool != nil:
+				if err = d.Skip(); err == nil {
+					end := d.InputOffset()
+					pool.submit(recorder.slice(offset, end), offset, namespaces)
+					recorder.discard(end)
+				}
+			case depth == 3 && env.Verb != "" && t.Name.Local == "record":
+				record, err := decode(d, &t)
+				if err != nil {
+					return nil, nil, &ParseError{Offset: d.InputOffset(), Err: fmt.Errorf("failed to parse XML: %w", err)}
+				}
+				records = append(records, record)
+			case depth == 3 && env.Verb == "ListRecords" && t.Name.Local == "resumptionToken":
+				env.ResumptionToken = &ResumptionToken{}
+				err = d.DecodeElement(env.ResumptionToken, &t)
+			default:
+				err = d.Skip()
+			}
+			if err != nil {
+				return nil, nil, &ParseError{Offset: d.InputOffset(), Err: fmt.Errorf("failed to parse XML: %w", err)}
+			}
+
+			// The element was consumed including its end tag
+			depth--
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	if env.Error != nil {
+		return nil, nil, env.Error
+	}
+	if pool != nil {
+		var err error
+		if records, err = pool.results(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if dropped > 0 && page.truncated != nil {
+		page.truncated(len(records), dropped)
+	}
+
+	return env, records, nil
+}
+
+// appendNamespaces adds the namespace declarations among attrs to namespaces
+func appendNamespaces(namespaces []xml.Attr, attrs []xml.Attr) []xml.Attr {
+	for _, attr := range attrs {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			namespaces = append(namespaces, attr)
+		}
+	}
+	return namespaces
+}
+
+// recordingReader keeps the bytes read from r since the last cut, so that elements can be
+// sliced out of the stream by the offsets of their decoder
+type recordingReader struct {
+	r    io.Reader
+	buf  []byte
+	base int64
+}
+
+// Read implements io.Reader
+func (rr *recordingReader) Read(p []byte) (int, error) {
+	n, err := rr.r.Read(p)
+	rr.buf = append(rr.buf, p[:n]...)
+	return n, err
+}
+
+// slice returns the bytes from start to end, valid until the next discard
+func (rr *recordingReader) slice(start, end int64) []byte {
+	return rr.buf[start-rr.base : end-rr.base]
+}
+
+// discard drops the bytes before end
+func (rr *recordingReader) discard(end int64) {
+	rr.buf = append(rr.buf[:0], rr.buf[end-rr.base:]...)
+	rr.base = end
+}
+
+// scopeEnd closes the scope start tag of an extractionPool
+const scopeEnd = "</scope>"
+
+// extraction is a record element decoded by an extractionPool
+type extraction[T any] struct {
+	record T
+	err    error
+}
+
+// extractionPool decodes record elements cut out of a response in worker goroutines
+type extractionPool[T any] struct {
+	decode recordDecodeFunc[T]
+	// scope is the start tag declaring the namespaces in scope of the records
+	scope  []byte
+	jobs   chan func()
+	wg     sync.WaitGroup
+	closed bool
+	slots  []*extraction[T]
+}
+
+// newExtractionPool starts workers decoding records with decode
+func newExtractionPool[T any](workers int, decode recordDecodeFunc[T]) *extractionPool[T] {
+	p := &extractionPool[T]{decode: decode, jobs: make(chan func(), workers)}
+	for range workers {
+		p.wg.Go(func() {
+			for job := range p.jobs {
+				job()
+			}
+		})
+	}
+	return p
+}
+
+// submit queues the decoding of a copy of a record element read at offset, in the scope
+// of the namespace declarations
+func (p *extractionPool[T]) submit(element []byte, offset int64, namespaces []xml.Attr) {
+	slot := &extraction[T]{}
+	p.slots = append(p.slots, slot)
+	// The namespaces in scope are the same for all records of a response
+	if p.scope == nil {
+		var scope bytes.Buffer
+		scope.WriteString("<scope")
+		for _, attr := range namespaces {
+			scope.WriteByte(' ')
+			if attr.Name.Space != "" {
+				scope.WriteString(attr.Name.Space + ":")
+			}
+			scope.WriteString(attr.Name.Local + `="`)
+			xml.EscapeText(&scope, []byte(attr.Value))
+			scope.WriteByte('"')
+		}
+		scope.WriteByte('>')
+		p.scope = scope.Bytes()
+	}
+
+	// One buffer holds the scoped element, and reading it needs no further buffering
+	scoped := make([]byte, 0, len(p.scope)+len(element)+len(scopeEnd))
+	scoped = append(append(append(scoped, p.scope...), element...), scopeEnd...)
+
+	p.jobs <- func() {
+		d := xml.NewDecoder(bytes.NewReader(scoped))
+		// The first start element is the scope, the second the record
+		for starts := 0; ; {
+			tok, err := d.Token()
+			if err != nil {
+				slot.err = &ParseError{Offset: offset, Err: fmt.Errorf("failed to parse XML: %w", err)}
+				return
+			}
+			start, ok := tok.(xml.StartElement)
+			if !ok {
+				continue
+			}
+			if starts++; starts == 2 {
+				slot.record, slot.err = p.decode(d, &start)
+				if slot.err != nil {
+					slot.err = &ParseError{Offset: offset, Err: fmt.Errorf("failed to parse XML: %w", slot.err)}
+				}
+				return
+			}
+		}
+	}
+}
+
+// close stops the workers once the queued records are decoded
+func (p *extractionPool[T]) close() {
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+		p.wg.Wait()
+	}
+}
+
+// results waits for the queued records and returns them in submission order, or the
+// error of the first record that failed
+func (p *extractionPool[T]) results() ([]T, error) {
+	p.close()
+	records := make([]T, 0, len(p.slots))
+	for _, slot := range p.slots {
+		if slot.err != nil {
+			return nil, slot.err
+		}
+		records = append(records, slot.record)
+	}
+	return records, nil
+}
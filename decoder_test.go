@@ -0,0 +1,173 @@
+package goharvest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// TestDecodeResponseMatchesUnmarshal ensures streaming decoding yields the same records as xml.Unmarshal
+func TestDecodeResponseMatchesUnmarshal(t *testing.T) {
+	data, err := os.ReadFile("testdata/sample_response.xml")
+	if err != nil {
+		t.Fatalf("Failed to read sample XML file: %v", err)
+	}
+
+	expected, err := ParseOAIPMHXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIPMHXML failed: %v", err)
+	}
+
+	streamed, err := decodeOAIPMHResponse(bytes.NewReader(data), decodeOptions{})
+	if err != nil {
+		t.Fatalf("decodeOAIPMHResponse failed: %v", err)
+	}
+
+	if streamed.ResponseDate != expected.ResponseDate {
+		t.Errorf("Expected responseDate '%s', got '%s'", expected.ResponseDate, streamed.ResponseDate)
+	}
+	if streamed.Request != expected.Request {
+		t.Errorf("Expected request %+v, got %+v", expected.Request, streamed.Request)
+	}
+	if len(streamed.ListRecords.Records) != len(expected.ListRecords.Records) {
+		t.Fatalf("Expected %d records, got %d", len(expected.ListRecords.Records), len(streamed.ListRecords.Records))
+	}
+	for i := range expected.ListRecords.Records {
+		if !reflect.DeepEqual(streamed.ListRecords.Records[i], expected.ListRecords.Records[i]) {
+			t.Errorf("Record %d differs between streamed and unmarshalled responses", i)
+		}
+	}
+	if !reflect.DeepEqual(streamed.ListRecords.ResumptionToken, expected.ListRecords.ResumptionToken) {
+		t.Errorf("Expected resumption token %+v, got %+v", expected.ListRecords.ResumptionToken, streamed.ListRecords.ResumptionToken)
+	}
+}
+
+func TestDecodeResponseDC(t *testing.T) {
+	data, err := os.ReadFile("testdata/listrecords_dc_page1.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := decodeOAIPMHResponseDC(bytes.NewReader(data), decodeOptions{})
+	if err != nil {
+		t.Fatalf("decodeOAIPMHResponseDC failed: %v", err)
+	}
+
+	if len(resp.ListRecords.Records) != 3 {
+		t.Errorf("Expected 3 records, got %d", len(resp.ListRecords.Records))
+	}
+	if resp.GetResumptionToken() != "dc-page-2" {
+		t.Errorf("Expected resumption token 'dc-page-2', got '%s'", resp.GetResumptionToken())
+	}
+	if len(resp.GetRecords()) != 2 {
+		t.Errorf("Expected 2 records with metadata, got %d", len(resp.GetRecords()))
+	}
+}
+
+func TestDecodeResponseErrors(t *testing.T) {
+	_, err := decodeOAIPMHResponse(strings.NewReader(`<OAI-PMH><error code="badResumptionToken">Expired</error></OAI-PMH>`), decodeOptions{})
+	if !errors.Is(err, ErrBadResumptionToken) {
+		t.Errorf("Expected ErrBadResumptionToken, got %v", err)
+	}
+
+	_, err = decodeOAIPMHResponse(strings.NewReader(`<html><body>Not found</body></html>`), decodeOptions{})
+	if err == nil {
+		t.Error("Expected error for non OAI-PMH document")
+	}
+
+	_, err = decodeOAIPMHResponse(strings.NewReader(`<OAI-PMH><ListRecords><record><header>`), decodeOptions{})
+	if err == nil {
+		t.Error("Expected error for truncated document")
+	}
+}
+
+// TestDecodeResponseWorkers ensures records decoded by extraction workers match the ones
+// decoded while reading
+func TestDecodeResponseWorkers(t *testing.T) {
+	fixtures := map[MetadataFormat]string{
+		FormatMARCXML:  "sample_response.xml",
+		FormatOAIMARC:  "listrecords_oai_marc.xml",
+		FormatOAIDC:    "listrecords_dc_page1.xml",
+		FormatMODS:     "listrecords_mods.xml",
+		FormatQDC:      "listrecords_qdc.xml",
+		FormatDataCite: "listrecords_datacite.xml",
+		FormatMETS:     "listrecords_mets.xml",
+		FormatETDMS:    "listrecords_etdms.xml",
+		FormatORE:      "listrecords_ore.xml",
+		FormatLIDO:     "listrecords_lido.xml",
+		FormatEAD:      "listrecords_ead.xml",
+	}
+
+	for format, fixture := range fixtures {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sequential, err := parseResponse(format, bytes.NewReader(data), decodeOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			parallel, err := parseResponse(format, iotest.OneByteReader(bytes.NewReader(data)), decodeOptions{workers: 4})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(parallel.GetRecords()) == 0 || !reflect.DeepEqual(sequential, parallel) {
+				t.Errorf("Expected the same response from extraction workers")
+			}
+		})
+	}
+}
+
+func TestDecodeResponseWorkersErrors(t *testing.T) {
+	page := `<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/"><ListRecords>
+	<record><header><identifier>oai:example.org:1</identifier></header></record>
+	<record><header><identifier>oai:example.org:2</identifier></header><metadata><dc><title><b></title></dc></metadata></record>
+	</ListRecords></OAI-PMH>`
+
+	_, err := decodeOAIPMHResponseDC(strings.NewReader(page), decodeOptions{workers: 4})
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Offset < int64(strings.Index(page, "<record><header><identifier>oai:example.org:2")) {
+		t.Errorf("Expected a parse error in the second record, got %v", err)
+	}
+
+	_, err = decodeOAIPMHResponseDC(strings.NewReader(`<OAI-PMH><error code="badResumptionToken">Expired</error></OAI-PMH>`), decodeOptions{workers: 4})
+	if !errors.Is(err, ErrBadResumptionToken) {
+		t.Errorf("Expected ErrBadResumptionToken, got %v", err)
+	}
+}
+
+func BenchmarkDecodeResponse(b *testing.B) {
+	pages := []struct {
+		format  MetadataFormat
+		fixture string
+	}{
+		{FormatMARCXML, "sample_response.xml"},
+		{FormatOAIDC, "listrecords_dc_page1.xml"},
+	}
+
+	for _, page := range pages {
+		data, err := os.ReadFile(filepath.Join("testdata", page.fixture))
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, workers := range []int{0, 4} {
+			b.Run(fmt.Sprintf("%s/workers=%d", page.format, workers), func(b *testing.B) {
+				b.SetBytes(int64(len(data)))
+				b.ReportAllocs()
+				for b.Loop() {
+					if _, err := parseResponse(page.format, bytes.NewReader(data), decodeOptions{workers: workers}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package goharvest
+
+import (
+	"hash/maphash"
+	"math"
+)
+
+// DefaultDedupFalsePositiveRate is the false positive rate of a Bloom filter deduplication
+// unless DedupOptions.FalsePositiveRate is set
+const DefaultDedupFalsePositiveRate = 0.001
+
+// DedupOptions configures the suppression of duplicate records within a harvest.
+// Repositories with overlapping sets or buggy resumption tokens can return a record more
+// than once.
+type DedupOptions struct {
+	// ExpectedRecords sizes a Bloom filter, which bounds memory at the cost of occasionally
+	// dropping a unique record. With 0 every identifier is kept in memory and no unique
+	// record is ever dropped.
+	ExpectedRecords int
+	// FalsePositiveRate is the probability of the Bloom filter taking a unique record for a
+	// duplicate (default DefaultDedupFalsePositiveRate)
+	FalsePositiveRate float64
+}
+
+// filter returns a new filter for one harvest
+func (o *DedupOptions) filter() identifierFilter {
+	if o.ExpectedRecords <= 0 {
+		return newHashFilter()
+	}
+	rate := o.FalsePositiveRate
+	if rate <= 0 || rate >= 1 {
+		rate = DefaultDedupFalsePositiveRate
+	}
+	return newBloomFilter(o.ExpectedRecords, rate)
+}
+
+// identifierFilter remembers record identifiers
+type identifierFilter interface {
+	// seen reports whether identifier was added before and adds it
+	seen(identifier string) bool
+}
+
+// hashFilter is an exact identifierFilter
+type hashFilter map[string]struct{}
+
+// newHashFilter creates an empty hashFilter
+func newHashFilter() hashFilter {
+	return make(hashFilter)
+}
+
+// seen implements identifierFilter
+func (f hashFilter) seen(identifier string) bool {
+	if _, ok := f[identifier]; ok {
+		return true
+	}
+	f[identifier] = struct{}{}
+	return false
+}
+
+// bloomFilter is an identifierFilter of fixed size that may report unseen identifiers as
+// seen, but never the other way round
+type bloomFilter struct {
+	bits         []uint64
+	size         uint64
+	hashes       int
+	seed1, seed2 maphash.Seed
+}
+
+// newBloomFilter creates a Bloom filter for n identifiers with false positive rate p
+func newBloomFilter(n int, p float64) *bloomFilter {
+	size := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	size = max(size, 64)
+	hashes := max(int(math.Round(float64(size)/float64(n)*math.Ln2)), 1)
+
+	return &bloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+		seed1:  maphash.MakeSeed(),
+		seed2:  maphash.MakeSeed(),
+	}
+}
+
+// seen implements identifierFilter. The bit positions are derived from two hashes by
+// double hashing.
+func (f *bloomFilter) seen(identifier string) bool {
+	h1 := maphash.String(f.seed1, identifier)
+	h2 := maphash.String(f.seed2, identifier) | 1
+
+	found := true
+	for i := range f.hashes {
+		bit := (h1 + uint64(i)*h2) % f.size
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if f.bits[word]&mask == 0 {
+			found = false
+			f.bits[word] |= mask
+		}
+	}
+	return found
+}
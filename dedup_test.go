@@ -0,0 +1,63 @@
+package goharvest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestHarvestDedup(t *testing.T) {
+	// The second page repeats records 2 and 4 of the first, as after a buggy resumption token
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_duplicates.xml",
+	})
+
+	for _, dedup := range []*DedupOptions{nil, {}, {ExpectedRecords: 100, FalsePositiveRate: 0.01}} {
+		var stats HarvestStats
+		var identifiers []string
+		err := NewClient(server.URL).HarvestRecords("oai_dc", &HarvestOptions{Dedup: dedup, Stats: &stats}, func(record HarvestedRecord) error {
+			identifiers = append(identifiers, record.Header.Identifier)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if dedup == nil {
+			if len(identifiers) != 6 || stats.DuplicateRecords != 0 {
+				t.Errorf("Expected duplicates to be delivered without dedup, got %v", identifiers)
+			}
+			continue
+		}
+		want := []string{"oai:example.org:1", "oai:example.org:2", "oai:example.org:4", "oai:example.org:5"}
+		if !slices.Equal(identifiers, want) || stats.DuplicateRecords != 2 || stats.Records != 6 {
+			t.Errorf("Expected %v with %+v, got %v and %+v", want, dedup, identifiers, stats)
+		}
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	filter := newBloomFilter(10000, 0.01)
+	falsePositives := 0
+	for i := range 10000 {
+		if filter.seen(fmt.Sprintf("oai:example.org:%d", i)) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 300 {
+		t.Errorf("Expected about 100 false positives, got %d", falsePositives)
+	}
+	for i := range 10000 {
+		if !filter.seen(fmt.Sprintf("oai:example.org:%d", i)) {
+			t.Fatalf("Expected identifier %d to be seen", i)
+		}
+	}
+}
+
+func TestHashFilter(t *testing.T) {
+	filter := newHashFilter()
+	if filter.seen("a") || filter.seen("b") || !filter.seen("a") {
+		t.Error("Unexpected hash filter result")
+	}
+}
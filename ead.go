@@ -0,0 +1,513 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// EAD represents an Encoded Archival Description finding aid (EAD 2002 or EAD3).
+// The root is matched by local name so namespaced and un-namespaced documents both parse.
+type EAD struct {
+	XMLName   xml.Name     `xml:"ead"`
+	EADHeader EadHeader    `xml:"eadheader"`
+	Control   EadHeader    `xml:"control"`
+	ArchDesc  *EadArchDesc `xml:"archdesc,omitempty"`
+}
+
+// EadHeader represents the eadheader (EAD 2002) or control (EAD3) element
+type EadHeader struct {
+	EADID       string  `xml:"eadid"`
+	RecordID    string  `xml:"recordid"`
+	TitleProper EadText `xml:"filedesc>titlestmt>titleproper"`
+}
+
+// EadArchDesc represents the archdesc element describing the whole collection
+type EadArchDesc struct {
+	Level          string        `xml:"level,attr,omitempty"`
+	Did            EadDid        `xml:"did"`
+	ScopeContent   []EadNote     `xml:"scopecontent"`
+	BiogHist       []EadNote     `xml:"bioghist"`
+	AccessRestrict []EadNote     `xml:"accessrestrict"`
+	UseRestrict    []EadNote     `xml:"userestrict"`
+	ControlAccess  []EadControl  `xml:"controlaccess"`
+	Dsc            *EadComponent `xml:"dsc,omitempty"`
+}
+
+// EadDid represents the descriptive identification (did) block
+type EadDid struct {
+	UnitTitle    []EadText     `xml:"unittitle"`
+	UnitDate     []EadUnitDate `xml:"unitdate"`
+	UnitID       []EadText     `xml:"unitid"`
+	PhysDesc     []EadPhysDesc `xml:"physdesc"`
+	Repository   []EadText     `xml:"repository"`
+	Origination  []EadText     `xml:"origination"`
+	LangMaterial []EadText     `xml:"langmaterial"`
+	Abstract     []EadText     `xml:"abstract"`
+}
+
+// EadUnitDate represents a unitdate with its normalized form
+type EadUnitDate struct {
+	Normal string `xml:"normal,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Value  string `xml:",chardata"`
+}
+
+// EadPhysDesc represents a physdesc element with optional extent children
+type EadPhysDesc struct {
+	Extent []EadText `xml:"extent"`
+	Value  string    `xml:",chardata"`
+}
+
+// EadNote represents a note block such as scopecontent or bioghist
+type EadNote struct {
+	Head string    `xml:"head"`
+	P    []EadText `xml:"p"`
+}
+
+// EadControl represents a controlaccess block of access points
+type EadControl struct {
+	Subject   []EadText `xml:"subject"`
+	PersName  []EadText `xml:"persname"`
+	CorpName  []EadText `xml:"corpname"`
+	FamName   []EadText `xml:"famname"`
+	GeogName  []EadText `xml:"geogname"`
+	GenreForm []EadText `xml:"genreform"`
+}
+
+// EadComponent represents a component (c, c01..c12) or the dsc container of components
+type EadComponent struct {
+	ID           string
+	Level        string
+	Did          EadDid
+	ScopeContent []EadNote
+	Components   []EadComponent
+}
+
+// EadText is the flattened character data of an element, including inline markup such as emph
+type EadText string
+
+// UnmarshalXML collects all nested character data into a single whitespace-normalized string
+func (t *EadText) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var b strings.Builder
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok := tok.(type) {
+		case xml.CharData:
+			b.Write(tok)
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				*t = EadText(strings.Join(strings.Fields(b.String()), " "))
+				return nil
+			}
+			depth--
+		}
+	}
+}
+
+// String returns the text value
+func (t EadText) String() string {
+	return string(t)
+}
+
+// UnmarshalXML decodes a component and its nested components regardless of numbering
+func (c *EadComponent) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "id":
+			c.ID = attr.Value
+		case "level":
+			c.Level = attr.Value
+		}
+	}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case isEadComponent(tok.Name.Local):
+				var child EadComponent
+				if err := d.DecodeElement(&child, &tok); err != nil {
+					return err
+				}
+				c.Components = append(c.Components, child)
+			case tok.Name.Local == "did":
+				if err := d.DecodeElement(&c.Did, &tok); err != nil {
+					return err
+				}
+			case tok.Name.Local == "scopecontent":
+				var note EadNote
+				if err := d.DecodeElement(&note, &tok); err != nil {
+					return err
+				}
+				c.ScopeContent = append(c.ScopeContent, note)
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// isEadComponent reports whether name is an unnumbered (c) or numbered (c01..c12) component
+func isEadComponent(name string) bool {
+	if name == "c" {
+		return true
+	}
+	return len(name) == 3 && name[0] == 'c' && name[1] >= '0' && name[1] <= '1' && name[2] >= '0' && name[2] <= '9'
+}
+
+// Text joins the paragraphs of a note
+func (n EadNote) Text() string {
+	var paragraphs []string
+	for _, p := range n.P {
+		if p != "" {
+			paragraphs = append(paragraphs, p.String())
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// MetadataEAD represents the metadata wrapper for EAD
+type MetadataEAD struct {
+	EAD *EAD   `xml:"ead,omitempty"`
+	Raw []byte `xml:",innerxml"`
+}
+
+// RecordEAD represents an OAI-PMH record with EAD metadata
+type RecordEAD struct {
+	Header   Header      `xml:"header"`
+	Metadata MetadataEAD `xml:"metadata"`
+	About    *About      `xml:"about,omitempty"`
+}
+
+// IsDeleted returns true if the record has been deleted from the repository
+func (r RecordEAD) IsDeleted() bool {
+	return r.Header.IsDeleted()
+}
+
+// ListRecordsEAD contains the list of EAD records from ListRecords verb
+type ListRecordsEAD struct {
+	Records         []RecordEAD      `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// GetRecordEAD contains a single EAD record from GetRecord verb
+type GetRecordEAD struct {
+	Record RecordEAD `xml:"record"`
+}
+
+// OAIPMHResponseEAD represents the OAI-PMH response with EAD metadata
+type OAIPMHResponseEAD struct {
+	XMLName      xml.Name        `xml:"OAI-PMH"`
+	ResponseDate string          `xml:"responseDate"`
+	Request      OAIRequest      `xml:"request"`
+	ListRecords  *ListRecordsEAD `xml:"ListRecords,omitempty"`
+	GetRecord    *GetRecordEAD   `xml:"GetRecord,omitempty"`
+	Error        *OAIError       `xml:"error,omitempty"`
+}
+
+// EadMetadata represents extracted EAD finding-aid metadata
+type EadMetadata struct {
+	EADID          string              `json:"eadid"`
+	Title          string              `json:"title"`
+	Level          string              `json:"level"`
+	UnitTitle      string              `json:"unit_title"`
+	UnitDate       string              `json:"unit_date"`
+	UnitDateNormal string              `json:"unit_date_normal"`
+	UnitID         string              `json:"unit_id"`
+	Extent         []string            `json:"extent"`
+	Repository     string              `json:"repository"`
+	Creators       []string            `json:"creators"`
+	Languages      []string            `json:"languages"`
+	Abstract       string              `json:"abstract"`
+	ScopeContent   string              `json:"scope_content"`
+	BiogHist       string              `json:"biog_hist"`
+	AccessRestrict string              `json:"access_restrict"`
+	UseRestrict    string              `json:"use_restrict"`
+	Subjects       []string            `json:"subjects"`
+	Components     []EadComponentEntry `json:"components"`
+}
+
+// EadComponentEntry is an extracted component of the collection's container list
+type EadComponentEntry struct {
+	Level      string              `json:"level"`
+	UnitID     string              `json:"unit_id"`
+	UnitTitle  string              `json:"unit_title"`
+	UnitDate   string              `json:"unit_date"`
+	Components []EadComponentEntry `json:"components,omitempty"`
+}
+
+// ExtractEadMetadata extracts the collection-level description and component tree
+func (e *EAD) ExtractEadMetadata() *EadMetadata {
+	if e == nil {
+		return nil
+	}
+
+	metadata := &EadMetadata{
+		EADID:      strings.TrimSpace(e.EADHeader.EADID),
+		Title:      e.EADHeader.TitleProper.String(),
+		Extent:     []string{},
+		Creators:   []string{},
+		Languages:  []string{},
+		Subjects:   []string{},
+		Components: []EadComponentEntry{},
+	}
+	if metadata.EADID == "" {
+		metadata.EADID = strings.TrimSpace(e.Control.RecordID)
+	}
+	if metadata.Title == "" {
+		metadata.Title = e.Control.TitleProper.String()
+	}
+
+	archDesc := e.ArchDesc
+	if archDesc == nil {
+		return metadata
+	}
+
+	did := archDesc.Did
+	metadata.Level = archDesc.Level
+	metadata.UnitTitle = firstEadText(did.UnitTitle)
+	metadata.UnitID = firstEadText(did.UnitID)
+	if len(did.UnitDate) > 0 {
+		metadata.UnitDate = strings.TrimSpace(did.UnitDate[0].Value)
+		metadata.UnitDateNormal = did.UnitDate[0].Normal
+	}
+	metadata.Extent = did.extents()
+	metadata.Repository = firstEadText(did.Repository)
+	metadata.Creators = eadTexts(did.Origination)
+	metadata.Languages = eadTexts(did.LangMaterial)
+	metadata.Abstract = firstEadText(did.Abstract)
+	if metadata.Title == "" {
+		metadata.Title = metadata.UnitTitle
+	}
+
+	metadata.ScopeContent = joinEadNotes(archDesc.ScopeContent)
+	metadata.BiogHist = joinEadNotes(archDesc.BiogHist)
+	metadata.AccessRestrict = joinEadNotes(archDesc.AccessRestrict)
+	metadata.UseRestrict = joinEadNotes(archDesc.UseRestrict)
+
+	for _, control := range archDesc.ControlAccess {
+		for _, terms := range [][]EadText{control.Subject, control.PersName, control.CorpName, control.FamName, control.GeogName, control.GenreForm} {
+			metadata.Subjects = append(metadata.Subjects, eadTexts(terms)...)
+		}
+	}
+	metadata.Subjects = deduplicate(metadata.Subjects)
+
+	if archDesc.Dsc != nil {
+		metadata.Components = extractEadComponents(archDesc.Dsc.Components)
+	}
+
+	return metadata
+}
+
+// extents returns the extent statements of a did, falling back to the physdesc text
+func (d EadDid) extents() []string {
+	extents := []string{}
+	for _, physDesc := range d.PhysDesc {
+		if len(physDesc.Extent) > 0 {
+			extents = append(extents, eadTexts(physDesc.Extent)...)
+		} else if value := strings.TrimSpace(physDesc.Value); value != "" {
+			extents = append(extents, value)
+		}
+	}
+	return extents
+}
+
+// extractEadComponents converts components recursively to their extracted form
+func extractEadComponents(components []EadComponent) []EadComponentEntry {
+	entries := make([]EadComponentEntry, 0, len(components))
+	for _, component := range components {
+		entry := EadComponentEntry{
+			Level:     component.Level,
+			UnitID:    firstEadText(component.Did.UnitID),
+			UnitTitle: firstEadText(component.Did.UnitTitle),
+		}
+		if len(component.Did.UnitDate) > 0 {
+			entry.UnitDate = strings.TrimSpace(component.Did.UnitDate[0].Value)
+		}
+		if len(component.Components) > 0 {
+			entry.Components = extractEadComponents(component.Components)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// firstEadText returns the first non-empty text value
+func firstEadText(values []EadText) string {
+	for _, value := range values {
+		if value != "" {
+			return value.String()
+		}
+	}
+	return ""
+}
+
+// eadTexts returns all non-empty text values
+func eadTexts(values []EadText) []string {
+	texts := []string{}
+	for _, value := range values {
+		if value != "" {
+			texts = append(texts, value.String())
+		}
+	}
+	return texts
+}
+
+// joinEadNotes joins the text of several note blocks
+func joinEadNotes(notes []EadNote) string {
+	var texts []string
+	for _, note := range notes {
+		if text := note.Text(); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// ExtractAllEadMetadata extracts metadata from all EAD records in OAI-PMH response
+func (o *OAIPMHResponseEAD) ExtractAllEadMetadata() []*EadMetadata {
+	var results []*EadMetadata
+
+	for _, extractor := range o.GetRecords() {
+		if metadata := extractor.(*EAD).ExtractEadMetadata(); metadata != nil {
+			results = append(results, metadata)
+		}
+	}
+
+	return results
+}
+
+// ParseOAIEADXML parses OAI-PMH XML data with EAD metadata from bytes
+func ParseOAIEADXML(data []byte) (*OAIPMHResponseEAD, error) {
+	var oaiResp OAIPMHResponseEAD
+	if err := xml.Unmarshal(data, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, oaiResp.Error
+	}
+
+	return &oaiResp, nil
+}
+
+// decodeOAIPMHResponseEAD stream-decodes a EAD ListRecords or GetRecord response
+func decodeOAIPMHResponseEAD(r io.Reader, page decodeOptions) (*OAIPMHResponseEAD, error) {
+	env, records, err := decodeResponse(r, page, decodeRecord[RecordEAD])
+	if err != nil {
+		return nil, err
+	}
+
+	oaiResp := &OAIPMHResponseEAD{
+		XMLName:      xml.Name{Local: "OAI-PMH"},
+		ResponseDate: env.ResponseDate,
+		Request:      env.Request,
+	}
+
+	switch env.Verb {
+	case "ListRecords":
+		oaiResp.ListRecords = &ListRecordsEAD{Records: records, ResumptionToken: env.ResumptionToken}
+	case "GetRecord":
+		if len(records) > 0 {
+			oaiResp.GetRecord = &GetRecordEAD{Record: records[0]}
+		}
+	}
+
+	return oaiResp, nil
+}
+
+// Implement OAIResponse interface for OAIPMHResponseEAD
+
+// GetRecords returns all records in the response as MetadataExtractor interface
+func (o *OAIPMHResponseEAD) GetRecords() []MetadataExtractor {
+	var extractors []MetadataExtractor
+
+	for _, record := range o.harvestedRecords() {
+		if record.Metadata != nil {
+			extractors = append(extractors, record.Metadata)
+		}
+	}
+
+	return extractors
+}
+
+// GetResumptionToken returns the resumption token if available
+func (o *OAIPMHResponseEAD) GetResumptionToken() string {
+	if o.ListRecords != nil && o.ListRecords.ResumptionToken != nil {
+		return o.ListRecords.ResumptionToken.Token
+	}
+	return ""
+}
+
+// GetResumptionTokenInfo returns the full resumption token if available
+func (o *OAIPMHResponseEAD) GetResumptionTokenInfo() *ResumptionToken {
+	if o.ListRecords != nil {
+		return o.ListRecords.ResumptionToken
+	}
+	return nil
+}
+
+// HasError returns true if the response contains an error
+func (o *OAIPMHResponseEAD) HasError() bool {
+	return o.Error != nil
+}
+
+// GetError returns the error information
+func (o *OAIPMHResponseEAD) GetError() *OAIError {
+	return o.Error
+}
+
+// harvestedRecords returns all records in the response with their headers
+func (o *OAIPMHResponseEAD) harvestedRecords() []HarvestedRecord {
+	var records []RecordEAD
+	if o.ListRecords != nil {
+		records = o.ListRecords.Records
+	}
+	if o.GetRecord != nil {
+		records = append(records, o.GetRecord.Record)
+	}
+
+	results := make([]HarvestedRecord, 0, len(records))
+	for _, record := range records {
+		harvested := HarvestedRecord{Header: record.Header, Raw: record.Metadata.Raw, About: record.About}
+		if record.Metadata.EAD != nil {
+			harvested.Metadata = record.Metadata.EAD
+		}
+		results = append(results, harvested)
+	}
+
+	return results
+}
+
+// responseDate returns the responseDate of the response
+func (o *OAIPMHResponseEAD) responseDate() string {
+	return o.ResponseDate
+}
+
+// Implement MetadataExtractor interface for EAD
+
+// ExtractMetadata extracts metadata from EAD record
+func (m *EAD) ExtractMetadata() interface{} {
+	return m.ExtractEadMetadata()
+}
+
+// GetFormat returns the metadata format type
+func (m *EAD) GetFormat() MetadataFormat {
+	return FormatEAD
+}
@@ -0,0 +1,103 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseOAIEADXML(t *testing.T) {
+	data, err := os.ReadFile("testdata/listrecords_ead.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAIEADXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIEADXML failed: %v", err)
+	}
+
+	records := resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if records[0].GetFormat() != FormatEAD {
+		t.Errorf("Expected format ead, got %s", records[0].GetFormat())
+	}
+
+	metadata, ok := records[0].ExtractMetadata().(*EadMetadata)
+	if !ok {
+		t.Fatalf("Expected *EadMetadata, got %T", records[0].ExtractMetadata())
+	}
+
+	if metadata.EADID != "ID-ARSIP-80" || metadata.Title != "Guide to the Kartini Family Papers" {
+		t.Errorf("Unexpected header '%s' / '%s'", metadata.EADID, metadata.Title)
+	}
+	if metadata.Level != "collection" || metadata.UnitTitle != "Kartini family papers" || metadata.UnitID != "MS 80" {
+		t.Errorf("Unexpected did %s / %s / %s", metadata.Level, metadata.UnitTitle, metadata.UnitID)
+	}
+	if metadata.UnitDate != "1879-1920" || metadata.UnitDateNormal != "1879/1920" {
+		t.Errorf("Unexpected unit date %s / %s", metadata.UnitDate, metadata.UnitDateNormal)
+	}
+	if len(metadata.Extent) != 2 || metadata.Repository != "Arsip Nasional Republik Indonesia" || len(metadata.Creators) != 1 {
+		t.Errorf("Unexpected extent/repository/creators %v / %s / %v", metadata.Extent, metadata.Repository, metadata.Creators)
+	}
+	if !strings.Contains(metadata.ScopeContent, "Door Duisternis tot Licht manuscript") || strings.Count(metadata.ScopeContent, "\n\n") != 1 {
+		t.Errorf("Unexpected scope content %q", metadata.ScopeContent)
+	}
+	if metadata.BiogHist == "" || metadata.AccessRestrict != "Open for research." || len(metadata.Subjects) != 3 {
+		t.Errorf("Unexpected notes/subjects %q / %q / %v", metadata.BiogHist, metadata.AccessRestrict, metadata.Subjects)
+	}
+
+	if len(metadata.Components) != 2 {
+		t.Fatalf("Expected 2 top-level components, got %d", len(metadata.Components))
+	}
+	series := metadata.Components[0]
+	if series.Level != "series" || series.UnitTitle != "Correspondence" || len(series.Components) != 1 {
+		t.Errorf("Unexpected series %+v", series)
+	}
+	if series.Components[0].UnitID != "1.1" {
+		t.Errorf("Unexpected nested component %+v", series.Components[0])
+	}
+}
+
+func TestEADUnnumberedComponents(t *testing.T) {
+	data := `<ead><archdesc level="fonds"><did><unittitle>Fonds</unittitle></did>
+		<dsc><c level="series"><did><unittitle>A</unittitle></did><c level="file"><did><unittitle>A.1</unittitle></did></c></c></dsc>
+	</archdesc></ead>`
+
+	var ead EAD
+	if err := xml.Unmarshal([]byte(data), &ead); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	metadata := ead.ExtractEadMetadata()
+	if metadata.Title != "Fonds" {
+		t.Errorf("Expected title to fall back to unittitle, got '%s'", metadata.Title)
+	}
+	if len(metadata.Components) != 1 || len(metadata.Components[0].Components) != 1 || metadata.Components[0].Components[0].UnitTitle != "A.1" {
+		t.Errorf("Unexpected components %+v", metadata.Components)
+	}
+}
+
+func TestUnifiedHarvestEAD(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_ead.xml"})
+	client := NewClient(server.URL)
+
+	count := 0
+	err := client.Harvest("ead", nil, func(response OAIResponse) error {
+		for _, record := range response.GetRecords() {
+			if _, ok := record.ExtractMetadata().(*EadMetadata); ok {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 EAD record, got %d", count)
+	}
+}
@@ -0,0 +1,41 @@
+package goharvest
+
+import "fmt"
+
+// OAI-PMH error codes as defined by the protocol specification
+const (
+	ErrorCodeBadArgument             = "badArgument"
+	ErrorCodeBadResumptionToken      = "badResumptionToken"
+	ErrorCodeBadVerb                 = "badVerb"
+	ErrorCodeCannotDisseminateFormat = "cannotDisseminateFormat"
+	ErrorCodeIDDoesNotExist          = "idDoesNotExist"
+	ErrorCodeNoRecordsMatch          = "noRecordsMatch"
+	ErrorCodeNoMetadataFormats       = "noMetadataFormats"
+	ErrorCodeNoSetHierarchy          = "noSetHierarchy"
+)
+
+// Sentinel OAI-PMH errors for use with errors.Is
+var (
+	ErrBadArgument             = &OAIError{Code: ErrorCodeBadArgument}
+	ErrBadResumptionToken      = &OAIError{Code: ErrorCodeBadResumptionToken}
+	ErrBadVerb                 = &OAIError{Code: ErrorCodeBadVerb}
+	ErrCannotDisseminateFormat = &OAIError{Code: ErrorCodeCannotDisseminateFormat}
+	ErrIDDoesNotExist          = &OAIError{Code: ErrorCodeIDDoesNotExist}
+	ErrNoRecordsMatch          = &OAIError{Code: ErrorCodeNoRecordsMatch}
+	ErrNoMetadataFormats       = &OAIError{Code: ErrorCodeNoMetadataFormats}
+	ErrNoSetHierarchy          = &OAIError{Code: ErrorCodeNoSetHierarchy}
+)
+
+// Error implements the error interface
+func (e *OAIError) Error() string {
+	return fmt.Sprintf("OAI-PMH error [%s]: %s", e.Code, e.Message)
+}
+
+// Is reports whether target is an OAI-PMH error with the same code
+func (e *OAIError) Is(target error) bool {
+	t, ok := target.(*OAIError)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code
+}
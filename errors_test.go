@@ -0,0 +1,59 @@
+package goharvest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOAIErrorIs(t *testing.T) {
+	_, err := ParseOAIPMHXML([]byte(`<OAI-PMH><error code="cannotDisseminateFormat">Format not supported</error></OAI-PMH>`))
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if !errors.Is(err, ErrCannotDisseminateFormat) {
+		t.Errorf("Expected ErrCannotDisseminateFormat, got %v", err)
+	}
+	if errors.Is(err, ErrNoRecordsMatch) {
+		t.Error("Did not expect ErrNoRecordsMatch")
+	}
+	if err.Error() != "OAI-PMH error [cannotDisseminateFormat]: Format not supported" {
+		t.Errorf("Unexpected error message '%s'", err.Error())
+	}
+
+	wrapped := fmt.Errorf("harvest failed: %w", err)
+	var oaiErr *OAIError
+	if !errors.As(wrapped, &oaiErr) || oaiErr.Message != "Format not supported" {
+		t.Errorf("Expected wrapped *OAIError, got %v", wrapped)
+	}
+}
+
+func TestHarvestNoRecordsMatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<OAI-PMH><error code="noRecordsMatch">No matching records</error></OAI-PMH>`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	callbackCalled := false
+	callback := func(OAIResponse) error {
+		callbackCalled = true
+		return nil
+	}
+
+	err := client.HarvestWithOptions("oai_dc", &HarvestOptions{DateRange: &DateRange{From: "2099-01-01"}}, callback)
+	if !errors.Is(err, ErrNoRecordsMatch) {
+		t.Errorf("Expected ErrNoRecordsMatch, got %v", err)
+	}
+
+	err = client.HarvestWithOptions("oai_dc", &HarvestOptions{IgnoreNoRecordsMatch: true}, callback)
+	if err != nil {
+		t.Errorf("Expected noRecordsMatch to be ignored, got %v", err)
+	}
+	if callbackCalled {
+		t.Error("Expected callback not to be called for empty result")
+	}
+}
@@ -0,0 +1,291 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ETDMS represents an ETD-MS (Electronic Theses and Dissertations Metadata Standard) thesis record
+type ETDMS struct {
+	XMLName          xml.Name           `xml:"http://www.ndltd.org/standards/metadata/etdms/1.0/ thesis"`
+	Title            []string           `xml:"title"`
+	AlternativeTitle []string           `xml:"alternativeTitle"`
+	Creator          []string           `xml:"creator"`
+	Subject          []string           `xml:"subject"`
+	Description      []string           `xml:"description"`
+	Publisher        []string           `xml:"publisher"`
+	Contributor      []EtdmsContributor `xml:"contributor"`
+	Date             []string           `xml:"date"`
+	Type             []string           `xml:"type"`
+	Format           []string           `xml:"format"`
+	Identifier       []string           `xml:"identifier"`
+	Language         []string           `xml:"language"`
+	Coverage         []string           `xml:"coverage"`
+	Rights           []string           `xml:"rights"`
+	Degree           []EtdmsDegree      `xml:"degree"`
+}
+
+// EtdmsContributor represents a contributor with an optional role (advisor, committee member)
+type EtdmsContributor struct {
+	Role  string `xml:"role,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// EtdmsDegree represents the thesis.degree element
+type EtdmsDegree struct {
+	Name       []string `xml:"name"`
+	Level      []string `xml:"level"`
+	Discipline []string `xml:"discipline"`
+	Grantor    []string `xml:"grantor"`
+}
+
+// MetadataETDMS represents the metadata wrapper for ETD-MS
+type MetadataETDMS struct {
+	ETDMS *ETDMS `xml:"http://www.ndltd.org/standards/metadata/etdms/1.0/ thesis,omitempty"`
+	Raw   []byte `xml:",innerxml"`
+}
+
+// RecordETDMS represents an OAI-PMH record with ETD-MS metadata
+type RecordETDMS struct {
+	Header   Header        `xml:"header"`
+	Metadata MetadataETDMS `xml:"metadata"`
+	About    *About        `xml:"about,omitempty"`
+}
+
+// IsDeleted returns true if the record has been deleted from the repository
+func (r RecordETDMS) IsDeleted() bool {
+	return r.Header.IsDeleted()
+}
+
+// ListRecordsETDMS contains the list of ETD-MS records from ListRecords verb
+type ListRecordsETDMS struct {
+	Records         []RecordETDMS    `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// GetRecordETDMS contains a single ETD-MS record from GetRecord verb
+type GetRecordETDMS struct {
+	Record RecordETDMS `xml:"record"`
+}
+
+// OAIPMHResponseETDMS represents the OAI-PMH response with ETD-MS metadata
+type OAIPMHResponseETDMS struct {
+	XMLName      xml.Name          `xml:"OAI-PMH"`
+	ResponseDate string            `xml:"responseDate"`
+	Request      OAIRequest        `xml:"request"`
+	ListRecords  *ListRecordsETDMS `xml:"ListRecords,omitempty"`
+	GetRecord    *GetRecordETDMS   `xml:"GetRecord,omitempty"`
+	Error        *OAIError         `xml:"error,omitempty"`
+}
+
+// EtdmsMetadata represents extracted ETD-MS metadata: the Dublin Core
+// elements merged with the degree information
+type EtdmsMetadata struct {
+	DCMetadata
+	AlternativeTitle []string `json:"alternative_title"`
+	Advisors         []string `json:"advisors"`
+	DegreeName       string   `json:"degree_name"`
+	DegreeLevel      string   `json:"degree_level"`
+	DegreeDiscipline []string `json:"degree_discipline"`
+	DegreeGrantor    string   `json:"degree_grantor"`
+}
+
+// ExtractEtdmsMetadata extracts ETD-MS metadata with deduplication
+func (e *ETDMS) ExtractEtdmsMetadata() *EtdmsMetadata {
+	if e == nil {
+		return nil
+	}
+
+	var contributors, advisors []string
+	for _, contributor := range e.Contributor {
+		name := strings.TrimSpace(contributor.Value)
+		if name == "" {
+			continue
+		}
+		contributors = append(contributors, name)
+		if strings.EqualFold(contributor.Role, "advisor") {
+			advisors = append(advisors, name)
+		}
+	}
+
+	metadata := &EtdmsMetadata{
+		DCMetadata: DCMetadata{
+			Title:       deduplicate(e.Title),
+			Creator:     deduplicate(e.Creator),
+			Subject:     deduplicate(e.Subject),
+			Description: deduplicate(e.Description),
+			Publisher:   deduplicate(e.Publisher),
+			Contributor: deduplicate(contributors),
+			Date:        deduplicate(e.Date),
+			Type:        deduplicate(e.Type),
+			Format:      deduplicate(e.Format),
+			Identifier:  deduplicate(e.Identifier),
+			Language:    deduplicate(e.Language),
+			Coverage:    deduplicate(e.Coverage),
+			Rights:      deduplicate(e.Rights),
+		},
+		AlternativeTitle: deduplicate(e.AlternativeTitle),
+		Advisors:         deduplicate(advisors),
+		DegreeDiscipline: []string{},
+	}
+
+	for _, degree := range e.Degree {
+		if metadata.DegreeName == "" {
+			metadata.DegreeName = firstNonEmpty(degree.Name)
+		}
+		if metadata.DegreeLevel == "" {
+			metadata.DegreeLevel = firstNonEmpty(degree.Level)
+		}
+		if metadata.DegreeGrantor == "" {
+			metadata.DegreeGrantor = firstNonEmpty(degree.Grantor)
+		}
+		metadata.DegreeDiscipline = append(metadata.DegreeDiscipline, degree.Discipline...)
+	}
+	metadata.DegreeDiscipline = deduplicate(metadata.DegreeDiscipline)
+
+	return metadata
+}
+
+// firstNonEmpty returns the first non-blank value, trimmed
+func firstNonEmpty(values []string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// ExtractAllEtdmsMetadata extracts metadata from all ETD-MS records in OAI-PMH response
+func (o *OAIPMHResponseETDMS) ExtractAllEtdmsMetadata() []*EtdmsMetadata {
+	var results []*EtdmsMetadata
+
+	for _, extractor := range o.GetRecords() {
+		if metadata := extractor.(*ETDMS).ExtractEtdmsMetadata(); metadata != nil {
+			results = append(results, metadata)
+		}
+	}
+
+	return results
+}
+
+// ParseOAIETDMSXML parses OAI-PMH XML data with ETD-MS metadata from bytes
+func ParseOAIETDMSXML(data []byte) (*OAIPMHResponseETDMS, error) {
+	var oaiResp OAIPMHResponseETDMS
+	if err := xml.Unmarshal(data, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, oaiResp.Error
+	}
+
+	return &oaiResp, nil
+}
+
+// decodeOAIPMHResponseETDMS stream-decodes a ETD-MS ListRecords or GetRecord response
+func decodeOAIPMHResponseETDMS(r io.Reader, page decodeOptions) (*OAIPMHResponseETDMS, error) {
+	env, records, err := decodeResponse(r, page, decodeRecord[RecordETDMS])
+	if err != nil {
+		return nil, err
+	}
+
+	oaiResp := &OAIPMHResponseETDMS{
+		XMLName:      xml.Name{Local: "OAI-PMH"},
+		ResponseDate: env.ResponseDate,
+		Request:      env.Request,
+	}
+
+	switch env.Verb {
+	case "ListRecords":
+		oaiResp.ListRecords = &ListRecordsETDMS{Records: records, ResumptionToken: env.ResumptionToken}
+	case "GetRecord":
+		if len(records) > 0 {
+			oaiResp.GetRecord = &GetRecordETDMS{Record: records[0]}
+		}
+	}
+
+	return oaiResp, nil
+}
+
+// Implement OAIResponse interface for OAIPMHResponseETDMS
+
+// GetRecords returns all records in the response as MetadataExtractor interface
+func (o *OAIPMHResponseETDMS) GetRecords() []MetadataExtractor {
+	var extractors []MetadataExtractor
+
+	for _, record := range o.harvestedRecords() {
+		if record.Metadata != nil {
+			extractors = append(extractors, record.Metadata)
+		}
+	}
+
+	return extractors
+}
+
+// GetResumptionToken returns the resumption token if available
+func (o *OAIPMHResponseETDMS) GetResumptionToken() string {
+	if o.ListRecords != nil && o.ListRecords.ResumptionToken != nil {
+		return o.ListRecords.ResumptionToken.Token
+	}
+	return ""
+}
+
+// GetResumptionTokenInfo returns the full resumption token if available
+func (o *OAIPMHResponseETDMS) GetResumptionTokenInfo() *ResumptionToken {
+	if o.ListRecords != nil {
+		return o.ListRecords.ResumptionToken
+	}
+	return nil
+}
+
+// HasError returns true if the response contains an error
+func (o *OAIPMHResponseETDMS) HasError() bool {
+	return o.Error != nil
+}
+
+// GetError returns the error information
+func (o *OAIPMHResponseETDMS) GetError() *OAIError {
+	return o.Error
+}
+
+// harvestedRecords returns all records in the response with their headers
+func (o *OAIPMHResponseETDMS) harvestedRecords() []HarvestedRecord {
+	var records []RecordETDMS
+	if o.ListRecords != nil {
+		records = o.ListRecords.Records
+	}
+	if o.GetRecord != nil {
+		records = append(records, o.GetRecord.Record)
+	}
+
+	results := make([]HarvestedRecord, 0, len(records))
+	for _, record := range records {
+		harvested := HarvestedRecord{Header: record.Header, Raw: record.Metadata.Raw, About: record.About}
+		if record.Metadata.ETDMS != nil {
+			harvested.Metadata = record.Metadata.ETDMS
+		}
+		results = append(results, harvested)
+	}
+
+	return results
+}
+
+// responseDate returns the responseDate of the response
+func (o *OAIPMHResponseETDMS) responseDate() string {
+	return o.ResponseDate
+}
+
+// Implement MetadataExtractor interface for ETDMS
+
+// ExtractMetadata extracts metadata from ETD-MS record
+func (m *ETDMS) ExtractMetadata() interface{} {
+	return m.ExtractEtdmsMetadata()
+}
+
+// GetFormat returns the metadata format type
+func (m *ETDMS) GetFormat() MetadataFormat {
+	return FormatETDMS
+}
@@ -0,0 +1,65 @@
+package goharvest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseOAIETDMSXML(t *testing.T) {
+	data, err := os.ReadFile("testdata/listrecords_etdms.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAIETDMSXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIETDMSXML failed: %v", err)
+	}
+
+	records := resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if records[0].GetFormat() != FormatETDMS {
+		t.Errorf("Expected format oai_etdms, got %s", records[0].GetFormat())
+	}
+
+	metadata, ok := records[0].ExtractMetadata().(*EtdmsMetadata)
+	if !ok {
+		t.Fatalf("Expected *EtdmsMetadata, got %T", records[0].ExtractMetadata())
+	}
+
+	if len(metadata.Title) != 1 || len(metadata.AlternativeTitle) != 1 || len(metadata.Subject) != 2 {
+		t.Errorf("Unexpected DC elements %+v", metadata.DCMetadata)
+	}
+	if len(metadata.Contributor) != 2 || len(metadata.Advisors) != 1 || metadata.Advisors[0] != "Purnomo, Hadi" {
+		t.Errorf("Unexpected contributors %v / advisors %v", metadata.Contributor, metadata.Advisors)
+	}
+	if metadata.DegreeName != "Master of Science" || metadata.DegreeLevel != "1" {
+		t.Errorf("Unexpected degree %s / %s", metadata.DegreeName, metadata.DegreeLevel)
+	}
+	if len(metadata.DegreeDiscipline) != 1 || metadata.DegreeGrantor != "Universitas Gadjah Mada" {
+		t.Errorf("Unexpected discipline/grantor %v / %s", metadata.DegreeDiscipline, metadata.DegreeGrantor)
+	}
+}
+
+func TestUnifiedHarvestETDMS(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_etdms.xml"})
+	client := NewClient(server.URL)
+
+	count := 0
+	err := client.Harvest("oai_etdms", nil, func(response OAIResponse) error {
+		for _, record := range response.GetRecords() {
+			if _, ok := record.ExtractMetadata().(*EtdmsMetadata); ok {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 ETD-MS record, got %d", count)
+	}
+}
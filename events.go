@@ -0,0 +1,145 @@
+package goharvest
+
+import (
+	"errors"
+	"time"
+)
+
+// HarvestEvent is an event reported to a HarvestObserver: PageFetched, RecordParsed,
+// RecordSkipped, RetryScheduled, TokenExpired, PageTruncated or HarvestFinished
+type HarvestEvent interface {
+	harvestEvent()
+}
+
+// PageFetched is reported when a ListRecords page has been fetched and parsed
+type PageFetched struct {
+	// Page is the number of the page in the harvest, starting at 1
+	Page int
+	// ResumptionToken is the token the page was requested with (empty for the first page)
+	ResumptionToken string
+	// NextResumptionToken is the token returned by the page (empty for the last page)
+	NextResumptionToken string
+	// Records is the number of records on the page, including deleted ones
+	Records int
+	// Duration is the time taken to fetch and parse the page
+	Duration time.Duration
+}
+
+// RecordParsed is reported for each record of a fetched page, before the page is
+// passed to the callback
+type RecordParsed struct {
+	Header Header
+}
+
+// RecordSkipped is reported when a malformed record is dropped in lenient mode
+type RecordSkipped struct {
+	Err *MalformedRecordError
+}
+
+// RetryScheduled is reported when a failed request will be retried
+type RetryScheduled struct {
+	// Verb is the OAI-PMH verb of the request
+	Verb string
+	// ResumptionToken is the token being retried (empty for the first page)
+	ResumptionToken string
+	// Attempt is the number of the retry, starting at 1
+	Attempt int
+	// Delay is the time waited before the retry
+	Delay time.Duration
+	// Err is the error of the failed attempt
+	Err error
+}
+
+// TokenExpired is reported when the repository rejects a resumption token with
+// badResumptionToken
+type TokenExpired struct {
+	ResumptionToken string
+	Err             error
+}
+
+// PageTruncated is reported when a ListRecords page exceeds HarvestOptions.MaxPageSize
+// and its remaining records are dropped
+type PageTruncated struct {
+	// ResumptionToken is the token the page was requested with (empty for the first page)
+	ResumptionToken string
+	// Records is the number of records kept from the page
+	Records int
+	// DroppedRecords is the number of records dropped
+	DroppedRecords int
+}
+
+// HarvestFinished is reported once when a harvest returns
+type HarvestFinished struct {
+	Stats HarvestStats
+	// Err is the error the harvest returned, nil on success
+	Err error
+}
+
+func (PageFetched) harvestEvent()     {}
+func (RecordParsed) harvestEvent()    {}
+func (RecordSkipped) harvestEvent()   {}
+func (RetryScheduled) harvestEvent()  {}
+func (TokenExpired) harvestEvent()    {}
+func (PageTruncated) harvestEvent()   {}
+func (HarvestFinished) harvestEvent() {}
+
+// HarvestObserver receives the events of a harvest. Events of one harvest are delivered
+// one at a time and in order, but with PrefetchPages they may be delivered from a
+// prefetching goroutine ahead of the callback.
+type HarvestObserver interface {
+	Observe(event HarvestEvent)
+}
+
+// ObserverFunc adapts a function to a HarvestObserver
+type ObserverFunc func(event HarvestEvent)
+
+// Observe implements HarvestObserver
+func (f ObserverFunc) Observe(event HarvestEvent) {
+	f(event)
+}
+
+// ChannelObserver sends the events to ch. Sends block, so ch must be drained while the
+// harvest runs.
+func ChannelObserver(ch chan<- HarvestEvent) HarvestObserver {
+	return ObserverFunc(func(event HarvestEvent) {
+		ch <- event
+	})
+}
+
+// emit reports an event to the harvest's observer, if any
+func (o *HarvestOptions) emit(event HarvestEvent) {
+	if o == nil || o.Observer == nil {
+		return
+	}
+	if o.run != nil {
+		o.run.observeMu.Lock()
+		defer o.run.observeMu.Unlock()
+	}
+	o.Observer.Observe(event)
+}
+
+// observePage reports a fetched page and its records
+func (o *HarvestOptions) observePage(page int, resumptionToken string, resp OAIResponse, d time.Duration) {
+	if o == nil || o.Observer == nil {
+		return
+	}
+	o.emit(PageFetched{
+		Page:                page,
+		ResumptionToken:     resumptionToken,
+		NextResumptionToken: resp.GetResumptionToken(),
+		Records:             countRecords(resp),
+		Duration:            d,
+	})
+	if lister, ok := resp.(recordLister); ok {
+		for _, record := range lister.harvestedRecords() {
+			o.emit(RecordParsed{Header: record.Header})
+		}
+	}
+}
+
+// observePageError reports a rejected resumption token
+func (o *HarvestOptions) observePageError(resumptionToken string, err error) {
+	if resumptionToken != "" && errors.Is(err, ErrBadResumptionToken) {
+		o.emit(TokenExpired{ResumptionToken: resumptionToken, Err: err})
+	}
+}
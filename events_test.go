@@ -0,0 +1,112 @@
+package goharvest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestObserverEvents(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+
+	var events []HarvestEvent
+	opts := &HarvestOptions{Observer: ObserverFunc(func(event HarvestEvent) {
+		events = append(events, event)
+	})}
+	if err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(HarvestedRecord) error { return nil }); err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+
+	if len(events) != 7 {
+		t.Fatalf("Expected 7 events, got %#v", events)
+	}
+	first, ok := events[0].(PageFetched)
+	if !ok || first.Page != 1 || first.ResumptionToken != "" || first.NextResumptionToken != "dc-page-2" || first.Records != 3 {
+		t.Errorf("Unexpected first event %#v", events[0])
+	}
+	if parsed, ok := events[1].(RecordParsed); !ok || parsed.Header.Identifier == "" {
+		t.Errorf("Expected a parsed record, got %#v", events[1])
+	}
+	if deleted, ok := events[2].(RecordParsed); !ok || !deleted.Header.IsDeleted() {
+		t.Errorf("Expected the deleted record, got %#v", events[2])
+	}
+	second, ok := events[4].(PageFetched)
+	if !ok || second.Page != 2 || second.ResumptionToken != "dc-page-2" || second.NextResumptionToken != "" || second.Records != 1 {
+		t.Errorf("Unexpected second page event %#v", events[4])
+	}
+	finished, ok := events[6].(HarvestFinished)
+	if !ok || finished.Err != nil || finished.Stats.Pages != 2 || finished.Stats.Records != 4 {
+		t.Errorf("Unexpected final event %#v", events[6])
+	}
+}
+
+func TestObserverSkippedRecords(t *testing.T) {
+	server := serveDocument(t, lenientTestPage)
+	events := make(chan HarvestEvent, 16)
+
+	opts := &HarvestOptions{Lenient: true, Observer: ChannelObserver(events)}
+	if err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(HarvestedRecord) error { return nil }); err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	close(events)
+
+	var skipped []*MalformedRecordError
+	var finished *HarvestFinished
+	for event := range events {
+		switch e := event.(type) {
+		case RecordSkipped:
+			skipped = append(skipped, e.Err)
+		case HarvestFinished:
+			finished = &e
+		}
+	}
+	if len(skipped) != 2 || skipped[0].Identifier != "oai:example.org:2" {
+		t.Errorf("Expected records 2 and 3 to be skipped, got %v", skipped)
+	}
+	if finished == nil || finished.Stats.SkippedRecords != 2 {
+		t.Errorf("Unexpected final event %#v", finished)
+	}
+}
+
+func TestObserverTokenExpired(t *testing.T) {
+	page1, err := os.ReadFile(filepath.Join("testdata", "listrecords_dc_page1.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("resumptionToken") != "" {
+			w.Write([]byte(`<OAI-PMH><error code="badResumptionToken">Token expired</error></OAI-PMH>`))
+			return
+		}
+		w.Write(page1)
+	}))
+	defer server.Close()
+
+	var expired []TokenExpired
+	var finished []HarvestFinished
+	opts := &HarvestOptions{Observer: ObserverFunc(func(event HarvestEvent) {
+		switch e := event.(type) {
+		case TokenExpired:
+			expired = append(expired, e)
+		case HarvestFinished:
+			finished = append(finished, e)
+		}
+	})}
+	err = NewClient(server.URL).HarvestRecords("oai_dc", opts, func(HarvestedRecord) error { return nil })
+	if !errors.Is(err, ErrBadResumptionToken) {
+		t.Fatalf("Expected badResumptionToken, got %v", err)
+	}
+
+	if len(expired) != 1 || expired[0].ResumptionToken != "dc-page-2" || !errors.Is(expired[0].Err, ErrBadResumptionToken) {
+		t.Errorf("Unexpected token events %v", expired)
+	}
+	if len(finished) != 1 || !errors.Is(finished[0].Err, ErrBadResumptionToken) || finished[0].Stats.Pages != 1 {
+		t.Errorf("Unexpected final events %v", finished)
+	}
+}
@@ -0,0 +1,202 @@
+package goharvest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// bibtexEscaper escapes characters with a special meaning in BibTeX values
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`,
+	"}", `\}`,
+	"&", `\&`,
+	"%", `\%`,
+	"$", `\$`,
+	"#", `\#`,
+	"_", `\_`,
+	"~", `\textasciitilde{}`,
+	"^", `\textasciicircum{}`,
+)
+
+// BibTeXType returns the BibTeX entry type for the record
+func (b *BibRecord) BibTeXType() string {
+	switch b.Type {
+	case BibTypeBook:
+		return "book"
+	case BibTypeArticle:
+		return "article"
+	case BibTypeThesis:
+		return "phdthesis"
+	}
+	return "misc"
+}
+
+// CiteKey generates a citation key from the first author's family name, the year and the
+// first significant title word, e.g. "toer1980bumi"
+func (b *BibRecord) CiteKey() string {
+	var family string
+	if authors := b.Authors(); len(authors) > 0 {
+		family, _ = authors[0].FamilyGiven()
+	}
+
+	var word string
+	for _, w := range strings.Fields(b.Title) {
+		if w = citeKeyPart(w); word == "" || (len(word) <= 3 && len(w) > 3) {
+			word = w
+		}
+		if len(word) > 3 {
+			break
+		}
+	}
+
+	key := citeKeyPart(family) + b.Year() + word
+	if key == "" {
+		key = citeKeyPart(b.ID)
+	}
+	if key == "" {
+		key = "record"
+	}
+	return key
+}
+
+// citeKeyPart keeps the lowercase ASCII letters and digits of s, folding common accented letters
+func citeKeyPart(s string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if folded, ok := asciiFolds[r]; ok {
+			r = folded
+		}
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// asciiFolds maps common accented lowercase letters to ASCII
+var asciiFolds = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a', 'å': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o', 'ø': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n', 'ý': 'y', 'ÿ': 'y',
+}
+
+// BibTeX formats the record as a BibTeX entry using its CiteKey
+func (b *BibRecord) BibTeX() string {
+	return b.bibTeX(b.CiteKey())
+}
+
+// bibTeX formats the record as a BibTeX entry with the given key
+func (b *BibRecord) bibTeX(key string) string {
+	var fields [][2]string
+	addEscaped := func(name, value string) {
+		if value != "" {
+			fields = append(fields, [2]string{name, value})
+		}
+	}
+	add := func(name, value string) {
+		addEscaped(name, bibtexEscaper.Replace(strings.TrimSpace(value)))
+	}
+
+	var authors, editors []string
+	for _, contributor := range b.Contributors {
+		name := bibtexEscaper.Replace(contributor.Name)
+		if family, given := contributor.FamilyGiven(); given == "" && family != "" {
+			// protect corporate and single-word names from being split by BibTeX
+			name = "{" + name + "}"
+		}
+		switch {
+		case hasRole(contributor.Roles, "editor"):
+			editors = append(editors, name)
+		case len(contributor.Roles) == 0 || hasRole(contributor.Roles, "author") || hasRole(contributor.Roles, "creator"):
+			authors = append(authors, name)
+		}
+	}
+
+	addEscaped("author", strings.Join(authors, " and "))
+	addEscaped("editor", strings.Join(editors, " and "))
+	add("title", b.FullTitle())
+	add("year", b.Year())
+	switch b.BibTeXType() {
+	case "phdthesis":
+		add("school", b.Publisher)
+	case "article":
+		add("journal", firstNonEmpty(b.Series))
+	default:
+		add("publisher", b.Publisher)
+		add("series", firstNonEmpty(b.Series))
+	}
+	add("address", b.PublishPlace)
+	add("edition", b.Edition)
+	add("isbn", b.IdentifierValue("isbn"))
+	add("issn", b.IdentifierValue("issn"))
+	add("doi", b.IdentifierValue("doi"))
+	if len(b.Links) > 0 {
+		add("url", b.Links[0].URL)
+	}
+	add("language", b.Language)
+	add("keywords", strings.Join(b.Subjects, ", "))
+	add("abstract", b.Abstract)
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "@%s{%s,\n", b.BibTeXType(), key)
+	for i, field := range fields {
+		fmt.Fprintf(&builder, "  %s = {%s}", field[0], field[1])
+		if i < len(fields)-1 {
+			builder.WriteByte(',')
+		}
+		builder.WriteByte('\n')
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// WriteBibTeX writes the records as BibTeX entries, appending a, b, c... to duplicate cite keys
+func WriteBibTeX(w io.Writer, records []*BibRecord) error {
+	seen := make(map[string]int)
+	for i, record := range records {
+		key := record.CiteKey()
+		if n := seen[key]; n > 0 {
+			seen[key]++
+			key += citeKeySuffix(n)
+		} else {
+			seen[key] = 1
+		}
+
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, record.bibTeX(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// citeKeySuffix returns "a" for 1, "b" for 2, ..., "aa" for 27
+func citeKeySuffix(n int) string {
+	suffix := ""
+	for n > 0 {
+		n--
+		suffix = string(rune('a'+n%26)) + suffix
+		n /= 26
+	}
+	return suffix
+}
+
+// hasRole reports whether roles contains role, ignoring case
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
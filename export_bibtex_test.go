@@ -0,0 +1,78 @@
+package goharvest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func testBibRecord() *BibRecord {
+	bib := newBibRecord(FormatMARCXML)
+	bib.ID = "REC-1"
+	bib.Type = BibTypeBook
+	bib.Title = "Bumi manusia"
+	bib.Subtitle = "roman"
+	bib.Contributors = []BibContributor{
+		{Name: "Toer, Pramoedya Ananta", Roles: []string{"author"}, Type: "personal"},
+		{Name: "Lane, Max", Roles: []string{"translator"}, Type: "personal"},
+		{Name: "Hasta Mitra", Roles: []string{"editor"}, Type: "corporate"},
+	}
+	bib.Identifiers = []BibIdentifier{{Type: "isbn", Value: "9780306406157"}}
+	bib.Dates = []BibDate{{Type: "issued", Value: "c1980."}}
+	bib.Publisher = "Hasta Mitra & Co"
+	bib.PublishPlace = "Jakarta"
+	bib.Subjects = []string{"Indonesian fiction", "Colonialism -- Java"}
+	bib.Links = []BibLink{{URL: "http://example.org/1?a=1&b=2_3"}}
+	return bib
+}
+
+func TestBibTeX(t *testing.T) {
+	bib := testBibRecord()
+
+	if key := bib.CiteKey(); key != "toer1980bumi" {
+		t.Errorf("CiteKey() = %q", key)
+	}
+
+	entry := bib.BibTeX()
+	for _, want := range []string{
+		"@book{toer1980bumi,\n",
+		"  author = {Toer, Pramoedya Ananta},\n",
+		"  editor = {{Hasta Mitra}},\n",
+		"  title = {Bumi manusia: roman},\n",
+		"  year = {1980},\n",
+		"  publisher = {Hasta Mitra \\& Co},\n",
+		"  isbn = {9780306406157},\n",
+		"  url = {http://example.org/1?a=1\\&b=2\\_3},\n",
+		"  keywords = {Indonesian fiction, Colonialism -- Java}\n}\n",
+	} {
+		if !strings.Contains(entry, want) {
+			t.Errorf("Expected %q in entry:\n%s", want, entry)
+		}
+	}
+	if strings.Contains(entry, "Lane") {
+		t.Error("Expected translator not to be listed as author")
+	}
+}
+
+func TestBibTeXTypesAndKeys(t *testing.T) {
+	thesis := &BibRecord{Type: BibTypeThesis, Title: "Ode", Publisher: "Universitas Gadjah Mada"}
+	if !strings.HasPrefix(thesis.BibTeX(), "@phdthesis{ode,") || !strings.Contains(thesis.BibTeX(), "school = {Universitas Gadjah Mada}") {
+		t.Errorf("Unexpected thesis entry:\n%s", thesis.BibTeX())
+	}
+	if (&BibRecord{Type: BibTypeDataset}).BibTeXType() != "misc" {
+		t.Error("Expected datasets to be @misc")
+	}
+	if key := (&BibRecord{Contributors: []BibContributor{{Name: "Jürgen Müller"}}, Title: "Über das"}).CiteKey(); key != "mulleruber" {
+		t.Errorf("CiteKey() = %q, want mulleruber", key)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBibTeX(&buf, []*BibRecord{testBibRecord(), testBibRecord(), testBibRecord()}); err != nil {
+		t.Fatalf("WriteBibTeX failed: %v", err)
+	}
+	for _, key := range []string{"{toer1980bumi,", "{toer1980bumia,", "{toer1980bumib,"} {
+		if !strings.Contains(buf.String(), key) {
+			t.Errorf("Expected key %s in output", key)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package goharvest
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CSLItem is a CSL-JSON item as consumed by citeproc processors
+type CSLItem struct {
+	ID              string    `json:"id"`
+	Type            string    `json:"type"`
+	Title           string    `json:"title,omitempty"`
+	Author          []CSLName `json:"author,omitempty"`
+	Editor          []CSLName `json:"editor,omitempty"`
+	Translator      []CSLName `json:"translator,omitempty"`
+	Issued          *CSLDate  `json:"issued,omitempty"`
+	Publisher       string    `json:"publisher,omitempty"`
+	PublisherPlace  string    `json:"publisher-place,omitempty"`
+	ContainerTitle  string    `json:"container-title,omitempty"`
+	CollectionTitle string    `json:"collection-title,omitempty"`
+	Edition         string    `json:"edition,omitempty"`
+	Genre           string    `json:"genre,omitempty"`
+	ISBN            string    `json:"ISBN,omitempty"`
+	ISSN            string    `json:"ISSN,omitempty"`
+	DOI             string    `json:"DOI,omitempty"`
+	URL             string    `json:"URL,omitempty"`
+	Abstract        string    `json:"abstract,omitempty"`
+	Keyword         string    `json:"keyword,omitempty"`
+	Language        string    `json:"language,omitempty"`
+}
+
+// CSLName is a CSL name; corporate names use Literal
+type CSLName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+// CSLDate is a CSL date with date-parts such as [[2019, 8, 1]]
+type CSLDate struct {
+	DateParts [][]int `json:"date-parts,omitempty"`
+	Raw       string  `json:"raw,omitempty"`
+}
+
+// cslTypes maps BibTypes to CSL item types
+var cslTypes = map[BibType]string{
+	BibTypeBook:     "book",
+	BibTypeArticle:  "article-journal",
+	BibTypeSerial:   "periodical",
+	BibTypeThesis:   "thesis",
+	BibTypeDataset:  "dataset",
+	BibTypeSoftware: "software",
+	BibTypeImage:    "graphic",
+	BibTypeMap:      "map",
+	BibTypeMusic:    "musical_score",
+	BibTypeSound:    "song",
+	BibTypeVideo:    "motion_picture",
+}
+
+// cslDatePattern matches "2019", "2019-08" or "2019-08-01" anywhere in a date string
+var cslDatePattern = regexp.MustCompile(`(?:^|[^0-9])(\d{4})(?:-(\d{1,2})(?:-(\d{1,2}))?)?`)
+
+// CSL converts the record to a CSL-JSON item
+func (b *BibRecord) CSL() CSLItem {
+	item := CSLItem{
+		ID:             firstNonEmpty([]string{b.ID, b.CiteKey()}),
+		Type:           "document",
+		Title:          b.FullTitle(),
+		Publisher:      b.Publisher,
+		PublisherPlace: b.PublishPlace,
+		Edition:        b.Edition,
+		ISBN:           b.IdentifierValue("isbn"),
+		ISSN:           b.IdentifierValue("issn"),
+		DOI:            b.IdentifierValue("doi"),
+		Abstract:       b.Abstract,
+		Keyword:        strings.Join(b.Subjects, ", "),
+		Language:       b.Language,
+	}
+	if cslType, ok := cslTypes[b.Type]; ok {
+		item.Type = cslType
+	}
+	if b.Type == BibTypeArticle {
+		item.ContainerTitle = firstNonEmpty(b.Series)
+	} else {
+		item.CollectionTitle = firstNonEmpty(b.Series)
+	}
+	if len(b.Links) > 0 {
+		item.URL = b.Links[0].URL
+	}
+
+	for _, contributor := range b.Contributors {
+		name := contributor.cslName()
+		switch {
+		case hasRole(contributor.Roles, "editor"):
+			item.Editor = append(item.Editor, name)
+		case hasRole(contributor.Roles, "translator"):
+			item.Translator = append(item.Translator, name)
+		case len(contributor.Roles) == 0 || hasRole(contributor.Roles, "author") || hasRole(contributor.Roles, "creator"):
+			item.Author = append(item.Author, name)
+		}
+	}
+
+	for _, date := range b.Dates {
+		if date.Type == "issued" {
+			item.Issued = parseCSLDate(date.Value)
+			break
+		}
+	}
+	if item.Issued == nil && len(b.Dates) > 0 {
+		item.Issued = parseCSLDate(b.Dates[0].Value)
+	}
+
+	return item
+}
+
+// cslName splits the contributor into family and given names, using a literal for corporate names
+func (c BibContributor) cslName() CSLName {
+	family, given := c.FamilyGiven()
+	if given == "" {
+		return CSLName{Literal: family}
+	}
+	return CSLName{Family: family, Given: given}
+}
+
+// parseCSLDate converts "2019-08-01", "2019" or "c1980." into date-parts, keeping the raw
+// string when no year can be found
+func parseCSLDate(value string) *CSLDate {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	match := cslDatePattern.FindStringSubmatch(value)
+	if match == nil {
+		return &CSLDate{Raw: value}
+	}
+
+	var parts []int
+	for _, part := range match[1:] {
+		if part == "" {
+			break
+		}
+		n, _ := strconv.Atoi(part)
+		parts = append(parts, n)
+	}
+	return &CSLDate{DateParts: [][]int{parts}}
+}
+
+// WriteCSLJSON writes the records as a CSL-JSON array
+func WriteCSLJSON(w io.Writer, records []*BibRecord) error {
+	items := make([]CSLItem, 0, len(records))
+	for _, record := range records {
+		items = append(items, record.CSL())
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
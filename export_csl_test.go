@@ -0,0 +1,58 @@
+package goharvest
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCSL(t *testing.T) {
+	item := testBibRecord().CSL()
+
+	if item.ID != "REC-1" || item.Type != "book" || item.Title != "Bumi manusia: roman" || item.ISBN != "9780306406157" {
+		t.Errorf("Unexpected item %+v", item)
+	}
+	if want := []CSLName{{Family: "Toer", Given: "Pramoedya Ananta"}}; !reflect.DeepEqual(item.Author, want) {
+		t.Errorf("Author = %+v, want %+v", item.Author, want)
+	}
+	if len(item.Translator) != 1 || item.Editor[0].Literal != "Hasta Mitra" {
+		t.Errorf("Unexpected translator/editor %+v / %+v", item.Translator, item.Editor)
+	}
+	if item.Issued == nil || !reflect.DeepEqual(item.Issued.DateParts, [][]int{{1980}}) {
+		t.Errorf("Unexpected issued %+v", item.Issued)
+	}
+}
+
+func TestParseCSLDate(t *testing.T) {
+	if got := parseCSLDate("2019-08-01"); !reflect.DeepEqual(got.DateParts, [][]int{{2019, 8, 1}}) {
+		t.Errorf("Unexpected date-parts %+v", got)
+	}
+	if got := parseCSLDate("[s.a.]"); got.Raw != "[s.a.]" || got.DateParts != nil {
+		t.Errorf("Expected raw date, got %+v", got)
+	}
+	if parseCSLDate(" ") != nil {
+		t.Error("Expected nil for empty date")
+	}
+}
+
+func TestWriteCSLJSON(t *testing.T) {
+	thesis := &BibRecord{Type: BibTypeThesis, Title: "Batik", Contributors: []BibContributor{{Name: "Dewi Sari"}}, Dates: []BibDate{{Type: "created", Value: "2020-05"}}}
+
+	var buf bytes.Buffer
+	if err := WriteCSLJSON(&buf, []*BibRecord{thesis}); err != nil {
+		t.Fatalf("WriteCSLJSON failed: %v", err)
+	}
+
+	var items []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &items); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if len(items) != 1 || items[0]["type"] != "thesis" || items[0]["id"] != "sari2020batik" {
+		t.Errorf("Unexpected items %v", items)
+	}
+	issued := items[0]["issued"].(map[string]interface{})["date-parts"].([]interface{})[0].([]interface{})
+	if len(issued) != 2 || issued[1].(float64) != 5 {
+		t.Errorf("Unexpected issued %v", issued)
+	}
+}
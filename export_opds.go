@@ -0,0 +1,296 @@
+package goharvest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// OPDS link relations and media types
+const (
+	OPDSRelAcquisition      = "http://opds-spec.org/acquisition"
+	OPDSRelOpenAccess       = "http://opds-spec.org/acquisition/open-access"
+	OPDSRelImage            = "http://opds-spec.org/image"
+	OPDSAcquisitionFeedType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	OPDS2FeedType           = "application/opds+json"
+)
+
+// Feed defaults and fallback media types
+const (
+	opdsDefaultFeedTitle        = "Harvested records"
+	opdsDefaultFeedID           = "urn:goharvest:opds"
+	opdsLandingPageType         = "text/html"
+	opdsAcquisitionFallbackType = "application/octet-stream"
+)
+
+// OPDSFeedOptions describes the feed that wraps the exported records
+type OPDSFeedOptions struct {
+	ID      string    // feed id; defaults to "urn:goharvest:opds"
+	Title   string    // feed title; defaults to "Harvested records"
+	SelfURL string    // URL the feed is served from
+	Updated time.Time // defaults to now
+}
+
+// withDefaults fills the unset options
+func (o OPDSFeedOptions) withDefaults() OPDSFeedOptions {
+	if o.ID == "" {
+		o.ID = opdsDefaultFeedID
+	}
+	if o.Title == "" {
+		o.Title = opdsDefaultFeedTitle
+	}
+	if o.Updated.IsZero() {
+		o.Updated = time.Now()
+	}
+	return o
+}
+
+// opdsMediaTypes maps file extensions of 856 links to media types
+var opdsMediaTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".epub": "application/epub+zip",
+	".mobi": "application/x-mobipocket-ebook",
+	".djvu": "image/vnd.djvu",
+	".txt":  "text/plain",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+}
+
+// opdsLink is a classified record link
+type opdsLink struct {
+	Rel  string
+	Href string
+	Type string
+}
+
+// opdsLinks classifies the record links into acquisition, image and landing-page links
+func (b *BibRecord) opdsLinks() []opdsLink {
+	var links []opdsLink
+	for _, link := range b.Links {
+		mediaType := opdsMediaTypes[strings.ToLower(path.Ext(strings.SplitN(link.URL, "?", 2)[0]))]
+		label := strings.ToLower(link.Label)
+
+		switch {
+		case strings.HasPrefix(mediaType, "image/") && mediaType != "image/vnd.djvu",
+			strings.Contains(label, "cover"), strings.Contains(label, "sampul"):
+			if mediaType == "" {
+				mediaType = "image/jpeg"
+			}
+			links = append(links, opdsLink{Rel: OPDSRelImage, Href: link.URL, Type: mediaType})
+		case mediaType != "":
+			links = append(links, opdsLink{Rel: OPDSRelOpenAccess, Href: link.URL, Type: mediaType})
+		case link.Relation == LinkRelationshipResource && strings.Contains(label, "full text"):
+			links = append(links, opdsLink{Rel: OPDSRelOpenAccess, Href: link.URL, Type: opdsAcquisitionFallbackType})
+		default:
+			links = append(links, opdsLink{Rel: "alternate", Href: link.URL, Type: opdsLandingPageType})
+		}
+	}
+	return links
+}
+
+// opdsID returns a URI identifying the record inside the feed
+func (b *BibRecord) opdsID(feedID string) string {
+	if isbn := b.IdentifierValue("isbn"); isbn != "" {
+		return "urn:isbn:" + isbn
+	}
+	if doi := b.IdentifierValue("doi"); doi != "" {
+		return "https://doi.org/" + doi
+	}
+	return feedID + ":" + firstNonEmpty([]string{b.ID, b.CiteKey()})
+}
+
+// opdsAtomFeed is an OPDS 1.2 acquisition feed
+type opdsAtomFeed struct {
+	XMLName xml.Name        `xml:"http://www.w3.org/2005/Atom feed"`
+	DCNS    string          `xml:"xmlns:dc,attr"`
+	OPDSNS  string          `xml:"xmlns:opds,attr"`
+	ID      string          `xml:"id"`
+	Title   string          `xml:"title"`
+	Updated string          `xml:"updated"`
+	Links   []opdsAtomLink  `xml:"link"`
+	Entries []opdsAtomEntry `xml:"entry"`
+}
+
+// opdsAtomLink is an Atom link
+type opdsAtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// opdsAtomEntry is an OPDS catalog entry
+type opdsAtomEntry struct {
+	Title      string             `xml:"title"`
+	ID         string             `xml:"id"`
+	Updated    string             `xml:"updated"`
+	Authors    []opdsAtomAuthor   `xml:"author"`
+	Identifier []string           `xml:"dc:identifier"`
+	Publisher  string             `xml:"dc:publisher,omitempty"`
+	Issued     string             `xml:"dc:issued,omitempty"`
+	Language   string             `xml:"dc:language,omitempty"`
+	Categories []opdsAtomCategory `xml:"category"`
+	Summary    string             `xml:"summary,omitempty"`
+	Links      []opdsAtomLink     `xml:"link"`
+}
+
+// opdsAtomAuthor is an Atom author
+type opdsAtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// opdsAtomCategory is an Atom category
+type opdsAtomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+// WriteOPDS writes the records as an OPDS 1.2 acquisition feed (Atom)
+func WriteOPDS(w io.Writer, opts OPDSFeedOptions, records []*BibRecord) error {
+	opts = opts.withDefaults()
+	updated := opts.Updated.UTC().Format(time.RFC3339)
+
+	feed := opdsAtomFeed{
+		DCNS:    "http://purl.org/dc/terms/",
+		OPDSNS:  "http://opds-spec.org/2010/catalog",
+		ID:      opts.ID,
+		Title:   opts.Title,
+		Updated: updated,
+	}
+	if opts.SelfURL != "" {
+		feed.Links = append(feed.Links, opdsAtomLink{Rel: "self", Href: opts.SelfURL, Type: OPDSAcquisitionFeedType})
+	}
+
+	for _, record := range records {
+		entry := opdsAtomEntry{
+			Title:     record.FullTitle(),
+			ID:        record.opdsID(opts.ID),
+			Updated:   updated,
+			Publisher: record.Publisher,
+			Issued:    record.Year(),
+			Language:  record.Language,
+			Summary:   record.Abstract,
+		}
+		for _, author := range record.Authors() {
+			entry.Authors = append(entry.Authors, opdsAtomAuthor{Name: author.Name})
+		}
+		for _, id := range record.Identifiers {
+			if id.Type == "isbn" {
+				entry.Identifier = append(entry.Identifier, "urn:isbn:"+id.Value)
+			}
+		}
+		for _, subject := range record.Subjects {
+			entry.Categories = append(entry.Categories, opdsAtomCategory{Term: subject, Label: subject})
+		}
+		for _, link := range record.opdsLinks() {
+			entry.Links = append(entry.Links, opdsAtomLink(link))
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// opds2Feed is an OPDS 2.0 feed
+type opds2Feed struct {
+	Metadata     opds2FeedMetadata  `json:"metadata"`
+	Links        []opds2Link        `json:"links"`
+	Publications []opds2Publication `json:"publications"`
+}
+
+// opds2FeedMetadata is the metadata of an OPDS 2.0 feed
+type opds2FeedMetadata struct {
+	Title    string `json:"title"`
+	Modified string `json:"modified"`
+}
+
+// opds2Link is an OPDS 2.0 link
+type opds2Link struct {
+	Rel  string `json:"rel,omitempty"`
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+// opds2Publication is an OPDS 2.0 publication
+type opds2Publication struct {
+	Metadata opds2Metadata `json:"metadata"`
+	Links    []opds2Link   `json:"links"`
+	Images   []opds2Link   `json:"images,omitempty"`
+}
+
+// opds2Metadata is the Readium Web Publication metadata of a publication
+type opds2Metadata struct {
+	Type        string             `json:"@type"`
+	Title       string             `json:"title"`
+	Identifier  string             `json:"identifier,omitempty"`
+	Author      []opds2Contributor `json:"author,omitempty"`
+	Publisher   string             `json:"publisher,omitempty"`
+	Published   string             `json:"published,omitempty"`
+	Language    string             `json:"language,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Subject     []string           `json:"subject,omitempty"`
+}
+
+// opds2Contributor is an OPDS 2.0 contributor
+type opds2Contributor struct {
+	Name string `json:"name"`
+}
+
+// WriteOPDS2 writes the records as an OPDS 2.0 (JSON) feed
+func WriteOPDS2(w io.Writer, opts OPDSFeedOptions, records []*BibRecord) error {
+	opts = opts.withDefaults()
+
+	feed := opds2Feed{
+		Metadata:     opds2FeedMetadata{Title: opts.Title, Modified: opts.Updated.UTC().Format(time.RFC3339)},
+		Links:        []opds2Link{},
+		Publications: make([]opds2Publication, 0, len(records)),
+	}
+	if opts.SelfURL != "" {
+		feed.Links = append(feed.Links, opds2Link{Rel: "self", Href: opts.SelfURL, Type: OPDS2FeedType})
+	}
+
+	for _, record := range records {
+		work := record.SchemaOrg()
+		publication := opds2Publication{
+			Metadata: opds2Metadata{
+				Type:        "http://schema.org/" + work.Type,
+				Title:       record.FullTitle(),
+				Identifier:  record.opdsID(opts.ID),
+				Publisher:   record.Publisher,
+				Published:   record.Year(),
+				Language:    record.Language,
+				Description: record.Abstract,
+				Subject:     record.Subjects,
+			},
+			Links: []opds2Link{},
+		}
+		for _, author := range record.Authors() {
+			publication.Metadata.Author = append(publication.Metadata.Author, opds2Contributor{Name: author.Name})
+		}
+		for _, link := range record.opdsLinks() {
+			if link.Rel == OPDSRelImage {
+				publication.Images = append(publication.Images, opds2Link{Href: link.Href, Type: link.Type})
+			} else {
+				publication.Links = append(publication.Links, opds2Link(link))
+			}
+		}
+		feed.Publications = append(feed.Publications, publication)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(feed)
+}
@@ -0,0 +1,81 @@
+package goharvest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func opdsTestRecords() []*BibRecord {
+	book := testBibRecord()
+	book.Links = []BibLink{
+		{URL: "http://example.org/opac?id=1", Relation: LinkRelationshipResource},
+		{URL: "http://example.org/files/1.pdf", Label: "Full text", Relation: LinkRelationshipResource},
+		{URL: "http://example.org/covers/1", Label: "Cover image", Relation: LinkRelationshipRelatedResource},
+	}
+	return []*BibRecord{book, {ID: "REC-2", Title: "Tanpa ISBN"}}
+}
+
+func TestWriteOPDS(t *testing.T) {
+	opts := OPDSFeedOptions{Title: "Koleksi digital", SelfURL: "http://example.org/opds", Updated: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	var buf bytes.Buffer
+	if err := WriteOPDS(&buf, opts, opdsTestRecords()); err != nil {
+		t.Fatalf("WriteOPDS failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/terms/" xmlns:opds="http://opds-spec.org/2010/catalog">`,
+		`<updated>2024-01-02T03:04:05Z</updated>`,
+		`<link rel="self" href="http://example.org/opds" type="application/atom+xml;profile=opds-catalog;kind=acquisition"></link>`,
+		`<id>urn:isbn:9780306406157</id>`,
+		`<dc:identifier>urn:isbn:9780306406157</dc:identifier>`,
+		`<link rel="http://opds-spec.org/acquisition/open-access" href="http://example.org/files/1.pdf" type="application/pdf"></link>`,
+		`<link rel="http://opds-spec.org/image" href="http://example.org/covers/1" type="image/jpeg"></link>`,
+		`<link rel="alternate" href="http://example.org/opac?id=1" type="text/html"></link>`,
+		`<id>urn:goharvest:opds:REC-2</id>`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in feed:\n%s", want, output)
+		}
+	}
+
+	var feed struct {
+		Entries []struct {
+			Title string `xml:"title"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &feed); err != nil || len(feed.Entries) != 2 {
+		t.Errorf("Expected a well-formed feed with 2 entries, got %v / %+v", err, feed)
+	}
+}
+
+func TestWriteOPDS2(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteOPDS2(&buf, OPDSFeedOptions{SelfURL: "http://example.org/opds.json"}, opdsTestRecords()); err != nil {
+		t.Fatalf("WriteOPDS2 failed: %v", err)
+	}
+
+	var feed struct {
+		Metadata     map[string]string `json:"metadata"`
+		Links        []map[string]string
+		Publications []struct {
+			Metadata map[string]interface{} `json:"metadata"`
+			Links    []map[string]string    `json:"links"`
+			Images   []map[string]string    `json:"images"`
+		} `json:"publications"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &feed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if feed.Metadata["title"] != "Harvested records" || feed.Links[0]["type"] != OPDS2FeedType || len(feed.Publications) != 2 {
+		t.Fatalf("Unexpected feed %+v", feed)
+	}
+	book := feed.Publications[0]
+	if book.Metadata["@type"] != "http://schema.org/Book" || len(book.Images) != 1 || len(book.Links) != 2 {
+		t.Errorf("Unexpected publication %+v", book)
+	}
+}
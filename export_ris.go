@@ -0,0 +1,102 @@
+package goharvest
+
+import (
+	"io"
+	"strings"
+)
+
+// risTypes maps BibTypes to RIS reference types
+var risTypes = map[BibType]string{
+	BibTypeBook:     "BOOK",
+	BibTypeArticle:  "JOUR",
+	BibTypeSerial:   "SER",
+	BibTypeThesis:   "THES",
+	BibTypeDataset:  "DATA",
+	BibTypeSoftware: "COMP",
+	BibTypeImage:    "ART",
+	BibTypeMap:      "MAP",
+	BibTypeMusic:    "MUSIC",
+	BibTypeSound:    "SOUND",
+	BibTypeVideo:    "VIDEO",
+}
+
+// RISType returns the RIS reference type (TY) for the record
+func (b *BibRecord) RISType() string {
+	if risType, ok := risTypes[b.Type]; ok {
+		return risType
+	}
+	return "GEN"
+}
+
+// RIS formats the record as a RIS reference for Zotero, EndNote or Mendeley.
+// Lines end with CRLF as the RIS specification requires.
+func (b *BibRecord) RIS() string {
+	var builder strings.Builder
+	add := func(tag, value string) {
+		value = strings.Join(strings.Fields(value), " ")
+		if value != "" {
+			builder.WriteString(tag + "  - " + value + "\r\n")
+		}
+	}
+
+	add("TY", b.RISType())
+	for _, contributor := range b.Contributors {
+		switch {
+		case hasRole(contributor.Roles, "editor"):
+			add("ED", contributor.Name)
+		case len(contributor.Roles) == 0 || hasRole(contributor.Roles, "author") || hasRole(contributor.Roles, "creator"):
+			add("AU", contributor.Name)
+		default:
+			add("A4", contributor.Name)
+		}
+	}
+	add("TI", b.FullTitle())
+	for _, title := range b.AlternativeTitles {
+		add("TT", title)
+	}
+	if b.Type == BibTypeArticle {
+		add("T2", firstNonEmpty(b.Series))
+	} else {
+		add("T3", firstNonEmpty(b.Series))
+	}
+	add("PY", b.Year())
+	for _, date := range b.Dates {
+		if date.Type == "issued" {
+			add("DA", date.Value)
+			break
+		}
+	}
+	add("PB", b.Publisher)
+	add("CY", b.PublishPlace)
+	add("ET", b.Edition)
+	for _, id := range b.Identifiers {
+		switch id.Type {
+		case "isbn", "issn":
+			add("SN", id.Value)
+		case "doi":
+			add("DO", id.Value)
+		}
+	}
+	for _, link := range b.Links {
+		add("UR", link.URL)
+	}
+	for _, subject := range b.Subjects {
+		add("KW", subject)
+	}
+	add("AB", b.Abstract)
+	add("LA", b.Language)
+	add("ID", b.ID)
+	builder.WriteString("ER  - \r\n")
+
+	return builder.String()
+}
+
+// WriteRIS writes the records as consecutive RIS references
+func WriteRIS(w io.Writer, records []*BibRecord) error {
+	for _, record := range records {
+		if _, err := io.WriteString(w, record.RIS()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package goharvest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRIS(t *testing.T) {
+	bib := testBibRecord()
+	bib.Identifiers = append(bib.Identifiers, BibIdentifier{Type: "doi", Value: "10.1234/abc"})
+
+	ris := bib.RIS()
+	want := strings.Join([]string{
+		"TY  - BOOK",
+		"AU  - Toer, Pramoedya Ananta",
+		"A4  - Lane, Max",
+		"ED  - Hasta Mitra",
+		"TI  - Bumi manusia: roman",
+		"PY  - 1980",
+		"DA  - c1980.",
+		"PB  - Hasta Mitra & Co",
+		"CY  - Jakarta",
+		"SN  - 9780306406157",
+		"DO  - 10.1234/abc",
+		"UR  - http://example.org/1?a=1&b=2_3",
+		"KW  - Indonesian fiction",
+		"KW  - Colonialism -- Java",
+		"ID  - REC-1",
+		"ER  - ",
+		"",
+	}, "\r\n")
+	if ris != want {
+		t.Errorf("RIS() =\n%s\nwant\n%s", ris, want)
+	}
+}
+
+func TestWriteRIS(t *testing.T) {
+	article := &BibRecord{Type: BibTypeArticle, Title: "Batik", Series: []string{"Jurnal Seni"}, Abstract: "Line one\nline two"}
+
+	var buf bytes.Buffer
+	if err := WriteRIS(&buf, []*BibRecord{article, {Type: BibTypeOther, Title: "Other"}}); err != nil {
+		t.Fatalf("WriteRIS failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"TY  - JOUR\r\n", "T2  - Jurnal Seni\r\n", "AB  - Line one line two\r\n", "TY  - GEN\r\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+	if strings.Count(output, "ER  - ") != 2 {
+		t.Errorf("Expected 2 references, got:\n%s", output)
+	}
+}
@@ -0,0 +1,188 @@
+package goharvest
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// SchemaOrgContext is the JSON-LD context of schema.org documents
+const SchemaOrgContext = "https://schema.org"
+
+// SchemaOrgCreativeWork is a schema.org Book, Thesis, ScholarlyArticle or other CreativeWork
+type SchemaOrgCreativeWork struct {
+	Context       string                 `json:"@context,omitempty"`
+	Type          string                 `json:"@type"`
+	ID            string                 `json:"@id,omitempty"`
+	Name          string                 `json:"name"`
+	AlternateName []string               `json:"alternateName,omitempty"`
+	Author        []SchemaOrgAgent       `json:"author,omitempty"`
+	Editor        []SchemaOrgAgent       `json:"editor,omitempty"`
+	Translator    []SchemaOrgAgent       `json:"translator,omitempty"`
+	Contributor   []SchemaOrgAgent       `json:"contributor,omitempty"`
+	Publisher     *SchemaOrgAgent        `json:"publisher,omitempty"`
+	DatePublished string                 `json:"datePublished,omitempty"`
+	BookEdition   string                 `json:"bookEdition,omitempty"`
+	ISBN          []string               `json:"isbn,omitempty"`
+	ISSN          string                 `json:"issn,omitempty"`
+	Identifier    []SchemaOrgIdentifier  `json:"identifier,omitempty"`
+	InLanguage    string                 `json:"inLanguage,omitempty"`
+	Keywords      []string               `json:"keywords,omitempty"`
+	Description   string                 `json:"description,omitempty"`
+	URL           string                 `json:"url,omitempty"`
+	SameAs        []string               `json:"sameAs,omitempty"`
+	IsPartOf      *SchemaOrgCreativeWork `json:"isPartOf,omitempty"`
+}
+
+// SchemaOrgAgent is a schema.org Person or Organization
+type SchemaOrgAgent struct {
+	Type       string `json:"@type"`
+	Name       string `json:"name"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	SameAs     string `json:"sameAs,omitempty"`
+}
+
+// SchemaOrgIdentifier is a schema.org PropertyValue identifier
+type SchemaOrgIdentifier struct {
+	Type       string `json:"@type"`
+	PropertyID string `json:"propertyID"`
+	Value      string `json:"value"`
+}
+
+// schemaOrgTypes maps BibTypes to schema.org types
+var schemaOrgTypes = map[BibType]string{
+	BibTypeBook:     "Book",
+	BibTypeArticle:  "ScholarlyArticle",
+	BibTypeSerial:   "Periodical",
+	BibTypeThesis:   "Thesis",
+	BibTypeDataset:  "Dataset",
+	BibTypeSoftware: "SoftwareSourceCode",
+	BibTypeImage:    "ImageObject",
+	BibTypeMap:      "Map",
+	BibTypeMusic:    "MusicComposition",
+	BibTypeSound:    "AudioObject",
+	BibTypeVideo:    "VideoObject",
+}
+
+// SchemaOrg converts the record to a schema.org CreativeWork with its @context set
+func (b *BibRecord) SchemaOrg() SchemaOrgCreativeWork {
+	work := SchemaOrgCreativeWork{
+		Context:       SchemaOrgContext,
+		Type:          "CreativeWork",
+		Name:          b.FullTitle(),
+		AlternateName: b.AlternativeTitles,
+		DatePublished: b.Year(),
+		BookEdition:   b.Edition,
+		InLanguage:    b.Language,
+		Keywords:      b.Subjects,
+		Description:   b.Abstract,
+	}
+	if schemaType, ok := schemaOrgTypes[b.Type]; ok {
+		work.Type = schemaType
+	}
+	if b.Publisher != "" {
+		work.Publisher = &SchemaOrgAgent{Type: "Organization", Name: b.Publisher}
+	}
+	if series := firstNonEmpty(b.Series); series != "" {
+		work.IsPartOf = &SchemaOrgCreativeWork{Type: "CreativeWorkSeries", Name: series}
+		if b.Type == BibTypeArticle {
+			work.IsPartOf.Type = "Periodical"
+		}
+	}
+
+	for _, contributor := range b.Contributors {
+		agent := contributor.schemaOrgAgent()
+		switch {
+		case hasRole(contributor.Roles, "editor"):
+			work.Editor = append(work.Editor, agent)
+		case hasRole(contributor.Roles, "translator"):
+			work.Translator = append(work.Translator, agent)
+		case len(contributor.Roles) == 0 || hasRole(contributor.Roles, "author") || hasRole(contributor.Roles, "creator"):
+			work.Author = append(work.Author, agent)
+		default:
+			work.Contributor = append(work.Contributor, agent)
+		}
+	}
+
+	for _, id := range b.Identifiers {
+		switch id.Type {
+		case "isbn":
+			work.ISBN = append(work.ISBN, id.Value)
+		case "issn":
+			work.ISSN = firstNonEmpty([]string{work.ISSN, id.Value})
+		case "doi":
+			work.SameAs = append(work.SameAs, "https://doi.org/"+id.Value)
+		case "handle":
+			work.SameAs = append(work.SameAs, "https://hdl.handle.net/"+id.Value)
+		case "oclc":
+			work.SameAs = append(work.SameAs, "https://www.worldcat.org/oclc/"+id.Value)
+		case "lccn":
+			work.SameAs = append(work.SameAs, "https://lccn.loc.gov/"+id.Value)
+		case "url":
+			continue
+		default:
+			work.Identifier = append(work.Identifier, SchemaOrgIdentifier{Type: "PropertyValue", PropertyID: id.Type, Value: id.Value})
+		}
+	}
+	if b.Type != BibTypeBook {
+		// isbn is only defined on Book
+		for _, isbn := range work.ISBN {
+			work.Identifier = append(work.Identifier, SchemaOrgIdentifier{Type: "PropertyValue", PropertyID: "isbn", Value: isbn})
+		}
+		work.ISBN = nil
+	}
+
+	for i, link := range b.Links {
+		if i == 0 {
+			work.URL = link.URL
+		} else if link.Relation != LinkRelationshipRelatedResource {
+			work.SameAs = append(work.SameAs, link.URL)
+		}
+	}
+	work.SameAs = deduplicate(work.SameAs)
+
+	return work
+}
+
+// schemaOrgAgent converts the contributor to a Person or Organization
+func (c BibContributor) schemaOrgAgent() SchemaOrgAgent {
+	family, given := c.FamilyGiven()
+	if given == "" {
+		return SchemaOrgAgent{Type: "Organization", Name: family, SameAs: orcidURL(c.Identifier)}
+	}
+	if c.Type == "" || c.Type == string(ContributorPersonal) {
+		return SchemaOrgAgent{Type: "Person", Name: strings.TrimSpace(given + " " + family), GivenName: given, FamilyName: family, SameAs: orcidURL(c.Identifier)}
+	}
+	return SchemaOrgAgent{Type: "Organization", Name: c.Name}
+}
+
+// orcidURL returns the ORCID iD as a URL
+func orcidURL(id string) string {
+	if id == "" || strings.HasPrefix(id, "http") {
+		return id
+	}
+	return "https://orcid.org/" + id
+}
+
+// MarshalJSONLD serializes the record as a schema.org JSON-LD document
+func (b *BibRecord) MarshalJSONLD() ([]byte, error) {
+	return json.MarshalIndent(b.SchemaOrg(), "", "  ")
+}
+
+// WriteJSONLD writes the records as one schema.org JSON-LD document with an @graph
+func WriteJSONLD(w io.Writer, records []*BibRecord) error {
+	graph := make([]SchemaOrgCreativeWork, 0, len(records))
+	for _, record := range records {
+		work := record.SchemaOrg()
+		work.Context = ""
+		graph = append(graph, work)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Context string                  `json:"@context"`
+		Graph   []SchemaOrgCreativeWork `json:"@graph"`
+	}{SchemaOrgContext, graph})
+}
@@ -0,0 +1,70 @@
+package goharvest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSchemaOrg(t *testing.T) {
+	bib := testBibRecord()
+	bib.Identifiers = append(bib.Identifiers, BibIdentifier{Type: "oclc", Value: "123"}, BibIdentifier{Type: "doi", Value: "10.1234/abc"})
+
+	work := bib.SchemaOrg()
+	if work.Context != SchemaOrgContext || work.Type != "Book" || work.Name != "Bumi manusia: roman" || work.DatePublished != "1980" {
+		t.Errorf("Unexpected work %+v", work)
+	}
+	if len(work.Author) != 1 || work.Author[0].Type != "Person" || work.Author[0].Name != "Pramoedya Ananta Toer" || work.Author[0].FamilyName != "Toer" {
+		t.Errorf("Unexpected author %+v", work.Author)
+	}
+	if len(work.Editor) != 1 || work.Editor[0].Type != "Organization" || len(work.Translator) != 1 {
+		t.Errorf("Unexpected editor/translator %+v / %+v", work.Editor, work.Translator)
+	}
+	if work.Publisher == nil || work.Publisher.Name != "Hasta Mitra & Co" || len(work.ISBN) != 1 {
+		t.Errorf("Unexpected publisher/isbn %+v / %v", work.Publisher, work.ISBN)
+	}
+	if len(work.SameAs) != 2 || work.SameAs[0] != "https://www.worldcat.org/oclc/123" || work.URL != "http://example.org/1?a=1&b=2_3" {
+		t.Errorf("Unexpected links %v / %q", work.SameAs, work.URL)
+	}
+
+	data, err := bib.MarshalJSONLD()
+	if err != nil {
+		t.Fatalf("MarshalJSONLD failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"@context": "https://schema.org"`) || !strings.Contains(string(data), `"@type": "Book"`) {
+		t.Errorf("Unexpected JSON-LD %s", data)
+	}
+}
+
+func TestSchemaOrgThesisAndGraph(t *testing.T) {
+	thesis := &BibRecord{
+		Type:         BibTypeThesis,
+		Title:        "Batik",
+		Contributors: []BibContributor{{Name: "Sari, Dewi", Roles: []string{"author"}, Identifier: "0000-0002-1825-0097"}},
+		Identifiers:  []BibIdentifier{{Type: "isbn", Value: "9780306406157"}},
+	}
+
+	work := thesis.SchemaOrg()
+	if work.Type != "Thesis" || work.ISBN != nil || len(work.Identifier) != 1 || work.Identifier[0].PropertyID != "isbn" {
+		t.Errorf("Unexpected thesis %+v", work)
+	}
+	if work.Author[0].SameAs != "https://orcid.org/0000-0002-1825-0097" {
+		t.Errorf("Unexpected author sameAs %q", work.Author[0].SameAs)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSONLD(&buf, []*BibRecord{thesis, testBibRecord()}); err != nil {
+		t.Fatalf("WriteJSONLD failed: %v", err)
+	}
+	var doc struct {
+		Context string                   `json:"@context"`
+		Graph   []map[string]interface{} `json:"@graph"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if doc.Context != SchemaOrgContext || len(doc.Graph) != 2 || doc.Graph[0]["@context"] != nil {
+		t.Errorf("Unexpected graph %+v", doc)
+	}
+}
@@ -0,0 +1,111 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// SyncReport is the result of a FullSync
+type SyncReport struct {
+	// Stats summarizes the harvest
+	Stats HarvestStats
+	// Tombstones are the identifiers of the local records the repository no longer has,
+	// which were delivered as deleted records
+	Tombstones []string
+}
+
+// FullSync harvests all records like HarvestRecords and then compares the local records
+// with the ones the repository listed. Each local record the repository no longer has is
+// delivered to callback as a deleted record (a tombstone) dated now, so sinks remove it.
+// This keeps aggregators consistent with repositories that do not report deletions
+// (deletedRecord=no).
+//
+// Local records are only compared within opts.Set or opts.Sets when given. No tombstones
+// are generated unless the harvest completes, and opts.DateRange must be nil: a partial
+// list would turn unlisted records into deletions.
+func (c *OAIClient) FullSync(ctx context.Context, metadataPrefix string, opts *HarvestOptions, local LocalIndex, callback RecordCallback) (*SyncReport, error) {
+	syncOpts := HarvestOptions{}
+	if opts != nil {
+		syncOpts = *opts
+	}
+	if syncOpts.DateRange != nil {
+		return nil, errors.New("a full sync cannot be limited to a date range")
+	}
+
+	// listed holds the identifiers the repository listed, and whether it listed them as deleted
+	listed := make(map[string]bool)
+	syncOpts.listed = func(header Header) {
+		listed[header.Identifier] = header.IsDeleted()
+	}
+	report := &SyncReport{}
+	callerStats := syncOpts.Stats
+	syncOpts.Stats = &report.Stats
+
+	err := c.harvestRecords(ctx, metadataPrefix, &syncOpts, callback)
+	if callerStats != nil {
+		*callerStats = report.Stats
+	}
+	if err != nil {
+		return report, err
+	}
+	if report.Stats.Stopped {
+		return report, nil
+	}
+
+	now := FormatDatestamp(time.Now(), GranularitySecond)
+	err = local.EachRecord(ctx, func(header Header) error {
+		if header.IsDeleted() || !syncScope(header, &syncOpts) {
+			return nil
+		}
+		// A deletion the repository reported was delivered unless SkipDeleted is set
+		if deleted, ok := listed[header.Identifier]; ok && (!deleted || !syncOpts.SkipDeleted) {
+			return nil
+		}
+
+		tombstone := HarvestedRecord{Header: Header{
+			Status:     HeaderStatusDeleted,
+			Identifier: header.Identifier,
+			DateStamp:  now,
+			SetSpec:    header.SetSpec,
+		}}
+		if syncOpts.ContentHash || syncOpts.SkipUnchanged != nil {
+			tombstone.Hash = deletedRecordHash
+		}
+		if err := callback(tombstone); err != nil {
+			if errors.Is(err, ErrStopHarvest) {
+				return err
+			}
+			return fmt.Errorf("callback error: %w", err)
+		}
+		if syncOpts.SkipUnchanged != nil {
+			if err := syncOpts.SkipUnchanged.SetHash(header.Identifier, deletedRecordHash); err != nil {
+				return fmt.Errorf("failed to store content hash: %w", err)
+			}
+		}
+		report.Tombstones = append(report.Tombstones, header.Identifier)
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrStopHarvest) {
+		return report, err
+	}
+
+	c.log().Info("full sync finished", "baseURL", c.BaseURL, "metadataPrefix", metadataPrefix,
+		"records", report.Stats.Records, "tombstones", len(report.Tombstones))
+	return report, nil
+}
+
+// syncScope reports whether a local record belongs to the sets harvested by opts
+func syncScope(header Header, opts *HarvestOptions) bool {
+	switch {
+	case len(opts.Sets) > 0:
+		return slices.ContainsFunc(opts.Sets, func(set string) bool {
+			return slices.Contains(header.SetSpec, set)
+		})
+	case opts.Set != "":
+		return slices.Contains(header.SetSpec, opts.Set)
+	}
+	return true
+}
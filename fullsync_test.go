@@ -0,0 +1,94 @@
+package goharvest
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// fullSyncLocal holds records 1 to 4 in set theses and 7 in set books; the fixtures list
+// 1, 4 and 5, and 2 as deleted
+var fullSyncLocal = localHeaders{
+	{Identifier: "oai:example.org:1", DateStamp: "2025-01-15", SetSpec: []string{"theses"}},
+	{Identifier: "oai:example.org:2", DateStamp: "2025-01-16", SetSpec: []string{"theses"}},
+	{Identifier: "oai:example.org:3", DateStamp: "2025-01-17", SetSpec: []string{"theses"}},
+	{Identifier: "oai:example.org:4", DateStamp: "2025-01-18", SetSpec: []string{"theses"}},
+	{Identifier: "oai:example.org:7", DateStamp: "2025-01-18", SetSpec: []string{"books"}},
+}
+
+func TestFullSync(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	tests := []struct {
+		name       string
+		opts       *HarvestOptions
+		tombstones []string
+	}{
+		{"all", nil, []string{"oai:example.org:3", "oai:example.org:7"}},
+		{"set", &HarvestOptions{Set: "theses"}, []string{"oai:example.org:3"}},
+		{"skip deleted", &HarvestOptions{Set: "theses", SkipDeleted: true}, []string{"oai:example.org:2", "oai:example.org:3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted []string
+			report, err := client.FullSync(context.Background(), "oai_dc", tt.opts, fullSyncLocal, func(record HarvestedRecord) error {
+				// Tombstones come from no page
+				if record.Page.Number == 0 {
+					if !record.IsDeleted() {
+						t.Errorf("Expected a deleted record, got %+v", record)
+					}
+					deleted = append(deleted, record.Header.Identifier)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(report.Tombstones, tt.tombstones) || !slices.Equal(deleted, tt.tombstones) {
+				t.Errorf("Expected tombstones %v, got %v delivered as %v", tt.tombstones, report.Tombstones, deleted)
+			}
+			if report.Stats.Records != 4 {
+				t.Errorf("Expected the stats of the harvest, got %+v", report.Stats)
+			}
+		})
+	}
+}
+
+func TestFullSyncWithoutCompleteHarvest(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords": "listrecords_dc_page1.xml",
+	})
+	client := NewClient(server.URL)
+
+	tombstones := 0
+	countTombstones := func(record HarvestedRecord) error {
+		if record.Page.Number == 0 {
+			tombstones++
+		}
+		return nil
+	}
+
+	if _, err := client.FullSync(context.Background(), "oai_dc", nil, fullSyncLocal, countTombstones); err == nil {
+		t.Error("Expected the missing second page to fail the sync")
+	}
+
+	report, err := client.FullSync(context.Background(), "oai_dc", nil, fullSyncLocal, func(HarvestedRecord) error {
+		return ErrStopHarvest
+	})
+	if err != nil || !report.Stats.Stopped {
+		t.Errorf("Expected a stopped sync, got %+v, %v", report, err)
+	}
+
+	if _, err := client.FullSync(context.Background(), "oai_dc", &HarvestOptions{DateRange: LastNDays(7)}, fullSyncLocal, countTombstones); err == nil {
+		t.Error("Expected a date range to be rejected")
+	}
+	if tombstones != 0 {
+		t.Errorf("Expected no tombstones, got %d", tombstones)
+	}
+}
@@ -0,0 +1,238 @@
+package goharvest
+
+import (
+	"context"
+	"sync"
+)
+
+// HarvestStatus is the status of a harvest started with StartHarvest
+type HarvestStatus int
+
+// Statuses of a Harvest
+const (
+	// HarvestRunning is a harvest fetching and delivering pages
+	HarvestRunning HarvestStatus = iota
+	// HarvestPaused is a harvest that starts no further page until resumed
+	HarvestPaused
+	// HarvestStopping is a harvest finishing its current page after Stop
+	HarvestStopping
+	// HarvestDone is a harvest that has returned
+	HarvestDone
+)
+
+// String returns the name of the status
+func (s HarvestStatus) String() string {
+	switch s {
+	case HarvestRunning:
+		return "running"
+	case HarvestPaused:
+		return "paused"
+	case HarvestStopping:
+		return "stopping"
+	case HarvestDone:
+		return "done"
+	default:
+		return "unknown"
+	}
+}
+
+// HarvestSnapshot describes a harvest started with StartHarvest at one point in time
+type HarvestSnapshot struct {
+	Status HarvestStatus
+	// ResumptionToken is the token of the next page, empty before the first page has
+	// been processed and after the last one. With Sets it belongs to the set of the
+	// latest processed page.
+	ResumptionToken string
+	// Progress holds the counts as of the latest processed page
+	Progress Progress
+	// Err is the error the harvest returned, once Status is HarvestDone
+	Err error
+}
+
+// Harvest is a handle on a harvest running in the background, for pausing, resuming and
+// stopping it between pages. Its methods may be called from any goroutine.
+type Harvest struct {
+	control *harvestControl
+	done    chan struct{}
+	err     error
+}
+
+// StartHarvest starts a harvest like HarvestContext in a background goroutine and
+// returns a handle on it
+func (c *OAIClient) StartHarvest(ctx context.Context, metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) *Harvest {
+	return c.startHarvest(ctx, metadataPrefix, opts, callback, nil)
+}
+
+// StartHarvestRecords starts a per-record harvest like HarvestRecords in a background
+// goroutine and returns a handle on it
+func (c *OAIClient) StartHarvestRecords(ctx context.Context, metadataPrefix string, opts *HarvestOptions, callback RecordCallback) *Harvest {
+	return c.startHarvest(ctx, metadataPrefix, opts, nil, callback)
+}
+
+// startHarvest runs harvest in a goroutine under the control of the returned handle
+func (c *OAIClient) startHarvest(ctx context.Context, metadataPrefix string, opts *HarvestOptions, callback HarvestCallback, records RecordCallback) *Harvest {
+	h := &Harvest{control: &harvestControl{}, done: make(chan struct{})}
+	controlled := HarvestOptions{}
+	if opts != nil {
+		controlled = *opts
+	}
+	controlled.control = h.control
+
+	go func() {
+		defer close(h.done)
+		h.err = c.harvest(ctx, metadataPrefix, &controlled, callback, records)
+	}()
+	return h
+}
+
+// Pause lets the harvest finish the page being delivered and holds it before the next
+// one. Pages already fetched ahead with PrefetchPages are kept until it resumes.
+func (h *Harvest) Pause() {
+	h.control.pause()
+}
+
+// Resume continues a paused harvest
+func (h *Harvest) Resume() {
+	h.control.resume()
+}
+
+// Stop ends the harvest after the page being delivered, as if the callback returned
+// ErrStopHarvest: the checkpoint is kept and HarvestStats.ResumptionToken reports where
+// to continue. A paused harvest stops without resuming. Use Wait for the harvest to drain.
+func (h *Harvest) Stop() {
+	h.control.stop()
+}
+
+// Wait blocks until the harvest returns and returns its error
+func (h *Harvest) Wait() error {
+	<-h.done
+	return h.err
+}
+
+// Done returns a channel that is closed when the harvest returns
+func (h *Harvest) Done() <-chan struct{} {
+	return h.done
+}
+
+// State returns a snapshot of the status and progress of the harvest
+func (h *Harvest) State() HarvestSnapshot {
+	select {
+	case <-h.done:
+		snapshot := h.control.snapshot()
+		snapshot.Status = HarvestDone
+		snapshot.Err = h.err
+		return snapshot
+	default:
+		return h.control.snapshot()
+	}
+}
+
+// harvestControl holds the requests made through a Harvest, which the harvest loop
+// checks between pages
+type harvestControl struct {
+	mu       sync.Mutex
+	paused   bool
+	stopping bool
+	// wake is closed to release a paused harvest
+	wake     chan struct{}
+	token    string
+	progress Progress
+}
+
+// pause holds the harvest before its next page
+func (c *harvestControl) pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused && !c.stopping {
+		c.paused = true
+		c.wake = make(chan struct{})
+	}
+}
+
+// resume releases a paused harvest
+func (c *harvestControl) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		c.paused = false
+		close(c.wake)
+	}
+}
+
+// stop ends the harvest before its next page
+func (c *harvestControl) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopping = true
+	if c.paused {
+		c.paused = false
+		close(c.wake)
+	}
+}
+
+// stopped reports whether Stop was called
+func (c *harvestControl) stopped() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stopping
+}
+
+// await blocks while the harvest is paused, and reports whether it is to stop before the
+// next page
+func (c *harvestControl) await(ctx context.Context) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+	for {
+		c.mu.Lock()
+		stopping, paused, wake := c.stopping, c.paused, c.wake
+		c.mu.Unlock()
+		if stopping || !paused {
+			return stopping, nil
+		}
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// pageProcessed records the progress after a page and the token of the next one
+func (c *harvestControl) pageProcessed(progress Progress, token string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.progress = progress
+	c.token = token
+}
+
+// stoppedAt records the token a harvest stopped partway through a page continues with
+func (c *harvestControl) stoppedAt(token string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// snapshot returns the state of a running harvest
+func (c *harvestControl) snapshot() HarvestSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := HarvestSnapshot{Status: HarvestRunning, ResumptionToken: c.token, Progress: c.progress}
+	switch {
+	case c.stopping:
+		snapshot.Status = HarvestStopping
+	case c.paused:
+		snapshot.Status = HarvestPaused
+	}
+	return snapshot
+}
@@ -0,0 +1,132 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// steppedHarvest starts a harvest of the Dublin Core fixtures whose callback reports each
+// page on pages and waits for a value on proceed before returning
+func steppedHarvest(t *testing.T, ctx context.Context, opts *HarvestOptions) (*Harvest, *fixtureServer, chan int, chan struct{}) {
+	t.Helper()
+
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	pages := make(chan int)
+	proceed := make(chan struct{})
+	page := 0
+	h := NewClient(server.URL).StartHarvest(ctx, "oai_dc", opts, func(OAIResponse) error {
+		page++
+		pages <- page
+		<-proceed
+		return nil
+	})
+	return h, server, pages, proceed
+}
+
+func TestHarvestPauseResume(t *testing.T) {
+	h, server, pages, proceed := steppedHarvest(t, context.Background(), nil)
+
+	<-pages
+	h.Pause()
+	proceed <- struct{}{}
+
+	state := h.State()
+	if state.Status != HarvestPaused || state.Status.String() != "paused" {
+		t.Errorf("Expected a paused harvest, got %v", state.Status)
+	}
+	// The harvest holds before requesting the second page
+	time.Sleep(50 * time.Millisecond)
+	if queries := server.Queries(); len(queries) != 1 {
+		t.Errorf("Expected no request while paused, got %d requests", len(queries))
+	}
+	if state := h.State(); state.ResumptionToken != "dc-page-2" || state.Progress.Pages != 1 || state.Progress.Records != 3 {
+		t.Errorf("Unexpected state after the first page %+v", state)
+	}
+
+	h.Resume()
+	if page := <-pages; page != 2 {
+		t.Errorf("Expected the second page, got %d", page)
+	}
+	proceed <- struct{}{}
+
+	if err := h.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	state = h.State()
+	if state.Status != HarvestDone || state.ResumptionToken != "" || state.Progress.Pages != 2 || state.Err != nil {
+		t.Errorf("Unexpected final state %+v", state)
+	}
+}
+
+func TestHarvestHandleStop(t *testing.T) {
+	for _, paused := range []bool{false, true} {
+		var stats HarvestStats
+		h, server, pages, proceed := steppedHarvest(t, context.Background(), &HarvestOptions{Stats: &stats})
+
+		<-pages
+		if paused {
+			h.Pause()
+		}
+		h.Stop()
+		if state := h.State(); state.Status != HarvestStopping {
+			t.Errorf("paused=%v: expected a stopping harvest, got %v", paused, state.Status)
+		}
+		proceed <- struct{}{}
+
+		select {
+		case <-h.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("paused=%v: harvest did not stop", paused)
+		}
+		if err := h.Wait(); err != nil {
+			t.Fatal(err)
+		}
+		if !stats.Stopped || stats.ResumptionToken != "dc-page-2" || stats.Pages != 1 {
+			t.Errorf("paused=%v: expected a harvest stopped after the first page, got %+v", paused, stats)
+		}
+		if queries := server.Queries(); len(queries) != 1 {
+			t.Errorf("paused=%v: expected 1 request, got %d", paused, len(queries))
+		}
+	}
+}
+
+func TestHarvestPausedCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h, _, pages, proceed := steppedHarvest(t, ctx, nil)
+
+	<-pages
+	h.Pause()
+	proceed <- struct{}{}
+	cancel()
+
+	if err := h.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if state := h.State(); state.Status != HarvestDone || !errors.Is(state.Err, context.Canceled) {
+		t.Errorf("Unexpected state %+v", state)
+	}
+}
+
+func TestStartHarvestRecords(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+
+	var identifiers []string
+	h := NewClient(server.URL).StartHarvestRecords(context.Background(), "oai_dc", nil, func(record HarvestedRecord) error {
+		identifiers = append(identifiers, record.Header.Identifier)
+		return nil
+	})
+	if err := h.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if len(identifiers) != 4 || h.State().Progress.Records != 4 {
+		t.Errorf("Expected 4 records, got %v and %+v", identifiers, h.State())
+	}
+}
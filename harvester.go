@@ -1,144 +1,903 @@
 package goharvest
 
 import (
-	"encoding/xml"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 // Harvest is the unified entry point for harvesting OAI-PMH records
 // It automatically detects the metadata format and returns appropriate parsers
 // Use dateRange parameter to filter records by datestamp (pass nil for no date filtering)
 func (c *OAIClient) Harvest(metadataPrefix string, dateRange *DateRange, callback HarvestCallback) error {
+	return c.HarvestWithOptions(metadataPrefix, &HarvestOptions{DateRange: dateRange}, callback)
+}
+
+// HarvestWithOptions harvests OAI-PMH records using selective harvesting options
+// such as date range and set (pass nil for no filtering)
+func (c *OAIClient) HarvestWithOptions(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.HarvestContext(context.Background(), metadataPrefix, opts, callback)
+}
+
+// HarvestContext is like HarvestWithOptions but stops when ctx is cancelled
+func (c *OAIClient) HarvestContext(ctx context.Context, metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.harvest(ctx, metadataPrefix, opts, callback, nil)
+}
+
+// harvestRecords runs a harvest that invokes callback once per record
+func (c *OAIClient) harvestRecords(ctx context.Context, metadataPrefix string, opts *HarvestOptions, callback RecordCallback) error {
+	return c.harvest(ctx, metadataPrefix, opts, nil, callback)
+}
+
+// harvest runs a harvest delivering pages to callback, or records to records if it is
+// not nil. The records are delivered with the options of the run, which track the
+// identifiers seen so far.
+func (c *OAIClient) harvest(ctx context.Context, metadataPrefix string, opts *HarvestOptions, callback HarvestCallback, records RecordCallback) error {
+	ctxOpts := HarvestOptions{}
+	if opts != nil {
+		ctxOpts = *opts
+	}
+	telemetry := c.telemetry()
+	ctx, span := telemetry.StartSpan(ctx, "oai.harvest",
+		Attr(AttrBaseURL, c.BaseURL), Attr(AttrFormat, metadataPrefix), Attr(AttrSet, ctxOpts.Set))
+	defer span.End()
+	telemetry.Count(ctx, MetricHarvestsActive, 1)
+	defer telemetry.Count(ctx, MetricHarvestsActive, -1)
+
+	ctxOpts.ctx = ctx
+	ctxOpts.run = newHarvestRun()
+	ctxOpts.run.perRecord = records != nil
+	if ctxOpts.resumptionToken == "" {
+		ctxOpts.resumptionToken = ctxOpts.ResumptionToken
+	}
+	if ctxOpts.Dedup != nil {
+		ctxOpts.run.dedup = ctxOpts.Dedup.filter()
+	}
+	opts = &ctxOpts
+
+	if records != nil {
+		page := 0
+		callback = func(response OAIResponse) error {
+			page++
+			return forEachRecord(response, page, opts, records)
+		}
+	}
+
+	var err error
+	if len(opts.Sets) > 0 {
+		c.log().Info("harvest started", "baseURL", c.BaseURL, "metadataPrefix", metadataPrefix, "sets", opts.Sets)
+		err = c.harvestSets(metadataPrefix, opts, callback)
+	} else {
+		c.log().Info("harvest started", "baseURL", c.BaseURL, "metadataPrefix", metadataPrefix, "set", opts.Set)
+		err = c.harvestFormat(metadataPrefix, opts, callback)
+	}
+
+	stats := opts.run.stats()
+	if opts.Stats != nil {
+		*opts.Stats = stats
+	}
+	c.logHarvest(ctx, metadataPrefix, stats, err)
+	opts.emit(HarvestFinished{Stats: stats, Err: err})
+	span.SetAttributes(Attr(AttrPages, stats.Pages), Attr(AttrRecords, stats.Records))
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+// harvestFormat runs a harvest with the parser for the metadata format
+func (c *OAIClient) harvestFormat(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	if opts.raw {
+		return c.harvestWithParser(metadataPrefix, opts, c.listRecordsRequestRaw, callback)
+	}
+
 	format := MetadataFormat(metadataPrefix)
 
 	switch format {
-	case FormatMARCXML:
-		return c.harvestMARCXML(metadataPrefix, dateRange, callback)
+	case FormatMARCXML, FormatMARC21, FormatOAIMARC:
+		return c.harvestMARCXML(metadataPrefix, opts, callback)
 	case FormatOAIDC:
-		return c.harvestDublinCore(metadataPrefix, dateRange, callback)
+		return c.harvestDublinCore(metadataPrefix, opts, callback)
+	case FormatMODS:
+		return c.harvestMODS(metadataPrefix, opts, callback)
+	case FormatQDC:
+		return c.harvestQDC(metadataPrefix, opts, callback)
+	case FormatDataCite, FormatDataCiteKernel:
+		return c.harvestDataCite(metadataPrefix, opts, callback)
+	case FormatMETS:
+		return c.harvestMETS(metadataPrefix, opts, callback)
+	case FormatETDMS:
+		return c.harvestETDMS(metadataPrefix, opts, callback)
+	case FormatORE:
+		return c.harvestORE(metadataPrefix, opts, callback)
+	case FormatLIDO:
+		return c.harvestLIDO(metadataPrefix, opts, callback)
+	case FormatEAD:
+		return c.harvestEAD(metadataPrefix, opts, callback)
 	default:
 		return fmt.Errorf("unsupported metadata format: %s", metadataPrefix)
 	}
 }
 
 // harvestMARCXML harvests MARCXML records
-func (c *OAIClient) harvestMARCXML(metadataPrefix string, dateRange *DateRange, callback HarvestCallback) error {
-	return c.harvestWithParser(metadataPrefix, dateRange, c.listRecordsRequestMARCXML, callback)
+func (c *OAIClient) harvestMARCXML(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.harvestWithParser(metadataPrefix, opts, c.listRecordsRequestMARCXML, callback)
 }
 
 // harvestDublinCore harvests Dublin Core records
-func (c *OAIClient) harvestDublinCore(metadataPrefix string, dateRange *DateRange, callback HarvestCallback) error {
-	return c.harvestWithParser(metadataPrefix, dateRange, c.listRecordsRequestDC, callback)
+func (c *OAIClient) harvestDublinCore(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.harvestWithParser(metadataPrefix, opts, c.listRecordsRequestDC, callback)
+}
+
+// harvestMODS harvests MODS records
+func (c *OAIClient) harvestMODS(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.harvestWithParser(metadataPrefix, opts, c.listRecordsRequestMODS, callback)
+}
+
+// harvestQDC harvests Qualified Dublin Core records
+func (c *OAIClient) harvestQDC(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.harvestWithParser(metadataPrefix, opts, c.listRecordsRequestQDC, callback)
+}
+
+// harvestDataCite harvests DataCite records
+func (c *OAIClient) harvestDataCite(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.harvestWithParser(metadataPrefix, opts, c.listRecordsRequestDataCite, callback)
+}
+
+// harvestMETS harvests METS records
+func (c *OAIClient) harvestMETS(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.harvestWithParser(metadataPrefix, opts, c.listRecordsRequestMETS, callback)
+}
+
+// harvestETDMS harvests ETD-MS records
+func (c *OAIClient) harvestETDMS(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.harvestWithParser(metadataPrefix, opts, c.listRecordsRequestETDMS, callback)
 }
 
+// harvestORE harvests OAI-ORE records
+func (c *OAIClient) harvestORE(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.harvestWithParser(metadataPrefix, opts, c.listRecordsRequestORE, callback)
+}
+
+// harvestLIDO harvests LIDO records
+func (c *OAIClient) harvestLIDO(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.harvestWithParser(metadataPrefix, opts, c.listRecordsRequestLIDO, callback)
+}
+
+// harvestEAD harvests EAD records
+func (c *OAIClient) harvestEAD(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	return c.harvestWithParser(metadataPrefix, opts, c.listRecordsRequestEAD, callback)
+}
+
+// ErrStopHarvest can be returned by a callback to end a harvest cleanly after the current page.
+// The harvest then returns nil, keeps its checkpoint and reports the resumption token needed
+// to continue in HarvestStats.ResumptionToken. A RecordCallback returning it before the last
+// record of a page stops at the token of that page instead, so the page is delivered again
+// when the harvest is resumed.
+var ErrStopHarvest = errors.New("stop harvest")
+
+// pageParser fetches and parses one ListRecords page for a metadata prefix and resumption token
+type pageParser func(string, string, *HarvestOptions) (OAIResponse, error)
+
 // harvestWithParser is the unified harvest loop for all metadata formats
 func (c *OAIClient) harvestWithParser(
 	metadataPrefix string,
-	dateRange *DateRange,
-	parser func(string, string, *DateRange) (OAIResponse, error),
+	opts *HarvestOptions,
+	parser pageParser,
 	callback HarvestCallback,
 ) error {
 	resumptionToken := ""
+	pages := 0
+	if opts != nil {
+		resumptionToken = opts.resumptionToken
+		pages = opts.pagesHarvested
+	}
+
+	// Selective harvesting arguments are only sent on the first request,
+	// follow-up requests carry them embedded in the resumption token
+	parent := opts.context()
+	cancel := func() {}
+	defer func() { cancel() }()
+	pagesFrom := func(resumptionToken string) pageSource {
+		next := sequentialPages(metadataPrefix, resumptionToken, opts, c.retriedPages(c.tracedPages(parser)))
+		if opts != nil && opts.PrefetchPages > 0 {
+			// A restarted list stops the prefetching of the previous one
+			cancel()
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(parent)
+			opts.ctx = ctx
+			next = prefetchPages(ctx, opts.PrefetchPages, next)
+		}
+		return next
+	}
+	next := pagesFrom(resumptionToken)
+
+	// lastDatestamp is the latest datestamp processed, from which the list restarts after
+	// a rejected resumption token
+	lastDatestamp := ""
+	recoveredFrom := ""
 
 	for {
-		resp, err := parser(metadataPrefix, resumptionToken, dateRange)
+		if opts != nil {
+			// A harvest started with StartHarvest waits here while paused
+			stop, err := opts.control.await(parent)
+			if err != nil {
+				return err
+			}
+			if stop {
+				if opts.run != nil {
+					opts.run.stop(resumptionToken)
+				}
+				return nil
+			}
+		}
+
+		resp, err := next()
 		if err != nil {
-			return err
+			if opts != nil && opts.IgnoreNoRecordsMatch && errors.Is(err, ErrNoRecordsMatch) {
+				break
+			}
+			if !recoverable(opts, err, lastDatestamp, recoveredFrom) {
+				return err
+			}
+			recoveredFrom = lastDatestamp
+			c.restartFrom(opts, lastDatestamp, pages, err)
+			resumptionToken = ""
+			next = pagesFrom("")
+			continue
+		}
+		if resp == nil {
+			break
 		}
 
-		if err := callback(resp); err != nil {
-			return fmt.Errorf("callback error: %w", err)
+		stopped, partial, err := processPage(resp, opts, callback)
+		if err != nil {
+			return err
+		}
+		// A page only partly delivered is delivered again when the harvest is resumed, so
+		// the harvest stops at the token it was requested with
+		if partial {
+			if opts.Checkpointer != nil {
+				if err := saveCheckpoint(metadataPrefix, opts, resumptionToken, nil, pages); err != nil {
+					return err
+				}
+			}
+			if opts.run != nil {
+				opts.run.stop(resumptionToken)
+			}
+			opts.control.stoppedAt(resumptionToken)
+			return nil
 		}
+		pages++
+		lastDatestamp = latestDatestamp(resp, lastDatestamp)
 
 		token := resp.GetResumptionToken()
 		if token == "" {
 			break
 		}
-
 		resumptionToken = token
-		// After first request with resumption token, clear dateRange as it's embedded in the token
-		dateRange = nil
+
+		if opts != nil && opts.Checkpointer != nil {
+			if err := saveCheckpoint(metadataPrefix, opts, token, resp.GetResumptionTokenInfo(), pages); err != nil {
+				return err
+			}
+		}
+
+		// A stopped harvest keeps its checkpoint so it can be resumed later
+		if stopped {
+			if opts != nil && opts.run != nil {
+				opts.run.stop(token)
+			}
+			return nil
+		}
+	}
+
+	if opts != nil && opts.Checkpointer != nil {
+		if err := opts.Checkpointer.Clear(); err != nil {
+			return fmt.Errorf("failed to clear checkpoint: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// recoverable reports whether a harvest can restart from lastDatestamp after err. The
+// repository must have rejected a resumption token and the harvest must have processed a
+// later datestamp than at its previous restart, so a list that keeps expiring before
+// reaching a new datestamp fails instead of looping.
+func recoverable(opts *HarvestOptions, err error, lastDatestamp, recoveredFrom string) bool {
+	if opts == nil || !opts.RecoverBadResumptionToken || !errors.Is(err, ErrBadResumptionToken) {
+		return false
+	}
+	return lastDatestamp != "" && (recoveredFrom == "" || datestampAfter(lastDatestamp, recoveredFrom))
+}
+
+// restartFrom selects the records changed at or after datestamp, keeping the set and the
+// until bound, so that the next request starts a new list
+func (c *OAIClient) restartFrom(opts *HarvestOptions, datestamp string, pages int, err error) {
+	dateRange := &DateRange{From: datestamp}
+	if opts.DateRange != nil {
+		dateRange.Until = opts.DateRange.Until
+		dateRange.UntilTime = opts.DateRange.UntilTime
+	}
+	opts.restartRange = dateRange
+	opts.resumptionToken = ""
+	opts.pagesHarvested = pages
+	if opts.run != nil {
+		opts.run.recoveries.Add(1)
+	}
+	c.log().Warn("resumption token rejected, restarting harvest from the latest datestamp",
+		"baseURL", c.BaseURL, "set", opts.Set, "from", datestamp, "error", err)
+}
+
+// latestDatestamp returns the latest datestamp of the records of a page, or latest if
+// none is later
+func latestDatestamp(resp OAIResponse, latest string) string {
+	lister, ok := resp.(recordLister)
+	if !ok {
+		return latest
+	}
+	for _, record := range lister.harvestedRecords() {
+		if datestamp := record.Header.DateStamp; latest == "" || datestampAfter(datestamp, latest) {
+			latest = datestamp
+		}
+	}
+	return latest
+}
+
+// processPage delivers a page to callback and reports progress. Pages of sets harvested
+// in parallel are processed one at a time. It reports whether the harvest stops, because
+// the callback returned ErrStopHarvest or a limit was reached, and whether it stopped
+// before the last record of the page.
+func processPage(resp OAIResponse, opts *HarvestOptions, callback HarvestCallback) (bool, bool, error) {
+	if opts != nil && opts.run != nil {
+		opts.run.pageMu.Lock()
+		defer opts.run.pageMu.Unlock()
+	}
+
+	stopped, partial := false, false
+	if err := callback(resp); err != nil {
+		if !errors.Is(err, ErrStopHarvest) {
+			return false, false, fmt.Errorf("callback error: %w", err)
+		}
+		stopped = true
+		partial = errors.Is(err, errStoppedInPage)
+	}
+
+	if opts != nil && opts.run != nil {
+		progress := opts.run.pageProcessed(resp)
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+		opts.control.pageProcessed(progress, resp.GetResumptionToken())
+		if opts.limitReached() {
+			stopped = true
+		}
+	}
+	return stopped, partial, nil
+}
+
+// limitReached reports whether the harvest has processed MaxPages pages or delivered
+// MaxRecords records. Callers hold run.pageMu if sets are harvested in parallel.
+func (o *HarvestOptions) limitReached() bool {
+	r := o.run
+	if o.MaxPages > 0 && r.pages >= o.MaxPages {
+		return true
+	}
+	if o.MaxRecords > 0 {
+		if r.perRecord {
+			return r.delivered >= o.MaxRecords
+		}
+		return r.records >= o.MaxRecords
+	}
+	return false
+}
+
+// pageSource returns successive pages of a harvest, or nil once the list is complete
+type pageSource func() (OAIResponse, error)
+
+// sequentialPages fetches each page on demand, following resumption tokens
+func sequentialPages(metadataPrefix string, resumptionToken string, opts *HarvestOptions, parser pageParser) pageSource {
+	done := false
+	page := 0
+	if opts != nil {
+		page = opts.pagesHarvested
+	}
+
+	return func() (OAIResponse, error) {
+		if done {
+			return nil, nil
+		}
+
+		start := time.Now()
+		resp, err := parser(metadataPrefix, resumptionToken, opts)
+		if err != nil {
+			done = true
+			opts.observePageError(resumptionToken, err)
+			return nil, err
+		}
+		elapsed := time.Since(start)
+		if opts != nil && opts.run != nil {
+			opts.run.pageFetched(elapsed)
+		}
+		page++
+		opts.observePage(page, resumptionToken, resp, elapsed)
+
+		resumptionToken = resp.GetResumptionToken()
+		done = resumptionToken == ""
+
+		return resp, nil
+	}
+}
+
+// fetchedPage is a page fetched ahead of time by prefetchPages
+type fetchedPage struct {
+	resp OAIResponse
+	err  error
+}
+
+// prefetchPages fetches up to depth pages ahead of the consumer in a background goroutine,
+// overlapping network I/O with callback processing. Cancel ctx to stop fetching.
+func prefetchPages(ctx context.Context, depth int, next pageSource) pageSource {
+	// The goroutine holds one page while blocked on send, so the buffer holds the rest
+	pages := make(chan fetchedPage, depth-1)
+
+	go func() {
+		defer close(pages)
+
+		for {
+			resp, err := next()
+			if resp == nil && err == nil {
+				return
+			}
+
+			select {
+			case pages <- fetchedPage{resp: resp, err: err}:
+			case <-ctx.Done():
+				return
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return func() (OAIResponse, error) {
+		page, ok := <-pages
+		if !ok {
+			return nil, ctx.Err()
+		}
+		return page.resp, page.err
+	}
+}
+
 // listRecordsRequestMARCXML performs a ListRecords request for MARCXML
-func (c *OAIClient) listRecordsRequestMARCXML(metadataPrefix string, resumptionToken string, dateRange *DateRange) (OAIResponse, error) {
-	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, dateRange)
+func (c *OAIClient) listRecordsRequestMARCXML(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, opts)
 	if err != nil {
 		return nil, err
 	}
+	defer body.Close()
 
-	var oaiResp OAIPMHResponse
-	if err := xml.Unmarshal(body, &oaiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	return c.parseBody(FormatMARCXML, body, c.decodeOptions(opts, resumptionToken))
+}
+
+// listRecordsRequestDC performs a ListRecords request for Dublin Core
+func (c *OAIClient) listRecordsRequestDC(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, opts)
+	if err != nil {
+		return nil, err
 	}
+	defer body.Close()
+
+	return c.parseBody(FormatOAIDC, body, c.decodeOptions(opts, resumptionToken))
+}
 
-	if oaiResp.Error != nil {
-		return nil, fmt.Errorf("OAI-PMH error [%s]: %s", oaiResp.Error.Code, oaiResp.Error.Message)
+// listRecordsRequestMODS performs a ListRecords request for MODS
+func (c *OAIClient) listRecordsRequestMODS(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, opts)
+	if err != nil {
+		return nil, err
 	}
+	defer body.Close()
 
-	return &oaiResp, nil
+	return c.parseBody(FormatMODS, body, c.decodeOptions(opts, resumptionToken))
 }
 
-// listRecordsRequestDC performs a ListRecords request for Dublin Core
-func (c *OAIClient) listRecordsRequestDC(metadataPrefix string, resumptionToken string, dateRange *DateRange) (OAIResponse, error) {
-	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, dateRange)
+// listRecordsRequestQDC performs a ListRecords request for Qualified Dublin Core
+func (c *OAIClient) listRecordsRequestQDC(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, opts)
 	if err != nil {
 		return nil, err
 	}
+	defer body.Close()
+
+	return c.parseBody(FormatQDC, body, c.decodeOptions(opts, resumptionToken))
+}
 
-	var oaiResp OAIPMHResponseDC
-	if err := xml.Unmarshal(body, &oaiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+// listRecordsRequestDataCite performs a ListRecords request for DataCite
+func (c *OAIClient) listRecordsRequestDataCite(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, opts)
+	if err != nil {
+		return nil, err
 	}
+	defer body.Close()
+
+	return c.parseBody(FormatDataCite, body, c.decodeOptions(opts, resumptionToken))
+}
 
-	if oaiResp.Error != nil {
-		return nil, fmt.Errorf("OAI-PMH error [%s]: %s", oaiResp.Error.Code, oaiResp.Error.Message)
+// listRecordsRequestMETS performs a ListRecords request for METS
+func (c *OAIClient) listRecordsRequestMETS(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return c.parseBody(FormatMETS, body, c.decodeOptions(opts, resumptionToken))
+}
+
+// listRecordsRequestETDMS performs a ListRecords request for ETD-MS
+func (c *OAIClient) listRecordsRequestETDMS(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return c.parseBody(FormatETDMS, body, c.decodeOptions(opts, resumptionToken))
+}
+
+// listRecordsRequestORE performs a ListRecords request for OAI-ORE
+func (c *OAIClient) listRecordsRequestORE(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return c.parseBody(FormatORE, body, c.decodeOptions(opts, resumptionToken))
+}
+
+// listRecordsRequestLIDO performs a ListRecords request for LIDO
+func (c *OAIClient) listRecordsRequestLIDO(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return c.parseBody(FormatLIDO, body, c.decodeOptions(opts, resumptionToken))
+}
+
+// listRecordsRequestEAD performs a ListRecords request for EAD
+func (c *OAIClient) listRecordsRequestEAD(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return c.parseBody(FormatEAD, body, c.decodeOptions(opts, resumptionToken))
+}
+
+// GetRecord retrieves a single record by identifier in the given metadata format
+func (c *OAIClient) GetRecord(ctx context.Context, identifier string, metadataPrefix string) (OAIResponse, error) {
+	format := MetadataFormat(metadataPrefix)
+	if !format.isSupported() {
+		return nil, fmt.Errorf("unsupported metadata format: %s", metadataPrefix)
+	}
+
+	if identifier == "" {
+		return nil, fmt.Errorf("identifier must be provided")
+	}
+
+	body, err := c.openRequest(ctx, "GetRecord", "identifier", identifier, "metadataPrefix", metadataPrefix)
+	if err != nil {
+		return nil, err
 	}
+	defer body.Close()
+
+	return c.parseBody(format, body, decodeOptions{})
+}
 
-	return &oaiResp, nil
+// parseBody parses a response body with parseResponse, completing parse failures with
+// the request URL
+func (c *OAIClient) parseBody(format MetadataFormat, body io.Reader, page decodeOptions) (OAIResponse, error) {
+	resp, err := parseResponse(format, body, page)
+	if err != nil {
+		return nil, c.parseFailure(body, err)
+	}
+	return resp, nil
+}
+
+// parseResponse stream-decodes a ListRecords or GetRecord response for the given format
+func parseResponse(format MetadataFormat, r io.Reader, page decodeOptions) (OAIResponse, error) {
+	switch format {
+	case FormatMARCXML, FormatMARC21, FormatOAIMARC:
+		resp, err := decodeOAIPMHResponse(r, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	case FormatOAIDC:
+		resp, err := decodeOAIPMHResponseDC(r, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	case FormatMODS:
+		resp, err := decodeOAIPMHResponseMODS(r, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	case FormatQDC:
+		resp, err := decodeOAIPMHResponseQDC(r, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	case FormatDataCite, FormatDataCiteKernel:
+		resp, err := decodeOAIPMHResponseDataCite(r, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	case FormatMETS:
+		resp, err := decodeOAIPMHResponseMETS(r, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	case FormatETDMS:
+		resp, err := decodeOAIPMHResponseETDMS(r, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	case FormatORE:
+		resp, err := decodeOAIPMHResponseORE(r, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	case FormatLIDO:
+		resp, err := decodeOAIPMHResponseLIDO(r, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	case FormatEAD:
+		resp, err := decodeOAIPMHResponseEAD(r, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	default:
+		return nil, fmt.Errorf("unsupported metadata format: %s", format)
+	}
 }
 
 // performListRecordsRequest performs the actual HTTP request (unified logic)
-func (c *OAIClient) performListRecordsRequest(metadataPrefix string, resumptionToken string, dateRange *DateRange) ([]byte, error) {
-	url := c.BaseURL + "?verb=ListRecords"
+func (c *OAIClient) performListRecordsRequest(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (io.ReadCloser, error) {
+	var args []string
 
 	if resumptionToken != "" {
-		url += "&resumptionToken=" + resumptionToken
+		args = append(args, "resumptionToken", resumptionToken)
 	} else if metadataPrefix != "" {
-		url += "&metadataPrefix=" + metadataPrefix
+		args = append(args, "metadataPrefix", metadataPrefix)
 
-		// Add date range parameters if provided
-		if dateRange != nil {
-			if dateRange.From != "" {
-				url += "&from=" + dateRange.From
+		// Add selective harvesting parameters if provided
+		if opts != nil {
+			args = append(args, "set", opts.Set)
+			dateRange := opts.DateRange
+			if opts.restartRange != nil {
+				dateRange = opts.restartRange
 			}
-			if dateRange.Until != "" {
-				url += "&until=" + dateRange.Until
+			if dateRange != nil {
+				from, until, err := c.dateArguments(opts.context(), dateRange)
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, "from", from, "until", until)
 			}
 		}
 	} else {
 		return nil, fmt.Errorf("either metadataPrefix or resumptionToken must be provided")
 	}
 
-	resp, err := c.HTTPClient.Get(url)
+	body, err := c.openRequest(opts.context(), "ListRecords", args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch OAI data: %w", err)
+		return nil, err
+	}
+
+	if opts != nil && opts.run != nil {
+		body = &countingReadCloser{ReadCloser: body, count: &opts.run.bytes}
+	}
+	body = opts.limitPage(body)
+
+	// Lenient mode and validation need the whole page before it is decoded
+	if opts != nil && (opts.Lenient || opts.Validation != nil) {
+		source := findResponseBody(body)
+		data, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		if opts.Lenient {
+			if data, err = opts.dropMalformed(data, resumptionToken, c.log()); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Validation != nil {
+			if err := opts.Validation.validatePage(data, metadataPrefix, resumptionToken, c.log()); err != nil {
+				return nil, err
+			}
+		}
+		body = c.newResponseBody(io.NopCloser(bytes.NewReader(data)), source.url)
+	}
+
+	return body, nil
+}
+
+// performRequest performs an OAI-PMH HTTP request for the given verb and reads the whole body.
+// args are key/value pairs; pairs with an empty value are omitted.
+func (c *OAIClient) performRequest(ctx context.Context, verb string, args ...string) ([]byte, error) {
+	body, err := c.openRequest(ctx, verb, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return data, nil
+}
+
+// openRequest performs an OAI-PMH HTTP request for the given verb and returns the
+// response body for streaming. The caller must close the body.
+func (c *OAIClient) openRequest(ctx context.Context, verb string, args ...string) (io.ReadCloser, error) {
+	resp, err := c.sendRequest(ctx, verb, args...)
+	if err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		c.telemetry().Count(ctx, MetricHTTPFailures, 1, Attr(AttrVerb, verb), Attr(AttrStatus, resp.StatusCode))
+		c.log().Warn("unexpected OAI-PMH response status", "verb", verb, "url", resp.Request.URL.String(), "status", resp.StatusCode)
+		resp.Body.Close()
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	body, err := rejectNotXML(resp.Body)
+	if err != nil {
+		c.log().Warn("OAI-PMH response is not XML", "verb", verb, "url", resp.Request.URL.String(), "contentType", contentType)
+		return nil, err
+	}
+	if c.Archive != nil {
+		// Archived pages keep the bytes as received
+		if body, err = c.archiveResponse(body, resp.Request.URL.String(), verb, args); err != nil {
+			return nil, err
+		}
+	}
+
+	if body, err = decodeCharset(body, contentType, c.ScrubInvalidChars); err != nil {
+		return nil, err
+	}
+	return c.newResponseBody(body, resp.Request.URL.String()), nil
+}
+
+// sendRequest sends an OAI-PMH HTTP request for the given verb and returns the response
+// whatever its status code. args are key/value pairs; pairs with an empty value are omitted.
+func (c *OAIClient) sendRequest(ctx context.Context, verb string, args ...string) (*http.Response, error) {
+	if c.optionErr != nil {
+		return nil, c.optionErr
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	ctx, span := c.telemetry().StartSpan(ctx, "OAI-PMH "+verb, Attr(AttrVerb, verb), Attr(AttrBaseURL, c.BaseURL))
+	defer span.End()
+
+	form := url.Values{"verb": {verb}}
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i+1] != "" {
+			form.Set(args[i], args[i+1])
+		}
+	}
+
+	var req *http.Request
+	var err error
+	if c.UsePOST {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL, strings.NewReader(form.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = c.getRequest(ctx, form)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for key, values := range c.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if encoding := c.acceptEncoding(); encoding != "" {
+		req.Header.Set("Accept-Encoding", encoding)
+	}
+
+	start := time.Now()
+	resp, err := c.doer().Do(req)
+	if err != nil {
+		c.log().Warn("OAI-PMH request failed", "verb", verb, "url", req.URL.String(),
+			"resumptionToken", form.Get("resumptionToken"), "duration", time.Since(start), "error", err)
+		span.RecordError(err)
+		c.telemetry().Count(ctx, MetricHTTPFailures, 1, Attr(AttrVerb, verb), Attr(AttrStatus, 0))
+		return nil, fmt.Errorf("failed to fetch OAI data: %w", err)
+	}
+	span.SetAttributes(Attr(AttrStatus, resp.StatusCode))
+	c.logResponse(req, resp, form, start)
+
+	return resp, nil
+}
+
+// getRequest builds a GET request carrying the OAI-PMH arguments in the query string.
+// Arguments are escaped, so resumption tokens containing &, = or + survive, and query
+// parameters of the base URL (e.g. index.php?page=oai) are kept.
+func (c *OAIClient) getRequest(ctx context.Context, args url.Values) (*http.Request, error) {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	for key, values := range args {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+
+	return http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+}
+
+// archiveResponse reads the whole response, stores it in the client's archive and
+// returns it for parsing
+func (c *OAIClient) archiveResponse(body io.ReadCloser, url string, verb string, args []string) (io.ReadCloser, error) {
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	return body, nil
+	page := &ArchivedPage{Verb: verb, URL: url, Fetched: time.Now().UTC()}
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i+1] != "" {
+			if page.Args == nil {
+				page.Args = make(map[string]string)
+			}
+			page.Args[args[i]] = args[i+1]
+		}
+	}
+	if err := c.Archive.Store(page, data); err != nil {
+		return nil, fmt.Errorf("failed to archive response: %w", err)
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
 }
@@ -1,7 +1,19 @@
 package goharvest
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // TestUnifiedHarvestMARCXML demonstrates using unified Harvest API with MARCXML
@@ -214,3 +226,508 @@ func TestHarvestWithUntilDateOnly(t *testing.T) {
 		t.Skipf("Skipped: %v", err)
 	}
 }
+
+// fixtureServer is a test OAI-PMH endpoint that records the queries it receives
+type fixtureServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	queries []url.Values
+}
+
+// Queries returns the query parameters of all requests received so far
+func (s *fixtureServer) Queries() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]url.Values(nil), s.queries...)
+}
+
+// newFixtureServer starts a test OAI-PMH endpoint that serves files from testdata.
+// Fixtures are keyed by verb, or by "verb:resumptionToken" for follow-up pages, with an
+// optional "@set" suffix to serve a set or "?from=datestamp" suffix to serve a date range
+// differently.
+func newFixtureServer(t *testing.T, fixtures map[string]string) *fixtureServer {
+	t.Helper()
+
+	server := &fixtureServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		server.mu.Lock()
+		server.queries = append(server.queries, query)
+		server.mu.Unlock()
+
+		key := query.Get("verb")
+		if token := query.Get("resumptionToken"); token != "" {
+			key += ":" + token
+		}
+
+		file, ok := fixtures[key+"@"+query.Get("set")]
+		if from := query.Get("from"); !ok && from != "" {
+			file, ok = fixtures[key+"?from="+from]
+		}
+		if !ok {
+			file, ok = fixtures[key]
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", file))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// TestGetRecord fetches single records in both supported formats
+func TestGetRecord(t *testing.T) {
+	marcServer := newFixtureServer(t, map[string]string{"GetRecord": "getrecord_marcxml.xml"})
+	dcServer := newFixtureServer(t, map[string]string{"GetRecord": "getrecord_oai_dc.xml"})
+
+	resp, err := NewClient(marcServer.URL).GetRecord(context.Background(), "oai:balaiyanpus.jogjaprov.go.id:14", "marcxml")
+	if err != nil {
+		t.Fatalf("GetRecord marcxml failed: %v", err)
+	}
+	records := resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 MARCXML record, got %d", len(records))
+	}
+	if bookMeta, ok := records[0].ExtractMetadata().(*BookMetadata); !ok || bookMeta.Publisher != "Kejora" {
+		t.Errorf("Unexpected MARCXML metadata: %+v", records[0].ExtractMetadata())
+	}
+
+	resp, err = NewClient(dcServer.URL).GetRecord(context.Background(), "oai:example.org:1", "oai_dc")
+	if err != nil {
+		t.Fatalf("GetRecord oai_dc failed: %v", err)
+	}
+	records = resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 DC record, got %d", len(records))
+	}
+	if dcMeta, ok := records[0].ExtractMetadata().(*DCMetadata); !ok || dcMeta.Title[0] != "Sistem Informasi Perpustakaan" {
+		t.Errorf("Unexpected DC metadata: %+v", records[0].ExtractMetadata())
+	}
+
+	if _, err := NewClient(dcServer.URL).GetRecord(context.Background(), "oai:example.org:1", "unknown"); err == nil {
+		t.Error("Expected error for unsupported metadata format")
+	}
+}
+
+// TestHarvestWithSet verifies set-scoped harvesting across resumption tokens
+func TestHarvestWithSet(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	opts := &HarvestOptions{
+		DateRange: &DateRange{From: "2025-01-01"},
+		Set:       "theses",
+	}
+
+	recordCount := 0
+	err := client.HarvestWithOptions("oai_dc", opts, func(response OAIResponse) error {
+		recordCount += len(response.GetRecords())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestWithOptions failed: %v", err)
+	}
+
+	if recordCount != 3 {
+		t.Errorf("Expected 3 records across both pages, got %d", recordCount)
+	}
+
+	queries := server.Queries()
+	if len(queries) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(queries))
+	}
+	if queries[0].Get("set") != "theses" || queries[0].Get("from") != "2025-01-01" {
+		t.Errorf("Expected set and from on first request, got %v", queries[0])
+	}
+	if queries[1].Get("set") != "" || queries[1].Get("resumptionToken") != "dc-page-2" {
+		t.Errorf("Expected only resumptionToken on follow-up request, got %v", queries[1])
+	}
+}
+
+// TestHarvestWithPrefetch verifies the next page is fetched while the callback is still running
+func TestHarvestWithPrefetch(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	pages := 0
+	recordCount := 0
+	err := client.HarvestWithOptions("oai_dc", &HarvestOptions{PrefetchPages: 1}, func(response OAIResponse) error {
+		pages++
+		recordCount += len(response.GetRecords())
+
+		if pages == 1 {
+			deadline := time.Now().Add(5 * time.Second)
+			for len(server.Queries()) < 2 {
+				if time.Now().After(deadline) {
+					return fmt.Errorf("second page was not prefetched")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestWithOptions failed: %v", err)
+	}
+
+	if pages != 2 || recordCount != 3 {
+		t.Errorf("Expected 2 pages and 3 records, got %d pages and %d records", pages, recordCount)
+	}
+}
+
+// TestHarvestExtractionWorkers verifies records decoded by several workers keep their document order
+func TestHarvestExtractionWorkers(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+
+	var got []string
+	opts := &HarvestOptions{ExtractionWorkers: 3, PrefetchPages: 2}
+	err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(record HarvestedRecord) error {
+		got = append(got, strings.TrimPrefix(record.Header.Identifier, "oai:example.org:"))
+		if !record.IsDeleted() && record.Metadata == nil {
+			t.Errorf("Expected metadata for %s", record.Header.Identifier)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1", "2", "4", "5"}; !slices.Equal(got, want) {
+		t.Errorf("Expected records %v in document order, got %v", want, got)
+	}
+}
+
+// TestHarvestStop verifies ErrStopHarvest ends a harvest cleanly and reports the resumption token
+func TestHarvestStop(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	stats := &HarvestStats{}
+	pages := 0
+	err := client.HarvestWithOptions("oai_dc", &HarvestOptions{Stats: stats}, func(OAIResponse) error {
+		pages++
+		return ErrStopHarvest
+	})
+	if err != nil {
+		t.Fatalf("Expected clean stop, got %v", err)
+	}
+
+	if pages != 1 || len(server.Queries()) != 1 {
+		t.Errorf("Expected harvest to stop after the first page, got %d pages and %d requests", pages, len(server.Queries()))
+	}
+	if !stats.Stopped || stats.ResumptionToken != "dc-page-2" {
+		t.Errorf("Expected stopped harvest with token dc-page-2, got %+v", stats)
+	}
+
+	err = client.HarvestRecords("oai_dc", nil, func(HarvestedRecord) error {
+		return ErrStopHarvest
+	})
+	if err != nil {
+		t.Errorf("Expected clean stop from record callback, got %v", err)
+	}
+}
+
+func TestHarvestLimits(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	stats := &HarvestStats{}
+	pages := 0
+	err := client.HarvestWithOptions("oai_dc", &HarvestOptions{MaxPages: 1, Stats: stats}, func(OAIResponse) error {
+		pages++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages != 1 || !stats.Stopped || stats.ResumptionToken != "dc-page-2" {
+		t.Errorf("Expected MaxPages to stop after the first page, got %d pages and %+v", pages, stats)
+	}
+
+	// The page APIs stop after the page reaching the limit
+	pages = 0
+	err = client.HarvestWithOptions("oai_dc", &HarvestOptions{MaxRecords: 2, Stats: stats}, func(OAIResponse) error {
+		pages++
+		return nil
+	})
+	if err != nil || pages != 1 || !stats.Stopped {
+		t.Errorf("Expected MaxRecords to stop after the first page, got %d pages, %+v, %v", pages, stats, err)
+	}
+
+	var identifiers []string
+	err = client.HarvestRecords("oai_dc", &HarvestOptions{MaxRecords: 2, SkipDeleted: true, Stats: stats}, func(record HarvestedRecord) error {
+		identifiers = append(identifiers, record.Header.Identifier)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(identifiers, []string{"oai:example.org:1", "oai:example.org:4"}) || !stats.Stopped {
+		t.Errorf("Expected the first 2 records, got %v and %+v", identifiers, stats)
+	}
+
+	// A limit that is not reached lets the harvest complete
+	identifiers = nil
+	err = client.HarvestRecords("oai_dc", &HarvestOptions{MaxRecords: 10, Stats: stats}, func(record HarvestedRecord) error {
+		identifiers = append(identifiers, record.Header.Identifier)
+		return nil
+	})
+	if err != nil || len(identifiers) != 4 || stats.Stopped {
+		t.Errorf("Expected a complete harvest, got %v, %+v, %v", identifiers, stats, err)
+	}
+}
+
+func TestHarvestMaxRecordsResume(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords:dc-page-1": "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+	checkpointer := NewFileCheckpointer(filepath.Join(t.TempDir(), "harvest.json"))
+
+	var stats HarvestStats
+	var identifiers []string
+	collect := func(record HarvestedRecord) error {
+		identifiers = append(identifiers, record.Header.Identifier)
+		return nil
+	}
+	opts := &HarvestOptions{ResumptionToken: "dc-page-1", MaxRecords: 1, Checkpointer: checkpointer, Stats: &stats}
+	if err := client.HarvestRecords("oai_dc", opts, collect); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(identifiers, []string{"oai:example.org:1"}) {
+		t.Errorf("Expected the first record, got %v", identifiers)
+	}
+	// The page was only partly delivered, so the harvest continues with it
+	if !stats.Stopped || stats.ResumptionToken != "dc-page-1" {
+		t.Errorf("Expected to stop at the token of the current page, got %+v", stats)
+	}
+	state, err := checkpointer.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state == nil || state.ResumptionToken != "dc-page-1" || state.PagesHarvested != 0 {
+		t.Errorf("Expected a checkpoint at the current page, got %+v", state)
+	}
+
+	identifiers = nil
+	if err := client.HarvestRecords("oai_dc", &HarvestOptions{ResumptionToken: stats.ResumptionToken}, collect); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"oai:example.org:1", "oai:example.org:2", "oai:example.org:4", "oai:example.org:5"}
+	if !slices.Equal(identifiers, expected) {
+		t.Errorf("Expected the resumed harvest to deliver the page again, got %v", identifiers)
+	}
+}
+
+func TestHarvestFromResumptionToken(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+
+	var identifiers []string
+	opts := &HarvestOptions{ResumptionToken: "dc-page-2", Set: "ignored"}
+	err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(record HarvestedRecord) error {
+		identifiers = append(identifiers, record.Header.Identifier)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(identifiers, []string{"oai:example.org:5"}) {
+		t.Errorf("Expected the records of the second page, got %v", identifiers)
+	}
+
+	query := server.Queries()[0]
+	if query.Get("resumptionToken") != "dc-page-2" || query.Has("metadataPrefix") || query.Has("set") {
+		t.Errorf("Expected only the resumption token to be sent, got %v", query)
+	}
+}
+
+func TestHarvestRecoverBadResumptionToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		restart  string
+		opts     HarvestOptions
+		want     []string
+		rejected bool
+	}{
+		{"recovered", "listrecords_dc_page2.xml", HarvestOptions{RecoverBadResumptionToken: true}, []string{"1", "2", "4", "5"}, false},
+		{"prefetched", "listrecords_dc_page2.xml", HarvestOptions{RecoverBadResumptionToken: true, PrefetchPages: 2}, []string{"1", "2", "4", "5"}, false},
+		{"disabled", "listrecords_dc_page2.xml", HarvestOptions{}, []string{"1", "2", "4"}, true},
+		// The restarted list expires again before a later datestamp
+		{"no progress", "listrecords_dc_page1.xml", HarvestOptions{RecoverBadResumptionToken: true, Dedup: &DedupOptions{}}, []string{"1", "2", "4"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFixtureServer(t, map[string]string{
+				"ListRecords":                 "listrecords_dc_page1.xml",
+				"ListRecords:dc-page-2":       "listrecords_badresumptiontoken.xml",
+				"ListRecords?from=2025-01-18": tt.restart,
+			})
+
+			var stats HarvestStats
+			opts := tt.opts
+			opts.Stats = &stats
+			var got []string
+			err := NewClient(server.URL).HarvestRecords("oai_dc", &opts, func(record HarvestedRecord) error {
+				got = append(got, strings.TrimPrefix(record.Header.Identifier, "oai:example.org:"))
+				return nil
+			})
+
+			if tt.rejected != errors.Is(err, ErrBadResumptionToken) || (!tt.rejected && err != nil) {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected records %v, got %v", tt.want, got)
+			}
+			if recovered := stats.TokenRecoveries > 0; recovered != opts.RecoverBadResumptionToken {
+				t.Errorf("Unexpected recoveries in %+v", stats)
+			}
+			if !tt.rejected {
+				restart := server.Queries()[2]
+				if restart.Get("from") != "2025-01-18" || restart.Get("resumptionToken") != "" || restart.Get("metadataPrefix") != "oai_dc" {
+					t.Errorf("Expected a new list from the latest datestamp, got %v", restart)
+				}
+			}
+		})
+	}
+}
+
+func TestPOSTRequests(t *testing.T) {
+	var mu sync.Mutex
+	var forms []url.Values
+	fixtures := map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" || r.URL.RawQuery != "" {
+			http.Error(w, "POST only", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		forms = append(forms, r.PostForm)
+		mu.Unlock()
+
+		key := r.PostForm.Get("verb")
+		if token := r.PostForm.Get("resumptionToken"); token != "" {
+			key += ":" + token
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", fixtures[key]))
+	}))
+	defer server.Close()
+
+	if err := NewClient(server.URL).HarvestWithOptions("oai_dc", nil, func(OAIResponse) error { return nil }); err == nil {
+		t.Fatal("Expected GET harvest to be rejected")
+	}
+
+	count := 0
+	opts := &HarvestOptions{Set: "theses", DateRange: &DateRange{From: "2025-01-01"}}
+	err := NewClient(server.URL, WithPOST()).HarvestRecords("oai_dc", opts, func(HarvestedRecord) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("POST harvest failed: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 records, got %d", count)
+	}
+
+	if len(forms) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(forms))
+	}
+	if forms[0].Get("metadataPrefix") != "oai_dc" || forms[0].Get("set") != "theses" || forms[0].Get("from") != "2025-01-01" || forms[0].Has("until") {
+		t.Errorf("Unexpected first form %v", forms[0])
+	}
+	if forms[1].Get("resumptionToken") != "dc-page-2" || len(forms[1]) != 2 {
+		t.Errorf("Unexpected second form %v", forms[1])
+	}
+}
+
+func TestRequestURLEscaping(t *testing.T) {
+	const token = "a&b=c+d e/f%;"
+	page1, err := os.ReadFile(filepath.Join("testdata", "listrecords_dc_page1.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page1 = []byte(strings.Replace(string(page1), "dc-page-2", "a&amp;b=c+d e/f%;", 1))
+
+	var mu sync.Mutex
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		mu.Lock()
+		queries = append(queries, query)
+		mu.Unlock()
+
+		if r.URL.Path != "/index.php" || query.Get("page") != "oai" {
+			http.NotFound(w, r)
+			return
+		}
+		switch query.Get("resumptionToken") {
+		case "":
+			w.Write(page1)
+		case token:
+			http.ServeFile(w, r, filepath.Join("testdata", "listrecords_dc_page2.xml"))
+		default:
+			http.Error(w, "unknown token", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	count := 0
+	opts := &HarvestOptions{Set: "a:b c+d", DateRange: &DateRange{From: "2025-01-01"}}
+	err = NewClient(server.URL+"/index.php?page=oai").HarvestRecords("oai_dc", opts, func(HarvestedRecord) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 records, got %d", count)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(queries))
+	}
+	if queries[0].Get("set") != "a:b c+d" || queries[0].Get("from") != "2025-01-01" || queries[0].Get("verb") != "ListRecords" {
+		t.Errorf("Unexpected first query %v", queries[0])
+	}
+	if queries[1].Get("resumptionToken") != token || queries[1].Get("page") != "oai" {
+		t.Errorf("Unexpected second query %v", queries[1])
+	}
+}
@@ -0,0 +1,82 @@
+package goharvest
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
+// HeaderCallback is the callback function type for identifier harvesting
+type HeaderCallback func(header Header) error
+
+// HarvestIdentifiers harvests record headers using the ListIdentifiers verb.
+// Use dateRange (nil for no date filtering) and set (empty for all sets) for selective harvesting.
+func (c *OAIClient) HarvestIdentifiers(metadataPrefix string, dateRange *DateRange, set string, callback HeaderCallback) error {
+	return c.harvestIdentifiers(context.Background(), metadataPrefix, dateRange, set, callback)
+}
+
+// harvestIdentifiers is HarvestIdentifiers stopping when ctx is cancelled
+func (c *OAIClient) harvestIdentifiers(ctx context.Context, metadataPrefix string, dateRange *DateRange, set string, callback HeaderCallback) error {
+	if metadataPrefix == "" {
+		return fmt.Errorf("metadataPrefix must be provided")
+	}
+
+	resumptionToken := ""
+
+	for {
+		resp, err := c.listIdentifiersRequest(ctx, metadataPrefix, resumptionToken, dateRange, set)
+		if err != nil {
+			return err
+		}
+
+		if resp.ListIdentifiers == nil {
+			return nil
+		}
+
+		for _, header := range resp.ListIdentifiers.Headers {
+			if err := callback(header); err != nil {
+				return fmt.Errorf("callback error: %w", err)
+			}
+		}
+
+		token := resp.ListIdentifiers.ResumptionToken
+		if token == nil || token.Token == "" {
+			return nil
+		}
+
+		resumptionToken = token.Token
+	}
+}
+
+// listIdentifiersRequest performs a single ListIdentifiers request
+func (c *OAIClient) listIdentifiersRequest(ctx context.Context, metadataPrefix string, resumptionToken string, dateRange *DateRange, set string) (*OAIPMHResponse, error) {
+	var args []string
+	if resumptionToken != "" {
+		args = append(args, "resumptionToken", resumptionToken)
+	} else {
+		args = append(args, "metadataPrefix", metadataPrefix, "set", set)
+		if dateRange != nil {
+			from, until, err := c.dateArguments(ctx, dateRange)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, "from", from, "until", until)
+		}
+	}
+
+	body, err := c.performRequest(ctx, "ListIdentifiers", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var oaiResp OAIPMHResponse
+	if err := xml.Unmarshal(body, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, oaiResp.Error
+	}
+
+	return &oaiResp, nil
+}
@@ -0,0 +1,63 @@
+package goharvest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHarvestIdentifiers(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListIdentifiers":            "listidentifiers_page1.xml",
+		"ListIdentifiers:ids-page-2": "listidentifiers_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	var headers []Header
+	err := client.HarvestIdentifiers("oai_dc", &DateRange{From: "2025-01-01"}, "theses", func(header Header) error {
+		headers = append(headers, header)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestIdentifiers failed: %v", err)
+	}
+
+	if len(headers) != 3 {
+		t.Fatalf("Expected 3 headers across both pages, got %d", len(headers))
+	}
+	if headers[1].Status != "deleted" {
+		t.Errorf("Expected second header to be deleted, got status '%s'", headers[1].Status)
+	}
+	if len(headers[2].SetSpec) != 2 {
+		t.Errorf("Expected 2 setSpecs on last header, got %d", len(headers[2].SetSpec))
+	}
+}
+
+func TestHarvestIdentifiersRequestParameters(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`<OAI-PMH><ListIdentifiers></ListIdentifiers></OAI-PMH>`))
+	}))
+	defer server.Close()
+
+	err := NewClient(server.URL).HarvestIdentifiers("marcxml", &DateRange{From: "2025-01-01", Until: "2025-01-31"}, "books", func(Header) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestIdentifiers failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"verb":           "ListIdentifiers",
+		"metadataPrefix": "marcxml",
+		"set":            "books",
+		"from":           "2025-01-01",
+		"until":          "2025-01-31",
+	}
+	for key, value := range expected {
+		if got := query[key]; len(got) != 1 || got[0] != value {
+			t.Errorf("Expected %s=%s, got %v", key, value, got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package goharvest
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
+// Deleted record support levels advertised by a repository
+const (
+	DeletedRecordNo         = "no"
+	DeletedRecordPersistent = "persistent"
+	DeletedRecordTransient  = "transient"
+)
+
+// Datestamp granularities advertised by a repository
+const (
+	GranularityDay    = "YYYY-MM-DD"
+	GranularitySecond = "YYYY-MM-DDThh:mm:ssZ"
+)
+
+// IdentifyResponse represents the OAI-PMH response to the Identify verb
+type IdentifyResponse struct {
+	XMLName      xml.Name   `xml:"OAI-PMH"`
+	ResponseDate string     `xml:"responseDate"`
+	Request      OAIRequest `xml:"request"`
+	Identify     *Identify  `xml:"Identify,omitempty"`
+	Error        *OAIError  `xml:"error,omitempty"`
+}
+
+// Identify contains the repository information returned by the Identify verb
+type Identify struct {
+	RepositoryName    string        `xml:"repositoryName"`
+	BaseURL           string        `xml:"baseURL"`
+	ProtocolVersion   string        `xml:"protocolVersion"`
+	AdminEmail        []string      `xml:"adminEmail"`
+	EarliestDatestamp string        `xml:"earliestDatestamp"`
+	DeletedRecord     string        `xml:"deletedRecord"`
+	Granularity       string        `xml:"granularity"`
+	Compression       []string      `xml:"compression,omitempty"`
+	Description       []Description `xml:"description,omitempty"`
+}
+
+// Description contains an optional description container (oai-identifier, eprints, etc.)
+type Description struct {
+	Raw []byte `xml:",innerxml"`
+}
+
+// SupportsDeletedRecords returns true if the repository keeps track of deleted records
+func (i *Identify) SupportsDeletedRecords() bool {
+	return i.DeletedRecord == DeletedRecordPersistent || i.DeletedRecord == DeletedRecordTransient
+}
+
+// Identify retrieves information about the repository
+func (c *OAIClient) Identify(ctx context.Context) (*Identify, error) {
+	body, err := c.performRequest(ctx, "Identify")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseIdentifyXML(body)
+}
+
+// parseIdentifyXML parses an Identify response from bytes
+func parseIdentifyXML(data []byte) (*Identify, error) {
+	var oaiResp IdentifyResponse
+	if err := xml.Unmarshal(data, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, oaiResp.Error
+	}
+
+	if oaiResp.Identify == nil {
+		return nil, fmt.Errorf("missing Identify element in response")
+	}
+
+	return oaiResp.Identify, nil
+}
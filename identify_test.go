@@ -0,0 +1,48 @@
+package goharvest
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestIdentify(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"Identify": "identify_response.xml",
+	})
+	client := NewClient(server.URL)
+
+	identify, err := client.Identify(context.Background())
+	if err != nil {
+		t.Fatalf("Identify failed: %v", err)
+	}
+
+	if identify.RepositoryName != "Example Repository" {
+		t.Errorf("Expected repositoryName 'Example Repository', got '%s'", identify.RepositoryName)
+	}
+	if identify.ProtocolVersion != "2.0" {
+		t.Errorf("Expected protocolVersion '2.0', got '%s'", identify.ProtocolVersion)
+	}
+	if identify.EarliestDatestamp != "2001-01-01" {
+		t.Errorf("Expected earliestDatestamp '2001-01-01', got '%s'", identify.EarliestDatestamp)
+	}
+	if identify.Granularity != GranularityDay {
+		t.Errorf("Expected granularity '%s', got '%s'", GranularityDay, identify.Granularity)
+	}
+	if !identify.SupportsDeletedRecords() {
+		t.Errorf("Expected deleted record support for policy '%s'", identify.DeletedRecord)
+	}
+	if len(identify.AdminEmail) != 2 {
+		t.Errorf("Expected 2 admin emails, got %d", len(identify.AdminEmail))
+	}
+	if len(identify.Description) != 1 || !strings.Contains(string(identify.Description[0].Raw), "oai-identifier") {
+		t.Errorf("Expected oai-identifier description container, got %v", identify.Description)
+	}
+}
+
+func TestIdentifyOAIError(t *testing.T) {
+	_, err := parseIdentifyXML([]byte(`<OAI-PMH><error code="badVerb">Illegal verb</error></OAI-PMH>`))
+	if err == nil {
+		t.Fatal("Expected error for OAI-PMH error response")
+	}
+}
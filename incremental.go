@@ -0,0 +1,210 @@
+package goharvest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultIncrementalOverlap is the overlap window of an IncrementalHarvester. Records
+// committed while the previous harvest ran can carry datestamps before its start, so each
+// harvest starts a little before the previous one did.
+const DefaultIncrementalOverlap = time.Hour
+
+// IncrementalKey identifies a series of incremental harvests
+type IncrementalKey struct {
+	BaseURL        string `json:"base_url"`
+	Set            string `json:"set,omitempty"`
+	MetadataPrefix string `json:"metadata_prefix"`
+}
+
+// IncrementalState is the persisted state of a series of incremental harvests
+type IncrementalState struct {
+	IncrementalKey
+	// LastHarvest is when the last successful harvest started, by the repository's clock
+	// when its responses tell
+	LastHarvest time.Time `json:"last_harvest"`
+	// Records is the number of records seen by the last successful harvest
+	Records int `json:"records"`
+	// UpdatedAt is when the state was saved
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IncrementalStore persists the state of incremental harvests
+type IncrementalStore interface {
+	// Load returns the state stored for key, or nil if there is none
+	Load(key IncrementalKey) (*IncrementalState, error)
+	// Save stores the state after a successful harvest
+	Save(state *IncrementalState) error
+}
+
+// IncrementalHarvester runs repeated harvests that only fetch the records changed since the
+// last successful one. State is committed only when a harvest completes, so a failed or
+// stopped harvest is repeated from the same date next time.
+type IncrementalHarvester struct {
+	Client *OAIClient
+	Store  IncrementalStore
+	// Overlap is subtracted from the last harvest time to compute the next from date
+	Overlap time.Duration
+	// Options are applied to every harvest; Set, DateRange and IgnoreNoRecordsMatch are
+	// set by the harvester
+	Options HarvestOptions
+}
+
+// NewIncrementalHarvester creates an IncrementalHarvester with DefaultIncrementalOverlap
+func NewIncrementalHarvester(client *OAIClient, store IncrementalStore) *IncrementalHarvester {
+	return &IncrementalHarvester{Client: client, Store: store, Overlap: DefaultIncrementalOverlap}
+}
+
+// Harvest harvests the records of set (empty for all) changed since the last successful
+// harvest, or all records the first time, and invokes callback once per record
+func (h *IncrementalHarvester) Harvest(ctx context.Context, metadataPrefix, set string, callback RecordCallback) error {
+	key := IncrementalKey{BaseURL: h.Client.BaseURL, Set: set, MetadataPrefix: metadataPrefix}
+	state, err := h.Store.Load(key)
+	if err != nil {
+		return fmt.Errorf("failed to load incremental state: %w", err)
+	}
+
+	opts := h.Options
+	opts.Set = set
+	opts.DateRange = nil
+	// A repository without changes answers noRecordsMatch
+	opts.IgnoreNoRecordsMatch = true
+	if state != nil {
+		opts.DateRange = Since(state.LastHarvest.Add(-h.Overlap))
+	}
+	var stats HarvestStats
+	if opts.Stats == nil {
+		opts.Stats = &stats
+	}
+
+	start := time.Now().UTC()
+	dated := false
+	err = h.Client.harvestRecords(ctx, metadataPrefix, &opts, func(record HarvestedRecord) error {
+		if record.Page.Number == 1 && !dated {
+			start = harvestStart(record.Page.ResponseDate, start)
+			dated = true
+		}
+		return callback(record)
+	})
+	if err != nil {
+		return err
+	}
+	if opts.Stats.Stopped {
+		return nil
+	}
+
+	next := &IncrementalState{
+		IncrementalKey: key,
+		LastHarvest:    start,
+		Records:        opts.Stats.Records,
+		UpdatedAt:      time.Now().UTC(),
+	}
+	if err := h.Store.Save(next); err != nil {
+		return fmt.Errorf("failed to save incremental state: %w", err)
+	}
+	return nil
+}
+
+// harvestStart returns the responseDate of the first page of a harvest, which is not
+// subject to the skew between the local and the repository clock, or fallback
+func harvestStart(responseDate string, fallback time.Time) time.Time {
+	t, err := time.Parse(time.RFC3339, responseDate)
+	if err != nil {
+		return fallback
+	}
+	return t.UTC()
+}
+
+// FileIncrementalStore stores the state of incremental harvests in a JSON file
+type FileIncrementalStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// NewFileIncrementalStore creates a store keeping state in the file at path
+func NewFileIncrementalStore(path string) *FileIncrementalStore {
+	return &FileIncrementalStore{Path: path}
+}
+
+// Load reads the state for key from the file
+func (f *FileIncrementalStore) Load(key IncrementalKey) (*IncrementalState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	states, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		if state.IncrementalKey == key {
+			return state, nil
+		}
+	}
+	return nil, nil
+}
+
+// Save replaces the state for its key and writes the file atomically
+func (f *FileIncrementalStore) Save(state *IncrementalState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	states, err := f.read()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i, existing := range states {
+		if existing.IncrementalKey == state.IncrementalKey {
+			states[i] = state
+			replaced = true
+		}
+	}
+	if !replaced {
+		states = append(states, state)
+	}
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// read returns all states in the file
+func (f *FileIncrementalStore) read() ([]*IncrementalState, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var states []*IncrementalState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
@@ -0,0 +1,132 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIncrementalHarvester(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	store := NewFileIncrementalStore(filepath.Join(t.TempDir(), "incremental.json"))
+	harvester := NewIncrementalHarvester(NewClient(server.URL, WithGranularity(GranularitySecond)), store)
+
+	for i := 0; i < 2; i++ {
+		count := 0
+		err := harvester.Harvest(context.Background(), "oai_dc", "theses", func(HarvestedRecord) error {
+			count++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Harvest %d failed: %v", i+1, err)
+		}
+		if count != 4 {
+			t.Errorf("Expected 4 records in harvest %d, got %d", i+1, count)
+		}
+	}
+
+	queries := server.Queries()
+	if len(queries) != 4 {
+		t.Fatalf("Expected 4 requests, got %v", queries)
+	}
+	if queries[0].Has("from") || queries[0].Get("set") != "theses" {
+		t.Errorf("Expected a full first harvest, got %v", queries[0])
+	}
+	if from := queries[2].Get("from"); from != "2025-10-02T09:05:19Z" {
+		t.Errorf("Expected the next harvest from the first responseDate minus the overlap, got %q", from)
+	}
+
+	state, err := store.Load(IncrementalKey{BaseURL: server.URL, Set: "theses", MetadataPrefix: "oai_dc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state == nil || !state.LastHarvest.Equal(time.Date(2025, 10, 2, 10, 5, 19, 0, time.UTC)) || state.Records != 4 {
+		t.Errorf("Unexpected state %+v", state)
+	}
+	if other, err := store.Load(IncrementalKey{BaseURL: server.URL, MetadataPrefix: "oai_dc"}); err != nil || other != nil {
+		t.Errorf("Expected no state for all sets, got %v, %v", other, err)
+	}
+}
+
+func TestIncrementalHarvesterKeepsStateOnFailure(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	store := NewFileIncrementalStore(filepath.Join(t.TempDir(), "incremental.json"))
+	last := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	key := IncrementalKey{BaseURL: server.URL, MetadataPrefix: "oai_dc"}
+	if err := store.Save(&IncrementalState{IncrementalKey: key, LastHarvest: last}); err != nil {
+		t.Fatal(err)
+	}
+	harvester := NewIncrementalHarvester(NewClient(server.URL, WithGranularity(GranularitySecond)), store)
+	harvester.Overlap = 0
+
+	failure := errors.New("sink unavailable")
+	count := 0
+	err := harvester.Harvest(context.Background(), "oai_dc", "", func(HarvestedRecord) error {
+		if count++; count == 4 {
+			return failure
+		}
+		return nil
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the callback error, got %v", err)
+	}
+
+	err = harvester.Harvest(context.Background(), "oai_dc", "", func(HarvestedRecord) error { return ErrStopHarvest })
+	if err != nil {
+		t.Fatalf("Stopped harvest failed: %v", err)
+	}
+
+	state, err := store.Load(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.LastHarvest.Equal(last) {
+		t.Errorf("Expected the state to be kept, got %v", state.LastHarvest)
+	}
+	if from := server.Queries()[0].Get("from"); from != "2025-09-01T00:00:00Z" {
+		t.Errorf("Expected the harvest from the stored date, got %q", from)
+	}
+}
+
+func TestIncrementalHarvesterOptions(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_duplicates.xml",
+	})
+	store := NewFileIncrementalStore(filepath.Join(t.TempDir(), "incremental.json"))
+	harvester := NewIncrementalHarvester(NewClient(server.URL), store)
+
+	// The options of the harvester apply to the records delivered
+	var stats HarvestStats
+	harvester.Options = HarvestOptions{MaxRecords: 1, Stats: &stats}
+	count := 0
+	err := harvester.Harvest(context.Background(), "oai_dc", "", func(HarvestedRecord) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || !stats.Stopped {
+		t.Errorf("Expected MaxRecords to stop after 1 record, got %d and %+v", count, stats)
+	}
+	if state, err := store.Load(IncrementalKey{BaseURL: server.URL, MetadataPrefix: "oai_dc"}); err != nil || state != nil {
+		t.Errorf("Expected no state after a stopped harvest, got %+v, %v", state, err)
+	}
+
+	harvester.Options = HarvestOptions{Dedup: &DedupOptions{}, Stats: &stats}
+	if err := harvester.Harvest(context.Background(), "oai_dc", "", func(HarvestedRecord) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if stats.DuplicateRecords == 0 {
+		t.Errorf("Expected duplicates to be suppressed, got %+v", stats)
+	}
+}
@@ -0,0 +1,230 @@
+package goharvest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+)
+
+// MalformedRecordError describes a record dropped from a page in lenient mode
+type MalformedRecordError struct {
+	// Index is the 1-based position of the record in its page
+	Index int
+	// Identifier is the record identifier if it could be recovered ("" otherwise)
+	Identifier string
+	// ResumptionToken that requested the page ("" for the first page)
+	ResumptionToken string
+	// Raw holds the bytes of the record element as received
+	Raw []byte
+	// Err is the XML syntax error
+	Err error
+}
+
+// Error implements the error interface
+func (e *MalformedRecordError) Error() string {
+	subject := fmt.Sprintf("record %d", e.Index)
+	if e.Identifier != "" {
+		subject = "record " + e.Identifier
+	}
+	return fmt.Sprintf("malformed %s: %v", subject, e.Err)
+}
+
+// Unwrap returns the XML syntax error
+func (e *MalformedRecordError) Unwrap() error {
+	return e.Err
+}
+
+// headerIdentifierPattern finds the header identifier of a record, with or without a prefix
+var headerIdentifierPattern = regexp.MustCompile(`<(?:[\w.-]+:)?identifier>\s*([^<]*?)\s*</`)
+
+// recordSpan is the byte range of a <record> element of a ListRecords response
+type recordSpan struct {
+	start, end int
+}
+
+// dropMalformedRecords removes the record elements that are not well-formed from a
+// ListRecords response, so that one broken record does not fail the whole page. Records
+// are located lexically because a syntax error leaves an xml.Decoder unusable.
+func dropMalformedRecords(data []byte, resumptionToken string, report func(*MalformedRecordError) error) ([]byte, error) {
+	spans := findRecordSpans(data)
+
+	var cleaned []byte
+	last := 0
+	for i, span := range spans {
+		raw := data[span.start:span.end]
+		syntaxErr := checkWellFormed(raw)
+		if syntaxErr == nil {
+			continue
+		}
+
+		malformed := &MalformedRecordError{
+			Index:           i + 1,
+			ResumptionToken: resumptionToken,
+			Raw:             bytes.Clone(raw),
+			Err:             syntaxErr,
+		}
+		if m := headerIdentifierPattern.FindSubmatch(raw); m != nil {
+			malformed.Identifier = string(m[1])
+		}
+		if err := report(malformed); err != nil {
+			return nil, err
+		}
+
+		cleaned = append(cleaned, data[last:span.start]...)
+		last = span.end
+	}
+
+	if cleaned == nil {
+		return data, nil
+	}
+	return append(cleaned, data[last:]...), nil
+}
+
+// checkWellFormed tokenizes a fragment and reports the first syntax error
+func checkWellFormed(fragment []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(fragment))
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			if depth != 0 {
+				return fmt.Errorf("unexpected end of record")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// findRecordSpans locates the top-level <record> elements of a ListRecords response. A
+// record is recognised by its start tag being followed by a <header>, which tells it apart
+// from records nested in metadata (e.g. MARCXML). A record that is never closed ends where
+// the next record, the resumption token or the end of ListRecords starts.
+func findRecordSpans(data []byte) []recordSpan {
+	var spans []recordSpan
+	start, depth := -1, 0
+
+	closeAt := func(end int) {
+		spans = append(spans, recordSpan{start: start, end: end})
+		start, depth = -1, 0
+	}
+
+	for i := 0; i < len(data); {
+		j := bytes.IndexByte(data[i:], '<')
+		if j < 0 {
+			break
+		}
+		i += j
+
+		if skip := skipMarkup(data[i:]); skip > 0 {
+			i += skip
+			continue
+		}
+
+		closing := i+1 < len(data) && data[i+1] == '/'
+		nameStart := i + 1
+		if closing {
+			nameStart++
+		}
+		local, tagEnd := scanTag(data, nameStart)
+
+		switch {
+		case local == "record" && !closing && data[tagEnd-2] != '/':
+			if isRecordStart(data[tagEnd:]) {
+				if start >= 0 {
+					closeAt(i)
+				}
+				start = i
+			}
+			if start >= 0 {
+				depth++
+			}
+		case local == "record" && closing && start >= 0:
+			depth--
+			if depth == 0 {
+				closeAt(tagEnd)
+			}
+		case start >= 0 && ((local == "resumptionToken" && !closing) || (local == "ListRecords" && closing)):
+			closeAt(i)
+		}
+		i = tagEnd
+	}
+
+	if start >= 0 {
+		closeAt(len(data))
+	}
+	return spans
+}
+
+// skipMarkup returns the length of a comment, CDATA section, processing instruction or
+// declaration at the start of data, or 0 for an element tag
+func skipMarkup(data []byte) int {
+	for _, delims := range [][2]string{{"<!--", "-->"}, {"<![CDATA[", "]]>"}, {"<?", "?>"}, {"<!", ">"}} {
+		if bytes.HasPrefix(data, []byte(delims[0])) {
+			end := bytes.Index(data[len(delims[0]):], []byte(delims[1]))
+			if end < 0 {
+				return len(data)
+			}
+			return len(delims[0]) + end + len(delims[1])
+		}
+	}
+	return 0
+}
+
+// scanTag reads the tag name starting at data[i] and returns its local part and the
+// offset just past the closing '>'
+func scanTag(data []byte, i int) (string, int) {
+	nameEnd := i
+	for nameEnd < len(data) && !bytes.ContainsRune([]byte(" \t\r\n/>"), rune(data[nameEnd])) {
+		nameEnd++
+	}
+	name := data[i:nameEnd]
+	if colon := bytes.LastIndexByte(name, ':'); colon >= 0 {
+		name = name[colon+1:]
+	}
+
+	end := bytes.IndexByte(data[nameEnd:], '>')
+	if end < 0 {
+		return string(name), len(data)
+	}
+	return string(name), nameEnd + end + 1
+}
+
+// isRecordStart reports whether the content after a <record> start tag begins with a
+// <header> element
+func isRecordStart(data []byte) bool {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if len(data) == 0 || data[0] != '<' {
+		return false
+	}
+	local, _ := scanTag(data, 1)
+	return local == "header"
+}
+
+// dropMalformed applies lenient mode to a ListRecords page
+func (o *HarvestOptions) dropMalformed(data []byte, resumptionToken string, logger *slog.Logger) ([]byte, error) {
+	return dropMalformedRecords(data, resumptionToken, func(malformed *MalformedRecordError) error {
+		logger.Warn("skipped malformed record",
+			"identifier", malformed.Identifier, "index", malformed.Index,
+			"resumptionToken", resumptionToken, "error", malformed.Err)
+		if o.run != nil {
+			o.run.skipped.Add(1)
+		}
+		o.emit(RecordSkipped{Err: malformed})
+		if o.OnMalformedRecord == nil {
+			return nil
+		}
+		return o.OnMalformedRecord(malformed)
+	})
+}
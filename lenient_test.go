@@ -0,0 +1,168 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const lenientTestPage = `<?xml version="1.0" encoding="UTF-8"?>
+<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/">
+  <responseDate>2025-10-02T10:05:19Z</responseDate>
+  <request verb="ListRecords" metadataPrefix="oai_dc">http://example.org/oai</request>
+  <ListRecords>
+    <record>
+      <header><identifier>oai:example.org:1</identifier><datestamp>2025-01-15</datestamp></header>
+      <metadata>
+        <oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+          <dc:title>Sistem Informasi Perpustakaan</dc:title>
+        </oai_dc:dc>
+      </metadata>
+    </record>
+    <record>
+      <header><identifier>oai:example.org:2</identifier><datestamp>2025-01-16</datestamp></header>
+      <metadata>
+        <oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+          <dc:title>Analisis Kebutuhan</dc:titel>
+        </oai_dc:dc>
+      </metadata>
+    </record>
+    <record>
+      <header><identifier>oai:example.org:3</identifier><datestamp>2025-01-17</datestamp></header>
+      <metadata>
+        <oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+          <dc:title>Katalog&nbsp;Induk</dc:title>
+        </oai_dc:dc>
+      </metadata>
+    </record>
+    <record>
+      <header><identifier>oai:example.org:4</identifier><datestamp>2025-01-18</datestamp></header>
+      <metadata>
+        <oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+          <dc:title>Pelestarian Naskah Kuno</dc:title>
+        </oai_dc:dc>
+      </metadata>
+    </record>
+  </ListRecords>
+</OAI-PMH>`
+
+func TestFindRecordSpans(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "listrecords_dc_page1.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans := findRecordSpans(data)
+	if want := strings.Count(string(data), "<record>"); len(spans) != want {
+		t.Fatalf("expected %d records, got %d", want, len(spans))
+	}
+	for _, span := range spans {
+		record := string(data[span.start:span.end])
+		if !strings.HasPrefix(record, "<record>") || !strings.HasSuffix(record, "</record>") {
+			t.Errorf("unexpected span %q", record)
+		}
+	}
+
+	// MARCXML records nest a <record> element inside the OAI-PMH record
+	data, err = os.ReadFile(filepath.Join("testdata", "getrecord_marcxml.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans = findRecordSpans(data)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(spans))
+	}
+	if rest := strings.TrimSpace(string(data[spans[0].end:])); !strings.HasPrefix(rest, "</GetRecord>") {
+		t.Errorf("expected span to end before </GetRecord>, followed by %q", rest[:40])
+	}
+}
+
+func TestDropMalformedRecordsUnclosed(t *testing.T) {
+	page := strings.Replace(lenientTestPage, "<dc:title>Analisis Kebutuhan</dc:titel>\n        </oai_dc:dc>\n      </metadata>\n    </record>", "<dc:title>Analisis Kebutuhan", 1)
+
+	var dropped []*MalformedRecordError
+	cleaned, err := dropMalformedRecords([]byte(page), "", func(m *MalformedRecordError) error {
+		dropped = append(dropped, m)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dropped) != 2 || dropped[0].Identifier != "oai:example.org:2" || dropped[1].Identifier != "oai:example.org:3" {
+		t.Fatalf("unexpected dropped records %v", dropped)
+	}
+	if dropped[0].Index != 2 || !strings.HasSuffix(string(dropped[0].Raw), "Analisis Kebutuhan\n    ") {
+		t.Errorf("unexpected raw record %q", dropped[0].Raw)
+	}
+
+	resp, err := decodeOAIPMHResponseDC(strings.NewReader(string(cleaned)), decodeOptions{})
+	if err != nil {
+		t.Fatalf("cleaned page does not parse: %v", err)
+	}
+	if n := len(resp.ListRecords.Records); n != 2 {
+		t.Errorf("expected 2 records left, got %d", n)
+	}
+}
+
+func TestHarvestLenient(t *testing.T) {
+	server := serveDocument(t, lenientTestPage)
+	client := NewClient(server.URL)
+
+	if err := client.HarvestWithOptions("oai_dc", nil, func(OAIResponse) error { return nil }); err == nil {
+		t.Fatal("expected strict harvest to fail")
+	}
+
+	var dropped []string
+	var stats HarvestStats
+	opts := &HarvestOptions{
+		Lenient: true,
+		Stats:   &stats,
+		OnMalformedRecord: func(m *MalformedRecordError) error {
+			dropped = append(dropped, m.Identifier)
+			return nil
+		},
+	}
+
+	var harvested []string
+	err := client.HarvestRecords("oai_dc", opts, func(record HarvestedRecord) error {
+		harvested = append(harvested, record.Header.Identifier)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("lenient harvest failed: %v", err)
+	}
+
+	if strings.Join(harvested, ",") != "oai:example.org:1,oai:example.org:4" {
+		t.Errorf("unexpected harvested records %v", harvested)
+	}
+	if strings.Join(dropped, ",") != "oai:example.org:2,oai:example.org:3" {
+		t.Errorf("unexpected dropped records %v", dropped)
+	}
+	if stats.SkippedRecords != 2 || stats.Records != 2 {
+		t.Errorf("expected 2 records and 2 skipped, got %+v", stats)
+	}
+}
+
+func TestHarvestLenientCallbackAborts(t *testing.T) {
+	server := serveDocument(t, lenientTestPage)
+
+	errTooBroken := errors.New("too many malformed records")
+	opts := &HarvestOptions{
+		Lenient: true,
+		OnMalformedRecord: func(m *MalformedRecordError) error {
+			var syntaxErr *xml.SyntaxError
+			if !errors.As(m, &syntaxErr) {
+				t.Errorf("expected syntax error, got %v", m.Err)
+			}
+			return errTooBroken
+		},
+	}
+
+	err := NewClient(server.URL).HarvestWithOptions("oai_dc", opts, func(OAIResponse) error { return nil })
+	if !errors.Is(err, errTooBroken) {
+		t.Errorf("expected callback error, got %v", err)
+	}
+}
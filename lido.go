@@ -0,0 +1,497 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LidoRecord represents a LIDO (Lightweight Information Describing Objects) v1.x record
+type LidoRecord struct {
+	XMLName                xml.Name                     `xml:"http://www.lido-schema.org lido"`
+	LidoRecID              []LidoTypedValue             `xml:"lidoRecID"`
+	DescriptiveMetadata    []LidoDescriptiveMetadata    `xml:"descriptiveMetadata"`
+	AdministrativeMetadata []LidoAdministrativeMetadata `xml:"administrativeMetadata"`
+}
+
+// LidoTypedValue represents a LIDO identifier element with its type attribute
+type LidoTypedValue struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// LidoTerm represents a LIDO concept with its terms (objectWorkType, eventType, roleActor, ...)
+type LidoTerm struct {
+	ConceptID []LidoTypedValue `xml:"conceptID"`
+	Term      []string         `xml:"term"`
+}
+
+// LidoAppellation represents an element holding appellationValue children
+type LidoAppellation struct {
+	AppellationValue []string `xml:"appellationValue"`
+}
+
+// LidoDescriptiveMetadata represents the descriptiveMetadata section
+type LidoDescriptiveMetadata struct {
+	Lang                     string                       `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	ObjectWorkType           []LidoTerm                   `xml:"objectClassificationWrap>objectWorkTypeWrap>objectWorkType"`
+	Classification           []LidoTerm                   `xml:"objectClassificationWrap>classificationWrap>classification"`
+	ObjectIdentificationWrap LidoObjectIdentificationWrap `xml:"objectIdentificationWrap"`
+	Events                   []LidoEvent                  `xml:"eventWrap>eventSet>event"`
+	Subjects                 []LidoTerm                   `xml:"objectRelationWrap>subjectWrap>subjectSet>subject>subjectConcept"`
+}
+
+// LidoObjectIdentificationWrap represents the objectIdentificationWrap section
+type LidoObjectIdentificationWrap struct {
+	Titles       []LidoTitleSet      `xml:"titleWrap>titleSet"`
+	Repositories []LidoRepositorySet `xml:"repositoryWrap>repositorySet"`
+	Descriptions []string            `xml:"objectDescriptionWrap>objectDescriptionSet>descriptiveNoteValue"`
+	Measurements []string            `xml:"objectMeasurementsWrap>objectMeasurementsSet>displayObjectMeasurements"`
+}
+
+// LidoTitleSet represents a titleSet with its preferred/alternate appellation values
+type LidoTitleSet struct {
+	Type             string                 `xml:"type,attr,omitempty"`
+	AppellationValue []LidoAppellationValue `xml:"appellationValue"`
+}
+
+// LidoAppellationValue represents an appellationValue with its pref attribute
+type LidoAppellationValue struct {
+	Pref  string `xml:"pref,attr,omitempty"`
+	Lang  string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// LidoRepositorySet represents a repositorySet (holding institution and inventory number)
+type LidoRepositorySet struct {
+	Type           string           `xml:"type,attr,omitempty"`
+	RepositoryName LidoAppellation  `xml:"repositoryName>legalBodyName"`
+	WorkID         []LidoTypedValue `xml:"workID"`
+}
+
+// LidoEvent represents an event (production, acquisition, excavation, ...)
+type LidoEvent struct {
+	EventID          []LidoTypedValue `xml:"eventID"`
+	EventType        LidoTerm         `xml:"eventType"`
+	Actors           []LidoActor      `xml:"eventActor"`
+	DisplayDate      []string         `xml:"eventDate>displayDate"`
+	EarliestDate     string           `xml:"eventDate>date>earliestDate"`
+	LatestDate       string           `xml:"eventDate>date>latestDate"`
+	Places           []LidoPlace      `xml:"eventPlace"`
+	DisplayMaterials []string         `xml:"eventMaterialsTech>displayMaterialsTech"`
+}
+
+// LidoActor represents an eventActor element
+type LidoActor struct {
+	DisplayActor []string          `xml:"displayActorInRole"`
+	Names        []LidoAppellation `xml:"actorInRole>actor>nameActorSet"`
+	Roles        []LidoTerm        `xml:"actorInRole>roleActor"`
+}
+
+// LidoPlace represents an eventPlace element
+type LidoPlace struct {
+	DisplayPlace []string          `xml:"displayPlace"`
+	Names        []LidoAppellation `xml:"place>namePlaceSet"`
+}
+
+// LidoAdministrativeMetadata represents the administrativeMetadata section
+type LidoAdministrativeMetadata struct {
+	Lang         string            `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	RightsWork   []LidoRights      `xml:"rightsWorkWrap>rightsWorkSet"`
+	RecordID     []LidoTypedValue  `xml:"recordWrap>recordID"`
+	RecordType   LidoTerm          `xml:"recordWrap>recordType"`
+	RecordSource []LidoAppellation `xml:"recordWrap>recordSource>legalBodyName"`
+	RecordLinks  []string          `xml:"recordWrap>recordInfoSet>recordInfoLink"`
+	Resources    []LidoResourceSet `xml:"resourceWrap>resourceSet"`
+}
+
+// LidoRights represents a rightsWorkSet or rightsResource element
+type LidoRights struct {
+	RightsType LidoTerm `xml:"rightsType"`
+	CreditLine []string `xml:"creditLine"`
+}
+
+// LidoResourceSet represents a resourceSet (digital image or other surrogate)
+type LidoResourceSet struct {
+	Representations []LidoResourceRepresentation `xml:"resourceRepresentation"`
+	ResourceType    LidoTerm                     `xml:"resourceType"`
+	Rights          []LidoRights                 `xml:"rightsResource"`
+}
+
+// LidoResourceRepresentation represents a resourceRepresentation (image_thumb, image_master, ...)
+type LidoResourceRepresentation struct {
+	Type         string `xml:"type,attr,omitempty"`
+	LinkResource string `xml:"linkResource"`
+}
+
+// Name returns the first appellation value
+func (a LidoAppellation) Name() string {
+	return firstNonEmpty(a.AppellationValue)
+}
+
+// Label returns the first term of a concept
+func (t LidoTerm) Label() string {
+	return firstNonEmpty(t.Term)
+}
+
+// MetadataLIDO represents the metadata wrapper for LIDO
+type MetadataLIDO struct {
+	LIDO *LidoRecord `xml:"http://www.lido-schema.org lido,omitempty"`
+	Raw  []byte      `xml:",innerxml"`
+}
+
+// RecordLIDO represents an OAI-PMH record with LIDO metadata
+type RecordLIDO struct {
+	Header   Header       `xml:"header"`
+	Metadata MetadataLIDO `xml:"metadata"`
+	About    *About       `xml:"about,omitempty"`
+}
+
+// IsDeleted returns true if the record has been deleted from the repository
+func (r RecordLIDO) IsDeleted() bool {
+	return r.Header.IsDeleted()
+}
+
+// ListRecordsLIDO contains the list of LIDO records from ListRecords verb
+type ListRecordsLIDO struct {
+	Records         []RecordLIDO     `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// GetRecordLIDO contains a single LIDO record from GetRecord verb
+type GetRecordLIDO struct {
+	Record RecordLIDO `xml:"record"`
+}
+
+// OAIPMHResponseLIDO represents the OAI-PMH response with LIDO metadata
+type OAIPMHResponseLIDO struct {
+	XMLName      xml.Name         `xml:"OAI-PMH"`
+	ResponseDate string           `xml:"responseDate"`
+	Request      OAIRequest       `xml:"request"`
+	ListRecords  *ListRecordsLIDO `xml:"ListRecords,omitempty"`
+	GetRecord    *GetRecordLIDO   `xml:"GetRecord,omitempty"`
+	Error        *OAIError        `xml:"error,omitempty"`
+}
+
+// LidoMetadata represents extracted LIDO metadata
+type LidoMetadata struct {
+	RecordID          string              `json:"record_id"`
+	Title             string              `json:"title"`
+	AlternativeTitles []string            `json:"alternative_titles"`
+	ObjectWorkTypes   []string            `json:"object_work_types"`
+	Classifications   []string            `json:"classifications"`
+	Repository        string              `json:"repository"`
+	InventoryNumber   string              `json:"inventory_number"`
+	Descriptions      []string            `json:"descriptions"`
+	Measurements      []string            `json:"measurements"`
+	Events            []LidoEventEntry    `json:"events"`
+	Subjects          []string            `json:"subjects"`
+	CreditLine        string              `json:"credit_line"`
+	RecordSource      string              `json:"record_source"`
+	RecordLinks       []string            `json:"record_links"`
+	Resources         []LidoResourceEntry `json:"resources"`
+}
+
+// LidoEventEntry is an extracted LIDO event
+type LidoEventEntry struct {
+	Type      string   `json:"type"`
+	Actors    []string `json:"actors"`
+	Date      string   `json:"date"`
+	Places    []string `json:"places"`
+	Materials []string `json:"materials"`
+}
+
+// LidoResourceEntry is an extracted LIDO digital resource
+type LidoResourceEntry struct {
+	URL        string `json:"url"`
+	Type       string `json:"type"`
+	CreditLine string `json:"credit_line"`
+}
+
+// ExtractLidoMetadata extracts key fields from a LIDO record
+func (l *LidoRecord) ExtractLidoMetadata() *LidoMetadata {
+	if l == nil {
+		return nil
+	}
+
+	metadata := &LidoMetadata{
+		AlternativeTitles: []string{},
+		ObjectWorkTypes:   []string{},
+		Classifications:   []string{},
+		Descriptions:      []string{},
+		Measurements:      []string{},
+		Events:            []LidoEventEntry{},
+		Subjects:          []string{},
+		RecordLinks:       []string{},
+		Resources:         []LidoResourceEntry{},
+	}
+
+	if len(l.LidoRecID) > 0 {
+		metadata.RecordID = strings.TrimSpace(l.LidoRecID[0].Value)
+	}
+
+	for _, desc := range l.DescriptiveMetadata {
+		for _, workType := range desc.ObjectWorkType {
+			metadata.ObjectWorkTypes = append(metadata.ObjectWorkTypes, workType.Term...)
+		}
+		for _, classification := range desc.Classification {
+			metadata.Classifications = append(metadata.Classifications, classification.Term...)
+		}
+
+		identification := desc.ObjectIdentificationWrap
+		for _, titleSet := range identification.Titles {
+			for _, title := range titleSet.AppellationValue {
+				value := strings.TrimSpace(title.Value)
+				if value == "" {
+					continue
+				}
+				if metadata.Title == "" && title.Pref != "alternate" {
+					metadata.Title = value
+					continue
+				}
+				metadata.AlternativeTitles = append(metadata.AlternativeTitles, value)
+			}
+		}
+		for _, repository := range identification.Repositories {
+			if metadata.Repository == "" {
+				metadata.Repository = repository.RepositoryName.Name()
+			}
+			if metadata.InventoryNumber == "" && len(repository.WorkID) > 0 {
+				metadata.InventoryNumber = strings.TrimSpace(repository.WorkID[0].Value)
+			}
+		}
+		metadata.Descriptions = append(metadata.Descriptions, identification.Descriptions...)
+		metadata.Measurements = append(metadata.Measurements, identification.Measurements...)
+
+		for _, event := range desc.Events {
+			metadata.Events = append(metadata.Events, event.entry())
+		}
+		for _, subject := range desc.Subjects {
+			metadata.Subjects = append(metadata.Subjects, subject.Term...)
+		}
+	}
+
+	for _, admin := range l.AdministrativeMetadata {
+		for _, rights := range admin.RightsWork {
+			if metadata.CreditLine == "" {
+				metadata.CreditLine = firstNonEmpty(rights.CreditLine)
+			}
+		}
+		if metadata.RecordID == "" && len(admin.RecordID) > 0 {
+			metadata.RecordID = strings.TrimSpace(admin.RecordID[0].Value)
+		}
+		for _, source := range admin.RecordSource {
+			if metadata.RecordSource == "" {
+				metadata.RecordSource = source.Name()
+			}
+		}
+		metadata.RecordLinks = append(metadata.RecordLinks, admin.RecordLinks...)
+
+		for _, resource := range admin.Resources {
+			var creditLine string
+			for _, rights := range resource.Rights {
+				if creditLine == "" {
+					creditLine = firstNonEmpty(rights.CreditLine)
+				}
+			}
+			for _, representation := range resource.Representations {
+				if link := strings.TrimSpace(representation.LinkResource); link != "" {
+					metadata.Resources = append(metadata.Resources, LidoResourceEntry{
+						URL:        link,
+						Type:       representation.Type,
+						CreditLine: creditLine,
+					})
+				}
+			}
+		}
+	}
+
+	metadata.ObjectWorkTypes = deduplicate(metadata.ObjectWorkTypes)
+	metadata.Classifications = deduplicate(metadata.Classifications)
+	metadata.AlternativeTitles = deduplicate(metadata.AlternativeTitles)
+	metadata.Subjects = deduplicate(metadata.Subjects)
+	metadata.RecordLinks = deduplicate(metadata.RecordLinks)
+
+	return metadata
+}
+
+// entry converts a LIDO event to its extracted form
+func (e LidoEvent) entry() LidoEventEntry {
+	entry := LidoEventEntry{
+		Type:      e.EventType.Label(),
+		Actors:    []string{},
+		Places:    []string{},
+		Materials: deduplicate(e.DisplayMaterials),
+	}
+
+	for _, actor := range e.Actors {
+		name := firstNonEmpty(actor.DisplayActor)
+		if name == "" {
+			for _, set := range actor.Names {
+				if name = set.Name(); name != "" {
+					break
+				}
+			}
+		}
+		if name != "" {
+			entry.Actors = append(entry.Actors, name)
+		}
+	}
+
+	entry.Date = firstNonEmpty(e.DisplayDate)
+	if entry.Date == "" && e.EarliestDate != "" {
+		entry.Date = e.EarliestDate
+		if e.LatestDate != "" && e.LatestDate != e.EarliestDate {
+			entry.Date += "/" + e.LatestDate
+		}
+	}
+
+	for _, place := range e.Places {
+		name := firstNonEmpty(place.DisplayPlace)
+		if name == "" {
+			for _, set := range place.Names {
+				if name = set.Name(); name != "" {
+					break
+				}
+			}
+		}
+		if name != "" {
+			entry.Places = append(entry.Places, name)
+		}
+	}
+
+	return entry
+}
+
+// ExtractAllLidoMetadata extracts metadata from all LIDO records in OAI-PMH response
+func (o *OAIPMHResponseLIDO) ExtractAllLidoMetadata() []*LidoMetadata {
+	var results []*LidoMetadata
+
+	for _, extractor := range o.GetRecords() {
+		if metadata := extractor.(*LidoRecord).ExtractLidoMetadata(); metadata != nil {
+			results = append(results, metadata)
+		}
+	}
+
+	return results
+}
+
+// ParseOAILIDOXML parses OAI-PMH XML data with LIDO metadata from bytes
+func ParseOAILIDOXML(data []byte) (*OAIPMHResponseLIDO, error) {
+	var oaiResp OAIPMHResponseLIDO
+	if err := xml.Unmarshal(data, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, oaiResp.Error
+	}
+
+	return &oaiResp, nil
+}
+
+// decodeOAIPMHResponseLIDO stream-decodes a LIDO ListRecords or GetRecord response
+func decodeOAIPMHResponseLIDO(r io.Reader, page decodeOptions) (*OAIPMHResponseLIDO, error) {
+	env, records, err := decodeResponse(r, page, decodeRecord[RecordLIDO])
+	if err != nil {
+		return nil, err
+	}
+
+	oaiResp := &OAIPMHResponseLIDO{
+		XMLName:      xml.Name{Local: "OAI-PMH"},
+		ResponseDate: env.ResponseDate,
+		Request:      env.Request,
+	}
+
+	switch env.Verb {
+	case "ListRecords":
+		oaiResp.ListRecords = &ListRecordsLIDO{Records: records, ResumptionToken: env.ResumptionToken}
+	case "GetRecord":
+		if len(records) > 0 {
+			oaiResp.GetRecord = &GetRecordLIDO{Record: records[0]}
+		}
+	}
+
+	return oaiResp, nil
+}
+
+// Implement OAIResponse interface for OAIPMHResponseLIDO
+
+// GetRecords returns all records in the response as MetadataExtractor interface
+func (o *OAIPMHResponseLIDO) GetRecords() []MetadataExtractor {
+	var extractors []MetadataExtractor
+
+	for _, record := range o.harvestedRecords() {
+		if record.Metadata != nil {
+			extractors = append(extractors, record.Metadata)
+		}
+	}
+
+	return extractors
+}
+
+// GetResumptionToken returns the resumption token if available
+func (o *OAIPMHResponseLIDO) GetResumptionToken() string {
+	if o.ListRecords != nil && o.ListRecords.ResumptionToken != nil {
+		return o.ListRecords.ResumptionToken.Token
+	}
+	return ""
+}
+
+// GetResumptionTokenInfo returns the full resumption token if available
+func (o *OAIPMHResponseLIDO) GetResumptionTokenInfo() *ResumptionToken {
+	if o.ListRecords != nil {
+		return o.ListRecords.ResumptionToken
+	}
+	return nil
+}
+
+// HasError returns true if the response contains an error
+func (o *OAIPMHResponseLIDO) HasError() bool {
+	return o.Error != nil
+}
+
+// GetError returns the error information
+func (o *OAIPMHResponseLIDO) GetError() *OAIError {
+	return o.Error
+}
+
+// harvestedRecords returns all records in the response with their headers
+func (o *OAIPMHResponseLIDO) harvestedRecords() []HarvestedRecord {
+	var records []RecordLIDO
+	if o.ListRecords != nil {
+		records = o.ListRecords.Records
+	}
+	if o.GetRecord != nil {
+		records = append(records, o.GetRecord.Record)
+	}
+
+	results := make([]HarvestedRecord, 0, len(records))
+	for _, record := range records {
+		harvested := HarvestedRecord{Header: record.Header, Raw: record.Metadata.Raw, About: record.About}
+		if record.Metadata.LIDO != nil {
+			harvested.Metadata = record.Metadata.LIDO
+		}
+		results = append(results, harvested)
+	}
+
+	return results
+}
+
+// responseDate returns the responseDate of the response
+func (o *OAIPMHResponseLIDO) responseDate() string {
+	return o.ResponseDate
+}
+
+// Implement MetadataExtractor interface for LidoRecord
+
+// ExtractMetadata extracts metadata from LIDO record
+func (m *LidoRecord) ExtractMetadata() interface{} {
+	return m.ExtractLidoMetadata()
+}
+
+// GetFormat returns the metadata format type
+func (m *LidoRecord) GetFormat() MetadataFormat {
+	return FormatLIDO
+}
@@ -0,0 +1,83 @@
+package goharvest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseOAILIDOXML(t *testing.T) {
+	data, err := os.ReadFile("testdata/listrecords_lido.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAILIDOXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAILIDOXML failed: %v", err)
+	}
+
+	records := resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if records[0].GetFormat() != FormatLIDO {
+		t.Errorf("Expected format lido, got %s", records[0].GetFormat())
+	}
+
+	metadata, ok := records[0].ExtractMetadata().(*LidoMetadata)
+	if !ok {
+		t.Fatalf("Expected *LidoMetadata, got %T", records[0].ExtractMetadata())
+	}
+
+	if metadata.RecordID != "MUS-70" {
+		t.Errorf("Unexpected record ID '%s'", metadata.RecordID)
+	}
+	if metadata.Title != "Keris with naga blade" || len(metadata.AlternativeTitles) != 1 {
+		t.Errorf("Unexpected titles '%s' / %v", metadata.Title, metadata.AlternativeTitles)
+	}
+	if metadata.Repository != "Museum Sonobudoyo" || metadata.InventoryNumber != "SB-1234" {
+		t.Errorf("Unexpected repository %s / %s", metadata.Repository, metadata.InventoryNumber)
+	}
+	if len(metadata.ObjectWorkTypes) != 1 || len(metadata.Classifications) != 1 || len(metadata.Subjects) != 1 {
+		t.Errorf("Unexpected classification %v / %v / %v", metadata.ObjectWorkTypes, metadata.Classifications, metadata.Subjects)
+	}
+
+	if len(metadata.Events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(metadata.Events))
+	}
+	event := metadata.Events[0]
+	if event.Type != "Production" || len(event.Actors) != 1 || event.Actors[0] != "Empu Supa" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if event.Date != "1700/1750" || len(event.Places) != 1 || len(event.Materials) != 1 {
+		t.Errorf("Unexpected event date/place/materials %+v", event)
+	}
+
+	if metadata.CreditLine != "Gift of the Sultanate, 1935" || len(metadata.RecordLinks) != 1 {
+		t.Errorf("Unexpected administrative metadata %s / %v", metadata.CreditLine, metadata.RecordLinks)
+	}
+	if len(metadata.Resources) != 2 || metadata.Resources[1].Type != "image_master" || metadata.Resources[1].CreditLine == "" {
+		t.Errorf("Unexpected resources %+v", metadata.Resources)
+	}
+}
+
+func TestUnifiedHarvestLIDO(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_lido.xml"})
+	client := NewClient(server.URL)
+
+	count := 0
+	err := client.Harvest("lido", nil, func(response OAIResponse) error {
+		for _, record := range response.GetRecords() {
+			if _, ok := record.ExtractMetadata().(*LidoMetadata); ok {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 LIDO record, got %d", count)
+	}
+}
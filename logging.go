@@ -0,0 +1,105 @@
+package goharvest
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// discardLogger is used when the client has no logger
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// WithLogger logs requests at debug level, harvests at info level, and failed requests,
+// skipped records and invalid records as warnings
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *OAIClient) {
+		c.Logger = logger
+	}
+}
+
+// log returns the client's logger, or one that discards everything
+func (c *OAIClient) log() *slog.Logger {
+	if c.Logger == nil {
+		return discardLogger
+	}
+	return c.Logger
+}
+
+// logResponse arranges for a debug event to be logged once the response body is closed,
+// when its size and the full request duration are known
+func (c *OAIClient) logResponse(req *http.Request, resp *http.Response, args url.Values, start time.Time) {
+	logger := c.log()
+	if !logger.Enabled(req.Context(), slog.LevelDebug) {
+		return
+	}
+
+	resp.Body = &loggedBody{ReadCloser: resp.Body, done: func(n int64) {
+		logger.LogAttrs(req.Context(), slog.LevelDebug, "OAI-PMH request",
+			slog.String("verb", args.Get("verb")),
+			slog.String("method", req.Method),
+			slog.String("url", req.URL.String()),
+			slog.String("resumptionToken", args.Get("resumptionToken")),
+			slog.Int("status", resp.StatusCode),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int64("bytes", n),
+		)
+	}}
+}
+
+// loggedBody counts the bytes read from a body and reports them when it is closed
+type loggedBody struct {
+	io.ReadCloser
+	n    int64
+	done func(n int64)
+}
+
+// Read implements io.Reader
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *loggedBody) Close() error {
+	if b.done != nil {
+		b.done(b.n)
+		b.done = nil
+	}
+	return b.ReadCloser.Close()
+}
+
+// logHarvest logs the outcome of a harvest
+func (c *OAIClient) logHarvest(ctx context.Context, metadataPrefix string, stats HarvestStats, err error) {
+	attrs := []slog.Attr{
+		slog.String("baseURL", c.BaseURL),
+		slog.String("metadataPrefix", metadataPrefix),
+		slog.Int("pages", stats.Pages),
+		slog.Int("records", stats.Records),
+		slog.Int("deleted", stats.DeletedRecords),
+		slog.Int("skipped", stats.SkippedRecords),
+		slog.Int64("bytes", stats.Bytes),
+		slog.Duration("duration", stats.Duration),
+	}
+	if stats.Retries > 0 {
+		attrs = append(attrs, slog.Int("retries", stats.Retries))
+	}
+	if stats.TokenRecoveries > 0 {
+		attrs = append(attrs, slog.Int("tokenRecoveries", stats.TokenRecoveries))
+	}
+	if stats.TruncatedPages > 0 {
+		attrs = append(attrs, slog.Int("truncatedPages", stats.TruncatedPages), slog.Int("droppedRecords", stats.DroppedRecords))
+	}
+	if stats.Stopped {
+		attrs = append(attrs, slog.String("resumptionToken", stats.ResumptionToken))
+	}
+
+	if err != nil {
+		c.log().LogAttrs(ctx, slog.LevelError, "harvest failed", append(attrs, slog.Any("error", err))...)
+		return
+	}
+	c.log().LogAttrs(ctx, slog.LevelInfo, "harvest finished", attrs...)
+}
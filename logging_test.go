@@ -0,0 +1,98 @@
+package goharvest
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordedLogs returns a logger that records JSON events, and a function decoding them
+func recordedLogs(t *testing.T) (*slog.Logger, func() []map[string]interface{}) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	return logger, func() []map[string]interface{} {
+		var events []map[string]interface{}
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var event map[string]interface{}
+			if err := dec.Decode(&event); err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, event)
+		}
+		return events
+	}
+}
+
+func TestLoggerHarvestEvents(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	logger, events := recordedLogs(t)
+
+	err := NewClient(server.URL, WithLogger(logger)).HarvestRecords("oai_dc", nil, func(HarvestedRecord) error { return nil })
+	if err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+
+	logged := events()
+	if len(logged) != 4 {
+		t.Fatalf("Expected 4 events, got %v", logged)
+	}
+	if logged[0]["msg"] != "harvest started" || logged[0]["level"] != "INFO" {
+		t.Errorf("Unexpected first event %v", logged[0])
+	}
+
+	page2, err := os.Stat(filepath.Join("testdata", "listrecords_dc_page2.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := logged[2]
+	if request["msg"] != "OAI-PMH request" || request["level"] != "DEBUG" || request["verb"] != "ListRecords" ||
+		request["resumptionToken"] != "dc-page-2" || request["status"] != 200.0 || request["bytes"] != float64(page2.Size()) {
+		t.Errorf("Unexpected request event %v", request)
+	}
+	if _, ok := request["duration"]; !ok {
+		t.Errorf("Expected a duration in %v", request)
+	}
+
+	finished := logged[3]
+	if finished["msg"] != "harvest finished" || finished["pages"] != 2.0 || finished["records"] != 4.0 {
+		t.Errorf("Unexpected final event %v", finished)
+	}
+}
+
+func TestLoggerWarnings(t *testing.T) {
+	logger, events := recordedLogs(t)
+
+	server := serveDocument(t, lenientTestPage)
+	client := NewClient(server.URL, WithLogger(logger))
+	if err := client.HarvestRecords("oai_dc", &HarvestOptions{Lenient: true}, func(HarvestedRecord) error { return nil }); err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	server.Close()
+	if err := client.HarvestRecords("oai_dc", nil, func(HarvestedRecord) error { return nil }); err == nil {
+		t.Fatal("Expected harvest from a closed server to fail")
+	}
+
+	var skipped, failed, harvestFailed int
+	for _, event := range events() {
+		switch {
+		case event["msg"] == "skipped malformed record" && event["level"] == "WARN":
+			skipped++
+		case event["msg"] == "OAI-PMH request failed" && event["level"] == "WARN":
+			failed++
+		case event["msg"] == "harvest failed" && event["level"] == "ERROR":
+			harvestFailed++
+		}
+	}
+	if skipped != 2 || failed != 1 || harvestFailed != 1 {
+		t.Errorf("Expected 2 skipped, 1 failed request and 1 failed harvest, got %d, %d and %d", skipped, failed, harvestFailed)
+	}
+}
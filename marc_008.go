@@ -0,0 +1,125 @@
+package goharvest
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNo008 is returned by Parse008 when the record has no 008 control field
+var ErrNo008 = errors.New("record has no 008 field")
+
+// Field008 holds the decoded positions of the MARC 21 bibliographic 008 fixed field.
+// Positions 18-34 are decoded using the Books definition; MaterialSpecific keeps them raw
+// for other material types.
+type Field008 struct {
+	DateEntered      string // 00-05 (yymmdd)
+	TypeOfDate       string // 06: s = single date, m = multiple dates, r = reprint, ...
+	Date1            string // 07-10
+	Date2            string // 11-14
+	Place            string // 15-17: MARC country code
+	MaterialSpecific string // 18-34
+	Language         string // 35-37: MARC language code
+	ModifiedRecord   string // 38
+	CatalogingSource string // 39
+
+	// Books (leader/06 a or t)
+	Illustrations         string // 18-21
+	TargetAudience        string // 22
+	FormOfItem            string // 23
+	NatureOfContents      string // 24-27
+	GovernmentPublication string // 28
+	ConferencePublication string // 29
+	Festschrift           string // 30
+	Index                 string // 31
+	LiteraryForm          string // 33
+	Biography             string // 34
+}
+
+// Parse008 decodes control field 008. Short fields are padded with blanks.
+func (m *MARCRecord) Parse008() (*Field008, error) {
+	value := m.GetControlFieldValue("008")
+	if value == "" {
+		return nil, ErrNo008
+	}
+	return ParseField008(value), nil
+}
+
+// ParseField008 decodes a 40-character 008 value, padding short values with blanks
+func ParseField008(value string) *Field008 {
+	if len(value) < 40 {
+		value += strings.Repeat(" ", 40-len(value))
+	}
+
+	return &Field008{
+		DateEntered:      strings.TrimSpace(value[0:6]),
+		TypeOfDate:       leaderCode(value[6]),
+		Date1:            strings.TrimSpace(value[7:11]),
+		Date2:            strings.TrimSpace(value[11:15]),
+		Place:            strings.TrimSpace(value[15:18]),
+		MaterialSpecific: value[18:35],
+		Language:         strings.TrimSpace(value[35:38]),
+		ModifiedRecord:   leaderCode(value[38]),
+		CatalogingSource: leaderCode(value[39]),
+
+		Illustrations:         strings.TrimSpace(value[18:22]),
+		TargetAudience:        leaderCode(value[22]),
+		FormOfItem:            leaderCode(value[23]),
+		NatureOfContents:      strings.TrimSpace(value[24:28]),
+		GovernmentPublication: leaderCode(value[28]),
+		ConferencePublication: leaderCode(value[29]),
+		Festschrift:           leaderCode(value[30]),
+		Index:                 leaderCode(value[31]),
+		LiteraryForm:          leaderCode(value[33]),
+		Biography:             leaderCode(value[34]),
+	}
+}
+
+// DateEnteredTime parses the date the record was entered on file
+func (f *Field008) DateEnteredTime() (time.Time, error) {
+	return time.Parse("060102", f.DateEntered)
+}
+
+// PublicationYear returns Date1 as a year, or 0 when it is blank or partly unknown (e.g. "19uu")
+func (f *Field008) PublicationYear() int {
+	year, err := strconv.Atoi(f.Date1)
+	if err != nil || len(f.Date1) != 4 {
+		return 0
+	}
+	return year
+}
+
+// EndYear returns Date2 as a year for ranges, or 0 when it is blank, unknown or open ("9999")
+func (f *Field008) EndYear() int {
+	year, err := strconv.Atoi(f.Date2)
+	if err != nil || len(f.Date2) != 4 || year == 9999 {
+		return 0
+	}
+	return year
+}
+
+// IsGovernmentPublication returns true if position 28 marks a government publication
+func (f *Field008) IsGovernmentPublication() bool {
+	return f.GovernmentPublication != "" && f.GovernmentPublication != "u"
+}
+
+// IsConferencePublication returns true if position 29 marks a conference publication
+func (f *Field008) IsConferencePublication() bool {
+	return f.ConferencePublication == "1"
+}
+
+// IsFiction returns true if the literary form is fiction, novels or short stories
+func (f *Field008) IsFiction() bool {
+	switch f.LiteraryForm {
+	case "1", "f", "j":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsOnline returns true if the form of item is online or direct electronic
+func (f *Field008) IsOnline() bool {
+	return f.FormOfItem == "o" || f.FormOfItem == "q" || f.FormOfItem == "s"
+}
@@ -0,0 +1,53 @@
+package goharvest
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse008(t *testing.T) {
+	record := &MARCRecord{ControlFields: []ControlField{
+		{Tag: "008", Value: "210315s2020    io a     b   f001 0 ind d"},
+	}}
+
+	field, err := record.Parse008()
+	if err != nil {
+		t.Fatalf("Parse008 failed: %v", err)
+	}
+
+	if field.DateEntered != "210315" || field.TypeOfDate != "s" || field.Date1 != "2020" || field.Date2 != "" {
+		t.Errorf("Unexpected dates %+v", field)
+	}
+	if entered, err := field.DateEnteredTime(); err != nil || entered.Year() != 2021 || entered.Month() != 3 {
+		t.Errorf("Unexpected date entered %v (%v)", entered, err)
+	}
+	if field.Place != "io" || field.Language != "ind" || field.CatalogingSource != "d" {
+		t.Errorf("Unexpected place/language/source %s / %s / %s", field.Place, field.Language, field.CatalogingSource)
+	}
+	if field.Illustrations != "a" || field.NatureOfContents != "b" {
+		t.Errorf("Unexpected illustrations/contents %q / %q", field.Illustrations, field.NatureOfContents)
+	}
+	if !field.IsGovernmentPublication() || field.IsConferencePublication() || field.Index != "1" {
+		t.Errorf("Unexpected government/conference/index flags %+v", field)
+	}
+	if field.LiteraryForm != "0" || field.IsFiction() {
+		t.Errorf("Expected non-fiction, got literary form %q", field.LiteraryForm)
+	}
+	if field.PublicationYear() != 2020 {
+		t.Errorf("Expected publication year 2020, got %d", field.PublicationYear())
+	}
+}
+
+func TestParse008Partial(t *testing.T) {
+	field := ParseField008("980101m19uu9999xx ")
+	if field.PublicationYear() != 0 || field.EndYear() != 0 {
+		t.Errorf("Expected unknown years, got %d / %d", field.PublicationYear(), field.EndYear())
+	}
+	if field.Place != "xx" || field.Language != "" {
+		t.Errorf("Unexpected padded fields %+v", field)
+	}
+
+	if _, err := (&MARCRecord{}).Parse008(); !errors.Is(err, ErrNo008) {
+		t.Errorf("Expected ErrNo008, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package goharvest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// breakerEscaper escapes subfield delimiters in MARC Breaker data
+var breakerEscaper = strings.NewReplacer("$", "{dollar}")
+
+// breakerUnescaper reverses breakerEscaper and the common MarcEdit character mnemonics
+var breakerUnescaper = strings.NewReplacer("{dollar}", "$", "{lcub}", "{", "{rcub}", "}", "{bsol}", `\`)
+
+// MarshalMARCBreaker serializes the record in MarcEdit's MARC Breaker (.mrk) text format:
+//
+//	=LDR  00000cam\\2200000\a\4500
+//	=001  REC-1
+//	=245  10$aBumi manusia :$broman
+//
+// Blanks in the leader, control fields and indicators are written as backslashes.
+func (m *MARCRecord) MarshalMARCBreaker() []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "=LDR  %s\n", breakerBlanks(m.Leader))
+	for _, field := range m.ControlFields {
+		fmt.Fprintf(&b, "=%s  %s\n", field.Tag, breakerBlanks(breakerEscaper.Replace(field.Value)))
+	}
+	for _, field := range m.DataFields {
+		fmt.Fprintf(&b, "=%s  %s%s", field.Tag, breakerIndicator(field.Ind1), breakerIndicator(field.Ind2))
+		for _, subfield := range field.Subfields {
+			fmt.Fprintf(&b, "$%s%s", subfield.Code, breakerEscaper.Replace(subfield.Value))
+		}
+		b.WriteByte('\n')
+	}
+
+	return b.Bytes()
+}
+
+// UnmarshalMARCBreaker replaces the record with the single MARC Breaker record in data
+func (m *MARCRecord) UnmarshalMARCBreaker(data []byte) error {
+	records, err := ReadMARCBreaker(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if len(records) != 1 {
+		return fmt.Errorf("failed to parse MARC Breaker: expected 1 record, got %d", len(records))
+	}
+
+	*m = *records[0]
+	return nil
+}
+
+// WriteMARCBreaker writes the records in MARC Breaker format, separated by blank lines
+func WriteMARCBreaker(w io.Writer, records []*MARCRecord) error {
+	for i, record := range records {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(record.MarshalMARCBreaker()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadMARCBreaker reads MARC Breaker records separated by blank lines, as exported by MarcEdit
+func ReadMARCBreaker(r io.Reader) ([]*MARCRecord, error) {
+	var records []*MARCRecord
+	var current *MARCRecord
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			current = nil
+			continue
+		}
+
+		if len(line) < 4 || line[0] != '=' {
+			return nil, fmt.Errorf("failed to parse MARC Breaker line %d: expected \"=TAG  data\"", lineNo)
+		}
+		tag := line[1:4]
+		data := strings.TrimPrefix(line[4:], "  ")
+
+		if current == nil {
+			current = &MARCRecord{}
+			records = append(records, current)
+		}
+
+		switch {
+		case tag == "LDR":
+			current.Leader = strings.ReplaceAll(data, `\`, " ")
+		case tag < "010":
+			value := strings.ReplaceAll(data, `\`, " ")
+			current.ControlFields = append(current.ControlFields, ControlField{Tag: tag, Value: breakerUnescaper.Replace(value)})
+		default:
+			field, err := parseBreakerDataField(tag, data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse MARC Breaker line %d: %w", lineNo, err)
+			}
+			current.DataFields = append(current.DataFields, field)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read MARC Breaker: %w", err)
+	}
+
+	return records, nil
+}
+
+// parseBreakerDataField parses the "10$aTitle$bSubtitle" part of a data field line
+func parseBreakerDataField(tag, data string) (DataField, error) {
+	if len(data) < 2 {
+		return DataField{}, fmt.Errorf("field %s has no indicators", tag)
+	}
+
+	field := DataField{
+		Tag:  tag,
+		Ind1: strings.ReplaceAll(data[:1], `\`, " "),
+		Ind2: strings.ReplaceAll(data[1:2], `\`, " "),
+	}
+
+	rest := data[2:]
+	if rest != "" && rest[0] != '$' {
+		return DataField{}, fmt.Errorf("field %s: expected subfield delimiter after indicators", tag)
+	}
+	for _, part := range strings.Split(rest, "$") {
+		if part == "" {
+			continue
+		}
+		field.Subfields = append(field.Subfields, Subfield{
+			Code:  part[:1],
+			Value: breakerUnescaper.Replace(part[1:]),
+		})
+	}
+
+	return field, nil
+}
+
+// breakerIndicator writes blank (or "#") indicators as a backslash
+func breakerIndicator(ind string) string {
+	if ind == "" || ind == " " || ind == "#" {
+		return `\`
+	}
+	return ind
+}
+
+// breakerBlanks writes blanks as backslashes
+func breakerBlanks(s string) string {
+	return strings.ReplaceAll(s, " ", `\`)
+}
@@ -0,0 +1,64 @@
+package goharvest
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMARCBreakerRoundTrip(t *testing.T) {
+	record := &MARCRecord{
+		Leader:        "00000cam a2200000 a 4500",
+		ControlFields: []ControlField{{Tag: "001", Value: "REC-1"}, {Tag: "008", Value: "210315s2020    io"}},
+		DataFields: []DataField{
+			{Tag: "245", Ind1: "1", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Bumi manusia :"}, {Code: "b", Value: "roman"}}},
+			{Tag: "365", Ind1: " ", Ind2: " ", Subfields: []Subfield{{Code: "b", Value: "$25.00"}}},
+		},
+	}
+
+	data := record.MarshalMARCBreaker()
+	want := "=LDR  00000cam\\a2200000\\a\\4500\n" +
+		"=001  REC-1\n" +
+		"=008  210315s2020\\\\\\\\io\n" +
+		"=245  10$aBumi manusia :$broman\n" +
+		"=365  \\\\$b{dollar}25.00\n"
+	if string(data) != want {
+		t.Errorf("MarshalMARCBreaker() =\n%s\nwant\n%s", data, want)
+	}
+
+	var parsed MARCRecord
+	if err := parsed.UnmarshalMARCBreaker(data); err != nil {
+		t.Fatalf("UnmarshalMARCBreaker failed: %v", err)
+	}
+	if !reflect.DeepEqual(&parsed, record) {
+		t.Errorf("Round trip mismatch:\n got %+v\nwant %+v", parsed, *record)
+	}
+}
+
+func TestReadWriteMARCBreakerMultiple(t *testing.T) {
+	records := []*MARCRecord{
+		{Leader: "00000nam a2200000 a 4500", ControlFields: []ControlField{{Tag: "001", Value: "A"}}},
+		{Leader: "00000nam a2200000 a 4500", ControlFields: []ControlField{{Tag: "001", Value: "B"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMARCBreaker(&buf, records); err != nil {
+		t.Fatalf("WriteMARCBreaker failed: %v", err)
+	}
+
+	parsed, err := ReadMARCBreaker(strings.NewReader(strings.ReplaceAll(buf.String(), "\n", "\r\n")))
+	if err != nil {
+		t.Fatalf("ReadMARCBreaker failed: %v", err)
+	}
+	if len(parsed) != 2 || parsed[1].GetControlFieldValue("001") != "B" {
+		t.Errorf("Unexpected records %+v", parsed)
+	}
+
+	if _, err := ReadMARCBreaker(strings.NewReader("245 10$aNo equals sign")); err == nil {
+		t.Error("Expected error for malformed line")
+	}
+	if _, err := ReadMARCBreaker(strings.NewReader("=245  10aMissing delimiter")); err == nil {
+		t.Error("Expected error for missing subfield delimiter")
+	}
+}
@@ -0,0 +1,38 @@
+package goharvest
+
+import "strings"
+
+// Language describes a MARC language code
+type Language struct {
+	Code    string // MARC (ISO 639-2/B) code, e.g. "ind"
+	ISO6391 string // ISO 639-1 code, empty when none exists
+	Name    string // English name
+}
+
+// LookupLanguage returns the language for a MARC language code
+func LookupLanguage(code string) (Language, bool) {
+	language, ok := marcLanguages[strings.ToLower(strings.TrimSpace(code))]
+	return language, ok
+}
+
+// LookupCountry returns the name for a MARC country code such as "io" or "nyu"
+func LookupCountry(code string) (string, bool) {
+	name, ok := marcCountries[strings.ToLower(strings.TrimSpace(code))]
+	return name, ok
+}
+
+// languageCode returns the record's language code from 008/35-37, falling back to 041$a
+func (m *MARCRecord) languageCode() string {
+	if field, err := m.Parse008(); err == nil && field.Language != "" {
+		return field.Language
+	}
+	return m.GetFieldValue("041", "a")
+}
+
+// languageName returns the English name for a MARC language code, or the code itself if unknown
+func languageName(code string) string {
+	if language, ok := LookupLanguage(code); ok {
+		return language.Name
+	}
+	return code
+}
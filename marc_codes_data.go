@@ -0,0 +1,331 @@
+package goharvest
+
+// marcLanguages maps MARC language codes (008/35-37, 041) to ISO 639-1 codes and English names.
+// It covers the languages most often seen in OAI-PMH catalog data, not the full MARC code list.
+var marcLanguages = map[string]Language{
+	"eng": {Code: "eng", ISO6391: "en", Name: "English"},
+	"ind": {Code: "ind", ISO6391: "id", Name: "Indonesian"},
+	"jav": {Code: "jav", ISO6391: "jv", Name: "Javanese"},
+	"sun": {Code: "sun", ISO6391: "su", Name: "Sundanese"},
+	"may": {Code: "may", ISO6391: "ms", Name: "Malay"},
+	"ace": {Code: "ace", ISO6391: "", Name: "Achinese"},
+	"ban": {Code: "ban", ISO6391: "", Name: "Balinese"},
+	"bug": {Code: "bug", ISO6391: "", Name: "Bugis"},
+	"mad": {Code: "mad", ISO6391: "", Name: "Madurese"},
+	"mak": {Code: "mak", ISO6391: "", Name: "Makasar"},
+	"min": {Code: "min", ISO6391: "", Name: "Minangkabau"},
+	"tet": {Code: "tet", ISO6391: "", Name: "Tetum"},
+	"ara": {Code: "ara", ISO6391: "ar", Name: "Arabic"},
+	"chi": {Code: "chi", ISO6391: "zh", Name: "Chinese"},
+	"jpn": {Code: "jpn", ISO6391: "ja", Name: "Japanese"},
+	"kor": {Code: "kor", ISO6391: "ko", Name: "Korean"},
+	"dut": {Code: "dut", ISO6391: "nl", Name: "Dutch"},
+	"ger": {Code: "ger", ISO6391: "de", Name: "German"},
+	"fre": {Code: "fre", ISO6391: "fr", Name: "French"},
+	"spa": {Code: "spa", ISO6391: "es", Name: "Spanish"},
+	"por": {Code: "por", ISO6391: "pt", Name: "Portuguese"},
+	"ita": {Code: "ita", ISO6391: "it", Name: "Italian"},
+	"rus": {Code: "rus", ISO6391: "ru", Name: "Russian"},
+	"hin": {Code: "hin", ISO6391: "hi", Name: "Hindi"},
+	"tha": {Code: "tha", ISO6391: "th", Name: "Thai"},
+	"vie": {Code: "vie", ISO6391: "vi", Name: "Vietnamese"},
+	"tgl": {Code: "tgl", ISO6391: "tl", Name: "Tagalog"},
+	"fil": {Code: "fil", ISO6391: "", Name: "Filipino"},
+	"ceb": {Code: "ceb", ISO6391: "", Name: "Cebuano"},
+	"lat": {Code: "lat", ISO6391: "la", Name: "Latin"},
+	"gre": {Code: "gre", ISO6391: "el", Name: "Greek, Modern (1453- )"},
+	"grc": {Code: "grc", ISO6391: "", Name: "Greek, Ancient (to 1453)"},
+	"heb": {Code: "heb", ISO6391: "he", Name: "Hebrew"},
+	"tur": {Code: "tur", ISO6391: "tr", Name: "Turkish"},
+	"per": {Code: "per", ISO6391: "fa", Name: "Persian"},
+	"urd": {Code: "urd", ISO6391: "ur", Name: "Urdu"},
+	"ben": {Code: "ben", ISO6391: "bn", Name: "Bengali"},
+	"tam": {Code: "tam", ISO6391: "ta", Name: "Tamil"},
+	"tel": {Code: "tel", ISO6391: "te", Name: "Telugu"},
+	"mar": {Code: "mar", ISO6391: "mr", Name: "Marathi"},
+	"guj": {Code: "guj", ISO6391: "gu", Name: "Gujarati"},
+	"pan": {Code: "pan", ISO6391: "pa", Name: "Panjabi"},
+	"kan": {Code: "kan", ISO6391: "kn", Name: "Kannada"},
+	"mal": {Code: "mal", ISO6391: "ml", Name: "Malayalam"},
+	"sin": {Code: "sin", ISO6391: "si", Name: "Sinhalese"},
+	"nep": {Code: "nep", ISO6391: "ne", Name: "Nepali"},
+	"bur": {Code: "bur", ISO6391: "my", Name: "Burmese"},
+	"khm": {Code: "khm", ISO6391: "km", Name: "Khmer"},
+	"lao": {Code: "lao", ISO6391: "lo", Name: "Lao"},
+	"pol": {Code: "pol", ISO6391: "pl", Name: "Polish"},
+	"cze": {Code: "cze", ISO6391: "cs", Name: "Czech"},
+	"slo": {Code: "slo", ISO6391: "sk", Name: "Slovak"},
+	"hun": {Code: "hun", ISO6391: "hu", Name: "Hungarian"},
+	"rum": {Code: "rum", ISO6391: "ro", Name: "Romanian"},
+	"bul": {Code: "bul", ISO6391: "bg", Name: "Bulgarian"},
+	"srp": {Code: "srp", ISO6391: "sr", Name: "Serbian"},
+	"hrv": {Code: "hrv", ISO6391: "hr", Name: "Croatian"},
+	"bos": {Code: "bos", ISO6391: "bs", Name: "Bosnian"},
+	"slv": {Code: "slv", ISO6391: "sl", Name: "Slovenian"},
+	"ukr": {Code: "ukr", ISO6391: "uk", Name: "Ukrainian"},
+	"bel": {Code: "bel", ISO6391: "be", Name: "Belarusian"},
+	"lit": {Code: "lit", ISO6391: "lt", Name: "Lithuanian"},
+	"lav": {Code: "lav", ISO6391: "lv", Name: "Latvian"},
+	"est": {Code: "est", ISO6391: "et", Name: "Estonian"},
+	"fin": {Code: "fin", ISO6391: "fi", Name: "Finnish"},
+	"swe": {Code: "swe", ISO6391: "sv", Name: "Swedish"},
+	"nor": {Code: "nor", ISO6391: "no", Name: "Norwegian"},
+	"dan": {Code: "dan", ISO6391: "da", Name: "Danish"},
+	"ice": {Code: "ice", ISO6391: "is", Name: "Icelandic"},
+	"gle": {Code: "gle", ISO6391: "ga", Name: "Irish"},
+	"wel": {Code: "wel", ISO6391: "cy", Name: "Welsh"},
+	"gla": {Code: "gla", ISO6391: "gd", Name: "Scottish Gaelic"},
+	"baq": {Code: "baq", ISO6391: "eu", Name: "Basque"},
+	"cat": {Code: "cat", ISO6391: "ca", Name: "Catalan"},
+	"glg": {Code: "glg", ISO6391: "gl", Name: "Galician"},
+	"alb": {Code: "alb", ISO6391: "sq", Name: "Albanian"},
+	"mac": {Code: "mac", ISO6391: "mk", Name: "Macedonian"},
+	"arm": {Code: "arm", ISO6391: "hy", Name: "Armenian"},
+	"geo": {Code: "geo", ISO6391: "ka", Name: "Georgian"},
+	"aze": {Code: "aze", ISO6391: "az", Name: "Azerbaijani"},
+	"kaz": {Code: "kaz", ISO6391: "kk", Name: "Kazakh"},
+	"uzb": {Code: "uzb", ISO6391: "uz", Name: "Uzbek"},
+	"kir": {Code: "kir", ISO6391: "ky", Name: "Kyrgyz"},
+	"tgk": {Code: "tgk", ISO6391: "tg", Name: "Tajik"},
+	"tuk": {Code: "tuk", ISO6391: "tk", Name: "Turkmen"},
+	"mon": {Code: "mon", ISO6391: "mn", Name: "Mongolian"},
+	"tib": {Code: "tib", ISO6391: "bo", Name: "Tibetan"},
+	"pus": {Code: "pus", ISO6391: "ps", Name: "Pushto"},
+	"kur": {Code: "kur", ISO6391: "ku", Name: "Kurdish"},
+	"swa": {Code: "swa", ISO6391: "sw", Name: "Swahili"},
+	"amh": {Code: "amh", ISO6391: "am", Name: "Amharic"},
+	"hau": {Code: "hau", ISO6391: "ha", Name: "Hausa"},
+	"yor": {Code: "yor", ISO6391: "yo", Name: "Yoruba"},
+	"ibo": {Code: "ibo", ISO6391: "ig", Name: "Igbo"},
+	"zul": {Code: "zul", ISO6391: "zu", Name: "Zulu"},
+	"xho": {Code: "xho", ISO6391: "xh", Name: "Xhosa"},
+	"afr": {Code: "afr", ISO6391: "af", Name: "Afrikaans"},
+	"som": {Code: "som", ISO6391: "so", Name: "Somali"},
+	"mlg": {Code: "mlg", ISO6391: "mg", Name: "Malagasy"},
+	"mao": {Code: "mao", ISO6391: "mi", Name: "Maori"},
+	"haw": {Code: "haw", ISO6391: "", Name: "Hawaiian"},
+	"smo": {Code: "smo", ISO6391: "sm", Name: "Samoan"},
+	"ton": {Code: "ton", ISO6391: "to", Name: "Tongan"},
+	"fij": {Code: "fij", ISO6391: "fj", Name: "Fijian"},
+	"epo": {Code: "epo", ISO6391: "eo", Name: "Esperanto"},
+	"san": {Code: "san", ISO6391: "sa", Name: "Sanskrit"},
+	"pli": {Code: "pli", ISO6391: "pi", Name: "Pali"},
+	"yid": {Code: "yid", ISO6391: "yi", Name: "Yiddish"},
+	"ltz": {Code: "ltz", ISO6391: "lb", Name: "Luxembourgish"},
+	"mlt": {Code: "mlt", ISO6391: "mt", Name: "Maltese"},
+	"hat": {Code: "hat", ISO6391: "ht", Name: "Haitian French Creole"},
+	"mul": {Code: "mul", ISO6391: "", Name: "Multiple languages"},
+	"und": {Code: "und", ISO6391: "", Name: "Undetermined"},
+	"zxx": {Code: "zxx", ISO6391: "", Name: "No linguistic content"},
+}
+
+// marcCountries maps MARC country codes (008/15-17, 044) to names.
+// It covers countries plus US states, Canadian provinces, Australian states and UK countries.
+var marcCountries = map[string]string{
+	"io":  "Indonesia",
+	"my":  "Malaysia",
+	"si":  "Singapore",
+	"bx":  "Brunei",
+	"ph":  "Philippines",
+	"th":  "Thailand",
+	"vm":  "Vietnam",
+	"cb":  "Cambodia",
+	"ls":  "Laos",
+	"br":  "Burma",
+	"em":  "Timor-Leste",
+	"pp":  "Papua New Guinea",
+	"cc":  "China",
+	"ch":  "Taiwan",
+	"ja":  "Japan",
+	"ko":  "Korea (South)",
+	"kn":  "Korea (North)",
+	"mp":  "Mongolia",
+	"ii":  "India",
+	"pk":  "Pakistan",
+	"bg":  "Bangladesh",
+	"ce":  "Sri Lanka",
+	"np":  "Nepal",
+	"bt":  "Bhutan",
+	"xc":  "Maldives",
+	"af":  "Afghanistan",
+	"ir":  "Iran",
+	"iq":  "Iraq",
+	"is":  "Israel",
+	"jo":  "Jordan",
+	"le":  "Lebanon",
+	"sy":  "Syria",
+	"su":  "Saudi Arabia",
+	"ts":  "United Arab Emirates",
+	"qa":  "Qatar",
+	"ku":  "Kuwait",
+	"mk":  "Oman",
+	"ye":  "Yemen",
+	"ba":  "Bahrain",
+	"tu":  "Turkey",
+	"cy":  "Cyprus",
+	"ua":  "Egypt",
+	"ae":  "Algeria",
+	"mr":  "Morocco",
+	"ti":  "Tunisia",
+	"ly":  "Libya",
+	"sj":  "Sudan",
+	"et":  "Ethiopia",
+	"ke":  "Kenya",
+	"tz":  "Tanzania",
+	"ug":  "Uganda",
+	"rw":  "Rwanda",
+	"nr":  "Nigeria",
+	"gh":  "Ghana",
+	"sg":  "Senegal",
+	"cm":  "Cameroon",
+	"ao":  "Angola",
+	"za":  "Zambia",
+	"rh":  "Zimbabwe",
+	"mz":  "Mozambique",
+	"mg":  "Madagascar",
+	"sa":  "South Africa",
+	"at":  "Australia",
+	"nz":  "New Zealand",
+	"fj":  "Fiji",
+	"xxu": "United States",
+	"xxc": "Canada",
+	"xxk": "United Kingdom",
+	"enk": "England",
+	"stk": "Scotland",
+	"wlk": "Wales",
+	"nik": "Northern Ireland",
+	"ie":  "Ireland",
+	"fr":  "France",
+	"gw":  "Germany",
+	"ne":  "Netherlands",
+	"be":  "Belgium",
+	"lu":  "Luxembourg",
+	"sz":  "Switzerland",
+	"au":  "Austria",
+	"it":  "Italy",
+	"sp":  "Spain",
+	"po":  "Portugal",
+	"gr":  "Greece",
+	"dk":  "Denmark",
+	"sw":  "Sweden",
+	"no":  "Norway",
+	"fi":  "Finland",
+	"ic":  "Iceland",
+	"pl":  "Poland",
+	"xr":  "Czech Republic",
+	"xo":  "Slovakia",
+	"hu":  "Hungary",
+	"rm":  "Romania",
+	"bu":  "Bulgaria",
+	"rb":  "Serbia",
+	"ci":  "Croatia",
+	"xv":  "Slovenia",
+	"bn":  "Bosnia and Herzegovina",
+	"xn":  "North Macedonia",
+	"aa":  "Albania",
+	"mo":  "Montenegro",
+	"ru":  "Russia (Federation)",
+	"un":  "Ukraine",
+	"bw":  "Belarus",
+	"li":  "Lithuania",
+	"lv":  "Latvia",
+	"er":  "Estonia",
+	"ai":  "Armenia",
+	"gs":  "Georgia (Republic)",
+	"aj":  "Azerbaijan",
+	"kz":  "Kazakhstan",
+	"uz":  "Uzbekistan",
+	"kg":  "Kyrgyzstan",
+	"ta":  "Tajikistan",
+	"tk":  "Turkmenistan",
+	"mx":  "Mexico",
+	"cu":  "Cuba",
+	"dr":  "Dominican Republic",
+	"ht":  "Haiti",
+	"jm":  "Jamaica",
+	"gt":  "Guatemala",
+	"cr":  "Costa Rica",
+	"pn":  "Panama",
+	"ck":  "Colombia",
+	"ve":  "Venezuela",
+	"ec":  "Ecuador",
+	"pe":  "Peru",
+	"bo":  "Bolivia",
+	"cl":  "Chile",
+	"ag":  "Argentina",
+	"uy":  "Uruguay",
+	"py":  "Paraguay",
+	"bl":  "Brazil",
+	"alu": "Alabama",
+	"aku": "Alaska",
+	"azu": "Arizona",
+	"aru": "Arkansas",
+	"cau": "California",
+	"cou": "Colorado",
+	"ctu": "Connecticut",
+	"deu": "Delaware",
+	"dcu": "District of Columbia",
+	"flu": "Florida",
+	"gau": "Georgia",
+	"hiu": "Hawaii",
+	"idu": "Idaho",
+	"ilu": "Illinois",
+	"inu": "Indiana",
+	"iau": "Iowa",
+	"ksu": "Kansas",
+	"kyu": "Kentucky",
+	"lau": "Louisiana",
+	"meu": "Maine",
+	"mdu": "Maryland",
+	"mau": "Massachusetts",
+	"miu": "Michigan",
+	"mnu": "Minnesota",
+	"msu": "Mississippi",
+	"mou": "Missouri",
+	"mtu": "Montana",
+	"nbu": "Nebraska",
+	"nvu": "Nevada",
+	"nhu": "New Hampshire",
+	"nju": "New Jersey",
+	"nmu": "New Mexico",
+	"nyu": "New York (State)",
+	"ncu": "North Carolina",
+	"ndu": "North Dakota",
+	"ohu": "Ohio",
+	"oku": "Oklahoma",
+	"oru": "Oregon",
+	"pau": "Pennsylvania",
+	"riu": "Rhode Island",
+	"scu": "South Carolina",
+	"sdu": "South Dakota",
+	"tnu": "Tennessee",
+	"txu": "Texas",
+	"utu": "Utah",
+	"vtu": "Vermont",
+	"vau": "Virginia",
+	"wau": "Washington (State)",
+	"wvu": "West Virginia",
+	"wiu": "Wisconsin",
+	"wyu": "Wyoming",
+	"abc": "Alberta",
+	"bcc": "British Columbia",
+	"mbc": "Manitoba",
+	"nkc": "New Brunswick",
+	"nfc": "Newfoundland and Labrador",
+	"nsc": "Nova Scotia",
+	"onc": "Ontario",
+	"pic": "Prince Edward Island",
+	"quc": "Québec (Province)",
+	"snc": "Saskatchewan",
+	"ykc": "Yukon Territory",
+	"ntc": "Northwest Territories",
+	"nuc": "Nunavut",
+	"aca": "Australian Capital Territory",
+	"xna": "New South Wales",
+	"xoa": "Northern Territory",
+	"qea": "Queensland",
+	"xra": "South Australia",
+	"tma": "Tasmania",
+	"vra": "Victoria",
+	"wea": "Western Australia",
+}
@@ -0,0 +1,57 @@
+package goharvest
+
+import "testing"
+
+func TestLookupLanguage(t *testing.T) {
+	language, ok := LookupLanguage("ind")
+	if !ok || language.Name != "Indonesian" || language.ISO6391 != "id" {
+		t.Errorf("Unexpected language %+v", language)
+	}
+
+	language, ok = LookupLanguage(" JAV ")
+	if !ok || language.Name != "Javanese" {
+		t.Errorf("Expected case-insensitive lookup, got %+v", language)
+	}
+
+	if language, ok := LookupLanguage("min"); !ok || language.ISO6391 != "" {
+		t.Errorf("Expected language without ISO 639-1 code, got %+v", language)
+	}
+	if _, ok := LookupLanguage("zzz"); ok {
+		t.Error("Expected unknown code to fail")
+	}
+}
+
+func TestLookupCountry(t *testing.T) {
+	if name, ok := LookupCountry("io "); !ok || name != "Indonesia" {
+		t.Errorf("Unexpected country '%s'", name)
+	}
+	if name, ok := LookupCountry("nyu"); !ok || name != "New York (State)" {
+		t.Errorf("Unexpected country '%s'", name)
+	}
+	if _, ok := LookupCountry("zz"); ok {
+		t.Error("Expected unknown code to fail")
+	}
+}
+
+func TestBookMetadataLanguage(t *testing.T) {
+	record := &MARCRecord{ControlFields: []ControlField{
+		{Tag: "008", Value: "210315s2020    io a     b   f001 0 ind d"},
+	}}
+	if lang := record.ExtractBookMetadata().Language; lang != "Indonesian" {
+		t.Errorf("Expected language from 008, got '%s'", lang)
+	}
+
+	record = &MARCRecord{DataFields: []DataField{
+		{Tag: "041", Subfields: []Subfield{{Code: "a", Value: "jav"}}},
+	}}
+	if lang := record.ExtractBookMetadata().Language; lang != "Javanese" {
+		t.Errorf("Expected language from 041, got '%s'", lang)
+	}
+
+	record = &MARCRecord{DataFields: []DataField{
+		{Tag: "041", Subfields: []Subfield{{Code: "a", Value: "xyz"}}},
+	}}
+	if lang := record.ExtractBookMetadata().Language; lang != "xyz" {
+		t.Errorf("Expected unknown code to be kept, got '%s'", lang)
+	}
+}
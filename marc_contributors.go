@@ -0,0 +1,158 @@
+package goharvest
+
+import "strings"
+
+// ContributorType identifies the kind of name in a MARC name field
+type ContributorType string
+
+// Contributor types by tag
+const (
+	ContributorPersonal  ContributorType = "personal"  // 100, 700
+	ContributorCorporate ContributorType = "corporate" // 110, 710
+	ContributorMeeting   ContributorType = "meeting"   // 111, 711
+)
+
+// contributorTypes maps name tags to contributor types
+var contributorTypes = map[string]ContributorType{
+	"100": ContributorPersonal,
+	"110": ContributorCorporate,
+	"111": ContributorMeeting,
+	"700": ContributorPersonal,
+	"710": ContributorCorporate,
+	"711": ContributorMeeting,
+}
+
+// relatorTerms maps common MARC relator codes ($4) to their terms
+var relatorTerms = map[string]string{
+	"aut": "author",
+	"cmp": "composer",
+	"com": "compiler",
+	"ctb": "contributor",
+	"cre": "creator",
+	"drt": "director",
+	"edt": "editor",
+	"ill": "illustrator",
+	"ive": "interviewee",
+	"ivr": "interviewer",
+	"nrt": "narrator",
+	"pbl": "publisher",
+	"pht": "photographer",
+	"prf": "performer",
+	"spk": "speaker",
+	"ths": "thesis advisor",
+	"trl": "translator",
+	"win": "writer of introduction",
+}
+
+// Contributor is a name from 100/110/111 (main entry) or 700/710/711 (added entries)
+type Contributor struct {
+	Name         string          `json:"name"`                    // $a (with $b numeration or subordinate unit)
+	Dates        string          `json:"dates,omitempty"`         // $d
+	Relators     []string        `json:"relators,omitempty"`      // $e (personal, corporate) or $j (meeting)
+	RelatorCodes []string        `json:"relator_codes,omitempty"` // $4
+	Type         ContributorType `json:"type"`
+	Tag          string          `json:"tag"`
+	Main         bool            `json:"main"` // true for 1XX main entries
+}
+
+// String returns the name followed by its dates, e.g. "Toer, Pramoedya Ananta, 1925-2006"
+func (c Contributor) String() string {
+	if c.Dates == "" {
+		return c.Name
+	}
+	return c.Name + ", " + c.Dates
+}
+
+// Roles returns the relator terms, with relator codes resolved to terms where known
+func (c Contributor) Roles() []string {
+	roles := append([]string{}, c.Relators...)
+	for _, code := range c.RelatorCodes {
+		if term, ok := relatorTerms[strings.ToLower(code)]; ok {
+			roles = append(roles, term)
+		} else {
+			roles = append(roles, code)
+		}
+	}
+	return deduplicate(roles)
+}
+
+// HasRole reports whether the contributor has the given relator term or code
+func (c Contributor) HasRole(role string) bool {
+	role = strings.ToLower(role)
+	if term, ok := relatorTerms[role]; ok {
+		role = term
+	}
+	for _, r := range c.Roles() {
+		if strings.ToLower(r) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Contributors returns the main entry followed by the added entries, in field order
+func (m *MARCRecord) Contributors() []Contributor {
+	contributors := []Contributor{}
+	if m == nil {
+		return contributors
+	}
+
+	for _, main := range []bool{true, false} {
+		for _, field := range m.DataFields {
+			contributorType, ok := contributorTypes[field.Tag]
+			if !ok || strings.HasPrefix(field.Tag, "1") != main {
+				continue
+			}
+			if contributor, ok := parseContributor(field, contributorType); ok {
+				contributors = append(contributors, contributor)
+			}
+		}
+	}
+
+	return contributors
+}
+
+// MainEntry returns the 1XX contributor, or nil when the record has none
+func (m *MARCRecord) MainEntry() *Contributor {
+	for _, contributor := range m.Contributors() {
+		if contributor.Main {
+			return &contributor
+		}
+	}
+	return nil
+}
+
+// parseContributor builds a Contributor from a name field
+func parseContributor(field DataField, contributorType ContributorType) (Contributor, bool) {
+	contributor := Contributor{
+		Type: contributorType,
+		Tag:  field.Tag,
+		Main: strings.HasPrefix(field.Tag, "1"),
+	}
+
+	relatorCode := "e"
+	if contributorType == ContributorMeeting {
+		relatorCode = "j"
+	}
+
+	var name []string
+	for _, subfield := range field.Subfields {
+		value := strings.TrimSpace(subfield.Value)
+		if value == "" {
+			continue
+		}
+		switch subfield.Code {
+		case "a", "b":
+			name = append(name, value)
+		case "d":
+			contributor.Dates = trimISBDPunctuation(value)
+		case relatorCode:
+			contributor.Relators = append(contributor.Relators, trimISBDPunctuation(value))
+		case "4":
+			contributor.RelatorCodes = append(contributor.RelatorCodes, value)
+		}
+	}
+
+	contributor.Name = trimISBDPunctuation(strings.Join(name, " "))
+	return contributor, contributor.Name != ""
+}
@@ -0,0 +1,67 @@
+package goharvest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContributors(t *testing.T) {
+	record := &MARCRecord{
+		DataFields: []DataField{
+			{Tag: "700", Ind1: "1", Ind2: " ", Subfields: []Subfield{
+				{Code: "a", Value: "Lane, Max,"},
+				{Code: "e", Value: "translator."},
+				{Code: "4", Value: "trl"},
+			}},
+			{Tag: "100", Ind1: "1", Ind2: " ", Subfields: []Subfield{
+				{Code: "a", Value: "Toer, Pramoedya Ananta,"},
+				{Code: "d", Value: "1925-2006,"},
+				{Code: "e", Value: "author."},
+			}},
+			{Tag: "710", Ind1: "2", Ind2: " ", Subfields: []Subfield{
+				{Code: "a", Value: "Hasta Mitra."},
+				{Code: "b", Value: "Redaksi,"},
+				{Code: "4", Value: "edt"},
+			}},
+			{Tag: "711", Ind1: "2", Ind2: " ", Subfields: []Subfield{
+				{Code: "a", Value: "Kongres Bahasa Indonesia"},
+				{Code: "d", Value: "(1978)"},
+				{Code: "j", Value: "sponsor."},
+			}},
+		},
+	}
+
+	contributors := record.Contributors()
+	if len(contributors) != 4 {
+		t.Fatalf("Expected 4 contributors, got %d", len(contributors))
+	}
+
+	main := contributors[0]
+	if !main.Main || main.Tag != "100" || main.String() != "Toer, Pramoedya Ananta, 1925-2006" {
+		t.Errorf("Unexpected main entry %+v", main)
+	}
+	if got := contributors[1].Roles(); !reflect.DeepEqual(got, []string{"translator"}) {
+		t.Errorf("Expected translator role once, got %v", got)
+	}
+	if corporate := contributors[2]; corporate.Type != ContributorCorporate || corporate.Name != "Hasta Mitra. Redaksi" || !corporate.HasRole("editor") {
+		t.Errorf("Unexpected corporate contributor %+v", corporate)
+	}
+	if meeting := contributors[3]; meeting.Type != ContributorMeeting || !meeting.HasRole("sponsor") {
+		t.Errorf("Unexpected meeting contributor %+v", meeting)
+	}
+
+	metadata := record.ExtractBookMetadata()
+	if metadata.MainAuthor == nil || metadata.MainAuthor.Name != "Toer, Pramoedya Ananta" {
+		t.Errorf("Unexpected main author %+v", metadata.MainAuthor)
+	}
+	if len(metadata.Authors) != 3 || !metadata.Authors[0].HasRole("trl") {
+		t.Errorf("Unexpected added authors %+v", metadata.Authors)
+	}
+}
+
+func TestMainEntryMissing(t *testing.T) {
+	record := &MARCRecord{DataFields: []DataField{{Tag: "700", Subfields: []Subfield{{Code: "a", Value: "Someone"}}}}}
+	if record.MainEntry() != nil {
+		t.Error("Expected no main entry")
+	}
+}
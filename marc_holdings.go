@@ -0,0 +1,152 @@
+package goharvest
+
+import "strings"
+
+// HoldingsProfile describes where an integrated library system puts item data. Each
+// subfield list holds the codes whose values are joined to build that item property.
+type HoldingsProfile struct {
+	Name        string   `json:"name"`
+	Tags        []string `json:"tags"`
+	Location    string   `json:"location,omitempty"`
+	Sublocation string   `json:"sublocation,omitempty"`
+	CallNumber  string   `json:"call_number,omitempty"`
+	Barcode     string   `json:"barcode,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	CopyNumber  string   `json:"copy_number,omitempty"`
+	Note        string   `json:"note,omitempty"`
+}
+
+// Predefined holdings profiles
+var (
+	// HoldingsProfileMARC21 reads the standard 852 location field
+	HoldingsProfileMARC21 = HoldingsProfile{
+		Name:        "marc21",
+		Tags:        []string{"852"},
+		Location:    "b",
+		Sublocation: "c",
+		CallNumber:  "hijk",
+		Barcode:     "p",
+		CopyNumber:  "t",
+		Note:        "z",
+	}
+
+	// HoldingsProfileKoha reads Koha's 952 item field
+	HoldingsProfileKoha = HoldingsProfile{
+		Name:        "koha",
+		Tags:        []string{"952"},
+		Location:    "a",
+		Sublocation: "c",
+		CallNumber:  "o",
+		Barcode:     "p",
+		Status:      "7",
+		CopyNumber:  "t",
+		Note:        "z",
+	}
+
+	// HoldingsProfileVoyager reads the 852 of Voyager holdings exports and their 876 item barcodes
+	HoldingsProfileVoyager = HoldingsProfile{
+		Name:        "voyager",
+		Tags:        []string{"852", "876"},
+		Location:    "b",
+		Sublocation: "c",
+		CallNumber:  "hi",
+		Barcode:     "p",
+		CopyNumber:  "t",
+		Note:        "z",
+	}
+
+	// HoldingsProfileSLiMS reads the local 990/999 inventory numbers written by SLiMS and
+	// INLIS OAI-PMH providers, which carry one barcode per field in $a
+	HoldingsProfileSLiMS = HoldingsProfile{
+		Name:    "slims",
+		Tags:    []string{"990", "999"},
+		Barcode: "a",
+	}
+)
+
+// DefaultHoldingsProfiles are tried by Items when no profile is given
+var DefaultHoldingsProfiles = []HoldingsProfile{HoldingsProfileMARC21, HoldingsProfileKoha, HoldingsProfileSLiMS}
+
+// Item is one holding or physical copy
+type Item struct {
+	Location    string `json:"location,omitempty"`
+	Sublocation string `json:"sublocation,omitempty"`
+	CallNumber  string `json:"call_number,omitempty"`
+	Barcode     string `json:"barcode,omitempty"`
+	Status      string `json:"status,omitempty"`
+	CopyNumber  string `json:"copy_number,omitempty"`
+	Note        string `json:"note,omitempty"`
+	Tag         string `json:"tag"`
+}
+
+// Items returns the items described by the given profiles (DefaultHoldingsProfiles when none
+// are given), one per matching field in field order
+func (m *MARCRecord) Items(profiles ...HoldingsProfile) []Item {
+	items := []Item{}
+	if m == nil {
+		return items
+	}
+	if len(profiles) == 0 {
+		profiles = DefaultHoldingsProfiles
+	}
+
+	for _, field := range m.DataFields {
+		for _, profile := range profiles {
+			if !profile.hasTag(field.Tag) {
+				continue
+			}
+			if item, ok := profile.item(field); ok {
+				items = append(items, item)
+			}
+			break
+		}
+	}
+
+	return items
+}
+
+// hasTag reports whether the profile reads the given tag
+func (p HoldingsProfile) hasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// item builds an Item from a holdings field
+func (p HoldingsProfile) item(field DataField) (Item, bool) {
+	item := Item{
+		Location:    joinSubfields(field, p.Location),
+		Sublocation: joinSubfields(field, p.Sublocation),
+		CallNumber:  joinSubfields(field, p.CallNumber),
+		Barcode:     joinSubfields(field, p.Barcode),
+		Status:      joinSubfields(field, p.Status),
+		CopyNumber:  joinSubfields(field, p.CopyNumber),
+		Note:        joinSubfields(field, p.Note),
+		Tag:         field.Tag,
+	}
+	empty := Item{Tag: field.Tag}
+	return item, item != empty
+}
+
+// joinSubfields joins, in field order, the values of the subfields whose codes are listed in codes
+func joinSubfields(field DataField, codes string) string {
+	if codes == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, subfield := range field.Subfields {
+		if subfield.Code != "" && strings.Contains(codes, subfield.Code) {
+			if value := strings.TrimSpace(subfield.Value); value != "" {
+				if b.Len() > 0 {
+					b.WriteByte(' ')
+				}
+				b.WriteString(value)
+			}
+		}
+	}
+	return b.String()
+}
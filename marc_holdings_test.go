@@ -0,0 +1,67 @@
+package goharvest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestItemsProfiles(t *testing.T) {
+	record := &MARCRecord{
+		DataFields: []DataField{
+			{Tag: "852", Subfields: []Subfield{
+				{Code: "b", Value: "PUSAT"},
+				{Code: "c", Value: "Referensi"},
+				{Code: "h", Value: "899.221"},
+				{Code: "i", Value: "TOE b"},
+				{Code: "p", Value: "B0001"},
+				{Code: "t", Value: "1"},
+			}},
+			{Tag: "952", Subfields: []Subfield{
+				{Code: "a", Value: "CPL"},
+				{Code: "o", Value: "899.221 TOE"},
+				{Code: "p", Value: "39999000001"},
+				{Code: "7", Value: "1"},
+			}},
+			{Tag: "999", Subfields: []Subfield{{Code: "a", Value: "07A0022641"}}},
+		},
+	}
+
+	items := record.Items()
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 items, got %d: %+v", len(items), items)
+	}
+	want := Item{Location: "PUSAT", Sublocation: "Referensi", CallNumber: "899.221 TOE b", Barcode: "B0001", CopyNumber: "1", Tag: "852"}
+	if items[0] != want {
+		t.Errorf("Unexpected 852 item %+v", items[0])
+	}
+	if koha := items[1]; koha.Location != "CPL" || koha.Barcode != "39999000001" || koha.Status != "1" {
+		t.Errorf("Unexpected Koha item %+v", koha)
+	}
+	if items[2].Barcode != "07A0022641" {
+		t.Errorf("Unexpected local item %+v", items[2])
+	}
+
+	if kohaOnly := record.Items(HoldingsProfileKoha); len(kohaOnly) != 1 || kohaOnly[0].Tag != "952" {
+		t.Errorf("Expected only the Koha item, got %+v", kohaOnly)
+	}
+}
+
+func TestItemsFromFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/getrecord_marcxml.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAIPMHXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIPMHXML failed: %v", err)
+	}
+
+	books := resp.ExtractAllBookMetadata()
+	if len(books) != 1 {
+		t.Fatalf("Expected 1 book, got %d", len(books))
+	}
+	if len(books[0].Items) != 3 || books[0].Items[0].Barcode != "2251/B.2013" {
+		t.Errorf("Unexpected items %+v", books[0].Items)
+	}
+}
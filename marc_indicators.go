@@ -0,0 +1,95 @@
+package goharvest
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GetFieldValueWithIndicators retrieves the first subfield value of a field whose indicators match.
+// Pass "*" (or "") to accept any indicator and "#" (or " ") to require a blank one.
+func (m *MARCRecord) GetFieldValueWithIndicators(tag, ind1, ind2, subfieldCode string) string {
+	if values := m.GetFieldValuesWithIndicators(tag, ind1, ind2, subfieldCode); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// GetFieldValuesWithIndicators retrieves all subfield values of fields whose indicators match
+func (m *MARCRecord) GetFieldValuesWithIndicators(tag, ind1, ind2, subfieldCode string) []string {
+	var values []string
+	for _, field := range m.DataFields {
+		if field.Tag != tag || !matchIndicator(ind1, field.Ind1) || !matchIndicator(ind2, field.Ind2) {
+			continue
+		}
+		for _, subfield := range field.Subfields {
+			if subfield.Code == subfieldCode {
+				values = append(values, subfield.Value)
+			}
+		}
+	}
+	return values
+}
+
+// NonFilingCharacters returns the number of leading characters to ignore when filing the
+// field, taken from the second indicator of 245, 240, 130, 730 and 830 (first indicator for 130 and 730)
+func (f DataField) NonFilingCharacters() int {
+	indicator := f.Ind2
+	if f.Tag == "130" || f.Tag == "730" {
+		indicator = f.Ind1
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(indicator))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// TitleSortKey returns a normalized key for sorting by title: the 245 $a$b$n$p title with
+// the non-filing characters from the second indicator removed (so "The history" files
+// under "history"), lowercased, with ISBD punctuation and extra whitespace stripped
+func (m *MARCRecord) TitleSortKey() string {
+	fields := m.GetAllSubfields("245")
+	if len(fields) == 0 {
+		return ""
+	}
+	field := fields[0]
+
+	var parts []string
+	for _, subfield := range field.Subfields {
+		switch subfield.Code {
+		case "a", "b", "n", "p":
+			parts = append(parts, subfield.Value)
+		}
+	}
+	title := strings.Join(parts, " ")
+
+	if skip := field.NonFilingCharacters(); skip > 0 {
+		runes := []rune(title)
+		if skip > len(runes) {
+			skip = len(runes)
+		}
+		title = string(runes[skip:])
+	}
+
+	return sortKey(title)
+}
+
+// sortKey lowercases s, keeps letters, digits and single spaces, and drops everything else
+func sortKey(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			space = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package goharvest
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestGetFieldValueWithIndicators(t *testing.T) {
+	record := &MARCRecord{DataFields: []DataField{
+		{Tag: "650", Ind1: " ", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "History"}}},
+		{Tag: "650", Ind1: " ", Ind2: "7", Subfields: []Subfield{{Code: "a", Value: "Sejarah"}}},
+	}}
+
+	if got := record.GetFieldValueWithIndicators("650", "#", "7", "a"); got != "Sejarah" {
+		t.Errorf("Expected local subject heading, got '%s'", got)
+	}
+	if got := record.GetFieldValueWithIndicators("650", " ", "0", "a"); got != "History" {
+		t.Errorf("Expected LCSH heading, got '%s'", got)
+	}
+	if got := record.GetFieldValuesWithIndicators("650", "*", "*", "a"); len(got) != 2 {
+		t.Errorf("Expected both headings, got %v", got)
+	}
+	if got := record.GetFieldValueWithIndicators("650", "1", "*", "a"); got != "" {
+		t.Errorf("Expected no match for first indicator 1, got '%s'", got)
+	}
+}
+
+func TestTitleSortKey(t *testing.T) {
+	title := func(ind2, a, b string) *MARCRecord {
+		return &MARCRecord{DataFields: []DataField{{Tag: "245", Ind1: "1", Ind2: ind2, Subfields: []Subfield{
+			{Code: "a", Value: a}, {Code: "b", Value: b}, {Code: "c", Value: "ignored"},
+		}}}}
+	}
+
+	records := []*MARCRecord{
+		title("4", "The history of Yogyakarta :", "a short introduction /"),
+		title("0", "Batik :", "design, style & history /"),
+		title("2", "A guide to Borobudur /", ""),
+		title("0", "Chronicle of Java.", ""),
+	}
+
+	if key := records[0].TitleSortKey(); key != "history of yogyakarta a short introduction" {
+		t.Errorf("Unexpected sort key %q", key)
+	}
+
+	keys := make([]string, len(records))
+	for i, record := range records {
+		keys[i] = record.TitleSortKey()
+	}
+	sort.Strings(keys)
+	if keys[0] != "batik design style history" || keys[1] != "chronicle of java" || keys[2] != "guide to borobudur" {
+		t.Errorf("Unexpected sort order %v", keys)
+	}
+
+	if key := (&MARCRecord{}).TitleSortKey(); key != "" {
+		t.Errorf("Expected empty key without 245, got %q", key)
+	}
+}
@@ -0,0 +1,111 @@
+package goharvest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// marcJSONRecord is the MARC-in-JSON layout used by pymarc, ruby-marc and Catmandu
+type marcJSONRecord struct {
+	Leader string                       `json:"leader"`
+	Fields []map[string]json.RawMessage `json:"fields"`
+}
+
+// marcJSONDataField is the value of a data field entry in MARC-in-JSON
+type marcJSONDataField struct {
+	Ind1      string              `json:"ind1"`
+	Ind2      string              `json:"ind2"`
+	Subfields []map[string]string `json:"subfields"`
+}
+
+// MarshalMARCJSON serializes the record as MARC-in-JSON:
+//
+//	{"leader": "...", "fields": [{"001": "..."}, {"245": {"ind1": "1", "ind2": "0", "subfields": [{"a": "..."}]}}]}
+func (m *MARCRecord) MarshalMARCJSON() ([]byte, error) {
+	record := marcJSONRecord{
+		Leader: m.Leader,
+		Fields: make([]map[string]json.RawMessage, 0, len(m.ControlFields)+len(m.DataFields)),
+	}
+
+	for _, field := range m.ControlFields {
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		record.Fields = append(record.Fields, map[string]json.RawMessage{field.Tag: value})
+	}
+
+	for _, field := range m.DataFields {
+		dataField := marcJSONDataField{
+			Ind1:      field.Ind1,
+			Ind2:      field.Ind2,
+			Subfields: make([]map[string]string, 0, len(field.Subfields)),
+		}
+		for _, subfield := range field.Subfields {
+			dataField.Subfields = append(dataField.Subfields, map[string]string{subfield.Code: subfield.Value})
+		}
+
+		value, err := json.Marshal(dataField)
+		if err != nil {
+			return nil, err
+		}
+		record.Fields = append(record.Fields, map[string]json.RawMessage{field.Tag: value})
+	}
+
+	return json.Marshal(record)
+}
+
+// UnmarshalMARCJSON replaces the record with one parsed from MARC-in-JSON
+func (m *MARCRecord) UnmarshalMARCJSON(data []byte) error {
+	var record marcJSONRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("failed to parse MARC-in-JSON: %w", err)
+	}
+
+	parsed := MARCRecord{XMLName: m.XMLName, Leader: record.Leader}
+	for i, entry := range record.Fields {
+		if len(entry) != 1 {
+			return fmt.Errorf("failed to parse MARC-in-JSON: field %d must have exactly one tag", i)
+		}
+
+		for tag, raw := range entry {
+			if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '"' {
+				var value string
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return fmt.Errorf("failed to parse MARC-in-JSON field %s: %w", tag, err)
+				}
+				parsed.ControlFields = append(parsed.ControlFields, ControlField{Tag: tag, Value: value})
+				continue
+			}
+
+			var dataField marcJSONDataField
+			if err := json.Unmarshal(raw, &dataField); err != nil {
+				return fmt.Errorf("failed to parse MARC-in-JSON field %s: %w", tag, err)
+			}
+
+			field := DataField{Tag: tag, Ind1: dataField.Ind1, Ind2: dataField.Ind2}
+			for _, subfield := range dataField.Subfields {
+				if len(subfield) != 1 {
+					return fmt.Errorf("failed to parse MARC-in-JSON field %s: subfield must have exactly one code", tag)
+				}
+				for code, value := range subfield {
+					field.Subfields = append(field.Subfields, Subfield{Code: code, Value: value})
+				}
+			}
+			parsed.DataFields = append(parsed.DataFields, field)
+		}
+	}
+
+	*m = parsed
+	return nil
+}
+
+// ParseMARCJSON parses a MARC-in-JSON record
+func ParseMARCJSON(data []byte) (*MARCRecord, error) {
+	var record MARCRecord
+	if err := record.UnmarshalMARCJSON(data); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
@@ -0,0 +1,48 @@
+package goharvest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMARCJSONRoundTrip(t *testing.T) {
+	record := &MARCRecord{
+		Leader:        "00000cam a2200000 a 4500",
+		ControlFields: []ControlField{{Tag: "001", Value: "REC-1"}, {Tag: "008", Value: "210315s2020    io a     b   f001 0 ind d"}},
+		DataFields: []DataField{
+			{Tag: "245", Ind1: "1", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Bumi manusia :"}, {Code: "b", Value: "roman"}}},
+			{Tag: "650", Ind1: " ", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Indonesian fiction"}}},
+		},
+	}
+
+	data, err := record.MarshalMARCJSON()
+	if err != nil {
+		t.Fatalf("MarshalMARCJSON failed: %v", err)
+	}
+	if !strings.Contains(string(data), `{"245":{"ind1":"1","ind2":"0","subfields":[{"a":"Bumi manusia :"},{"b":"roman"}]}}`) {
+		t.Errorf("Unexpected MARC-in-JSON %s", data)
+	}
+
+	parsed, err := ParseMARCJSON(data)
+	if err != nil {
+		t.Fatalf("ParseMARCJSON failed: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, record) {
+		t.Errorf("Round trip mismatch:\n got %+v\nwant %+v", parsed, record)
+	}
+}
+
+func TestParseMARCJSONErrors(t *testing.T) {
+	inputs := []string{
+		`{"leader": "x", "fields": [{"001": "a", "002": "b"}]}`,
+		`{"leader": "x", "fields": [{"245": {"subfields": [{"a": "x", "b": "y"}]}}]}`,
+		`{"leader": "x", "fields": [{"245": 12}]}`,
+		`not json`,
+	}
+	for _, input := range inputs {
+		if _, err := ParseMARCJSON([]byte(input)); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package goharvest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Leader holds the decoded positions of a MARC 21 bibliographic leader
+type Leader struct {
+	RecordLength              int    // 00-04
+	RecordStatus              string // 05: a, c, d, n, p
+	TypeOfRecord              string // 06: a = language material, m = computer file, ...
+	BibliographicLevel        string // 07: m = monograph, s = serial, ...
+	TypeOfControl             string // 08: blank or a = archival
+	CharacterCoding           string // 09: blank = MARC-8, a = UCS/Unicode
+	IndicatorCount            int    // 10
+	SubfieldCodeCount         int    // 11
+	BaseAddress               int    // 12-16
+	EncodingLevel             string // 17: blank = full, 7 = minimal, ...
+	DescriptiveCatalogingForm string // 18: a = AACR2, i = ISBD, ...
+	MultipartLevel            string // 19
+}
+
+// Record status values (leader/05)
+const (
+	RecordStatusCorrected = "c"
+	RecordStatusDeleted   = "d"
+	RecordStatusNew       = "n"
+)
+
+// Bibliographic level values (leader/07)
+const (
+	BibLevelMonographicPart = "a"
+	BibLevelSerialPart      = "b"
+	BibLevelCollection      = "c"
+	BibLevelSubunit         = "d"
+	BibLevelIntegrating     = "i"
+	BibLevelMonograph       = "m"
+	BibLevelSerial          = "s"
+)
+
+// typeOfRecordNames maps leader/06 codes to their MARC 21 names
+var typeOfRecordNames = map[string]string{
+	"a": "Language material",
+	"c": "Notated music",
+	"d": "Manuscript notated music",
+	"e": "Cartographic material",
+	"f": "Manuscript cartographic material",
+	"g": "Projected medium",
+	"i": "Nonmusical sound recording",
+	"j": "Musical sound recording",
+	"k": "Two-dimensional nonprojectable graphic",
+	"m": "Computer file",
+	"o": "Kit",
+	"p": "Mixed materials",
+	"r": "Three-dimensional artifact or naturally occurring object",
+	"t": "Manuscript language material",
+}
+
+// ParseLeader decodes the leader of the record into its typed positions
+func (m *MARCRecord) ParseLeader() (*Leader, error) {
+	return ParseLeader(m.Leader)
+}
+
+// ParseLeader decodes a 24-character MARC 21 leader
+func ParseLeader(leader string) (*Leader, error) {
+	if len(leader) != 24 {
+		return nil, fmt.Errorf("invalid leader length: %d", len(leader))
+	}
+
+	return &Leader{
+		RecordLength:              leaderNumber(leader[0:5]),
+		RecordStatus:              leaderCode(leader[5]),
+		TypeOfRecord:              leaderCode(leader[6]),
+		BibliographicLevel:        leaderCode(leader[7]),
+		TypeOfControl:             leaderCode(leader[8]),
+		CharacterCoding:           leaderCode(leader[9]),
+		IndicatorCount:            leaderNumber(leader[10:11]),
+		SubfieldCodeCount:         leaderNumber(leader[11:12]),
+		BaseAddress:               leaderNumber(leader[12:17]),
+		EncodingLevel:             leaderCode(leader[17]),
+		DescriptiveCatalogingForm: leaderCode(leader[18]),
+		MultipartLevel:            leaderCode(leader[19]),
+	}, nil
+}
+
+// leaderNumber parses a numeric leader position, returning 0 for blanks or placeholders
+func leaderNumber(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// leaderCode returns a single-character leader code with blank and fill characters as ""
+func leaderCode(b byte) string {
+	if b == ' ' || b == '|' || b == '#' {
+		return ""
+	}
+	return string(b)
+}
+
+// IsMonograph returns true for monographs and monographic component parts
+func (l *Leader) IsMonograph() bool {
+	return l.BibliographicLevel == BibLevelMonograph || l.BibliographicLevel == BibLevelMonographicPart
+}
+
+// IsSerial returns true for serials and serial component parts
+func (l *Leader) IsSerial() bool {
+	return l.BibliographicLevel == BibLevelSerial || l.BibliographicLevel == BibLevelSerialPart
+}
+
+// IsBook returns true for printed or manuscript language material at monograph level
+func (l *Leader) IsBook() bool {
+	return (l.TypeOfRecord == "a" || l.TypeOfRecord == "t") && l.IsMonograph()
+}
+
+// IsDeleted returns true if the leader marks the record as deleted
+func (l *Leader) IsDeleted() bool {
+	return l.RecordStatus == RecordStatusDeleted
+}
+
+// IsUnicode returns true if the record is encoded in UCS/Unicode
+func (l *Leader) IsUnicode() bool {
+	return l.CharacterCoding == "a"
+}
+
+// IsFullLevel returns true for full-level cataloging (encoding level blank or 1)
+func (l *Leader) IsFullLevel() bool {
+	return l.EncodingLevel == "" || l.EncodingLevel == "1"
+}
+
+// TypeOfRecordName returns the descriptive name of the type of record
+func (l *Leader) TypeOfRecordName() string {
+	return typeOfRecordNames[l.TypeOfRecord]
+}
@@ -0,0 +1,50 @@
+package goharvest
+
+import "testing"
+
+func TestParseLeader(t *testing.T) {
+	record := &MARCRecord{Leader: "01142cam a2200301 a 4500"}
+
+	leader, err := record.ParseLeader()
+	if err != nil {
+		t.Fatalf("ParseLeader failed: %v", err)
+	}
+
+	if leader.RecordLength != 1142 || leader.BaseAddress != 301 {
+		t.Errorf("Unexpected length/base address %d / %d", leader.RecordLength, leader.BaseAddress)
+	}
+	if leader.RecordStatus != RecordStatusCorrected || leader.TypeOfRecord != "a" || leader.BibliographicLevel != BibLevelMonograph {
+		t.Errorf("Unexpected status/type/level %+v", leader)
+	}
+	if leader.TypeOfControl != "" || !leader.IsUnicode() || leader.IndicatorCount != 2 || leader.SubfieldCodeCount != 2 {
+		t.Errorf("Unexpected control/coding/counts %+v", leader)
+	}
+	if !leader.IsFullLevel() || leader.DescriptiveCatalogingForm != "a" {
+		t.Errorf("Unexpected encoding level/cataloging form %+v", leader)
+	}
+	if !leader.IsMonograph() || !leader.IsBook() || leader.IsSerial() || leader.IsDeleted() {
+		t.Errorf("Unexpected helpers for monograph leader %+v", leader)
+	}
+	if leader.TypeOfRecordName() != "Language material" {
+		t.Errorf("Unexpected type name '%s'", leader.TypeOfRecordName())
+	}
+}
+
+func TestParseLeaderSerial(t *testing.T) {
+	leader, err := ParseLeader("     das  22     7i 4500")
+	if err != nil {
+		t.Fatalf("ParseLeader failed: %v", err)
+	}
+	if !leader.IsSerial() || leader.IsMonograph() || !leader.IsDeleted() {
+		t.Errorf("Expected deleted serial, got %+v", leader)
+	}
+	if leader.RecordLength != 0 || leader.EncodingLevel != "7" || leader.IsFullLevel() {
+		t.Errorf("Unexpected blank length or encoding level %+v", leader)
+	}
+}
+
+func TestParseLeaderInvalid(t *testing.T) {
+	if _, err := ParseLeader("00000nam"); err == nil {
+		t.Error("Expected error for short leader")
+	}
+}
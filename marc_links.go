@@ -0,0 +1,81 @@
+package goharvest
+
+import "strings"
+
+// Relationship values of 856 second indicator
+const (
+	LinkRelationshipResource        = "resource"         // 0
+	LinkRelationshipVersion         = "version"          // 1
+	LinkRelationshipRelatedResource = "related_resource" // 2
+	LinkRelationshipNoDisplay       = "no_display"       // 8
+)
+
+// linkRelationships maps 856 second indicators to relationships
+var linkRelationships = map[string]string{
+	"0": LinkRelationshipResource,
+	"1": LinkRelationshipVersion,
+	"2": LinkRelationshipRelatedResource,
+	"8": LinkRelationshipNoDisplay,
+}
+
+// ElectronicResource is an electronic location from 856
+type ElectronicResource struct {
+	URL          string `json:"url"`                     // first $u
+	LinkText     string `json:"link_text,omitempty"`     // $y
+	Note         string `json:"note,omitempty"`          // $z
+	MaterialSpec string `json:"material_spec,omitempty"` // $3, e.g. "Table of contents"
+	Indicator2   string `json:"indicator2"`
+	Relationship string `json:"relationship,omitempty"` // from Indicator2, "" when unspecified
+}
+
+// ElectronicResources returns every 856 field that has a URL
+func (m *MARCRecord) ElectronicResources() []ElectronicResource {
+	resources := []ElectronicResource{}
+	if m == nil {
+		return resources
+	}
+
+	for _, field := range m.GetAllSubfields("856") {
+		resource := ElectronicResource{
+			Indicator2:   field.Ind2,
+			Relationship: linkRelationships[field.Ind2],
+		}
+
+		var notes []string
+		for _, subfield := range field.Subfields {
+			value := strings.TrimSpace(subfield.Value)
+			switch subfield.Code {
+			case "u":
+				if resource.URL == "" {
+					resource.URL = value
+				}
+			case "y":
+				resource.LinkText = value
+			case "z":
+				notes = append(notes, value)
+			case "3":
+				resource.MaterialSpec = value
+			}
+		}
+		resource.Note = strings.Join(notes, " ")
+
+		if resource.URL != "" {
+			resources = append(resources, resource)
+		}
+	}
+
+	return resources
+}
+
+// primaryURL returns the URL of the resource itself (856 ind2 0), falling back to the first link
+func primaryURL(resources []ElectronicResource) string {
+	for _, resource := range resources {
+		if resource.Relationship == LinkRelationshipResource {
+			return resource.URL
+		}
+	}
+	if len(resources) > 0 {
+		return resources[0].URL
+	}
+	return ""
+}
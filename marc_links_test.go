@@ -0,0 +1,37 @@
+package goharvest
+
+import "testing"
+
+func TestElectronicResources(t *testing.T) {
+	record := &MARCRecord{
+		DataFields: []DataField{
+			{Tag: "856", Ind1: "4", Ind2: "2", Subfields: []Subfield{
+				{Code: "3", Value: "Table of contents"},
+				{Code: "u", Value: "http://example.org/toc.pdf"},
+			}},
+			{Tag: "856", Ind1: "4", Ind2: "0", Subfields: []Subfield{
+				{Code: "u", Value: "http://example.org/fulltext.pdf"},
+				{Code: "u", Value: "http://mirror.example.org/fulltext.pdf"},
+				{Code: "y", Value: "Full text"},
+				{Code: "z", Value: "Campus access only."},
+			}},
+			{Tag: "856", Ind1: "4", Ind2: " ", Subfields: []Subfield{{Code: "z", Value: "Link without URL"}}},
+		},
+	}
+
+	resources := record.ElectronicResources()
+	if len(resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(resources))
+	}
+	if toc := resources[0]; toc.MaterialSpec != "Table of contents" || toc.Relationship != LinkRelationshipRelatedResource {
+		t.Errorf("Unexpected TOC link %+v", toc)
+	}
+	if full := resources[1]; full.URL != "http://example.org/fulltext.pdf" || full.LinkText != "Full text" || full.Note != "Campus access only." {
+		t.Errorf("Unexpected full text link %+v", full)
+	}
+
+	metadata := record.ExtractBookMetadata()
+	if metadata.URL != "http://example.org/fulltext.pdf" || len(metadata.ElectronicResources) != 2 {
+		t.Errorf("Unexpected URL %q / %d resources", metadata.URL, len(metadata.ElectronicResources))
+	}
+}
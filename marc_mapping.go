@@ -0,0 +1,154 @@
+package goharvest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// MappingRule maps MARC fields to one output field
+type MappingRule struct {
+	// Field is the output field name (the JSON name when applying to a struct)
+	Field string `json:"field"`
+	// Queries are field queries (see ParseQuery) tried in order; the first one
+	// that yields a value wins, so later queries act as fallbacks
+	Queries []string `json:"queries"`
+	// Separator joins the selected subfields of one field (default " ")
+	Separator string `json:"separator,omitempty"`
+	// Repeatable outputs every matching field as []string instead of the first one as string
+	Repeatable bool `json:"repeatable,omitempty"`
+	// TrimPunctuation strips trailing ISBD punctuation (" /:;,.=") from each value
+	TrimPunctuation bool `json:"trim_punctuation,omitempty"`
+
+	compiled []*FieldQuery
+}
+
+// Mapping is a set of rules that turns a MARC record into a map or a user struct
+type Mapping struct {
+	Rules []MappingRule `json:"rules"`
+}
+
+// NewMapping validates the rules and compiles their queries
+func NewMapping(rules ...MappingRule) (*Mapping, error) {
+	mapping := &Mapping{Rules: rules}
+	if err := mapping.compile(); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// LoadMapping reads a JSON mapping specification, e.g.
+//
+//	{"rules": [{"field": "title", "queries": ["245$a$b"], "trim_punctuation": true}]}
+func LoadMapping(r io.Reader) (*Mapping, error) {
+	var mapping Mapping
+	if err := json.NewDecoder(r).Decode(&mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping: %w", err)
+	}
+	if err := mapping.compile(); err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+// LoadMappingFile reads a JSON mapping specification from a file
+func LoadMappingFile(path string) (*Mapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mapping: %w", err)
+	}
+	defer f.Close()
+
+	return LoadMapping(f)
+}
+
+// compile parses the queries of every rule
+func (mp *Mapping) compile() error {
+	for i := range mp.Rules {
+		rule := &mp.Rules[i]
+		if rule.Field == "" {
+			return fmt.Errorf("mapping rule %d: missing field name", i)
+		}
+		if len(rule.Queries) == 0 {
+			return fmt.Errorf("mapping rule %q: no queries", rule.Field)
+		}
+
+		rule.compiled = rule.compiled[:0]
+		for _, expr := range rule.Queries {
+			query, err := ParseQuery(expr)
+			if err != nil {
+				return fmt.Errorf("mapping rule %q: %w", rule.Field, err)
+			}
+			rule.compiled = append(rule.compiled, query)
+		}
+	}
+	return nil
+}
+
+// Apply maps the record to a map of field name to string (or []string for repeatable rules).
+// Fields without a value are omitted.
+func (mp *Mapping) Apply(record *MARCRecord) map[string]interface{} {
+	result := make(map[string]interface{}, len(mp.Rules))
+	if record == nil {
+		return result
+	}
+
+	for _, rule := range mp.Rules {
+		values := rule.values(record)
+		if len(values) == 0 {
+			continue
+		}
+		if rule.Repeatable {
+			result[rule.Field] = values
+		} else {
+			result[rule.Field] = values[0]
+		}
+	}
+
+	return result
+}
+
+// ApplyTo maps the record into dst, a pointer to a struct whose json tags match the rule
+// field names. Repeatable rules need []string fields and the others string fields.
+func (mp *Mapping) ApplyTo(record *MARCRecord, dst interface{}) error {
+	data, err := json.Marshal(mp.Apply(record))
+	if err != nil {
+		return fmt.Errorf("failed to map record: %w", err)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("failed to map record: %w", err)
+	}
+	return nil
+}
+
+// values returns the values of the first query with results
+func (r *MappingRule) values(record *MARCRecord) []string {
+	sep := r.Separator
+	if sep == "" {
+		sep = " "
+	}
+
+	for _, query := range r.compiled {
+		values := record.queryJoined(query, sep)
+		if r.TrimPunctuation {
+			trimmed := values[:0]
+			for _, value := range values {
+				if value = trimISBDPunctuation(value); value != "" {
+					trimmed = append(trimmed, value)
+				}
+			}
+			values = trimmed
+		}
+		if len(values) > 0 {
+			return values
+		}
+	}
+	return nil
+}
+
+// trimISBDPunctuation strips trailing ISBD punctuation and whitespace
+func trimISBDPunctuation(s string) string {
+	return strings.TrimRight(s, " /:;,.=")
+}
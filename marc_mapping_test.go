@@ -0,0 +1,92 @@
+package goharvest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mappingTestRecord() *MARCRecord {
+	return &MARCRecord{
+		ControlFields: []ControlField{{Tag: "001", Value: "REC-1"}},
+		DataFields: []DataField{
+			{Tag: "245", Ind1: "1", Ind2: "0", Subfields: []Subfield{
+				{Code: "a", Value: "Bumi manusia :"},
+				{Code: "b", Value: "sebuah roman /"},
+			}},
+			{Tag: "264", Ind1: " ", Ind2: "1", Subfields: []Subfield{{Code: "b", Value: "Hasta Mitra,"}}},
+			{Tag: "650", Ind1: " ", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Indonesian fiction"}, {Code: "y", Value: "20th century."}}},
+			{Tag: "650", Ind1: " ", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Colonialism"}}},
+			{Tag: "852", Subfields: []Subfield{{Code: "h", Value: "899.221"}, {Code: "i", Value: "TOE b"}}},
+		},
+	}
+}
+
+func TestMappingApply(t *testing.T) {
+	mapping, err := NewMapping(
+		MappingRule{Field: "id", Queries: []string{"001"}},
+		MappingRule{Field: "title", Queries: []string{"245$a$b"}, TrimPunctuation: true},
+		MappingRule{Field: "publisher", Queries: []string{"260$b", "264 #1$b"}, TrimPunctuation: true},
+		MappingRule{Field: "subjects", Queries: []string{"650$a$y"}, Separator: " -- ", Repeatable: true, TrimPunctuation: true},
+		MappingRule{Field: "call_number", Queries: []string{"090$a$b", "852$h$i"}},
+		MappingRule{Field: "isbn", Queries: []string{"020$a"}},
+	)
+	if err != nil {
+		t.Fatalf("NewMapping failed: %v", err)
+	}
+
+	got := mapping.Apply(mappingTestRecord())
+	want := map[string]interface{}{
+		"id":          "REC-1",
+		"title":       "Bumi manusia : sebuah roman",
+		"publisher":   "Hasta Mitra",
+		"subjects":    []string{"Indonesian fiction -- 20th century", "Colonialism"},
+		"call_number": "899.221 TOE b",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %v, want %v", got, want)
+	}
+}
+
+func TestMappingApplyTo(t *testing.T) {
+	spec := `{"rules": [
+		{"field": "title", "queries": ["245$a"], "trim_punctuation": true},
+		{"field": "subjects", "queries": ["6xx$a"], "repeatable": true}
+	]}`
+	path := filepath.Join(t.TempDir(), "mapping.json")
+	if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	mapping, err := LoadMappingFile(path)
+	if err != nil {
+		t.Fatalf("LoadMappingFile failed: %v", err)
+	}
+
+	var book struct {
+		Title    string   `json:"title"`
+		Subjects []string `json:"subjects"`
+	}
+	if err := mapping.ApplyTo(mappingTestRecord(), &book); err != nil {
+		t.Fatalf("ApplyTo failed: %v", err)
+	}
+	if book.Title != "Bumi manusia" || len(book.Subjects) != 2 {
+		t.Errorf("Unexpected mapped struct %+v", book)
+	}
+}
+
+func TestLoadMappingErrors(t *testing.T) {
+	specs := []string{
+		`{"rules": [{"queries": ["245$a"]}]}`,
+		`{"rules": [{"field": "title"}]}`,
+		`{"rules": [{"field": "title", "queries": ["24"]}]}`,
+		`{"rules": [`,
+	}
+	for _, spec := range specs {
+		if _, err := LoadMapping(strings.NewReader(spec)); err == nil {
+			t.Errorf("Expected error for %s", spec)
+		}
+	}
+}
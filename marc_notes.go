@@ -0,0 +1,82 @@
+package goharvest
+
+import "strings"
+
+// Contents returns the table of contents entries from 505. Enhanced contents ($t titles with
+// $r responsibility) give one entry per title; basic contents ($a) are split on "--".
+func (m *MARCRecord) Contents() []string {
+	contents := []string{}
+	if m == nil {
+		return contents
+	}
+
+	for _, field := range m.GetAllSubfields("505") {
+		var entry []string
+		flush := func() {
+			if value := trimISBDPunctuation(strings.Join(entry, " ")); value != "" {
+				contents = append(contents, value)
+			}
+			entry = nil
+		}
+
+		for _, subfield := range field.Subfields {
+			value := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(subfield.Value), "--"))
+			switch subfield.Code {
+			case "a":
+				flush()
+				for _, part := range strings.Split(value, "--") {
+					if part = trimISBDPunctuation(strings.TrimSpace(part)); part != "" {
+						contents = append(contents, part)
+					}
+				}
+			case "t":
+				flush()
+				entry = append(entry, trimISBDPunctuation(value))
+			case "r":
+				entry = append(entry, "/", value)
+			case "g":
+				entry = append(entry, value)
+			}
+		}
+		flush()
+	}
+
+	return contents
+}
+
+// Series returns the series statements from 490 and the authorized series entries from 830,
+// with the volume ($v) appended after " ; "
+func (m *MARCRecord) Series() []string {
+	series := []string{}
+	if m == nil {
+		return series
+	}
+
+	for _, field := range m.DataFields {
+		if field.Tag != "490" && field.Tag != "830" {
+			continue
+		}
+
+		var title, volume []string
+		for _, subfield := range field.Subfields {
+			value := trimISBDPunctuation(strings.TrimSpace(subfield.Value))
+			switch subfield.Code {
+			case "a", "n", "p":
+				title = append(title, value)
+			case "v":
+				volume = append(volume, value)
+			}
+		}
+
+		statement := strings.Join(title, " ")
+		if statement == "" {
+			continue
+		}
+		if len(volume) > 0 {
+			statement += " ; " + strings.Join(volume, " ")
+		}
+		series = append(series, statement)
+	}
+
+	return deduplicate(series)
+}
@@ -0,0 +1,52 @@
+package goharvest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContents(t *testing.T) {
+	record := &MARCRecord{
+		DataFields: []DataField{
+			{Tag: "505", Ind1: "0", Ind2: " ", Subfields: []Subfield{
+				{Code: "a", Value: "Bumi manusia -- Anak semua bangsa -- Jejak langkah."},
+			}},
+			{Tag: "505", Ind1: "0", Ind2: "0", Subfields: []Subfield{
+				{Code: "t", Value: "Pendahuluan /"},
+				{Code: "r", Value: "A. Teeuw --"},
+				{Code: "t", Value: "Rumah kaca."},
+			}},
+		},
+	}
+
+	want := []string{"Bumi manusia", "Anak semua bangsa", "Jejak langkah", "Pendahuluan / A. Teeuw", "Rumah kaca"}
+	if got := record.Contents(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Contents() = %q, want %q", got, want)
+	}
+}
+
+func TestSeriesAndSummary(t *testing.T) {
+	record := &MARCRecord{
+		DataFields: []DataField{
+			{Tag: "490", Ind1: "1", Ind2: " ", Subfields: []Subfield{{Code: "a", Value: "Tetralogi Buru ;"}, {Code: "v", Value: "1"}}},
+			{Tag: "520", Ind1: " ", Ind2: " ", Subfields: []Subfield{{Code: "a", Value: "Kisah Minke."}, {Code: "b", Value: "Roman sejarah."}}},
+			{Tag: "521", Ind1: " ", Ind2: " ", Subfields: []Subfield{{Code: "a", Value: "Dewasa"}}},
+			{Tag: "830", Ind1: " ", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Tetralogi Buru ;"}, {Code: "v", Value: "1."}}},
+			{Tag: "830", Ind1: " ", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Karya lengkap Pramoedya."}}},
+		},
+	}
+
+	metadata := record.ExtractBookMetadata()
+	if want := []string{"Tetralogi Buru ; 1", "Karya lengkap Pramoedya"}; !reflect.DeepEqual(metadata.Series, want) {
+		t.Errorf("Series = %q, want %q", metadata.Series, want)
+	}
+	if metadata.Summary != "Kisah Minke. Roman sejarah." {
+		t.Errorf("Unexpected summary %q", metadata.Summary)
+	}
+	if metadata.TargetAudience != "Dewasa" {
+		t.Errorf("Unexpected target audience %q", metadata.TargetAudience)
+	}
+	if len(metadata.Contents) != 0 {
+		t.Errorf("Expected no contents, got %q", metadata.Contents)
+	}
+}
@@ -0,0 +1,175 @@
+package goharvest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldQuery is a compiled MARC field query such as "245$a$b", "6xx$a" or "245 1#$a".
+//
+// The syntax is a three-character tag, optional indicators and optional subfield codes:
+//   - tag characters x, X or . match any character ("6xx" matches 600-699)
+//   - indicators follow the tag after a space; # matches a blank and * matches anything
+//   - each $ is followed by one or more subfield codes ("$a$b" and "$ab" are equivalent)
+//
+// Without subfield codes all subfields of a matching field are selected.
+type FieldQuery struct {
+	Tag       string
+	Ind1      string
+	Ind2      string
+	Subfields []string
+}
+
+// ParseQuery compiles a field query expression
+func ParseQuery(expr string) (*FieldQuery, error) {
+	expr = strings.TrimSpace(expr)
+	if len(expr) < 3 {
+		return nil, fmt.Errorf("invalid field query %q: tag must have 3 characters", expr)
+	}
+
+	query := &FieldQuery{Tag: expr[:3], Ind1: "*", Ind2: "*"}
+	rest := expr[3:]
+
+	if trimmed := strings.TrimLeft(rest, " "); trimmed != rest || (rest != "" && rest[0] != '$') {
+		if len(trimmed) < 2 || strings.Contains(trimmed[:2], "$") {
+			return nil, fmt.Errorf("invalid field query %q: indicators must have 2 characters", expr)
+		}
+		query.Ind1, query.Ind2 = trimmed[:1], trimmed[1:2]
+		rest = trimmed[2:]
+	}
+
+	if rest != "" {
+		if rest[0] != '$' {
+			return nil, fmt.Errorf("invalid field query %q: expected $ before subfield codes", expr)
+		}
+		for _, part := range strings.Split(rest[1:], "$") {
+			if part == "" {
+				return nil, fmt.Errorf("invalid field query %q: empty subfield code", expr)
+			}
+			for _, code := range part {
+				query.Subfields = append(query.Subfields, string(code))
+			}
+		}
+	}
+
+	return query, nil
+}
+
+// MatchTag returns true if tag matches the query tag pattern
+func (q *FieldQuery) MatchTag(tag string) bool {
+	if len(tag) != len(q.Tag) {
+		return false
+	}
+	for i := 0; i < len(tag); i++ {
+		p := q.Tag[i]
+		if p != 'x' && p != 'X' && p != '.' && p != tag[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns true if the data field matches the query tag and indicators
+func (q *FieldQuery) Match(field DataField) bool {
+	return q.MatchTag(field.Tag) && matchIndicator(q.Ind1, field.Ind1) && matchIndicator(q.Ind2, field.Ind2)
+}
+
+// matchIndicator compares an indicator pattern (* or empty for any, # or space for blank)
+// with a field indicator
+func matchIndicator(pattern, value string) bool {
+	switch pattern {
+	case "*", "":
+		return true
+	case "#", " ":
+		return strings.TrimSpace(value) == ""
+	default:
+		return pattern == value
+	}
+}
+
+// values returns the selected subfield values of a matching field in field order
+func (q *FieldQuery) values(field DataField) []string {
+	var values []string
+	for _, subfield := range field.Subfields {
+		if q.selects(subfield.Code) && strings.TrimSpace(subfield.Value) != "" {
+			values = append(values, strings.TrimSpace(subfield.Value))
+		}
+	}
+	return values
+}
+
+// selects returns true if the subfield code is selected by the query
+func (q *FieldQuery) selects(code string) bool {
+	if len(q.Subfields) == 0 {
+		return true
+	}
+	for _, selected := range q.Subfields {
+		if selected == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Query returns one value per matching field, joining the selected subfields with a space.
+// Control fields (tags 001-009) return their whole value. An invalid expression returns nil;
+// use ParseQuery to check expressions up front.
+func (m *MARCRecord) Query(expr string) []string {
+	query, err := ParseQuery(expr)
+	if err != nil {
+		return nil
+	}
+	return m.QueryCompiled(query)
+}
+
+// QueryFirst returns the first value returned by Query, or an empty string
+func (m *MARCRecord) QueryFirst(expr string) string {
+	if values := m.Query(expr); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// QueryCompiled is like Query for a query compiled with ParseQuery
+func (m *MARCRecord) QueryCompiled(query *FieldQuery) []string {
+	return m.queryJoined(query, " ")
+}
+
+// queryJoined returns one value per matching field, joining the selected subfields with sep
+func (m *MARCRecord) queryJoined(query *FieldQuery, sep string) []string {
+	var results []string
+
+	for _, field := range m.ControlFields {
+		if query.MatchTag(field.Tag) && field.Value != "" {
+			results = append(results, field.Value)
+		}
+	}
+
+	for _, field := range m.DataFields {
+		if !query.Match(field) {
+			continue
+		}
+		if values := query.values(field); len(values) > 0 {
+			results = append(results, strings.Join(values, sep))
+		}
+	}
+
+	return results
+}
+
+// QueryFields returns the data fields matching the query tag and indicators, for
+// iterating repeatable fields while keeping their subfield structure
+func (m *MARCRecord) QueryFields(expr string) []DataField {
+	query, err := ParseQuery(expr)
+	if err != nil {
+		return nil
+	}
+
+	var fields []DataField
+	for _, field := range m.DataFields {
+		if query.Match(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,68 @@
+package goharvest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func queryTestRecord() *MARCRecord {
+	return &MARCRecord{
+		ControlFields: []ControlField{{Tag: "001", Value: "REC-1"}},
+		DataFields: []DataField{
+			{Tag: "245", Ind1: "1", Ind2: "4", Subfields: []Subfield{
+				{Code: "a", Value: "The history of Yogyakarta :"},
+				{Code: "b", Value: "a short introduction /"},
+				{Code: "c", Value: "Budi Santoso."},
+			}},
+			{Tag: "650", Ind1: " ", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "History"}, {Code: "z", Value: "Yogyakarta"}}},
+			{Tag: "651", Ind1: " ", Ind2: "7", Subfields: []Subfield{{Code: "a", Value: "Java"}}},
+			{Tag: "600", Ind1: "1", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Hamengkubuwono IX"}}},
+			{Tag: "700", Ind1: "1", Ind2: " ", Subfields: []Subfield{{Code: "a", Value: "Sari, Intan"}}},
+		},
+	}
+}
+
+func TestMARCQuery(t *testing.T) {
+	record := queryTestRecord()
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"245$a$b", []string{"The history of Yogyakarta : a short introduction /"}},
+		{"245$ab", []string{"The history of Yogyakarta : a short introduction /"}},
+		{"6xx$a", []string{"History", "Java", "Hamengkubuwono IX"}},
+		{"65X$a$z", []string{"History Yogyakarta", "Java"}},
+		{"6xx #0$a", []string{"History"}},
+		{"6xx *0$a", []string{"History", "Hamengkubuwono IX"}},
+		{"245 14$c", []string{"Budi Santoso."}},
+		{"245 1#$a", nil},
+		{"700 1#", []string{"Sari, Intan"}},
+		{"001", []string{"REC-1"}},
+		{"999$a", nil},
+	}
+
+	for _, tt := range tests {
+		if got := record.Query(tt.expr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Query(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	if got := record.QueryFirst("6xx$a"); got != "History" {
+		t.Errorf("QueryFirst = %q", got)
+	}
+	if fields := record.QueryFields("6xx"); len(fields) != 3 || fields[2].Tag != "600" {
+		t.Errorf("Unexpected QueryFields result %+v", fields)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, expr := range []string{"24", "245 1", "245$", "245$a$", "245a"} {
+		if _, err := ParseQuery(expr); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+	if record := queryTestRecord(); record.Query("24") != nil {
+		t.Error("Expected nil result for invalid query")
+	}
+}
@@ -0,0 +1,286 @@
+package goharvest
+
+import (
+	"strings"
+	"unicode"
+)
+
+// IdentifierType identifies the scheme of a standard identifier
+type IdentifierType string
+
+// Standard identifier schemes
+const (
+	IdentifierISBN   IdentifierType = "isbn"   // 020
+	IdentifierISSN   IdentifierType = "issn"   // 022
+	IdentifierLCCN   IdentifierType = "lccn"   // 010
+	IdentifierOCLC   IdentifierType = "oclc"   // 035 with (OCoLC) prefix
+	IdentifierSystem IdentifierType = "system" // other 035 control numbers
+)
+
+// StandardIdentifier is an identifier from 010, 020, 022 or 035
+type StandardIdentifier struct {
+	Type      IdentifierType `json:"type"`
+	Value     string         `json:"value"` // normalized
+	Raw       string         `json:"raw"`
+	Qualifier string         `json:"qualifier,omitempty"` // 020$q or a trailing "(pbk.)"
+	Canceled  bool           `json:"canceled"`            // $z, or $y/$z for 022
+	Valid     bool           `json:"valid"`               // checksum (ISBN, ISSN) or format check passed
+	Tag       string         `json:"tag"`
+}
+
+// StandardIdentifiers returns all ISBNs, ISSNs, LCCNs and 035 control numbers in field order,
+// including canceled/invalid ones marked as Canceled
+func (m *MARCRecord) StandardIdentifiers() []StandardIdentifier {
+	identifiers := []StandardIdentifier{}
+	if m == nil {
+		return identifiers
+	}
+
+	for _, field := range m.DataFields {
+		var qualifiers []string
+		start := len(identifiers)
+
+		for _, subfield := range field.Subfields {
+			raw := strings.TrimSpace(subfield.Value)
+			if raw == "" {
+				continue
+			}
+			canceled := subfield.Code == "z" || (field.Tag == "022" && subfield.Code == "y")
+
+			var id StandardIdentifier
+			switch {
+			case field.Tag == "020" && subfield.Code == "q":
+				qualifiers = append(qualifiers, trimISBDPunctuation(strings.Trim(raw, "()")))
+				continue
+			case field.Tag == "020" && (subfield.Code == "a" || canceled):
+				value, qualifier := splitQualifier(raw)
+				id = StandardIdentifier{Type: IdentifierISBN, Value: NormalizeISBN(value), Qualifier: qualifier}
+				id.Valid = ValidISBN(id.Value)
+				if id.Value == "" {
+					// keep malformed numbers (wrong length) in compact form rather than dropping them
+					id.Value = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(value))
+				}
+			case field.Tag == "022" && (subfield.Code == "a" || canceled):
+				id = StandardIdentifier{Type: IdentifierISSN, Value: NormalizeISSN(raw)}
+				id.Valid = ValidISSN(id.Value)
+			case field.Tag == "010" && (subfield.Code == "a" || canceled):
+				id = StandardIdentifier{Type: IdentifierLCCN, Value: NormalizeLCCN(raw)}
+				id.Valid = id.Value != ""
+			case field.Tag == "035" && (subfield.Code == "a" || canceled):
+				if oclc := NormalizeOCLC(raw); oclc != "" {
+					id = StandardIdentifier{Type: IdentifierOCLC, Value: oclc, Valid: true}
+				} else {
+					id = StandardIdentifier{Type: IdentifierSystem, Value: raw, Valid: true}
+				}
+			default:
+				continue
+			}
+
+			id.Raw = raw
+			id.Canceled = canceled
+			id.Tag = field.Tag
+			identifiers = append(identifiers, id)
+		}
+
+		// $q qualifiers apply to the ISBNs of their own field
+		if len(qualifiers) > 0 {
+			for i := start; i < len(identifiers); i++ {
+				if identifiers[i].Qualifier == "" {
+					identifiers[i].Qualifier = strings.Join(qualifiers, "; ")
+				}
+			}
+		}
+	}
+
+	return identifiers
+}
+
+// identifierValues returns the normalized, non-canceled values of one identifier type
+func (m *MARCRecord) identifierValues(idType IdentifierType) []string {
+	var values []string
+	for _, id := range m.StandardIdentifiers() {
+		if id.Type == idType && !id.Canceled && id.Value != "" {
+			values = append(values, id.Value)
+		}
+	}
+	return deduplicate(values)
+}
+
+// ISBNs returns the normalized, non-canceled ISBNs of the record
+func (m *MARCRecord) ISBNs() []string {
+	return m.identifierValues(IdentifierISBN)
+}
+
+// ISSNs returns the normalized, non-canceled ISSNs of the record
+func (m *MARCRecord) ISSNs() []string {
+	return m.identifierValues(IdentifierISSN)
+}
+
+// splitQualifier separates "978-0-00-000000-2 (pbk.)" into the number and "pbk"
+func splitQualifier(s string) (string, string) {
+	tokens := strings.Fields(strings.Replace(s, "(", " (", 1))
+	n := 0
+	for n < len(tokens) && strings.IndexFunc(tokens[n], func(r rune) bool { return !isISBNChar(r) && r != '-' }) < 0 {
+		n++
+	}
+	qualifier := trimISBDPunctuation(strings.Trim(strings.Join(tokens[n:], " "), "()"))
+	return strings.Join(tokens[:n], " "), qualifier
+}
+
+// isISBNChar reports whether r can appear in a compact ISBN
+func isISBNChar(r rune) bool {
+	return unicode.IsDigit(r) || r == 'X' || r == 'x'
+}
+
+// NormalizeISBN strips hyphens, spaces and qualifiers and upper-cases the check digit.
+// It returns "" when the result is not 10 or 13 characters long.
+func NormalizeISBN(s string) string {
+	s, _ = splitQualifier(strings.TrimSpace(s))
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case isISBNChar(r):
+			b.WriteRune(unicode.ToUpper(r))
+		case r == '-' || r == ' ':
+		default:
+			return ""
+		}
+	}
+
+	isbn := b.String()
+	if len(isbn) != 10 && len(isbn) != 13 {
+		return ""
+	}
+	if strings.Contains(isbn[:len(isbn)-1], "X") || (len(isbn) == 13 && strings.HasSuffix(isbn, "X")) {
+		return ""
+	}
+	return isbn
+}
+
+// ValidISBN reports whether s is an ISBN-10 or ISBN-13 with a correct check digit
+func ValidISBN(s string) bool {
+	isbn := NormalizeISBN(s)
+	switch len(isbn) {
+	case 10:
+		return isbn10CheckDigit(isbn[:9]) == isbn[9]
+	case 13:
+		return isbn13CheckDigit(isbn[:12]) == isbn[12]
+	}
+	return false
+}
+
+// ISBN10To13 converts an ISBN-10 to its 978-prefixed ISBN-13
+func ISBN10To13(s string) (string, bool) {
+	isbn := NormalizeISBN(s)
+	if len(isbn) != 10 {
+		return "", false
+	}
+	base := "978" + isbn[:9]
+	return base + string(isbn13CheckDigit(base)), true
+}
+
+// ISBN13To10 converts a 978-prefixed ISBN-13 to an ISBN-10. 979 ISBNs have no ISBN-10 form.
+func ISBN13To10(s string) (string, bool) {
+	isbn := NormalizeISBN(s)
+	if len(isbn) != 13 || !strings.HasPrefix(isbn, "978") {
+		return "", false
+	}
+	base := isbn[3:12]
+	return base + string(isbn10CheckDigit(base)), true
+}
+
+// isbn10CheckDigit computes the check digit for the first 9 digits of an ISBN-10
+func isbn10CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(digits[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+// isbn13CheckDigit computes the check digit for the first 12 digits of an ISBN-13
+func isbn13CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(digits[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// NormalizeISSN formats an ISSN as "NNNN-NNNC", returning "" when it does not have 8 characters
+func NormalizeISSN(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if unicode.IsDigit(r) || r == 'X' {
+			b.WriteRune(r)
+		}
+	}
+	issn := b.String()
+	if len(issn) != 8 || strings.Contains(issn[:7], "X") {
+		return ""
+	}
+	return issn[:4] + "-" + issn[4:]
+}
+
+// ValidISSN reports whether s is an ISSN with a correct check digit
+func ValidISSN(s string) bool {
+	issn := strings.ReplaceAll(NormalizeISSN(s), "-", "")
+	if issn == "" {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 7; i++ {
+		sum += int(issn[i]-'0') * (8 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return issn[7] == 'X'
+	}
+	return issn[7] == byte('0'+check)
+}
+
+// NormalizeLCCN normalizes an LCCN following the Library of Congress rules: blanks are
+// removed, anything after "/" is dropped and the serial after a hyphen is zero-padded to 6 digits
+func NormalizeLCCN(s string) string {
+	s = strings.Join(strings.Fields(s), "")
+	if i := strings.Index(s, "/"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, "-"); i >= 0 {
+		serial := s[i+1:]
+		if len(serial) < 6 {
+			serial = strings.Repeat("0", 6-len(serial)) + serial
+		}
+		s = s[:i] + serial
+	}
+	return strings.ToLower(s)
+}
+
+// NormalizeOCLC extracts the OCLC number from an 035 value such as "(OCoLC)ocm00012345",
+// returning "" for other control numbers
+func NormalizeOCLC(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "(OCoLC)") {
+		return ""
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(s, "(OCoLC)"))
+	for _, prefix := range []string{"ocm", "ocn", "on"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return ""
+		}
+	}
+	return strings.TrimLeft(s, "0")
+}
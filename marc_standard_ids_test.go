@@ -0,0 +1,104 @@
+package goharvest
+
+import "testing"
+
+func TestISBNHelpers(t *testing.T) {
+	if got := NormalizeISBN("978-0-306-40615-7 (pbk.)"); got != "9780306406157" {
+		t.Errorf("NormalizeISBN = %q", got)
+	}
+	if got := NormalizeISBN("0-8044-2957-x"); got != "080442957X" {
+		t.Errorf("NormalizeISBN = %q", got)
+	}
+	if NormalizeISBN("12345") != "" {
+		t.Error("Expected short ISBN to normalize to empty")
+	}
+
+	for _, isbn := range []string{"9780306406157", "0-306-40615-2", "080442957X"} {
+		if !ValidISBN(isbn) {
+			t.Errorf("Expected %s to be valid", isbn)
+		}
+	}
+	if ValidISBN("9780306406158") {
+		t.Error("Expected bad check digit to be invalid")
+	}
+
+	if got, ok := ISBN10To13("0-306-40615-2"); !ok || got != "9780306406157" {
+		t.Errorf("ISBN10To13 = %q, %v", got, ok)
+	}
+	if got, ok := ISBN13To10("9780306406157"); !ok || got != "0306406152" {
+		t.Errorf("ISBN13To10 = %q, %v", got, ok)
+	}
+	if _, ok := ISBN13To10("9791234567896"); ok {
+		t.Error("Expected 979 ISBN to have no ISBN-10 form")
+	}
+}
+
+func TestOtherIdentifierHelpers(t *testing.T) {
+	if got := NormalizeISSN("0378 5955"); got != "0378-5955" || !ValidISSN(got) {
+		t.Errorf("NormalizeISSN = %q", got)
+	}
+	if ValidISSN("0378-5954") {
+		t.Error("Expected bad ISSN check digit to be invalid")
+	}
+
+	lccns := map[string]string{
+		"n78-890351":         "n78890351",
+		"n  78-89035":        "n78089035",
+		"   85000002 ":       "85000002",
+		"85-2 ":              "85000002",
+		"2001-000002":        "2001000002",
+		"75-425165//r75":     "75425165",
+		" 79139101 /AC/r932": "79139101",
+	}
+	for raw, want := range lccns {
+		if got := NormalizeLCCN(raw); got != want {
+			t.Errorf("NormalizeLCCN(%q) = %q, want %q", raw, got, want)
+		}
+	}
+
+	if got := NormalizeOCLC("(OCoLC)ocm00012345"); got != "12345" {
+		t.Errorf("NormalizeOCLC = %q", got)
+	}
+	if NormalizeOCLC("(DLC)12345") != "" {
+		t.Error("Expected non-OCLC control number to be ignored")
+	}
+}
+
+func TestStandardIdentifiers(t *testing.T) {
+	record := &MARCRecord{
+		DataFields: []DataField{
+			{Tag: "010", Subfields: []Subfield{{Code: "a", Value: "  2001-12345 "}}},
+			{Tag: "020", Subfields: []Subfield{{Code: "a", Value: "978-0-306-40615-7 (pbk.)"}}},
+			{Tag: "020", Subfields: []Subfield{{Code: "a", Value: "0306406152"}, {Code: "q", Value: "(hardcover)"}}},
+			{Tag: "020", Subfields: []Subfield{{Code: "z", Value: "9780306406158"}}},
+			{Tag: "022", Subfields: []Subfield{{Code: "a", Value: "0378-5955"}, {Code: "y", Value: "0378-5954"}}},
+			{Tag: "035", Subfields: []Subfield{{Code: "a", Value: "(OCoLC)ocn123456789"}}},
+			{Tag: "035", Subfields: []Subfield{{Code: "a", Value: "(IndJkPN)INLIS0001"}}},
+		},
+	}
+
+	ids := record.StandardIdentifiers()
+	if len(ids) != 8 {
+		t.Fatalf("Expected 8 identifiers, got %d: %+v", len(ids), ids)
+	}
+	if ids[1].Qualifier != "pbk" || !ids[1].Valid || ids[2].Qualifier != "hardcover" {
+		t.Errorf("Unexpected ISBN qualifiers %+v / %+v", ids[1], ids[2])
+	}
+	if !ids[3].Canceled || ids[3].Valid || !ids[5].Canceled {
+		t.Errorf("Expected canceled identifiers, got %+v / %+v", ids[3], ids[5])
+	}
+	if ids[7].Type != IdentifierSystem {
+		t.Errorf("Expected system control number, got %+v", ids[7])
+	}
+
+	metadata := record.ExtractBookMetadata()
+	if metadata.ISBN != "9780306406157" || len(metadata.ISBNs) != 2 {
+		t.Errorf("Unexpected ISBNs %q / %v", metadata.ISBN, metadata.ISBNs)
+	}
+	if metadata.ISSN != "0378-5955" || metadata.LCCN != "2001012345" || metadata.OCLCNumber != "123456789" {
+		t.Errorf("Unexpected identifiers %q / %q / %q", metadata.ISSN, metadata.LCCN, metadata.OCLCNumber)
+	}
+	if got := record.ISSNs(); len(got) != 1 {
+		t.Errorf("Expected canceled ISSN to be skipped, got %v", got)
+	}
+}
@@ -0,0 +1,140 @@
+package goharvest
+
+import "strings"
+
+// SubjectType identifies the kind of a MARC subject heading
+type SubjectType string
+
+// Subject heading types by tag
+const (
+	SubjectPersonal     SubjectType = "personal"      // 600
+	SubjectCorporate    SubjectType = "corporate"     // 610
+	SubjectMeeting      SubjectType = "meeting"       // 611
+	SubjectUniformTitle SubjectType = "uniform_title" // 630
+	SubjectTopical      SubjectType = "topical"       // 650
+	SubjectGeographic   SubjectType = "geographic"    // 651
+	SubjectGenre        SubjectType = "genre"         // 655
+)
+
+// subjectTypes maps subject tags to heading types
+var subjectTypes = map[string]SubjectType{
+	"600": SubjectPersonal,
+	"610": SubjectCorporate,
+	"611": SubjectMeeting,
+	"630": SubjectUniformTitle,
+	"650": SubjectTopical,
+	"651": SubjectGeographic,
+	"655": SubjectGenre,
+}
+
+// subjectSources maps the second indicator of subject fields to thesaurus codes
+var subjectSources = map[string]string{
+	"0": "lcsh",
+	"1": "lcshac",
+	"2": "mesh",
+	"3": "nal",
+	"5": "cash",
+	"6": "rvm",
+}
+
+// SubjectSubdivision is one subdivision of a subject heading
+type SubjectSubdivision struct {
+	Code  string `json:"code"` // x general, y chronological, z geographic, v form
+	Value string `json:"value"`
+}
+
+// Subject is a structured subject heading from 600, 610, 611, 630, 650, 651 or 655
+type Subject struct {
+	Type         SubjectType          `json:"type"`
+	Tag          string               `json:"tag"`
+	Heading      string               `json:"heading"`
+	Source       string               `json:"source,omitempty"` // from ind2, or $2 when ind2 is 7
+	Subdivisions []SubjectSubdivision `json:"subdivisions,omitempty"`
+}
+
+// String assembles the heading and its subdivisions, e.g. "History -- 20th century"
+func (s Subject) String() string {
+	parts := []string{s.Heading}
+	for _, subdivision := range s.Subdivisions {
+		parts = append(parts, subdivision.Value)
+	}
+	return strings.Join(parts, " -- ")
+}
+
+// subdivisionValues returns the values of the subdivisions with the given code
+func (s Subject) subdivisionValues(code string) []string {
+	var values []string
+	for _, subdivision := range s.Subdivisions {
+		if subdivision.Code == code {
+			values = append(values, subdivision.Value)
+		}
+	}
+	return values
+}
+
+// GeneralSubdivisions returns the $x subdivisions
+func (s Subject) GeneralSubdivisions() []string { return s.subdivisionValues("x") }
+
+// ChronologicalSubdivisions returns the $y subdivisions
+func (s Subject) ChronologicalSubdivisions() []string { return s.subdivisionValues("y") }
+
+// GeographicSubdivisions returns the $z subdivisions
+func (s Subject) GeographicSubdivisions() []string { return s.subdivisionValues("z") }
+
+// FormSubdivisions returns the $v subdivisions
+func (s Subject) FormSubdivisions() []string { return s.subdivisionValues("v") }
+
+// Subjects returns the structured subject headings of the record in field order
+func (m *MARCRecord) Subjects() []Subject {
+	subjects := []Subject{}
+	if m == nil {
+		return subjects
+	}
+
+	for _, field := range m.DataFields {
+		subjectType, ok := subjectTypes[field.Tag]
+		if !ok {
+			continue
+		}
+		if subject, ok := parseSubject(field, subjectType); ok {
+			subjects = append(subjects, subject)
+		}
+	}
+
+	return subjects
+}
+
+// parseSubject builds a Subject from a subject data field
+func parseSubject(field DataField, subjectType SubjectType) (Subject, bool) {
+	subject := Subject{
+		Type:   subjectType,
+		Tag:    field.Tag,
+		Source: subjectSources[field.Ind2],
+	}
+
+	var heading []string
+	for _, subfield := range field.Subfields {
+		value := strings.TrimSpace(subfield.Value)
+		if value == "" {
+			continue
+		}
+		switch subfield.Code {
+		case "x", "y", "z", "v":
+			subject.Subdivisions = append(subject.Subdivisions, SubjectSubdivision{
+				Code:  subfield.Code,
+				Value: trimISBDPunctuation(value),
+			})
+		case "2":
+			if field.Ind2 == "7" {
+				subject.Source = value
+			}
+		case "0", "1", "3", "4", "5", "6", "8", "e":
+			// identifiers, relators and linkage are not part of the heading
+		default:
+			heading = append(heading, value)
+		}
+	}
+
+	subject.Heading = trimISBDPunctuation(strings.Join(heading, " "))
+	return subject, subject.Heading != ""
+}
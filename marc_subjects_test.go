@@ -0,0 +1,62 @@
+package goharvest
+
+import "testing"
+
+func TestSubjects(t *testing.T) {
+	record := &MARCRecord{
+		DataFields: []DataField{
+			{Tag: "600", Ind1: "1", Ind2: "0", Subfields: []Subfield{
+				{Code: "a", Value: "Toer, Pramoedya Ananta,"},
+				{Code: "d", Value: "1925-2006"},
+				{Code: "x", Value: "Criticism and interpretation."},
+			}},
+			{Tag: "650", Ind1: " ", Ind2: "0", Subfields: []Subfield{
+				{Code: "a", Value: "Indonesian fiction"},
+				{Code: "y", Value: "20th century"},
+				{Code: "v", Value: "History and criticism."},
+				{Code: "0", Value: "http://id.loc.gov/authorities/subjects/sh85065856"},
+			}},
+			{Tag: "651", Ind1: " ", Ind2: "7", Subfields: []Subfield{
+				{Code: "a", Value: "Jawa (Indonesia)"},
+				{Code: "z", Value: "Yogyakarta."},
+				{Code: "2", Value: "fast"},
+			}},
+			{Tag: "655", Ind1: " ", Ind2: "7", Subfields: []Subfield{{Code: "a", Value: "Novels."}, {Code: "2", Value: "lcgft"}}},
+			{Tag: "650", Ind1: " ", Ind2: "4", Subfields: []Subfield{{Code: "2", Value: "local"}}},
+		},
+	}
+
+	subjects := record.Subjects()
+	if len(subjects) != 4 {
+		t.Fatalf("Expected 4 subjects, got %d: %+v", len(subjects), subjects)
+	}
+
+	personal := subjects[0]
+	if personal.Type != SubjectPersonal || personal.Source != "lcsh" || personal.Heading != "Toer, Pramoedya Ananta, 1925-2006" {
+		t.Errorf("Unexpected personal subject %+v", personal)
+	}
+	if got := personal.GeneralSubdivisions(); len(got) != 1 || got[0] != "Criticism and interpretation" {
+		t.Errorf("Unexpected general subdivisions %v", got)
+	}
+
+	topical := subjects[1]
+	if got := topical.String(); got != "Indonesian fiction -- 20th century -- History and criticism" {
+		t.Errorf("Unexpected topical subject %q", got)
+	}
+	if len(topical.ChronologicalSubdivisions()) != 1 || len(topical.FormSubdivisions()) != 1 {
+		t.Errorf("Unexpected topical subdivisions %+v", topical.Subdivisions)
+	}
+
+	geographic := subjects[2]
+	if geographic.Type != SubjectGeographic || geographic.Source != "fast" || geographic.GeographicSubdivisions()[0] != "Yogyakarta" {
+		t.Errorf("Unexpected geographic subject %+v", geographic)
+	}
+
+	if genre := subjects[3]; genre.Type != SubjectGenre || genre.Heading != "Novels" || genre.Source != "lcgft" {
+		t.Errorf("Unexpected genre subject %+v", genre)
+	}
+
+	if metadata := record.ExtractBookMetadata(); len(metadata.Subjects) != 4 {
+		t.Errorf("Expected BookMetadata to carry 4 subjects, got %d", len(metadata.Subjects))
+	}
+}
@@ -0,0 +1,112 @@
+package goharvest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// MARCXMLNamespace is the MARC 21 slim schema namespace
+const MARCXMLNamespace = "http://www.loc.gov/MARC21/slim"
+
+// marcXMLRecord is the serialized form of MARCRecord
+type marcXMLRecord struct {
+	Leader        string             `xml:"leader,omitempty"`
+	ControlFields []ControlField     `xml:"controlfield"`
+	DataFields    []marcXMLDataField `xml:"datafield"`
+}
+
+// marcXMLDataField is a data field with its indicator attributes always present
+type marcXMLDataField struct {
+	Tag       string     `xml:"tag,attr"`
+	Ind1      string     `xml:"ind1,attr"`
+	Ind2      string     `xml:"ind2,attr"`
+	Subfields []Subfield `xml:"subfield"`
+}
+
+// marcXMLCollection wraps records in a MARCXML collection element
+type marcXMLCollection struct {
+	XMLName xml.Name      `xml:"http://www.loc.gov/MARC21/slim collection"`
+	Records []*MARCRecord `xml:"record"`
+}
+
+// MarshalXML writes the record as a MARCXML record element in the MARC 21 slim namespace.
+// Blank ("", "#") indicators are written as a space, as required by the schema.
+func (m MARCRecord) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	record := marcXMLRecord{
+		Leader:        m.Leader,
+		ControlFields: m.ControlFields,
+		DataFields:    make([]marcXMLDataField, 0, len(m.DataFields)),
+	}
+	for _, field := range m.DataFields {
+		record.DataFields = append(record.DataFields, marcXMLDataField{
+			Tag:       field.Tag,
+			Ind1:      marcXMLIndicator(field.Ind1),
+			Ind2:      marcXMLIndicator(field.Ind2),
+			Subfields: field.Subfields,
+		})
+	}
+
+	return e.EncodeElement(record, xml.StartElement{Name: xml.Name{Space: MARCXMLNamespace, Local: "record"}})
+}
+
+// MarshalMARCXML serializes the record as a standalone MARCXML document
+func (m *MARCRecord) MarshalMARCXML() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(m); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// WriteMARCXMLCollection writes the records wrapped in a MARCXML collection element
+func WriteMARCXMLCollection(w io.Writer, records []*MARCRecord) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(marcXMLCollection{Records: records}); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ParseMARCXMLCollection parses a MARCXML collection document, or a single record document
+func ParseMARCXMLCollection(data []byte) ([]*MARCRecord, error) {
+	var collection struct {
+		XMLName xml.Name
+		Records []*MARCRecord `xml:"record"`
+	}
+	if err := xml.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if collection.XMLName.Local == "record" {
+		var record MARCRecord
+		if err := xml.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+		return []*MARCRecord{&record}, nil
+	}
+
+	return collection.Records, nil
+}
+
+// marcXMLIndicator writes blank indicators as a single space
+func marcXMLIndicator(ind string) string {
+	if ind == "" || ind == "#" {
+		return " "
+	}
+	return ind
+}
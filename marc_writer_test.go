@@ -0,0 +1,89 @@
+package goharvest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMarshalMARCXML(t *testing.T) {
+	record := &MARCRecord{
+		Leader:        "00000cam a2200000 a 4500",
+		ControlFields: []ControlField{{Tag: "001", Value: "REC-1"}},
+		DataFields: []DataField{
+			{Tag: "245", Ind1: "1", Ind2: "#", Subfields: []Subfield{{Code: "a", Value: "Sejarah & budaya <Jawa>"}}},
+			{Tag: "500", Subfields: []Subfield{{Code: "a", Value: "Catatan"}}},
+		},
+	}
+
+	data, err := record.MarshalMARCXML()
+	if err != nil {
+		t.Fatalf("MarshalMARCXML failed: %v", err)
+	}
+	output := string(data)
+	for _, want := range []string{
+		`<record xmlns="http://www.loc.gov/MARC21/slim">`,
+		`<datafield tag="245" ind1="1" ind2=" ">`,
+		`<datafield tag="500" ind1=" " ind2=" ">`,
+		`Sejarah &amp; budaya &lt;Jawa&gt;`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+
+	var parsed MARCRecord
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("Failed to re-parse output: %v", err)
+	}
+	if parsed.XMLName.Space != MARCXMLNamespace || parsed.GetFieldValue("245", "a") != "Sejarah & budaya <Jawa>" {
+		t.Errorf("Unexpected re-parsed record %+v", parsed)
+	}
+}
+
+func TestMARCXMLCollectionRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("testdata/sample_response.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAIPMHXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIPMHXML failed: %v", err)
+	}
+
+	var records []*MARCRecord
+	for _, record := range resp.ListRecords.Records {
+		if record.Metadata.MARCXML != nil {
+			records = append(records, record.Metadata.MARCXML)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMARCXMLCollection(&buf, records); err != nil {
+		t.Fatalf("WriteMARCXMLCollection failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `<collection xmlns="http://www.loc.gov/MARC21/slim">`) {
+		t.Errorf("Expected collection wrapper, got %.200s", buf.String())
+	}
+
+	parsed, err := ParseMARCXMLCollection(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseMARCXMLCollection failed: %v", err)
+	}
+	if len(parsed) != len(records) {
+		t.Fatalf("Expected %d records, got %d", len(records), len(parsed))
+	}
+	for i := range records {
+		if got, want := parsed[i].ExtractBookMetadata().Title, records[i].ExtractBookMetadata().Title; got != want {
+			t.Errorf("Record %d title %q, want %q", i, got, want)
+		}
+	}
+
+	single, err := ParseMARCXMLCollection([]byte(`<record xmlns="http://www.loc.gov/MARC21/slim"><leader>x</leader></record>`))
+	if err != nil || len(single) != 1 || single[0].Leader != "x" {
+		t.Errorf("Unexpected single record parse %+v, %v", single, err)
+	}
+}
@@ -2,8 +2,14 @@ package goharvest
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -11,16 +17,77 @@ import (
 type OAIClient struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	// UserAgent is sent with every request when not empty
+	UserAgent string
+	// Header contains additional headers sent with every request
+	Header http.Header
+	// Archive stores every raw response when not nil
+	Archive PageArchive
+	// ScrubInvalidChars removes characters that XML does not allow, such as stray
+	// control characters, from responses before they are decoded
+	ScrubInvalidChars bool
+	// MaxResponseSize is the largest response body read, after decompression
+	// (0 for no limit)
+	MaxResponseSize int64
+	// MaxXMLDepth is the deepest element nesting accepted in a response (0 for no limit)
+	MaxXMLDepth int
+	// Compression lists the content codings requested, e.g. gzip and deflate. nil leaves
+	// compression to the HTTP transport; an empty slice requests uncompressed responses.
+	Compression []string
+	// UsePOST sends requests as form-encoded POST requests instead of GET
+	UsePOST bool
+	// Middleware wraps every request, outermost first
+	Middleware []Middleware
+	// Logger receives request and harvest events (nil for no logging)
+	Logger *slog.Logger
+	// Telemetry receives spans and metrics (nil for none)
+	Telemetry Telemetry
+	// ParseFailureDir is a directory that responses failing to parse are written to
+	// (empty for none)
+	ParseFailureDir string
+	// OnParseFailure is called with responses that fail to parse (nil for none)
+	OnParseFailure ParseFailureFunc
+	// Granularity is the repository's datestamp granularity, GranularityDay or
+	// GranularitySecond. When empty it is taken from Identify once a date range needs it.
+	Granularity string
+
+	// httpOptions configure HTTPClient once all options have run
+	httpOptions []func(c *OAIClient)
+	// optionErr is the error of an option that could not take effect
+	optionErr error
+	// ownTransport is the transport cloned by transport options, so that several options
+	// configure the same one
+	ownTransport *http.Transport
+	// granularityMu guards the lookup of Granularity
+	granularityMu sync.Mutex
 }
 
 // NewClient creates a new OAI-PMH client
-func NewClient(baseURL string) *OAIClient {
-	return &OAIClient{
+func NewClient(baseURL string, opts ...ClientOption) *OAIClient {
+	c := &OAIClient{
 		BaseURL: baseURL,
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		Header:          make(http.Header),
+		MaxResponseSize: DefaultMaxResponseSize,
+		MaxXMLDepth:     DefaultMaxXMLDepth,
+		Compression:     slices.Clone(DefaultCompression),
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	if len(c.httpOptions) > 0 {
+		httpClient := *c.HTTPClient
+		c.HTTPClient = &httpClient
+		for _, apply := range c.httpOptions {
+			apply(c)
+		}
+		c.httpOptions = nil
+	}
+
+	return c
 }
 
 // OAIPMHResponse represents the top-level OAI-PMH response
@@ -73,6 +140,26 @@ type ResumptionToken struct {
 	ExpirationDate   string `xml:"expirationDate,attr,omitempty"`
 }
 
+// ExpiresAt returns the parsed expirationDate of the token, if present and valid
+func (r *ResumptionToken) ExpiresAt() (time.Time, bool) {
+	if r == nil || r.ExpirationDate == "" {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339, r.ExpirationDate)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// IsExpired returns true if the token has an expirationDate before now
+func (r *ResumptionToken) IsExpired(now time.Time) bool {
+	expiresAt, ok := r.ExpiresAt()
+	return ok && now.After(expiresAt)
+}
+
 // Record represents an OAI-PMH record
 type Record struct {
 	Header   Header   `xml:"header"`
@@ -88,15 +175,24 @@ type Header struct {
 	SetSpec    []string `xml:"setSpec,omitempty"`
 }
 
-// Metadata contains the actual record data
-type Metadata struct {
-	MARCXML *MARCRecord `xml:"record,omitempty"`
-	Raw     []byte      `xml:",innerxml"`
+// HeaderStatusDeleted is the header status of deleted records
+const HeaderStatusDeleted = "deleted"
+
+// IsDeleted returns true if the header has status="deleted"
+func (h Header) IsDeleted() bool {
+	return h.Status == HeaderStatusDeleted
 }
 
-// About contains optional about information
-type About struct {
-	Raw []byte `xml:",innerxml"`
+// IsDeleted returns true if the record has been deleted from the repository
+func (r Record) IsDeleted() bool {
+	return r.Header.IsDeleted()
+}
+
+// Metadata contains the actual record data
+type Metadata struct {
+	MARCXML *MARCRecord    `xml:"record,omitempty"`
+	OAIMARC *OAIMARCRecord `xml:"oai_marc,omitempty"`
+	Raw     []byte         `xml:",innerxml"`
 }
 
 // MARCRecord represents a MARCXML record
@@ -144,6 +240,9 @@ func (c *OAIClient) HarvestAll(metadataPrefix string, callback func(*OAIPMHRespo
 		}
 
 		if err := callback(marcResp); err != nil {
+			if errors.Is(err, ErrStopHarvest) {
+				return nil
+			}
 			return fmt.Errorf("callback error: %w", err)
 		}
 
@@ -166,36 +265,81 @@ func ParseOAIPMHXML(data []byte) (*OAIPMHResponse, error) {
 	}
 
 	if oaiResp.Error != nil {
-		return nil, fmt.Errorf("OAI-PMH error [%s]: %s", oaiResp.Error.Code, oaiResp.Error.Message)
+		return nil, oaiResp.Error
 	}
+	oaiResp.normalizeOAIMARC()
 
 	return &oaiResp, nil
 }
 
+// decodeOAIPMHResponse stream-decodes a MARCXML ListRecords or GetRecord response
+func decodeOAIPMHResponse(r io.Reader, page decodeOptions) (*OAIPMHResponse, error) {
+	env, records, err := decodeResponse(r, page, func(d *xml.Decoder, start *xml.StartElement) (Record, error) {
+		var record Record
+		if err := d.DecodeElement(&record, start); err != nil {
+			return record, err
+		}
+		record.Metadata.normalizeOAIMARC()
+		return record, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	oaiResp := &OAIPMHResponse{
+		XMLName:      xml.Name{Local: "OAI-PMH"},
+		ResponseDate: env.ResponseDate,
+		Request:      env.Request,
+	}
+
+	switch env.Verb {
+	case "ListRecords":
+		oaiResp.ListRecords = &ListRecords{Records: records, ResumptionToken: env.ResumptionToken}
+	case "GetRecord":
+		if len(records) > 0 {
+			oaiResp.GetRecord = &GetRecord{Record: records[0]}
+		}
+	}
+
+	return oaiResp, nil
+}
+
 // BookMetadata represents extracted bibliographic metadata from MARC record
 type BookMetadata struct {
-	RecordID        string   `json:"record_id"`        // 001
-	LastModified    string   `json:"last_modified"`    // 005
-	ISBN            string   `json:"isbn"`             // 020
-	CallNumber      string   `json:"call_number"`      // 090
-	MainAuthor      string   `json:"main_author"`      // 100
-	CorporateAuthor string   `json:"corporate_author"` // 110
-	MeetingName     string   `json:"meeting_name"`     // 111
-	Title           string   `json:"title"`            // 245$a
-	Subtitle        string   `json:"subtitle"`         // 245$b
-	Responsibility  string   `json:"responsibility"`   // 245$c
-	Edition         string   `json:"edition"`          // 250
-	PublishPlace    string   `json:"publish_place"`    // 260$a
-	Publisher       string   `json:"publisher"`        // 260$b
-	PublishYear     string   `json:"publish_year"`     // 260$c
-	PhysicalDesc    string   `json:"physical_desc"`    // 300
-	Notes           []string `json:"notes"`            // 500
-	Bibliography    string   `json:"bibliography"`     // 504
-	Subjects        []string `json:"subjects"`         // 650
-	Authors         []string `json:"authors"`          // 700
-	Holdings        []string `json:"holdings"`         // 990, 999
-	URL             string   `json:"url"`              // 856$u
-	Classification  string   `json:"classification"`   // 082
+	RecordID            string               `json:"record_id"`            // 001
+	LastModified        string               `json:"last_modified"`        // 005
+	ISBN                string               `json:"isbn"`                 // first 020$a, normalized
+	ISBNs               []string             `json:"isbns"`                // 020$a, normalized
+	ISSN                string               `json:"issn"`                 // 022$a, normalized
+	LCCN                string               `json:"lccn"`                 // 010$a, normalized
+	OCLCNumber          string               `json:"oclc_number"`          // 035$a with (OCoLC) prefix
+	CallNumber          string               `json:"call_number"`          // 090
+	MainAuthor          *Contributor         `json:"main_author"`          // 100, 110 or 111
+	CorporateAuthor     string               `json:"corporate_author"`     // 110
+	MeetingName         string               `json:"meeting_name"`         // 111
+	Title               string               `json:"title"`                // 245$a
+	Subtitle            string               `json:"subtitle"`             // 245$b
+	Responsibility      string               `json:"responsibility"`       // 245$c
+	Edition             string               `json:"edition"`              // 250
+	PublishPlace        string               `json:"publish_place"`        // 264 _1$a or 260$a
+	Publisher           string               `json:"publisher"`            // 264 _1$b or 260$b
+	PublishYear         string               `json:"publish_year"`         // 264 _1$c or 260$c
+	CopyrightDate       string               `json:"copyright_date"`       // 264 _4$c
+	PhysicalDesc        string               `json:"physical_desc"`        // 300
+	Notes               []string             `json:"notes"`                // 500
+	Bibliography        string               `json:"bibliography"`         // 504
+	Contents            []string             `json:"contents"`             // 505
+	Summary             string               `json:"summary"`              // 520$a$b
+	TargetAudience      string               `json:"target_audience"`      // 521$a
+	Series              []string             `json:"series"`               // 490$a$v, 830$a$v
+	Subjects            []Subject            `json:"subjects"`             // 600, 610, 611, 630, 650, 651, 655
+	Authors             []Contributor        `json:"authors"`              // 700, 710, 711
+	Holdings            []string             `json:"holdings"`             // 990, 999
+	Items               []Item               `json:"items"`                // 852, 952, 990, 999 (see DefaultHoldingsProfiles)
+	URL                 string               `json:"url"`                  // 856$u, preferring the resource itself (ind2 0)
+	ElectronicResources []ElectronicResource `json:"electronic_resources"` // 856
+	Classification      string               `json:"classification"`       // 082
+	Language            string               `json:"language"`             // 008/35-37 or 041$a
 }
 
 // GetFieldValue retrieves the value of a specific MARC field and subfield
@@ -214,8 +358,13 @@ func (m *MARCRecord) GetFieldValue(tag, subfieldCode string) string {
 
 // GetFieldValues retrieves all values of a specific MARC field and subfield
 func (m *MARCRecord) GetFieldValues(tag, subfieldCode string) []string {
-	var values []string
-	for _, field := range m.DataFields {
+	return m.appendFieldValues(nil, tag, subfieldCode)
+}
+
+// appendFieldValues appends the values of a MARC field and subfield to values
+func (m *MARCRecord) appendFieldValues(values []string, tag, subfieldCode string) []string {
+	for i := range m.DataFields {
+		field := &m.DataFields[i]
 		if field.Tag == tag {
 			for _, subfield := range field.Subfields {
 				if subfield.Code == subfieldCode {
@@ -254,42 +403,51 @@ func (m *MARCRecord) ExtractBookMetadata() *BookMetadata {
 		return nil
 	}
 
+	// Notes, Contents and Series are assigned below, the other lists are appended to
 	metadata := &BookMetadata{
-		Notes:    []string{},
-		Subjects: []string{},
-		Authors:  []string{},
-		Holdings: []string{},
+		ISBNs:   []string{},
+		Authors: []Contributor{},
 	}
 
 	// Extract control fields
 	metadata.RecordID = m.GetControlFieldValue("001")
 	metadata.LastModified = m.GetControlFieldValue("005")
 
-	// Extract ISBN (020)
-	metadata.ISBN = m.GetFieldValue("020", "a")
+	// Extract standard identifiers (010, 020, 022, 035), skipping canceled numbers
+	for _, id := range m.StandardIdentifiers() {
+		if id.Canceled || id.Value == "" {
+			continue
+		}
+		switch id.Type {
+		case IdentifierISBN:
+			metadata.ISBNs = append(metadata.ISBNs, id.Value)
+		case IdentifierISSN:
+			metadata.ISSN = firstNonEmpty([]string{metadata.ISSN, id.Value})
+		case IdentifierLCCN:
+			metadata.LCCN = firstNonEmpty([]string{metadata.LCCN, id.Value})
+		case IdentifierOCLC:
+			metadata.OCLCNumber = firstNonEmpty([]string{metadata.OCLCNumber, id.Value})
+		}
+	}
+	metadata.ISBNs = deduplicate(metadata.ISBNs)
+	metadata.ISBN = firstNonEmpty(metadata.ISBNs)
 
 	// Extract Classification (082)
 	metadata.Classification = m.GetFieldValue("082", "a")
 
-	// Extract Call Number (090)
-	callNum090 := m.GetAllSubfields("090")
-	if len(callNum090) > 0 {
-		var callParts []string
-		for _, subfield := range callNum090[0].Subfields {
-			if subfield.Value != "" {
-				callParts = append(callParts, subfield.Value)
-			}
-		}
-		if len(callParts) > 0 {
-			metadata.CallNumber = callParts[0]
-			if len(callParts) > 1 {
-				metadata.CallNumber += " " + callParts[1]
-			}
-		}
+	// Extract Call Number (090), the class and item numbers
+	if field := m.firstDataField("090"); field != nil {
+		metadata.CallNumber = joinFirstSubfields(field, 2)
 	}
 
-	// Extract Main Author (100)
-	metadata.MainAuthor = m.GetFieldValue("100", "a")
+	// Extract Main Author (100, 110 or 111); the contributors are parsed once for both
+	contributors := m.Contributors()
+	for i := range contributors {
+		if contributors[i].Main {
+			metadata.MainAuthor = &contributors[i]
+			break
+		}
+	}
 
 	// Extract Corporate Author (110)
 	metadata.CorporateAuthor = m.GetFieldValue("110", "a")
@@ -305,26 +463,15 @@ func (m *MARCRecord) ExtractBookMetadata() *BookMetadata {
 	// Extract Edition (250)
 	metadata.Edition = m.GetFieldValue("250", "a")
 
-	// Extract Publication info (260)
-	metadata.PublishPlace = m.GetFieldValue("260", "a")
-	metadata.Publisher = m.GetFieldValue("260", "b")
-	metadata.PublishYear = m.GetFieldValue("260", "c")
+	// Extract Publication info (RDA 264 _1, falling back to 260)
+	metadata.PublishPlace = m.publicationValue("a")
+	metadata.Publisher = m.publicationValue("b")
+	metadata.PublishYear = m.publicationValue("c")
+	metadata.CopyrightDate = m.GetFieldValueWithIndicators("264", "*", "4", "c")
 
 	// Extract Physical Description (300)
-	field300 := m.GetAllSubfields("300")
-	if len(field300) > 0 {
-		var physDesc []string
-		for _, subfield := range field300[0].Subfields {
-			if subfield.Value != "" {
-				physDesc = append(physDesc, subfield.Value)
-			}
-		}
-		if len(physDesc) > 0 {
-			metadata.PhysicalDesc = physDesc[0]
-			for i := 1; i < len(physDesc); i++ {
-				metadata.PhysicalDesc += " " + physDesc[i]
-			}
-		}
+	if field := m.firstDataField("300"); field != nil {
+		metadata.PhysicalDesc = joinFirstSubfields(field, len(field.Subfields))
 	}
 
 	// Extract Notes (500)
@@ -333,29 +480,90 @@ func (m *MARCRecord) ExtractBookMetadata() *BookMetadata {
 	// Extract Bibliography (504)
 	metadata.Bibliography = m.GetFieldValue("504", "a")
 
-	// Extract Subjects (650)
-	metadata.Subjects = m.GetFieldValues("650", "a")
+	// Extract Contents (505), Summary (520) and Target Audience (521)
+	metadata.Contents = m.Contents()
+	if summaries := m.QueryCompiled(summaryQuery); len(summaries) > 0 {
+		metadata.Summary = summaries[0]
+	}
+	metadata.TargetAudience = m.GetFieldValue("521", "a")
+
+	// Extract Series (490 as transcribed, 830 as authorized)
+	metadata.Series = m.Series()
+
+	// Extract Subjects (600-655)
+	metadata.Subjects = m.Subjects()
 
-	// Extract Additional Authors (700)
-	metadata.Authors = m.GetFieldValues("700", "a")
+	// Extract Additional Authors (700, 710, 711)
+	for _, contributor := range contributors {
+		if !contributor.Main {
+			metadata.Authors = append(metadata.Authors, contributor)
+		}
+	}
 
 	// Extract Holdings (990 and 999)
-	holdings990 := m.GetFieldValues("990", "a")
-	holdings999 := m.GetFieldValues("999", "a")
-	metadata.Holdings = append(metadata.Holdings, holdings990...)
-	metadata.Holdings = append(metadata.Holdings, holdings999...)
+	metadata.Holdings = m.appendFieldValues(m.appendFieldValues([]string{}, "990", "a"), "999", "a")
+
+	// Extract Items (852, 952, 990, 999)
+	metadata.Items = m.Items()
+
+	// Extract electronic locations (856)
+	metadata.ElectronicResources = m.ElectronicResources()
+	metadata.URL = primaryURL(metadata.ElectronicResources)
 
-	// Extract URL (856)
-	metadata.URL = m.GetFieldValue("856", "u")
+	// Extract Language (008/35-37, falling back to 041)
+	metadata.Language = languageName(m.languageCode())
 
 	return metadata
 }
 
+// summaryQuery selects the summary and its expansion (520)
+var summaryQuery = &FieldQuery{Tag: "520", Ind1: "*", Ind2: "*", Subfields: []string{"a", "b"}}
+
+// firstDataField returns the first data field with tag, or nil
+func (m *MARCRecord) firstDataField(tag string) *DataField {
+	for i := range m.DataFields {
+		if m.DataFields[i].Tag == tag {
+			return &m.DataFields[i]
+		}
+	}
+	return nil
+}
+
+// joinFirstSubfields joins the first n non-empty subfield values of field with spaces
+func joinFirstSubfields(field *DataField, n int) string {
+	var b strings.Builder
+	for _, subfield := range field.Subfields {
+		if subfield.Value == "" {
+			continue
+		}
+		if n == 0 {
+			break
+		}
+		n--
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(subfield.Value)
+	}
+	return b.String()
+}
+
+// publicationValue returns a 264 _1 (RDA publication) subfield, falling back to 260
+func (m *MARCRecord) publicationValue(subfieldCode string) string {
+	if value := m.GetFieldValueWithIndicators("264", "*", "1", subfieldCode); value != "" {
+		return value
+	}
+	return m.GetFieldValue("260", subfieldCode)
+}
+
 // ExtractAllBookMetadata extracts metadata from all records in OAI-PMH response
 func (o *OAIPMHResponse) ExtractAllBookMetadata() []*BookMetadata {
 	var results []*BookMetadata
 
 	if o.ListRecords != nil {
+		if n := len(o.ListRecords.Records); n > 0 {
+			results = make([]*BookMetadata, 0, n)
+		}
 		for _, record := range o.ListRecords.Records {
 			if record.Metadata.MARCXML != nil {
 				metadata := record.Metadata.MARCXML.ExtractBookMetadata()
@@ -385,6 +593,9 @@ func (o *OAIPMHResponse) GetRecords() []MetadataExtractor {
 	var extractors []MetadataExtractor
 
 	if o.ListRecords != nil {
+		if n := len(o.ListRecords.Records); n > 0 {
+			extractors = make([]MetadataExtractor, 0, n)
+		}
 		for _, record := range o.ListRecords.Records {
 			if record.Metadata.MARCXML != nil {
 				extractors = append(extractors, record.Metadata.MARCXML)
@@ -409,6 +620,14 @@ func (o *OAIPMHResponse) GetResumptionToken() string {
 	return ""
 }
 
+// GetResumptionTokenInfo returns the full resumption token if available
+func (o *OAIPMHResponse) GetResumptionTokenInfo() *ResumptionToken {
+	if o.ListRecords != nil {
+		return o.ListRecords.ResumptionToken
+	}
+	return nil
+}
+
 // HasError returns true if the response contains an error
 func (o *OAIPMHResponse) HasError() bool {
 	return o.Error != nil
@@ -419,6 +638,33 @@ func (o *OAIPMHResponse) GetError() *OAIError {
 	return o.Error
 }
 
+// harvestedRecords returns all records in the response with their headers
+func (o *OAIPMHResponse) harvestedRecords() []HarvestedRecord {
+	var records []Record
+	if o.ListRecords != nil {
+		records = o.ListRecords.Records
+	}
+	if o.GetRecord != nil {
+		records = append(records, o.GetRecord.Record)
+	}
+
+	results := make([]HarvestedRecord, 0, len(records))
+	for _, record := range records {
+		harvested := HarvestedRecord{Header: record.Header, Raw: record.Metadata.Raw, About: record.About}
+		if record.Metadata.MARCXML != nil {
+			harvested.Metadata = record.Metadata.MARCXML
+		}
+		results = append(results, harvested)
+	}
+
+	return results
+}
+
+// responseDate returns the responseDate of the response
+func (o *OAIPMHResponse) responseDate() string {
+	return o.ResponseDate
+}
+
 // Implement MetadataExtractor interface for MARCRecord
 
 // ExtractMetadata extracts metadata from MARC record
@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestHarvestAll(t *testing.T) {
@@ -224,3 +225,85 @@ func TestParseOAIPMHResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestResumptionTokenInfo(t *testing.T) {
+	data, err := os.ReadFile("testdata/listrecords_dc_page1.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAIDCXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIDCXML failed: %v", err)
+	}
+
+	var response OAIResponse = resp
+	info := response.GetResumptionTokenInfo()
+	if info == nil {
+		t.Fatal("Expected resumption token info")
+	}
+	if info.Token != "dc-page-2" || info.Cursor != 0 || info.CompleteListSize != 4 {
+		t.Errorf("Unexpected resumption token info: %+v", info)
+	}
+
+	expiresAt, ok := info.ExpiresAt()
+	if !ok || expiresAt.Year() != 2099 {
+		t.Errorf("Expected expiration in 2099, got %v (%v)", expiresAt, ok)
+	}
+	if info.IsExpired(time.Now()) {
+		t.Error("Expected token not to be expired")
+	}
+	if !info.IsExpired(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expected token to be expired in 2100")
+	}
+
+	var missing *ResumptionToken
+	if missing.IsExpired(time.Now()) {
+		t.Error("Expected nil token not to be expired")
+	}
+}
+
+func TestExtractBookMetadataRDAPublication(t *testing.T) {
+	record := &MARCRecord{
+		DataFields: []DataField{
+			{Tag: "260", Ind1: " ", Ind2: " ", Subfields: []Subfield{
+				{Code: "a", Value: "Old place"},
+				{Code: "b", Value: "Old publisher"},
+				{Code: "c", Value: "1999"},
+			}},
+			{Tag: "264", Ind1: " ", Ind2: "0", Subfields: []Subfield{{Code: "b", Value: "Producer"}}},
+			{Tag: "264", Ind1: " ", Ind2: "1", Subfields: []Subfield{
+				{Code: "a", Value: "Jakarta"},
+				{Code: "b", Value: "Gramedia"},
+			}},
+			{Tag: "264", Ind1: " ", Ind2: "4", Subfields: []Subfield{{Code: "c", Value: "©2019"}}},
+		},
+	}
+
+	metadata := record.ExtractBookMetadata()
+	if metadata.PublishPlace != "Jakarta" || metadata.Publisher != "Gramedia" {
+		t.Errorf("Expected 264 _1 publication, got %q / %q", metadata.PublishPlace, metadata.Publisher)
+	}
+	if metadata.PublishYear != "1999" {
+		t.Errorf("Expected 260$c fallback year, got %q", metadata.PublishYear)
+	}
+	if metadata.CopyrightDate != "©2019" {
+		t.Errorf("Expected copyright date, got %q", metadata.CopyrightDate)
+	}
+}
+
+func BenchmarkExtractBookMetadata(b *testing.B) {
+	data, err := os.ReadFile("testdata/sample_response.xml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	resp, err := ParseOAIPMHXML(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		resp.ExtractAllBookMetadata()
+	}
+}
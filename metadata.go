@@ -1,13 +1,42 @@
 package goharvest
 
+import (
+	"context"
+	"time"
+)
+
 // MetadataFormat represents the type of metadata format
 type MetadataFormat string
 
 const (
-	FormatMARCXML MetadataFormat = "marcxml"
-	FormatOAIDC   MetadataFormat = "oai_dc"
+	FormatMARCXML  MetadataFormat = "marcxml"
+	FormatMARC21   MetadataFormat = "marc21"
+	FormatOAIMARC  MetadataFormat = "oai_marc"
+	FormatOAIDC    MetadataFormat = "oai_dc"
+	FormatMODS     MetadataFormat = "mods"
+	FormatQDC      MetadataFormat = "qdc"
+	FormatDataCite MetadataFormat = "oai_datacite"
+	// FormatDataCiteKernel is the bare DataCite kernel prefix used by Zenodo and Dryad
+	FormatDataCiteKernel MetadataFormat = "datacite"
+	FormatMETS           MetadataFormat = "mets"
+	FormatETDMS          MetadataFormat = "oai_etdms"
+	FormatORE            MetadataFormat = "oai_ore"
+	FormatLIDO           MetadataFormat = "lido"
+	FormatEAD            MetadataFormat = "ead"
 )
 
+// isSupported returns true if the format has a typed parser
+func (f MetadataFormat) isSupported() bool {
+	switch f {
+	case FormatMARCXML, FormatMARC21, FormatOAIMARC,
+		FormatOAIDC, FormatQDC, FormatMODS, FormatDataCite, FormatDataCiteKernel,
+		FormatMETS, FormatETDMS, FormatORE, FormatLIDO, FormatEAD:
+		return true
+	default:
+		return false
+	}
+}
+
 // MetadataExtractor is the interface for all metadata extractors
 type MetadataExtractor interface {
 	// ExtractMetadata extracts metadata from the record
@@ -22,6 +51,9 @@ type OAIResponse interface {
 	GetRecords() []MetadataExtractor
 	// GetResumptionToken returns the resumption token if available
 	GetResumptionToken() string
+	// GetResumptionTokenInfo returns the full resumption token with cursor,
+	// completeListSize and expirationDate, or nil if not available
+	GetResumptionTokenInfo() *ResumptionToken
 	// HasError returns true if the response contains an error
 	HasError() bool
 	// GetError returns the error information
@@ -35,10 +67,118 @@ type OAIResponse interface {
 type HarvestCallback func(response OAIResponse) error
 
 // DateRange represents the date range filter for selective harvesting
-// Dates should be in UTC and formatted as YYYY-MM-DD or YYYY-MM-DDThh:mm:ssZ
+// Dates should be in UTC and formatted as YYYY-MM-DD or YYYY-MM-DDThh:mm:ssZ, or given as
+// times, which are formatted for the repository's granularity
 type DateRange struct {
 	// From specifies the lower bound (inclusive) for datestamp-based selective harvesting
 	From string
 	// Until specifies the upper bound (inclusive) for datestamp-based selective harvesting
 	Until string
+	// FromTime is the lower bound as a time, used instead of From
+	FromTime time.Time `json:",omitzero"`
+	// UntilTime is the upper bound as a time, used instead of Until
+	UntilTime time.Time `json:",omitzero"`
+}
+
+// HarvestOptions contains selective harvesting options for ListRecords requests
+type HarvestOptions struct {
+	// DateRange filters records by datestamp (nil for no date filtering)
+	DateRange *DateRange
+	// Set restricts the harvest to records in the given setSpec (empty for all sets)
+	Set string
+	// Sets harvests several sets in one run instead of Set. Records in more than one of
+	// them are delivered once by the per-record APIs; a harvest stopped with
+	// ErrStopHarvest starts no further sets. Sets cannot be combined with a Checkpointer.
+	Sets []string
+	// ParallelSets is the number of Sets harvested at once (default 1, one after the other).
+	// Pages are still delivered to the callback one at a time.
+	ParallelSets int
+	// IgnoreNoRecordsMatch treats a noRecordsMatch error as an empty result
+	IgnoreNoRecordsMatch bool
+	// SkipDeleted excludes records with status="deleted" from the per-record APIs,
+	// which deliver them (with nil Metadata) by default
+	SkipDeleted bool
+	// Dedup suppresses records whose identifier was already delivered by the per-record
+	// APIs during the harvest (nil to deliver duplicates)
+	Dedup *DedupOptions
+	// ContentHash fills HarvestedRecord.Hash
+	ContentHash bool
+	// SkipUnchanged holds the content hashes of the records delivered so far; the
+	// per-record APIs skip records whose hash is unchanged and store the hash of each
+	// record the callback accepted (nil to deliver every record)
+	SkipUnchanged HashStore
+	// Checkpointer persists progress after each page (nil for no checkpointing)
+	Checkpointer Checkpointer
+	// PrefetchPages is the number of pages fetched ahead while the callback
+	// processes the current page (0 disables prefetching)
+	PrefetchPages int
+	// ExtractionWorkers is the number of goroutines decoding the records of a page while
+	// the rest of the page is read (0 or 1 decodes them one after the other). Records are
+	// delivered in document order either way.
+	ExtractionWorkers int
+	// MaxPageSize fails the harvest with ErrResponseTooLarge as soon as more than this
+	// many bytes of a ListRecords page are read, after decompression (0 for no limit
+	// besides the client's MaxResponseSize)
+	MaxPageSize int64
+	// TruncateOversizedPages keeps the records read before MaxPageSize instead of failing.
+	// The rest of the page is only scanned for its resumption token, so the harvest goes
+	// on with the next page; the records not decoded are counted in
+	// HarvestStats.DroppedRecords. Pages read whole for Lenient or Validation still fail.
+	TruncateOversizedPages bool
+	// Progress is invoked after each processed page (nil for no progress reporting)
+	Progress ProgressFunc
+	// Stats is filled with a summary of the harvest when it returns (nil to skip)
+	Stats *HarvestStats
+	// Validation checks each ListRecords page against the OAI-PMH and metadata schemas
+	// (nil for no validation)
+	Validation *ValidationOptions
+	// Lenient drops records that are not well-formed XML instead of failing the page
+	Lenient bool
+	// OnMalformedRecord is called for each record dropped in lenient mode. Returning nil
+	// continues the harvest; returning an error aborts it.
+	OnMalformedRecord func(*MalformedRecordError) error
+	// Observer receives the events of the harvest (nil for none)
+	Observer HarvestObserver
+	// ResumptionToken starts the harvest from a resumption token, e.g. the one reported in
+	// HarvestStats.ResumptionToken, instead of with the selective harvesting arguments
+	ResumptionToken string
+	// MaxPages stops the harvest after this many pages (0 for no limit)
+	MaxPages int
+	// MaxRecords stops the harvest after this many records (0 for no limit). The
+	// per-record APIs deliver exactly this many; the page APIs stop after the page that
+	// reaches it. When the limit falls partway through a page, the resumption token
+	// reported and checkpointed is the one of that page, which is delivered again when
+	// the harvest is resumed.
+	MaxRecords int
+	// RecoverBadResumptionToken restarts the list from the latest datestamp harvested when
+	// the repository rejects a resumption token with badResumptionToken, instead of
+	// failing. Records with that datestamp are delivered again unless Dedup is set.
+	RecoverBadResumptionToken bool
+	// TokenRetry requests a page again with the same resumption token after a timeout, a
+	// refused or reset connection or a 5xx status (nil to fail the harvest)
+	TokenRetry *TokenRetryOptions
+
+	// resumptionToken and pagesHarvested are set when resuming from a checkpoint
+	resumptionToken string
+	pagesHarvested  int
+	// restartRange replaces DateRange in requests once the list restarted after a
+	// rejected resumption token; checkpoints keep DateRange, which ResumeHarvest checks
+	restartRange *DateRange
+	// raw is set by HarvestRaw to bypass the metadata format switch
+	raw bool
+	// listed is set by FullSync to see the header of every record before any is skipped
+	listed func(Header)
+	// ctx and run are set by HarvestContext for the duration of a harvest
+	ctx context.Context
+	run *harvestRun
+	// control is set by StartHarvest to pause and stop the harvest between pages
+	control *harvestControl
+}
+
+// context returns the context of the harvest the options belong to
+func (o *HarvestOptions) context() context.Context {
+	if o == nil || o.ctx == nil {
+		return context.Background()
+	}
+	return o.ctx
 }
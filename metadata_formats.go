@@ -0,0 +1,62 @@
+package goharvest
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
+// ListMetadataFormatsResponse represents the OAI-PMH response to the ListMetadataFormats verb
+type ListMetadataFormatsResponse struct {
+	XMLName             xml.Name             `xml:"OAI-PMH"`
+	ResponseDate        string               `xml:"responseDate"`
+	Request             OAIRequest           `xml:"request"`
+	ListMetadataFormats *ListMetadataFormats `xml:"ListMetadataFormats,omitempty"`
+	Error               *OAIError            `xml:"error,omitempty"`
+}
+
+// ListMetadataFormats contains the metadata formats from ListMetadataFormats verb
+type ListMetadataFormats struct {
+	MetadataFormats []MetadataFormatInfo `xml:"metadataFormat"`
+}
+
+// MetadataFormatInfo describes a metadata format supported by the repository
+type MetadataFormatInfo struct {
+	MetadataPrefix    string `xml:"metadataPrefix"`
+	Schema            string `xml:"schema"`
+	MetadataNamespace string `xml:"metadataNamespace"`
+}
+
+// ListMetadataFormats retrieves the metadata formats available from the repository.
+// If identifier is not empty, only the formats available for that record are returned.
+func (c *OAIClient) ListMetadataFormats(ctx context.Context, identifier string) ([]MetadataFormatInfo, error) {
+	body, err := c.performRequest(ctx, "ListMetadataFormats", "identifier", identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var oaiResp ListMetadataFormatsResponse
+	if err := xml.Unmarshal(body, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, oaiResp.Error
+	}
+
+	if oaiResp.ListMetadataFormats == nil {
+		return nil, nil
+	}
+
+	return oaiResp.ListMetadataFormats.MetadataFormats, nil
+}
+
+// SupportsMetadataPrefix returns true if formats contains the given metadata prefix
+func SupportsMetadataPrefix(formats []MetadataFormatInfo, metadataPrefix string) bool {
+	for _, format := range formats {
+		if format.MetadataPrefix == metadataPrefix {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package goharvest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListMetadataFormats(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListMetadataFormats": "listmetadataformats_response.xml",
+	})
+	client := NewClient(server.URL)
+
+	formats, err := client.ListMetadataFormats(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListMetadataFormats failed: %v", err)
+	}
+
+	if len(formats) != 2 {
+		t.Fatalf("Expected 2 metadata formats, got %d", len(formats))
+	}
+	if formats[1].MetadataNamespace != "http://www.loc.gov/MARC21/slim" {
+		t.Errorf("Unexpected marcxml namespace '%s'", formats[1].MetadataNamespace)
+	}
+	if !SupportsMetadataPrefix(formats, "marcxml") {
+		t.Error("Expected marcxml to be supported")
+	}
+	if SupportsMetadataPrefix(formats, "mods") {
+		t.Error("Expected mods to be unsupported")
+	}
+}
+
+func TestListMetadataFormatsForIdentifier(t *testing.T) {
+	var gotIdentifier string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotIdentifier = r.URL.Query().Get("identifier")
+		w.Write([]byte(`<OAI-PMH><error code="idDoesNotExist">No such record</error></OAI-PMH>`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL).ListMetadataFormats(context.Background(), "oai:example.org:1")
+	if err == nil {
+		t.Fatal("Expected idDoesNotExist error")
+	}
+	if gotIdentifier != "oai:example.org:1" {
+		t.Errorf("Expected identifier parameter 'oai:example.org:1', got '%s'", gotIdentifier)
+	}
+}
@@ -0,0 +1,369 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// METS represents a Metadata Encoding and Transmission Standard document
+type METS struct {
+	XMLName   xml.Name        `xml:"http://www.loc.gov/METS/ mets"`
+	ObjID     string          `xml:"OBJID,attr,omitempty"`
+	Label     string          `xml:"LABEL,attr,omitempty"`
+	Type      string          `xml:"TYPE,attr,omitempty"`
+	DmdSec    []MetsDmdSec    `xml:"dmdSec"`
+	FileSec   *MetsFileSec    `xml:"fileSec,omitempty"`
+	StructMap []MetsStructMap `xml:"structMap"`
+}
+
+// MetsDmdSec represents a descriptive metadata section
+type MetsDmdSec struct {
+	ID     string      `xml:"ID,attr"`
+	MdWrap *MetsMdWrap `xml:"mdWrap,omitempty"`
+	MdRef  *MetsMdRef  `xml:"mdRef,omitempty"`
+}
+
+// MetsMdWrap represents metadata wrapped inside a METS section
+type MetsMdWrap struct {
+	MDType      string      `xml:"MDTYPE,attr"`
+	OtherMDType string      `xml:"OTHERMDTYPE,attr,omitempty"`
+	XMLData     MetsXMLData `xml:"xmlData"`
+}
+
+// MetsXMLData holds the wrapped descriptive record (MODS or Dublin Core)
+type MetsXMLData struct {
+	MODS *MODS       `xml:"http://www.loc.gov/mods/v3 mods,omitempty"`
+	DC   *DublinCore `xml:"http://www.openarchives.org/OAI/2.0/oai_dc/ dc,omitempty"`
+	Raw  []byte      `xml:",innerxml"`
+}
+
+// MetsMdRef represents a reference to external metadata
+type MetsMdRef struct {
+	MDType  string `xml:"MDTYPE,attr"`
+	LocType string `xml:"LOCTYPE,attr"`
+	Href    string `xml:"http://www.w3.org/1999/xlink href,attr"`
+}
+
+// MetsFileSec represents the file section listing all content files
+type MetsFileSec struct {
+	FileGrp []MetsFileGrp `xml:"fileGrp"`
+}
+
+// MetsFileGrp represents a group of files, e.g. USE="MASTER" or USE="THUMBS"
+type MetsFileGrp struct {
+	ID      string        `xml:"ID,attr,omitempty"`
+	Use     string        `xml:"USE,attr,omitempty"`
+	File    []MetsFile    `xml:"file"`
+	FileGrp []MetsFileGrp `xml:"fileGrp"`
+}
+
+// MetsFile represents a single content file
+type MetsFile struct {
+	ID           string       `xml:"ID,attr"`
+	MimeType     string       `xml:"MIMETYPE,attr,omitempty"`
+	Size         string       `xml:"SIZE,attr,omitempty"`
+	Checksum     string       `xml:"CHECKSUM,attr,omitempty"`
+	ChecksumType string       `xml:"CHECKSUMTYPE,attr,omitempty"`
+	Use          string       `xml:"USE,attr,omitempty"`
+	FLocat       []MetsFLocat `xml:"FLocat"`
+}
+
+// MetsFLocat represents the location of a file
+type MetsFLocat struct {
+	LocType string `xml:"LOCTYPE,attr"`
+	Href    string `xml:"http://www.w3.org/1999/xlink href,attr"`
+}
+
+// MetsStructMap represents a structural map (physical or logical)
+type MetsStructMap struct {
+	Type string  `xml:"TYPE,attr,omitempty"`
+	Div  MetsDiv `xml:"div"`
+}
+
+// MetsDiv represents a node of the structural map
+type MetsDiv struct {
+	ID    string     `xml:"ID,attr,omitempty"`
+	Type  string     `xml:"TYPE,attr,omitempty"`
+	Label string     `xml:"LABEL,attr,omitempty"`
+	Order string     `xml:"ORDER,attr,omitempty"`
+	DmdID string     `xml:"DMDID,attr,omitempty"`
+	Fptr  []MetsFptr `xml:"fptr"`
+	Div   []MetsDiv  `xml:"div"`
+}
+
+// MetsFptr points from a structural division to a file
+type MetsFptr struct {
+	FileID string `xml:"FILEID,attr"`
+}
+
+// Files returns all files in the file section, flattening nested file groups.
+// Files without their own USE attribute inherit it from their group.
+func (m *METS) Files() []MetsFileEntry {
+	if m == nil || m.FileSec == nil {
+		return nil
+	}
+
+	var entries []MetsFileEntry
+	var walk func(groups []MetsFileGrp, use string)
+	walk = func(groups []MetsFileGrp, use string) {
+		for _, group := range groups {
+			groupUse := use
+			if group.Use != "" {
+				groupUse = group.Use
+			}
+			for _, file := range group.File {
+				entry := MetsFileEntry{ID: file.ID, Use: groupUse, MimeType: file.MimeType, Size: file.Size}
+				if file.Use != "" {
+					entry.Use = file.Use
+				}
+				for _, loc := range file.FLocat {
+					if loc.Href != "" {
+						entry.URLs = append(entry.URLs, strings.TrimSpace(loc.Href))
+					}
+				}
+				entries = append(entries, entry)
+			}
+			walk(group.FileGrp, groupUse)
+		}
+	}
+	walk(m.FileSec.FileGrp, "")
+
+	return entries
+}
+
+// MetadataMETS represents the metadata wrapper for METS
+type MetadataMETS struct {
+	METS *METS  `xml:"http://www.loc.gov/METS/ mets,omitempty"`
+	Raw  []byte `xml:",innerxml"`
+}
+
+// RecordMETS represents an OAI-PMH record with METS metadata
+type RecordMETS struct {
+	Header   Header       `xml:"header"`
+	Metadata MetadataMETS `xml:"metadata"`
+	About    *About       `xml:"about,omitempty"`
+}
+
+// IsDeleted returns true if the record has been deleted from the repository
+func (r RecordMETS) IsDeleted() bool {
+	return r.Header.IsDeleted()
+}
+
+// ListRecordsMETS contains the list of METS records from ListRecords verb
+type ListRecordsMETS struct {
+	Records         []RecordMETS     `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// GetRecordMETS contains a single METS record from GetRecord verb
+type GetRecordMETS struct {
+	Record RecordMETS `xml:"record"`
+}
+
+// OAIPMHResponseMETS represents the OAI-PMH response with METS metadata
+type OAIPMHResponseMETS struct {
+	XMLName      xml.Name         `xml:"OAI-PMH"`
+	ResponseDate string           `xml:"responseDate"`
+	Request      OAIRequest       `xml:"request"`
+	ListRecords  *ListRecordsMETS `xml:"ListRecords,omitempty"`
+	GetRecord    *GetRecordMETS   `xml:"GetRecord,omitempty"`
+	Error        *OAIError        `xml:"error,omitempty"`
+}
+
+// MetsRecord represents extracted METS metadata: the descriptive record,
+// the content files and the structural maps
+type MetsRecord struct {
+	ObjID     string          `json:"obj_id"`
+	Label     string          `json:"label"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	MODS      *ModsMetadata   `json:"mods,omitempty"`
+	DC        *DCMetadata     `json:"dc,omitempty"`
+	Files     []MetsFileEntry `json:"files"`
+	StructMap []MetsStructMap `json:"struct_map"`
+}
+
+// MetsFileEntry is an extracted METS file with its resolved URLs
+type MetsFileEntry struct {
+	ID       string   `json:"id"`
+	Use      string   `json:"use"`
+	MimeType string   `json:"mime_type"`
+	Size     string   `json:"size"`
+	URLs     []string `json:"urls"`
+}
+
+// ExtractMetsRecord extracts the descriptive metadata, files and structure from a METS document
+func (m *METS) ExtractMetsRecord() *MetsRecord {
+	if m == nil {
+		return nil
+	}
+
+	record := &MetsRecord{
+		ObjID:     m.ObjID,
+		Label:     m.Label,
+		Type:      m.Type,
+		Files:     m.Files(),
+		StructMap: m.StructMap,
+	}
+	if record.Files == nil {
+		record.Files = []MetsFileEntry{}
+	}
+
+	// The first wrapped MODS or Dublin Core record describes the object
+	for _, dmd := range m.DmdSec {
+		if dmd.MdWrap == nil {
+			continue
+		}
+		if record.MODS == nil && dmd.MdWrap.XMLData.MODS != nil {
+			record.MODS = dmd.MdWrap.XMLData.MODS.ExtractModsMetadata()
+		}
+		if record.DC == nil && dmd.MdWrap.XMLData.DC != nil {
+			record.DC = dmd.MdWrap.XMLData.DC.ExtractDCMetadata()
+		}
+	}
+
+	switch {
+	case record.MODS != nil && record.MODS.Title != "":
+		record.Title = record.MODS.Title
+	case record.DC != nil && len(record.DC.Title) > 0:
+		record.Title = record.DC.Title[0]
+	default:
+		record.Title = m.Label
+	}
+
+	return record
+}
+
+// ExtractAllMetsRecord extracts metadata from all METS records in OAI-PMH response
+func (o *OAIPMHResponseMETS) ExtractAllMetsRecord() []*MetsRecord {
+	var results []*MetsRecord
+
+	for _, extractor := range o.GetRecords() {
+		if metadata := extractor.(*METS).ExtractMetsRecord(); metadata != nil {
+			results = append(results, metadata)
+		}
+	}
+
+	return results
+}
+
+// ParseOAIMETSXML parses OAI-PMH XML data with METS metadata from bytes
+func ParseOAIMETSXML(data []byte) (*OAIPMHResponseMETS, error) {
+	var oaiResp OAIPMHResponseMETS
+	if err := xml.Unmarshal(data, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, oaiResp.Error
+	}
+
+	return &oaiResp, nil
+}
+
+// decodeOAIPMHResponseMETS stream-decodes a METS ListRecords or GetRecord response
+func decodeOAIPMHResponseMETS(r io.Reader, page decodeOptions) (*OAIPMHResponseMETS, error) {
+	env, records, err := decodeResponse(r, page, decodeRecord[RecordMETS])
+	if err != nil {
+		return nil, err
+	}
+
+	oaiResp := &OAIPMHResponseMETS{
+		XMLName:      xml.Name{Local: "OAI-PMH"},
+		ResponseDate: env.ResponseDate,
+		Request:      env.Request,
+	}
+
+	switch env.Verb {
+	case "ListRecords":
+		oaiResp.ListRecords = &ListRecordsMETS{Records: records, ResumptionToken: env.ResumptionToken}
+	case "GetRecord":
+		if len(records) > 0 {
+			oaiResp.GetRecord = &GetRecordMETS{Record: records[0]}
+		}
+	}
+
+	return oaiResp, nil
+}
+
+// Implement OAIResponse interface for OAIPMHResponseMETS
+
+// GetRecords returns all records in the response as MetadataExtractor interface
+func (o *OAIPMHResponseMETS) GetRecords() []MetadataExtractor {
+	var extractors []MetadataExtractor
+
+	for _, record := range o.harvestedRecords() {
+		if record.Metadata != nil {
+			extractors = append(extractors, record.Metadata)
+		}
+	}
+
+	return extractors
+}
+
+// GetResumptionToken returns the resumption token if available
+func (o *OAIPMHResponseMETS) GetResumptionToken() string {
+	if o.ListRecords != nil && o.ListRecords.ResumptionToken != nil {
+		return o.ListRecords.ResumptionToken.Token
+	}
+	return ""
+}
+
+// GetResumptionTokenInfo returns the full resumption token if available
+func (o *OAIPMHResponseMETS) GetResumptionTokenInfo() *ResumptionToken {
+	if o.ListRecords != nil {
+		return o.ListRecords.ResumptionToken
+	}
+	return nil
+}
+
+// HasError returns true if the response contains an error
+func (o *OAIPMHResponseMETS) HasError() bool {
+	return o.Error != nil
+}
+
+// GetError returns the error information
+func (o *OAIPMHResponseMETS) GetError() *OAIError {
+	return o.Error
+}
+
+// harvestedRecords returns all records in the response with their headers
+func (o *OAIPMHResponseMETS) harvestedRecords() []HarvestedRecord {
+	var records []RecordMETS
+	if o.ListRecords != nil {
+		records = o.ListRecords.Records
+	}
+	if o.GetRecord != nil {
+		records = append(records, o.GetRecord.Record)
+	}
+
+	results := make([]HarvestedRecord, 0, len(records))
+	for _, record := range records {
+		harvested := HarvestedRecord{Header: record.Header, Raw: record.Metadata.Raw, About: record.About}
+		if record.Metadata.METS != nil {
+			harvested.Metadata = record.Metadata.METS
+		}
+		results = append(results, harvested)
+	}
+
+	return results
+}
+
+// responseDate returns the responseDate of the response
+func (o *OAIPMHResponseMETS) responseDate() string {
+	return o.ResponseDate
+}
+
+// Implement MetadataExtractor interface for METS
+
+// ExtractMetadata extracts metadata from METS record
+func (m *METS) ExtractMetadata() interface{} {
+	return m.ExtractMetsRecord()
+}
+
+// GetFormat returns the metadata format type
+func (m *METS) GetFormat() MetadataFormat {
+	return FormatMETS
+}
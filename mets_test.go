@@ -0,0 +1,77 @@
+package goharvest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseOAIMETSXML(t *testing.T) {
+	data, err := os.ReadFile("testdata/listrecords_mets.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAIMETSXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIMETSXML failed: %v", err)
+	}
+
+	records := resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if records[0].GetFormat() != FormatMETS {
+		t.Errorf("Expected format mets, got %s", records[0].GetFormat())
+	}
+
+	record, ok := records[0].ExtractMetadata().(*MetsRecord)
+	if !ok {
+		t.Fatalf("Expected *MetsRecord, got %T", records[0].ExtractMetadata())
+	}
+
+	if record.ObjID != "ms-40" || record.Type != "Manuscript" {
+		t.Errorf("Unexpected object attributes %s / %s", record.ObjID, record.Type)
+	}
+	if record.Title != "Babad Tanah Jawi" || record.MODS == nil || record.DC == nil {
+		t.Errorf("Unexpected descriptive metadata '%s' mods=%v dc=%v", record.Title, record.MODS, record.DC)
+	}
+
+	if len(record.Files) != 3 {
+		t.Fatalf("Expected 3 files, got %d", len(record.Files))
+	}
+	if record.Files[0].Use != "MASTER" || record.Files[0].URLs[0] != "https://example.org/files/ms-40/0001.tif" {
+		t.Errorf("Unexpected first file %+v", record.Files[0])
+	}
+	if record.Files[2].Use != "THUMBS" || record.Files[2].MimeType != "image/jpeg" {
+		t.Errorf("Expected nested group USE to apply, got %+v", record.Files[2])
+	}
+
+	if len(record.StructMap) != 1 || record.StructMap[0].Type != "physical" {
+		t.Fatalf("Unexpected struct maps %+v", record.StructMap)
+	}
+	pages := record.StructMap[0].Div.Div
+	if len(pages) != 2 || pages[0].Label != "Folio 1r" || len(pages[0].Fptr) != 2 || pages[1].Fptr[0].FileID != "IMG2" {
+		t.Errorf("Unexpected structure %+v", pages)
+	}
+}
+
+func TestUnifiedHarvestMETS(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_mets.xml"})
+	client := NewClient(server.URL)
+
+	count := 0
+	err := client.Harvest("mets", nil, func(response OAIResponse) error {
+		for _, record := range response.GetRecords() {
+			if _, ok := record.ExtractMetadata().(*MetsRecord); ok {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 METS record, got %d", count)
+	}
+}
@@ -0,0 +1,96 @@
+package goharvest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Doer sends an HTTP request. *http.Client implements it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function to the Doer interface
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req)
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the sending of requests, e.g. to sign them, log them or capture
+// responses. Responses passed back through middleware are already decompressed and
+// subject to the client's size and depth limits.
+type Middleware func(next Doer) Doer
+
+// WithMiddleware adds middleware to the client. The first middleware added is the
+// outermost: it sees requests first and responses last.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(c *OAIClient) {
+		c.Middleware = append(c.Middleware, middleware...)
+	}
+}
+
+// RequestHook returns middleware that calls hook before each request is sent. An error
+// from hook aborts the request.
+func RequestHook(hook func(req *http.Request) error) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if err := hook(req); err != nil {
+				return nil, err
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+// ResponseHook returns middleware that calls hook with each response and its whole body,
+// whatever the status code. The body is buffered, so the response can still be read
+// afterwards. An error from hook aborts the request.
+func ResponseHook(hook func(resp *http.Response, body []byte) error) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.Do(req)
+			if err != nil {
+				return nil, err
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", err)
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+
+			if err := hook(resp, body); err != nil {
+				return nil, err
+			}
+			return resp, nil
+		})
+	}
+}
+
+// doer returns the client's HTTP client wrapped in its middleware. The innermost layer
+// decompresses responses, recognizes non-XML ones and applies the limits, which count
+// decompressed bytes to defuse compression bombs.
+func (c *OAIClient) doer() Doer {
+	var doer Doer = DoerFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if err := decompressResponse(resp); err != nil {
+			return nil, err
+		}
+		sniffResponse(resp)
+		resp.Body = c.guardBody(resp.Body)
+		return resp, nil
+	})
+
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		doer = c.Middleware[i](doer)
+	}
+	return doer
+}
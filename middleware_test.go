@@ -0,0 +1,124 @@
+package goharvest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"Identify": "identify_response.xml"})
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" request")
+				resp, err := next.Do(req)
+				calls = append(calls, name+" response")
+				return resp, err
+			})
+		}
+	}
+
+	client := NewClient(server.URL, WithMiddleware(trace("outer")), WithMiddleware(trace("inner")))
+	if _, err := client.Identify(context.Background()); err != nil {
+		t.Fatalf("Identify failed: %v", err)
+	}
+
+	want := "outer request,inner request,inner response,outer response"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("Unexpected call order %s", got)
+	}
+}
+
+func TestRequestHook(t *testing.T) {
+	var signature string
+	server := newFixtureServer(t, map[string]string{"Identify": "identify_response.xml"})
+	server.Config.Handler = func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			signature = r.Header.Get("X-Signature")
+			next.ServeHTTP(w, r)
+		})
+	}(server.Config.Handler)
+
+	sign := RequestHook(func(req *http.Request) error {
+		req.Header.Set("X-Signature", "signed:"+req.URL.Query().Get("verb"))
+		return nil
+	})
+	if _, err := NewClient(server.URL, WithMiddleware(sign)).Identify(context.Background()); err != nil {
+		t.Fatalf("Identify failed: %v", err)
+	}
+	if signature != "signed:Identify" {
+		t.Errorf("Unexpected signature %q", signature)
+	}
+
+	errDenied := errors.New("denied by policy")
+	deny := RequestHook(func(*http.Request) error { return errDenied })
+	if _, err := NewClient(server.URL, WithMiddleware(deny)).Identify(context.Background()); !errors.Is(err, errDenied) {
+		t.Errorf("Expected hook error, got %v", err)
+	}
+}
+
+func TestResponseHookSeesDecompressedBody(t *testing.T) {
+	want, err := os.ReadFile(filepath.Join("testdata", "identify_response.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, _ := compressingServer(t, "identify_response.xml", "gzip", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+
+	var captured []byte
+	var status int
+	capture := ResponseHook(func(resp *http.Response, body []byte) error {
+		status = resp.StatusCode
+		captured = body
+		return nil
+	})
+
+	identify, err := NewClient(server.URL, WithMiddleware(capture)).Identify(context.Background())
+	if err != nil {
+		t.Fatalf("Identify failed: %v", err)
+	}
+	if identify.RepositoryName != "Example Repository" {
+		t.Errorf("Unexpected repository name %q", identify.RepositoryName)
+	}
+	if status != http.StatusOK || !bytes.Equal(captured, want) {
+		t.Errorf("Captured status %d and %d bytes, want the %d byte fixture", status, len(captured), len(want))
+	}
+
+	_, err = NewClient(server.URL, WithMiddleware(capture), WithMaxResponseSize(100)).Identify(context.Background())
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected limits to apply inside middleware, got %v", err)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "identify_response.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A caching middleware answers without calling the next Doer
+	cached := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"text/xml"}},
+				Body:       io.NopCloser(bytes.NewReader(data)),
+				Request:    req,
+			}, nil
+		})
+	}
+
+	identify, err := NewClient("http://repository.invalid/oai", WithMiddleware(cached)).Identify(context.Background())
+	if err != nil || identify.RepositoryName != "Example Repository" {
+		t.Errorf("Cached Identify failed: %v", err)
+	}
+}
@@ -0,0 +1,489 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MODS represents a Metadata Object Description Schema (MODS v3) record
+type MODS struct {
+	XMLName             xml.Name                  `xml:"http://www.loc.gov/mods/v3 mods"`
+	TitleInfo           []ModsTitleInfo           `xml:"titleInfo"`
+	Name                []ModsName                `xml:"name"`
+	TypeOfResource      []string                  `xml:"typeOfResource"`
+	Genre               []string                  `xml:"genre"`
+	OriginInfo          []ModsOriginInfo          `xml:"originInfo"`
+	Language            []ModsLanguage            `xml:"language"`
+	PhysicalDescription []ModsPhysicalDescription `xml:"physicalDescription"`
+	Abstract            []string                  `xml:"abstract"`
+	TableOfContents     []string                  `xml:"tableOfContents"`
+	Note                []string                  `xml:"note"`
+	Subject             []ModsSubject             `xml:"subject"`
+	Classification      []string                  `xml:"classification"`
+	Identifier          []ModsIdentifier          `xml:"identifier"`
+	Location            []ModsLocation            `xml:"location"`
+	AccessCondition     []string                  `xml:"accessCondition"`
+}
+
+// ModsTitleInfo represents a MODS titleInfo element
+type ModsTitleInfo struct {
+	Type       string `xml:"type,attr,omitempty"`
+	NonSort    string `xml:"nonSort"`
+	Title      string `xml:"title"`
+	SubTitle   string `xml:"subTitle"`
+	PartNumber string `xml:"partNumber"`
+	PartName   string `xml:"partName"`
+}
+
+// ModsName represents a MODS name element
+type ModsName struct {
+	Type        string         `xml:"type,attr,omitempty"`
+	NamePart    []ModsNamePart `xml:"namePart"`
+	DisplayForm string         `xml:"displayForm"`
+	Affiliation []string       `xml:"affiliation"`
+	Role        []ModsRole     `xml:"role"`
+}
+
+// ModsNamePart represents a MODS namePart element (given, family, date, termsOfAddress)
+type ModsNamePart struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// ModsRole represents a MODS role element
+type ModsRole struct {
+	RoleTerm []ModsTerm `xml:"roleTerm"`
+}
+
+// ModsTerm represents a MODS term with type and authority (roleTerm, placeTerm, languageTerm)
+type ModsTerm struct {
+	Type      string `xml:"type,attr,omitempty"`
+	Authority string `xml:"authority,attr,omitempty"`
+	Value     string `xml:",chardata"`
+}
+
+// ModsOriginInfo represents a MODS originInfo element
+type ModsOriginInfo struct {
+	Place       []ModsPlace `xml:"place"`
+	Publisher   []string    `xml:"publisher"`
+	DateIssued  []ModsDate  `xml:"dateIssued"`
+	DateCreated []ModsDate  `xml:"dateCreated"`
+	Edition     string      `xml:"edition"`
+	Issuance    string      `xml:"issuance"`
+}
+
+// ModsPlace represents a MODS place element
+type ModsPlace struct {
+	PlaceTerm []ModsTerm `xml:"placeTerm"`
+}
+
+// ModsDate represents a MODS date element
+type ModsDate struct {
+	Encoding string `xml:"encoding,attr,omitempty"`
+	KeyDate  string `xml:"keyDate,attr,omitempty"`
+	Point    string `xml:"point,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+// ModsLanguage represents a MODS language element
+type ModsLanguage struct {
+	LanguageTerm []ModsTerm `xml:"languageTerm"`
+}
+
+// ModsPhysicalDescription represents a MODS physicalDescription element
+type ModsPhysicalDescription struct {
+	Form              []string `xml:"form"`
+	Extent            []string `xml:"extent"`
+	InternetMediaType []string `xml:"internetMediaType"`
+}
+
+// ModsSubject represents a MODS subject element
+type ModsSubject struct {
+	Authority  string     `xml:"authority,attr,omitempty"`
+	Topic      []string   `xml:"topic"`
+	Geographic []string   `xml:"geographic"`
+	Temporal   []string   `xml:"temporal"`
+	Genre      []string   `xml:"genre"`
+	Name       []ModsName `xml:"name"`
+}
+
+// ModsIdentifier represents a MODS identifier element
+type ModsIdentifier struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// ModsLocation represents a MODS location element
+type ModsLocation struct {
+	URL              []ModsURL `xml:"url"`
+	PhysicalLocation []string  `xml:"physicalLocation"`
+	ShelfLocator     []string  `xml:"shelfLocator"`
+}
+
+// ModsURL represents a MODS location URL
+type ModsURL struct {
+	Usage        string `xml:"usage,attr,omitempty"`
+	Access       string `xml:"access,attr,omitempty"`
+	DisplayLabel string `xml:"displayLabel,attr,omitempty"`
+	Value        string `xml:",chardata"`
+}
+
+// MetadataMODS represents the metadata wrapper for MODS
+type MetadataMODS struct {
+	MODS *MODS  `xml:"http://www.loc.gov/mods/v3 mods,omitempty"`
+	Raw  []byte `xml:",innerxml"`
+}
+
+// RecordMODS represents an OAI-PMH record with MODS metadata
+type RecordMODS struct {
+	Header   Header       `xml:"header"`
+	Metadata MetadataMODS `xml:"metadata"`
+	About    *About       `xml:"about,omitempty"`
+}
+
+// IsDeleted returns true if the record has been deleted from the repository
+func (r RecordMODS) IsDeleted() bool {
+	return r.Header.IsDeleted()
+}
+
+// ListRecordsMODS contains the list of MODS records from ListRecords verb
+type ListRecordsMODS struct {
+	Records         []RecordMODS     `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// GetRecordMODS contains a single MODS record from GetRecord verb
+type GetRecordMODS struct {
+	Record RecordMODS `xml:"record"`
+}
+
+// OAIPMHResponseMODS represents the OAI-PMH response with MODS metadata
+type OAIPMHResponseMODS struct {
+	XMLName      xml.Name         `xml:"OAI-PMH"`
+	ResponseDate string           `xml:"responseDate"`
+	Request      OAIRequest       `xml:"request"`
+	ListRecords  *ListRecordsMODS `xml:"ListRecords,omitempty"`
+	GetRecord    *GetRecordMODS   `xml:"GetRecord,omitempty"`
+	Error        *OAIError        `xml:"error,omitempty"`
+}
+
+// ModsMetadata represents extracted MODS metadata
+type ModsMetadata struct {
+	Title             string           `json:"title"`
+	Subtitle          string           `json:"subtitle"`
+	AlternativeTitles []string         `json:"alternative_titles"`
+	Names             []ModsNameEntry  `json:"names"`
+	TypeOfResource    string           `json:"type_of_resource"`
+	Genre             []string         `json:"genre"`
+	Publisher         string           `json:"publisher"`
+	PublishPlace      string           `json:"publish_place"`
+	DateIssued        string           `json:"date_issued"`
+	Edition           string           `json:"edition"`
+	Language          []string         `json:"language"`
+	Extent            string           `json:"extent"`
+	Abstract          string           `json:"abstract"`
+	TableOfContents   string           `json:"table_of_contents"`
+	Notes             []string         `json:"notes"`
+	Subjects          []string         `json:"subjects"`
+	Classification    []string         `json:"classification"`
+	Identifiers       []ModsIdentifier `json:"identifiers"`
+	URLs              []string         `json:"urls"`
+	PhysicalLocation  string           `json:"physical_location"`
+	ShelfLocator      string           `json:"shelf_locator"`
+	AccessCondition   []string         `json:"access_condition"`
+}
+
+// ModsNameEntry is an extracted MODS name with its roles
+type ModsNameEntry struct {
+	Name  string   `json:"name"`
+	Type  string   `json:"type"`
+	Roles []string `json:"roles"`
+}
+
+// FullName assembles the name from displayForm or its name parts
+func (n ModsName) FullName() string {
+	if n.DisplayForm != "" {
+		return n.DisplayForm
+	}
+
+	var family, given string
+	var parts []string
+	for _, part := range n.NamePart {
+		switch part.Type {
+		case "family":
+			family = part.Value
+		case "given":
+			given = part.Value
+		case "":
+			parts = append(parts, part.Value)
+		}
+	}
+
+	if family != "" && given != "" {
+		return family + ", " + given
+	}
+	if family != "" {
+		return family
+	}
+	if given != "" {
+		return given
+	}
+	return strings.Join(parts, " ")
+}
+
+// ExtractModsMetadata extracts descriptive metadata from a MODS record
+func (m *MODS) ExtractModsMetadata() *ModsMetadata {
+	if m == nil {
+		return nil
+	}
+
+	metadata := &ModsMetadata{
+		AlternativeTitles: []string{},
+		Names:             []ModsNameEntry{},
+		Language:          []string{},
+		Notes:             deduplicate(m.Note),
+		Subjects:          []string{},
+		Genre:             deduplicate(m.Genre),
+		Classification:    deduplicate(m.Classification),
+		Identifiers:       m.Identifier,
+		URLs:              []string{},
+		AccessCondition:   deduplicate(m.AccessCondition),
+	}
+
+	// The first titleInfo without type is the main title
+	for _, title := range m.TitleInfo {
+		full := strings.TrimSpace(title.NonSort + title.Title)
+		if title.Type == "" && metadata.Title == "" {
+			metadata.Title = full
+			metadata.Subtitle = title.SubTitle
+			continue
+		}
+		if full != "" {
+			metadata.AlternativeTitles = append(metadata.AlternativeTitles, full)
+		}
+	}
+
+	for _, name := range m.Name {
+		entry := ModsNameEntry{Name: name.FullName(), Type: name.Type, Roles: []string{}}
+		for _, role := range name.Role {
+			for _, term := range role.RoleTerm {
+				if term.Type != "code" {
+					entry.Roles = append(entry.Roles, term.Value)
+				}
+			}
+		}
+		if entry.Name != "" {
+			metadata.Names = append(metadata.Names, entry)
+		}
+	}
+
+	if len(m.TypeOfResource) > 0 {
+		metadata.TypeOfResource = m.TypeOfResource[0]
+	}
+
+	for _, origin := range m.OriginInfo {
+		if metadata.Publisher == "" && len(origin.Publisher) > 0 {
+			metadata.Publisher = origin.Publisher[0]
+		}
+		for _, place := range origin.Place {
+			for _, term := range place.PlaceTerm {
+				if metadata.PublishPlace == "" && term.Type != "code" {
+					metadata.PublishPlace = term.Value
+				}
+			}
+		}
+		for _, date := range origin.DateIssued {
+			if metadata.DateIssued == "" || date.KeyDate == "yes" {
+				metadata.DateIssued = date.Value
+			}
+		}
+		if metadata.Edition == "" {
+			metadata.Edition = origin.Edition
+		}
+	}
+
+	for _, language := range m.Language {
+		for _, term := range language.LanguageTerm {
+			metadata.Language = append(metadata.Language, term.Value)
+		}
+	}
+	metadata.Language = deduplicate(metadata.Language)
+
+	for _, physical := range m.PhysicalDescription {
+		if metadata.Extent == "" && len(physical.Extent) > 0 {
+			metadata.Extent = physical.Extent[0]
+		}
+	}
+
+	if len(m.Abstract) > 0 {
+		metadata.Abstract = m.Abstract[0]
+	}
+	if len(m.TableOfContents) > 0 {
+		metadata.TableOfContents = m.TableOfContents[0]
+	}
+
+	// Subjects are assembled from their components as "Topic -- Geographic -- Temporal"
+	for _, subject := range m.Subject {
+		var parts []string
+		for _, name := range subject.Name {
+			parts = append(parts, name.FullName())
+		}
+		parts = append(parts, subject.Topic...)
+		parts = append(parts, subject.Geographic...)
+		parts = append(parts, subject.Temporal...)
+		parts = append(parts, subject.Genre...)
+		if len(parts) > 0 {
+			metadata.Subjects = append(metadata.Subjects, strings.Join(parts, " -- "))
+		}
+	}
+	metadata.Subjects = deduplicate(metadata.Subjects)
+
+	for _, location := range m.Location {
+		for _, url := range location.URL {
+			metadata.URLs = append(metadata.URLs, url.Value)
+		}
+		if metadata.PhysicalLocation == "" && len(location.PhysicalLocation) > 0 {
+			metadata.PhysicalLocation = location.PhysicalLocation[0]
+		}
+		if metadata.ShelfLocator == "" && len(location.ShelfLocator) > 0 {
+			metadata.ShelfLocator = location.ShelfLocator[0]
+		}
+	}
+	metadata.URLs = deduplicate(metadata.URLs)
+
+	return metadata
+}
+
+// ExtractAllModsMetadata extracts metadata from all MODS records in OAI-PMH response
+func (o *OAIPMHResponseMODS) ExtractAllModsMetadata() []*ModsMetadata {
+	var results []*ModsMetadata
+
+	for _, extractor := range o.GetRecords() {
+		if metadata := extractor.(*MODS).ExtractModsMetadata(); metadata != nil {
+			results = append(results, metadata)
+		}
+	}
+
+	return results
+}
+
+// ParseOAIMODSXML parses OAI-PMH XML data with MODS metadata from bytes
+func ParseOAIMODSXML(data []byte) (*OAIPMHResponseMODS, error) {
+	var oaiResp OAIPMHResponseMODS
+	if err := xml.Unmarshal(data, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, oaiResp.Error
+	}
+
+	return &oaiResp, nil
+}
+
+// decodeOAIPMHResponseMODS stream-decodes a MODS ListRecords or GetRecord response
+func decodeOAIPMHResponseMODS(r io.Reader, page decodeOptions) (*OAIPMHResponseMODS, error) {
+	env, records, err := decodeResponse(r, page, decodeRecord[RecordMODS])
+	if err != nil {
+		return nil, err
+	}
+
+	oaiResp := &OAIPMHResponseMODS{
+		XMLName:      xml.Name{Local: "OAI-PMH"},
+		ResponseDate: env.ResponseDate,
+		Request:      env.Request,
+	}
+
+	switch env.Verb {
+	case "ListRecords":
+		oaiResp.ListRecords = &ListRecordsMODS{Records: records, ResumptionToken: env.ResumptionToken}
+	case "GetRecord":
+		if len(records) > 0 {
+			oaiResp.GetRecord = &GetRecordMODS{Record: records[0]}
+		}
+	}
+
+	return oaiResp, nil
+}
+
+// Implement OAIResponse interface for OAIPMHResponseMODS
+
+// GetRecords returns all records in the response as MetadataExtractor interface
+func (o *OAIPMHResponseMODS) GetRecords() []MetadataExtractor {
+	var extractors []MetadataExtractor
+
+	for _, record := range o.harvestedRecords() {
+		if record.Metadata != nil {
+			extractors = append(extractors, record.Metadata)
+		}
+	}
+
+	return extractors
+}
+
+// GetResumptionToken returns the resumption token if available
+func (o *OAIPMHResponseMODS) GetResumptionToken() string {
+	if o.ListRecords != nil && o.ListRecords.ResumptionToken != nil {
+		return o.ListRecords.ResumptionToken.Token
+	}
+	return ""
+}
+
+// GetResumptionTokenInfo returns the full resumption token if available
+func (o *OAIPMHResponseMODS) GetResumptionTokenInfo() *ResumptionToken {
+	if o.ListRecords != nil {
+		return o.ListRecords.ResumptionToken
+	}
+	return nil
+}
+
+// HasError returns true if the response contains an error
+func (o *OAIPMHResponseMODS) HasError() bool {
+	return o.Error != nil
+}
+
+// GetError returns the error information
+func (o *OAIPMHResponseMODS) GetError() *OAIError {
+	return o.Error
+}
+
+// harvestedRecords returns all records in the response with their headers
+func (o *OAIPMHResponseMODS) harvestedRecords() []HarvestedRecord {
+	var records []RecordMODS
+	if o.ListRecords != nil {
+		records = o.ListRecords.Records
+	}
+	if o.GetRecord != nil {
+		records = append(records, o.GetRecord.Record)
+	}
+
+	results := make([]HarvestedRecord, 0, len(records))
+	for _, record := range records {
+		harvested := HarvestedRecord{Header: record.Header, Raw: record.Metadata.Raw, About: record.About}
+		if record.Metadata.MODS != nil {
+			harvested.Metadata = record.Metadata.MODS
+		}
+		results = append(results, harvested)
+	}
+
+	return results
+}
+
+// responseDate returns the responseDate of the response
+func (o *OAIPMHResponseMODS) responseDate() string {
+	return o.ResponseDate
+}
+
+// Implement MetadataExtractor interface for MODS
+
+// ExtractMetadata extracts metadata from MODS record
+func (m *MODS) ExtractMetadata() interface{} {
+	return m.ExtractModsMetadata()
+}
+
+// GetFormat returns the metadata format type
+func (m *MODS) GetFormat() MetadataFormat {
+	return FormatMODS
+}
@@ -0,0 +1,77 @@
+package goharvest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseOAIMODSXML(t *testing.T) {
+	data, err := os.ReadFile("testdata/listrecords_mods.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAIMODSXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIMODSXML failed: %v", err)
+	}
+
+	records := resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if records[0].GetFormat() != FormatMODS {
+		t.Errorf("Expected format mods, got %s", records[0].GetFormat())
+	}
+
+	metadata, ok := records[0].ExtractMetadata().(*ModsMetadata)
+	if !ok {
+		t.Fatalf("Expected *ModsMetadata, got %T", records[0].ExtractMetadata())
+	}
+
+	if metadata.Title != "The history of Yogyakarta" || metadata.Subtitle != "a short introduction" {
+		t.Errorf("Unexpected title '%s' / '%s'", metadata.Title, metadata.Subtitle)
+	}
+	if len(metadata.AlternativeTitles) != 1 || metadata.AlternativeTitles[0] != "Sejarah Yogyakarta" {
+		t.Errorf("Unexpected alternative titles %v", metadata.AlternativeTitles)
+	}
+	if len(metadata.Names) != 2 || metadata.Names[0].Name != "Santoso, Budi" || metadata.Names[0].Roles[0] != "author" {
+		t.Errorf("Unexpected names %+v", metadata.Names)
+	}
+	if metadata.Publisher != "Kejora" || metadata.PublishPlace != "Yogyakarta" || metadata.DateIssued != "2005-03" {
+		t.Errorf("Unexpected origin info: %s, %s, %s", metadata.Publisher, metadata.PublishPlace, metadata.DateIssued)
+	}
+	if len(metadata.Subjects) != 1 || metadata.Subjects[0] != "History -- Yogyakarta (Indonesia)" {
+		t.Errorf("Unexpected subjects %v", metadata.Subjects)
+	}
+	if len(metadata.Identifiers) != 1 || metadata.Identifiers[0].Type != "isbn" {
+		t.Errorf("Unexpected identifiers %+v", metadata.Identifiers)
+	}
+	if len(metadata.URLs) != 1 || metadata.ShelfLocator != "959.8 SAN h" {
+		t.Errorf("Unexpected location %v / %s", metadata.URLs, metadata.ShelfLocator)
+	}
+	if len(metadata.Language) != 1 || metadata.Language[0] != "ind" {
+		t.Errorf("Unexpected language %v", metadata.Language)
+	}
+}
+
+func TestUnifiedHarvestMODS(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_mods.xml"})
+	client := NewClient(server.URL)
+
+	count := 0
+	err := client.Harvest("mods", nil, func(response OAIResponse) error {
+		for _, record := range response.GetRecords() {
+			if _, ok := record.ExtractMetadata().(*ModsMetadata); ok {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 MODS record, got %d", count)
+	}
+}
@@ -2,7 +2,9 @@ package goharvest
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 )
 
 // DublinCore represents Dublin Core metadata
@@ -39,6 +41,11 @@ type RecordDC struct {
 	About    *About     `xml:"about,omitempty"`
 }
 
+// IsDeleted returns true if the record has been deleted from the repository
+func (r RecordDC) IsDeleted() bool {
+	return r.Header.IsDeleted()
+}
+
 // ListRecordsDC contains the list of Dublin Core records from ListRecords verb
 type ListRecordsDC struct {
 	Records         []RecordDC       `xml:"record"`
@@ -171,6 +178,9 @@ func (c *OAIClient) HarvestAllDC(metadataPrefix string, callback func(*OAIPMHRes
 		}
 
 		if err := callback(dcResp); err != nil {
+			if errors.Is(err, ErrStopHarvest) {
+				return nil
+			}
 			return fmt.Errorf("callback error: %w", err)
 		}
 
@@ -193,12 +203,37 @@ func ParseOAIDCXML(data []byte) (*OAIPMHResponseDC, error) {
 	}
 
 	if oaiResp.Error != nil {
-		return nil, fmt.Errorf("OAI-PMH error [%s]: %s", oaiResp.Error.Code, oaiResp.Error.Message)
+		return nil, oaiResp.Error
 	}
 
 	return &oaiResp, nil
 }
 
+// decodeOAIPMHResponseDC stream-decodes a Dublin Core ListRecords or GetRecord response
+func decodeOAIPMHResponseDC(r io.Reader, page decodeOptions) (*OAIPMHResponseDC, error) {
+	env, records, err := decodeResponse(r, page, decodeRecord[RecordDC])
+	if err != nil {
+		return nil, err
+	}
+
+	oaiResp := &OAIPMHResponseDC{
+		XMLName:      xml.Name{Local: "OAI-PMH"},
+		ResponseDate: env.ResponseDate,
+		Request:      env.Request,
+	}
+
+	switch env.Verb {
+	case "ListRecords":
+		oaiResp.ListRecords = &ListRecordsDC{Records: records, ResumptionToken: env.ResumptionToken}
+	case "GetRecord":
+		if len(records) > 0 {
+			oaiResp.GetRecord = &GetRecordDC{Record: records[0]}
+		}
+	}
+
+	return oaiResp, nil
+}
+
 // Implement OAIResponse interface for OAIPMHResponseDC
 
 // GetRecords returns all records in the response as MetadataExtractor interface
@@ -230,6 +265,14 @@ func (o *OAIPMHResponseDC) GetResumptionToken() string {
 	return ""
 }
 
+// GetResumptionTokenInfo returns the full resumption token if available
+func (o *OAIPMHResponseDC) GetResumptionTokenInfo() *ResumptionToken {
+	if o.ListRecords != nil {
+		return o.ListRecords.ResumptionToken
+	}
+	return nil
+}
+
 // HasError returns true if the response contains an error
 func (o *OAIPMHResponseDC) HasError() bool {
 	return o.Error != nil
@@ -240,6 +283,33 @@ func (o *OAIPMHResponseDC) GetError() *OAIError {
 	return o.Error
 }
 
+// harvestedRecords returns all records in the response with their headers
+func (o *OAIPMHResponseDC) harvestedRecords() []HarvestedRecord {
+	var records []RecordDC
+	if o.ListRecords != nil {
+		records = o.ListRecords.Records
+	}
+	if o.GetRecord != nil {
+		records = append(records, o.GetRecord.Record)
+	}
+
+	results := make([]HarvestedRecord, 0, len(records))
+	for _, record := range records {
+		harvested := HarvestedRecord{Header: record.Header, Raw: record.Metadata.Raw, About: record.About}
+		if record.Metadata.DC != nil {
+			harvested.Metadata = record.Metadata.DC
+		}
+		results = append(results, harvested)
+	}
+
+	return results
+}
+
+// responseDate returns the responseDate of the response
+func (o *OAIPMHResponseDC) responseDate() string {
+	return o.ResponseDate
+}
+
 // Implement MetadataExtractor interface for DublinCore
 
 // ExtractMetadata extracts metadata from Dublin Core record
@@ -10,10 +10,6 @@ import (
 func testHarvestHelper(t *testing.T, oaiURL, sourceName string, recordIndex int, stopAfterFirst bool) {
 	client := NewClient(oaiURL)
 	totalRecords := 0
-	var stopError error
-	if stopAfterFirst {
-		stopError = fmt.Errorf("stop harvesting for testing")
-	}
 
 	err := client.HarvestAllDC("oai_dc", func(resp *OAIPMHResponseDC) error {
 		metadata := resp.ExtractAllDCMetadata()
@@ -31,19 +27,13 @@ func testHarvestHelper(t *testing.T, oaiURL, sourceName string, recordIndex int,
 		totalRecords += len(metadata)
 		fmt.Printf("Processed %d records from %s...\n", totalRecords, sourceName)
 
-		if stopError != nil {
-			return stopError
+		if stopAfterFirst {
+			return ErrStopHarvest
 		}
 		return nil
 	})
 
-	// Check error
-	if stopError != nil && err != nil {
-		expectedErr := fmt.Sprintf("callback error: %s", stopError.Error())
-		if err.Error() != expectedErr {
-			t.Fatalf("Error harvesting: %v", err)
-		}
-	} else if err != nil {
+	if err != nil {
 		t.Fatalf("Error harvesting: %v", err)
 	}
 
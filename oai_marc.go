@@ -0,0 +1,123 @@
+package goharvest
+
+import (
+	"strings"
+)
+
+// OAIMARCRecord represents a record in the legacy oai_marc schema
+// (http://www.openarchives.org/OAI/1.1/oai_marc) still served by some Koha and Voyager endpoints
+type OAIMARCRecord struct {
+	Status    string            `xml:"status,attr,omitempty"`
+	Type      string            `xml:"type,attr,omitempty"`
+	Level     string            `xml:"level,attr,omitempty"`
+	CtlType   string            `xml:"ctlType,attr,omitempty"`
+	CharEnc   string            `xml:"charEnc,attr,omitempty"`
+	EncLvl    string            `xml:"encLvl,attr,omitempty"`
+	CatForm   string            `xml:"catForm,attr,omitempty"`
+	LrRqrd    string            `xml:"lrRqrd,attr,omitempty"`
+	FixFields []OAIMARCFixField `xml:"fixfield"`
+	VarFields []OAIMARCVarField `xml:"varfield"`
+}
+
+// OAIMARCFixField represents an oai_marc fixed (control) field
+type OAIMARCFixField struct {
+	ID    string `xml:"id,attr"`
+	Value string `xml:",chardata"`
+}
+
+// OAIMARCVarField represents an oai_marc variable (data) field
+type OAIMARCVarField struct {
+	ID        string            `xml:"id,attr"`
+	I1        string            `xml:"i1,attr"`
+	I2        string            `xml:"i2,attr"`
+	Subfields []OAIMARCSubfield `xml:"subfield"`
+}
+
+// OAIMARCSubfield represents an oai_marc subfield
+type OAIMARCSubfield struct {
+	Label string `xml:"label,attr"`
+	Value string `xml:",chardata"`
+}
+
+// MARCRecord converts the oai_marc markup to a MARCXML record so it can use the MARC extraction path
+func (o *OAIMARCRecord) MARCRecord() *MARCRecord {
+	if o == nil {
+		return nil
+	}
+
+	record := &MARCRecord{Leader: o.leader()}
+
+	for _, field := range o.FixFields {
+		value := strings.TrimSpace(field.Value)
+		// oai_marc wraps fixed field values in double quotes
+		if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+			value = value[1 : len(value)-1]
+		}
+		record.ControlFields = append(record.ControlFields, ControlField{Tag: padTag(field.ID), Value: value})
+	}
+
+	for _, field := range o.VarFields {
+		dataField := DataField{Tag: padTag(field.ID), Ind1: indicator(field.I1), Ind2: indicator(field.I2)}
+		for _, subfield := range field.Subfields {
+			dataField.Subfields = append(dataField.Subfields, Subfield{Code: subfield.Label, Value: subfield.Value})
+		}
+		record.DataFields = append(record.DataFields, dataField)
+	}
+
+	return record
+}
+
+// leader assembles a MARC 21 leader from the oai_marc root attributes
+func (o *OAIMARCRecord) leader() string {
+	leader := []byte("00000     2200000   4500")
+	set := func(pos int, value string) {
+		if value != "" {
+			leader[pos] = value[0]
+		}
+	}
+	set(5, o.Status)
+	set(6, o.Type)
+	set(7, o.Level)
+	set(8, o.CtlType)
+	set(9, o.CharEnc)
+	set(17, o.EncLvl)
+	set(18, o.CatForm)
+	set(19, o.LrRqrd)
+	return string(leader)
+}
+
+// padTag left-pads numeric tags such as "8" or "20" to three digits
+func padTag(tag string) string {
+	tag = strings.TrimSpace(tag)
+	for len(tag) < 3 {
+		tag = "0" + tag
+	}
+	return tag
+}
+
+// indicator normalizes an empty indicator to a blank
+func indicator(value string) string {
+	if value == "" {
+		return " "
+	}
+	return value
+}
+
+// normalizeOAIMARC converts legacy oai_marc metadata in place so MARCXML is always populated
+func (m *Metadata) normalizeOAIMARC() {
+	if m.MARCXML == nil && m.OAIMARC != nil {
+		m.MARCXML = m.OAIMARC.MARCRecord()
+	}
+}
+
+// normalizeOAIMARC converts legacy oai_marc metadata in every record of the response
+func (o *OAIPMHResponse) normalizeOAIMARC() {
+	if o.ListRecords != nil {
+		for i := range o.ListRecords.Records {
+			o.ListRecords.Records[i].Metadata.normalizeOAIMARC()
+		}
+	}
+	if o.GetRecord != nil {
+		o.GetRecord.Record.Metadata.normalizeOAIMARC()
+	}
+}
@@ -0,0 +1,69 @@
+package goharvest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseOAIMARCLegacy(t *testing.T) {
+	data, err := os.ReadFile("testdata/listrecords_oai_marc.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAIPMHXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIPMHXML failed: %v", err)
+	}
+
+	records := resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+
+	record := resp.ListRecords.Records[0].Metadata.MARCXML
+	if record.Leader != "00000cam  22000007a 4500" {
+		t.Errorf("Unexpected leader %q", record.Leader)
+	}
+	if record.GetControlFieldValue("001") != "KOHA-90" {
+		t.Errorf("Expected unquoted, padded 001, got %q", record.GetControlFieldValue("001"))
+	}
+	if fields := record.GetAllSubfields("100"); len(fields) != 1 || fields[0].Ind2 != " " {
+		t.Errorf("Expected blank indicator for empty i2, got %+v", fields)
+	}
+
+	book := record.ExtractBookMetadata()
+	if book.Title != "Bumi manusia" || book.ISBN != "9789791234567" || book.Publisher != "Hasta Mitra" {
+		t.Errorf("Unexpected book metadata %+v", book)
+	}
+}
+
+func TestUnifiedHarvestMARCPrefixes(t *testing.T) {
+	fixtures := map[string]string{
+		"marc21":   "getrecord_marcxml.xml",
+		"oai_marc": "listrecords_oai_marc.xml",
+	}
+
+	for prefix, fixture := range fixtures {
+		server := newFixtureServer(t, map[string]string{"ListRecords": fixture})
+
+		count := 0
+		err := NewClient(server.URL).Harvest(prefix, nil, func(response OAIResponse) error {
+			for _, record := range response.GetRecords() {
+				if book, ok := record.ExtractMetadata().(*BookMetadata); ok && book.Title != "" {
+					count++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Harvest %s failed: %v", prefix, err)
+		}
+		if count != 1 {
+			t.Errorf("Expected 1 %s record, got %d", prefix, count)
+		}
+		if queries := server.Queries(); len(queries) != 1 || queries[0].Get("metadataPrefix") != prefix {
+			t.Errorf("Expected metadataPrefix %s to be sent, got %v", prefix, queries)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package goharvest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"time"
+)
+
+// ClientOption configures an OAIClient created by NewClient
+type ClientOption func(*OAIClient)
+
+// ErrInvalidOption is returned by every request of a client created with options that
+// cannot take effect, such as WithProxy combined with a custom RoundTripper
+var ErrInvalidOption = errors.New("invalid client option")
+
+// WithHTTPClient sets the HTTP client used for all requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *OAIClient) {
+		c.HTTPClient = httpClient
+	}
+}
+
+// httpOption returns an option configuring the client's HTTP client. NewClient applies
+// these after all other options, to a copy of the client, so that a client passed to
+// WithHTTPClient is never modified and the order of the options does not matter.
+func httpOption(apply func(c *OAIClient)) ClientOption {
+	return func(c *OAIClient) {
+		c.httpOptions = append(c.httpOptions, apply)
+	}
+}
+
+// WithTimeout sets the timeout of the client's HTTP client
+func WithTimeout(timeout time.Duration) ClientOption {
+	return httpOption(func(c *OAIClient) {
+		c.HTTPClient.Timeout = timeout
+	})
+}
+
+// WithCookieJar keeps cookies across requests, for repositories that tie resumption
+// tokens to a session cookie. A nil jar creates an in-memory one.
+func WithCookieJar(jar http.CookieJar) ClientOption {
+	return httpOption(func(c *OAIClient) {
+		if jar == nil {
+			// cookiejar.New only fails for invalid options
+			jar, _ = cookiejar.New(nil)
+		}
+		c.HTTPClient.Jar = jar
+	})
+}
+
+// WithTransport sets the RoundTripper of the client's HTTP client, e.g. an instrumented
+// or SOCKS-tunnelling transport
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return httpOption(func(c *OAIClient) {
+		c.HTTPClient.Transport = transport
+	})
+}
+
+// WithProxy sends requests through an HTTP, HTTPS or SOCKS5 proxy, such as
+// http://proxy.example.org:3128 or socks5://localhost:1080. A nil URL disables proxying,
+// including proxies from the environment.
+func WithProxy(proxyURL *url.URL) ClientOption {
+	return httpOption(func(c *OAIClient) {
+		transport := c.httpTransport("WithProxy")
+		if proxyURL == nil {
+			transport.Proxy = nil
+		} else {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	})
+}
+
+// WithRootCAs sets the certificate authorities trusted for repository certificates,
+// e.g. a campus CA. To trust it in addition to the system roots, start from
+// x509.SystemCertPool and append to it.
+func WithRootCAs(pool *x509.CertPool) ClientOption {
+	return httpOption(func(c *OAIClient) {
+		c.tlsConfig("WithRootCAs").RootCAs = pool
+	})
+}
+
+// WithClientCertificate presents a client certificate for mutual TLS, as loaded with
+// tls.LoadX509KeyPair
+func WithClientCertificate(cert tls.Certificate) ClientOption {
+	return httpOption(func(c *OAIClient) {
+		config := c.tlsConfig("WithClientCertificate")
+		config.Certificates = append(config.Certificates, cert)
+	})
+}
+
+// WithMinTLSVersion sets the minimum TLS version, e.g. tls.VersionTLS13
+func WithMinTLSVersion(version uint16) ClientOption {
+	return httpOption(func(c *OAIClient) {
+		c.tlsConfig("WithMinTLSVersion").MinVersion = version
+	})
+}
+
+// WithInsecureSkipVerify disables verification of repository certificates. Connections
+// are then open to interception; prefer WithRootCAs for private CAs.
+func WithInsecureSkipVerify() ClientOption {
+	return httpOption(func(c *OAIClient) {
+		c.tlsConfig("WithInsecureSkipVerify").InsecureSkipVerify = true
+	})
+}
+
+// tlsConfig returns the TLS configuration of the client's transport for an option
+func (c *OAIClient) tlsConfig(option string) *tls.Config {
+	transport := c.httpTransport(option)
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	return transport.TLSClientConfig
+}
+
+// httpTransport returns an *http.Transport owned by the client for options to configure.
+// A transport shared with other clients, including http.DefaultTransport, is cloned first.
+// If a custom RoundTripper is in use the option could not take effect; the client then
+// fails its requests with ErrInvalidOption and the option configures a discarded transport.
+func (c *OAIClient) httpTransport(option string) *http.Transport {
+	if c.ownTransport != nil && c.HTTPClient.Transport == c.ownTransport {
+		return c.ownTransport
+	}
+
+	var transport *http.Transport
+	switch rt := c.HTTPClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = rt.Clone()
+	default:
+		if c.optionErr == nil {
+			c.optionErr = fmt.Errorf("%w: %s needs an *http.Transport, not %T; configure the custom transport instead", ErrInvalidOption, option, rt)
+		}
+		return &http.Transport{}
+	}
+
+	c.HTTPClient.Transport = transport
+	c.ownTransport = transport
+	return transport
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *OAIClient) {
+		c.UserAgent = userAgent
+	}
+}
+
+// WithHeader adds a header sent with every request
+func WithHeader(key, value string) ClientOption {
+	return func(c *OAIClient) {
+		c.Header.Add(key, value)
+	}
+}
+
+// WithBasicAuth authenticates every request with HTTP Basic authentication
+func WithBasicAuth(username, password string) ClientOption {
+	return func(c *OAIClient) {
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		c.Header.Set("Authorization", "Basic "+credentials)
+	}
+}
+
+// WithBearerToken authenticates every request with a static bearer token. API keys sent
+// in a custom header, such as X-Api-Key, are set with WithHeader.
+func WithBearerToken(token string) ClientOption {
+	return func(c *OAIClient) {
+		c.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithPOST sends requests as application/x-www-form-urlencoded POST requests, which
+// OAI-PMH allows. Use it for long resumption tokens that proxies truncate and for
+// repositories that only accept POST.
+func WithPOST() ClientOption {
+	return func(c *OAIClient) {
+		c.UsePOST = true
+	}
+}
+
+// WithScrubInvalidChars removes characters that XML does not allow from responses
+// before they are decoded
+func WithScrubInvalidChars() ClientOption {
+	return func(c *OAIClient) {
+		c.ScrubInvalidChars = true
+	}
+}
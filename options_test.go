@@ -0,0 +1,277 @@
+package goharvest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClientOptions(t *testing.T) {
+	var gotHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		w.Write([]byte(`<OAI-PMH><Identify><repositoryName>Test</repositoryName></Identify></OAI-PMH>`))
+	}))
+	defer server.Close()
+
+	// HTTP options apply to a copy of the custom client, whatever their order
+	jar, _ := cookiejar.New(nil)
+	httpClient := &http.Client{Jar: jar}
+	client := NewClient(server.URL,
+		WithTimeout(2*time.Minute),
+		WithHTTPClient(httpClient),
+		WithUserAgent("MyLibraryHarvester/1.0 (mailto:admin@example.org)"),
+		WithHeader("X-Harvest-Job", "nightly"),
+	)
+
+	if client.HTTPClient == httpClient || client.HTTPClient.Jar != jar {
+		t.Error("Expected a copy of the custom HTTP client to be used")
+	}
+	if client.HTTPClient.Timeout != 2*time.Minute {
+		t.Errorf("Expected timeout 2m, got %s", client.HTTPClient.Timeout)
+	}
+	if httpClient.Timeout != 0 {
+		t.Errorf("Expected the custom HTTP client to be unchanged, got timeout %s", httpClient.Timeout)
+	}
+
+	if _, err := client.Identify(context.Background()); err != nil {
+		t.Fatalf("Identify failed: %v", err)
+	}
+
+	if ua := gotHeader.Get("User-Agent"); ua != "MyLibraryHarvester/1.0 (mailto:admin@example.org)" {
+		t.Errorf("Unexpected User-Agent '%s'", ua)
+	}
+	if job := gotHeader.Get("X-Harvest-Job"); job != "nightly" {
+		t.Errorf("Unexpected X-Harvest-Job '%s'", job)
+	}
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	client := NewClient("http://example.org/oai")
+
+	if client.HTTPClient.Timeout != 30*time.Second {
+		t.Errorf("Expected default timeout 30s, got %s", client.HTTPClient.Timeout)
+	}
+	if client.UserAgent != "" {
+		t.Errorf("Expected empty default User-Agent, got '%s'", client.UserAgent)
+	}
+}
+
+func TestAuthenticationOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, basic := r.BasicAuth()
+		authorized := (basic && user == "harvester" && password == "s3cret:pass") ||
+			r.Header.Get("Authorization") == "Bearer token-123" ||
+			r.Header.Get("X-Api-Key") == "key-456"
+		if !authorized {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		http.ServeFile(w, r, "testdata/identify_response.xml")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		opts       []ClientOption
+		authorized bool
+	}{
+		{"anonymous", nil, false},
+		{"basic", []ClientOption{WithBasicAuth("harvester", "s3cret:pass")}, true},
+		{"wrong password", []ClientOption{WithBasicAuth("harvester", "guess")}, false},
+		{"bearer", []ClientOption{WithBearerToken("token-123")}, true},
+		{"api key", []ClientOption{WithHeader("X-Api-Key", "key-456")}, true},
+	}
+
+	for _, tt := range tests {
+		_, err := NewClient(server.URL, tt.opts...).Identify(context.Background())
+		if tt.authorized && err != nil {
+			t.Errorf("%s: Identify failed: %v", tt.name, err)
+		}
+		if !tt.authorized && err == nil {
+			t.Errorf("%s: expected Identify to be rejected", tt.name)
+		}
+	}
+}
+
+func TestCookieJar(t *testing.T) {
+	fixtures := map[string]string{
+		"":          "testdata/listrecords_dc_page1.xml",
+		"dc-page-2": "testdata/listrecords_dc_page2.xml",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("resumptionToken")
+		if token == "" {
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "session-1", Path: "/"})
+		} else if cookie, err := r.Cookie("JSESSIONID"); err != nil || cookie.Value != "session-1" {
+			w.Header().Set("Content-Type", "text/xml")
+			w.Write([]byte(`<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/"><responseDate>2025-10-02T10:05:19Z</responseDate><request>http://example.org/oai</request><error code="badResumptionToken">session expired</error></OAI-PMH>`))
+			return
+		}
+		http.ServeFile(w, r, fixtures[token])
+	}))
+	defer server.Close()
+
+	harvest := func(client *OAIClient) error {
+		return client.HarvestWithOptions("oai_dc", nil, func(OAIResponse) error { return nil })
+	}
+
+	if err := harvest(NewClient(server.URL)); !errors.Is(err, ErrBadResumptionToken) {
+		t.Errorf("Expected badResumptionToken without cookies, got %v", err)
+	}
+	if err := harvest(NewClient(server.URL, WithCookieJar(nil))); err != nil {
+		t.Errorf("Harvest with cookie jar failed: %v", err)
+	}
+}
+
+// countingTransport counts the requests passed to the wrapped transport
+type countingTransport struct {
+	next     http.RoundTripper
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests++
+	return t.next.RoundTrip(r)
+}
+
+func TestTransportOptions(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		http.ServeFile(w, r, "testdata/identify_response.xml")
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient("http://repository.invalid/oai", WithProxy(proxyURL))
+	if _, err := client.Identify(context.Background()); err != nil {
+		t.Fatalf("Identify through proxy failed: %v", err)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://repository.invalid/oai?verb=Identify" {
+		t.Errorf("Unexpected proxied requests %v", proxied)
+	}
+	if client.HTTPClient.Transport == http.DefaultTransport {
+		t.Error("Expected the default transport to be cloned, not modified")
+	}
+	shared := &http.Client{}
+	NewClient(proxy.URL, WithProxy(proxyURL), WithHTTPClient(shared))
+	if shared.Transport != nil {
+		t.Error("Expected the client passed to WithHTTPClient to be left unchanged")
+	}
+
+	counting := &countingTransport{next: http.DefaultTransport}
+	client = NewClient(proxy.URL, WithTransport(counting))
+	if _, err := client.Identify(context.Background()); err != nil {
+		t.Fatalf("Identify with custom transport failed: %v", err)
+	}
+	if counting.requests != 1 {
+		t.Errorf("Expected 1 request through the custom transport, got %d", counting.requests)
+	}
+
+	client = NewClient(proxy.URL, WithTransport(counting), WithProxy(proxyURL))
+	if _, err := client.Identify(context.Background()); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for WithProxy with a custom transport, got %v", err)
+	}
+	if client.HTTPClient.Transport != counting || counting.requests != 1 {
+		t.Error("Expected the custom transport to be kept and unused")
+	}
+}
+
+// newClientCertificate creates a self-signed certificate for mutual TLS tests
+func newClientCertificate(t *testing.T) (tls.Certificate, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "harvester"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}, cert
+}
+
+func TestTLSOptions(t *testing.T) {
+	clientCert, clientCA := newClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/mtls" && len(r.TLS.VerifiedChains) == 0 {
+			http.Error(w, "client certificate required", http.StatusForbidden)
+			return
+		}
+		http.ServeFile(w, r, "testdata/identify_response.xml")
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		ClientCAs:  clientCAs,
+		MaxVersion: tls.VersionTLS12,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCAs := x509.NewCertPool()
+	serverCAs.AddCert(server.Certificate())
+
+	tests := []struct {
+		name string
+		opts []ClientOption
+		ok   bool
+	}{
+		{"system roots", nil, false},
+		{"custom CA", []ClientOption{WithRootCAs(serverCAs)}, true},
+		{"insecure", []ClientOption{WithInsecureSkipVerify()}, true},
+		{"mutual TLS", []ClientOption{WithRootCAs(serverCAs), WithClientCertificate(clientCert)}, true},
+		{"minimum version", []ClientOption{WithRootCAs(serverCAs), WithMinTLSVersion(tls.VersionTLS13)}, false},
+	}
+
+	for _, tt := range tests {
+		_, err := NewClient(server.URL, tt.opts...).Identify(context.Background())
+		if tt.ok && err != nil {
+			t.Errorf("%s: Identify failed: %v", tt.name, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("%s: expected the TLS handshake to fail", tt.name)
+		}
+	}
+
+	// The /mtls endpoint rejects clients without a verified certificate
+	if _, err := NewClient(server.URL+"/mtls", WithRootCAs(serverCAs)).Identify(context.Background()); err == nil {
+		t.Error("Expected /mtls to require a client certificate")
+	}
+	if _, err := NewClient(server.URL+"/mtls", WithRootCAs(serverCAs), WithClientCertificate(clientCert)).Identify(context.Background()); err != nil {
+		t.Errorf("Mutual TLS failed: %v", err)
+	}
+}
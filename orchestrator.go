@@ -0,0 +1,191 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultOrchestratorWorkers is the number of endpoints an Orchestrator harvests at once
+// unless Workers is set
+const DefaultOrchestratorWorkers = 4
+
+// Endpoint is a repository harvested by an Orchestrator
+type Endpoint struct {
+	// Name identifies the endpoint in results and callbacks (default BaseURL)
+	Name           string
+	BaseURL        string
+	MetadataPrefix string
+	// ClientOptions configure the endpoint's client, after the orchestrator's ClientOptions
+	ClientOptions []ClientOption
+	// Options are the harvest options; Stats is set by the orchestrator
+	Options HarvestOptions
+}
+
+// name returns the name of the endpoint
+func (e Endpoint) name() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return e.BaseURL
+}
+
+// EndpointResult reports the harvest of one endpoint
+type EndpointResult struct {
+	Endpoint string
+	Stats    HarvestStats
+	// Err is nil if the endpoint was harvested completely or stopped with ErrStopHarvest
+	Err error
+}
+
+// EndpointRecordCallback receives the records of all endpoints of an Orchestrator
+type EndpointRecordCallback func(endpoint string, record HarvestedRecord) error
+
+// Orchestrator harvests many repositories concurrently, e.g. for a union catalog, and
+// merges their records into one callback
+type Orchestrator struct {
+	Endpoints []Endpoint
+	// Workers is the number of endpoints harvested at once (default DefaultOrchestratorWorkers)
+	Workers int
+	// ClientOptions configure the clients of all endpoints
+	ClientOptions []ClientOption
+	// RateLimiter spaces the requests to each host across all endpoints (nil for no limit)
+	RateLimiter *HostRateLimiter
+}
+
+// NewOrchestrator creates an Orchestrator for endpoints with DefaultOrchestratorWorkers
+func NewOrchestrator(endpoints ...Endpoint) *Orchestrator {
+	return &Orchestrator{Endpoints: endpoints, Workers: DefaultOrchestratorWorkers}
+}
+
+// Harvest harvests all endpoints and invokes callback once per record. Calls to callback
+// are serialized, so it can write to a sink that is not safe for concurrent use. An error
+// from callback ends the harvest of that endpoint only; cancel ctx to end all of them.
+// The results are in the order of Endpoints, and the returned error joins the errors of
+// the endpoints that failed.
+func (o *Orchestrator) Harvest(ctx context.Context, callback EndpointRecordCallback) ([]EndpointResult, error) {
+	workers := o.Workers
+	if workers <= 0 {
+		workers = DefaultOrchestratorWorkers
+	}
+
+	results := make([]EndpointResult, len(o.Endpoints))
+	slots := make(chan struct{}, workers)
+	var callbackMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, endpoint := range o.Endpoints {
+		results[i].Endpoint = endpoint.name()
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			results[i].Stats, results[i].Err = o.harvestEndpoint(ctx, endpoint, func(record HarvestedRecord) error {
+				callbackMu.Lock()
+				defer callbackMu.Unlock()
+				return callback(results[i].Endpoint, record)
+			})
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("endpoint %s: %w", result.Endpoint, result.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// harvestEndpoint harvests the records of one endpoint
+func (o *Orchestrator) harvestEndpoint(ctx context.Context, endpoint Endpoint, callback RecordCallback) (HarvestStats, error) {
+	if endpoint.BaseURL == "" || endpoint.MetadataPrefix == "" {
+		return HarvestStats{}, errors.New("endpoint needs a base URL and metadata prefix")
+	}
+
+	options := append(append([]ClientOption(nil), o.ClientOptions...), endpoint.ClientOptions...)
+	if o.RateLimiter != nil {
+		// Innermost of the middleware, so that only requests actually sent are limited
+		options = append(options, WithMiddleware(o.RateLimiter.Middleware()))
+	}
+	client := NewClient(endpoint.BaseURL, options...)
+
+	var stats HarvestStats
+	opts := endpoint.Options
+	opts.Stats = &stats
+	err := client.harvestRecords(ctx, endpoint.MetadataPrefix, &opts, callback)
+	return stats, err
+}
+
+// HostRateLimiter spaces the requests sent to each host. It is safe for concurrent use and
+// can be shared by several clients through its Middleware.
+type HostRateLimiter struct {
+	// Interval is the minimum time between the starts of two requests to the same host
+	Interval time.Duration
+	// Intervals overrides Interval for some hosts, keyed by host (with port, if any)
+	Intervals map[string]time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// NewHostRateLimiter creates a HostRateLimiter allowing one request per interval to each host
+func NewHostRateLimiter(interval time.Duration) *HostRateLimiter {
+	return &HostRateLimiter{Interval: interval}
+}
+
+// Wait blocks until a request to host may be sent, or ctx is done
+func (l *HostRateLimiter) Wait(ctx context.Context, host string) error {
+	interval := l.Interval
+	if override, ok := l.Intervals[host]; ok {
+		interval = override
+	}
+	if interval <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	if l.next == nil {
+		l.next = make(map[string]time.Time)
+	}
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Middleware returns middleware that waits for the limiter before each request
+func (l *HostRateLimiter) Middleware() Middleware {
+	return RequestHook(func(req *http.Request) error {
+		return l.Wait(req.Context(), req.URL.Host)
+	})
+}
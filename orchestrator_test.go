@@ -0,0 +1,134 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestOrchestrator(t *testing.T) {
+	pages := map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	}
+	first := newFixtureServer(t, pages)
+	second := newFixtureServer(t, pages)
+	broken := newFixtureServer(t, map[string]string{})
+
+	orchestrator := NewOrchestrator(
+		Endpoint{Name: "first", BaseURL: first.URL, MetadataPrefix: "oai_dc"},
+		Endpoint{BaseURL: broken.URL, MetadataPrefix: "oai_dc"},
+		Endpoint{Name: "second", BaseURL: second.URL, MetadataPrefix: "oai_dc", Options: HarvestOptions{SkipDeleted: true}},
+	)
+	orchestrator.Workers = 2
+
+	// Not synchronized: the orchestrator serializes the callback
+	counts := map[string]int{}
+	results, err := orchestrator.Harvest(context.Background(), func(endpoint string, record HarvestedRecord) error {
+		counts[endpoint]++
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected the broken endpoint to be reported")
+	}
+
+	if counts["first"] != 4 || counts["second"] != 3 || len(counts) != 2 {
+		t.Errorf("Unexpected records per endpoint %v", counts)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if results[0].Endpoint != "first" || results[0].Err != nil || results[0].Stats.Pages != 2 || results[0].Stats.Records != 4 {
+		t.Errorf("Unexpected result %+v", results[0])
+	}
+	if results[1].Endpoint != broken.URL || results[1].Err == nil {
+		t.Errorf("Expected the broken endpoint to fail, got %+v", results[1])
+	}
+	if results[2].Err != nil || results[2].Stats.DeletedRecords != 1 {
+		t.Errorf("Unexpected result %+v", results[2])
+	}
+}
+
+func TestOrchestratorCallbackError(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	failure := errors.New("sink full")
+
+	orchestrator := NewOrchestrator(
+		Endpoint{Name: "failing", BaseURL: server.URL, MetadataPrefix: "oai_dc"},
+		Endpoint{Name: "stopped", BaseURL: server.URL, MetadataPrefix: "oai_dc"},
+	)
+	results, err := orchestrator.Harvest(context.Background(), func(endpoint string, record HarvestedRecord) error {
+		if endpoint == "failing" {
+			return failure
+		}
+		return ErrStopHarvest
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the callback error, got %v", err)
+	}
+	if !errors.Is(results[0].Err, failure) {
+		t.Errorf("Unexpected result %+v", results[0])
+	}
+	if results[1].Err != nil || !results[1].Stats.Stopped {
+		t.Errorf("Expected a stopped harvest, got %+v", results[1])
+	}
+}
+
+func TestHostRateLimiter(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+
+	orchestrator := NewOrchestrator(
+		Endpoint{Name: "a", BaseURL: server.URL, MetadataPrefix: "oai_dc"},
+		Endpoint{Name: "b", BaseURL: server.URL, MetadataPrefix: "oai_dc"},
+	)
+	orchestrator.RateLimiter = NewHostRateLimiter(40 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := orchestrator.Harvest(context.Background(), func(string, HarvestedRecord) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	// Four requests to the same host
+	if elapsed := time.Since(start); elapsed < 120*time.Millisecond {
+		t.Errorf("Expected the requests to be spaced, took %v", elapsed)
+	}
+	if len(server.Queries()) != 4 {
+		t.Errorf("Expected 4 requests, got %d", len(server.Queries()))
+	}
+}
+
+func TestHostRateLimiterWait(t *testing.T) {
+	limiter := NewHostRateLimiter(time.Hour)
+	limiter.Intervals = map[string]time.Duration{"fast.example.org": 0}
+
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background(), "fast.example.org"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := limiter.Wait(context.Background(), "slow.example.org"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, "slow.example.org"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to be cancelled, got %v", err)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://slow.example.org/oai", nil)
+	doer := limiter.Middleware()(DoerFunc(func(*http.Request) (*http.Response, error) {
+		t.Error("Expected the request not to be sent")
+		return nil, nil
+	}))
+	if _, err := doer.Do(req); err == nil {
+		t.Error("Expected the middleware to return the wait error")
+	}
+}
@@ -0,0 +1,304 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// OAI-ORE link relations used in Atom-serialized resource maps
+const (
+	OreRelDescribes  = "http://www.openarchives.org/ore/terms/describes"
+	OreRelAggregates = "http://www.openarchives.org/ore/terms/aggregates"
+)
+
+// ORE represents an OAI-ORE resource map in its Atom serialization (as served by DSpace)
+type ORE struct {
+	XMLName    xml.Name      `xml:"http://www.w3.org/2005/Atom entry"`
+	ID         string        `xml:"http://www.w3.org/2005/Atom id"`
+	Title      string        `xml:"http://www.w3.org/2005/Atom title"`
+	Published  string        `xml:"http://www.w3.org/2005/Atom published"`
+	Updated    string        `xml:"http://www.w3.org/2005/Atom updated"`
+	Authors    []OreAuthor   `xml:"http://www.w3.org/2005/Atom author"`
+	Links      []OreLink     `xml:"http://www.w3.org/2005/Atom link"`
+	Categories []OreCategory `xml:"http://www.w3.org/2005/Atom category"`
+	Triples    *OreTriples   `xml:"http://www.openarchives.org/ore/atom/ triples,omitempty"`
+}
+
+// OreAuthor represents the author of a resource map
+type OreAuthor struct {
+	Name string `xml:"http://www.w3.org/2005/Atom name"`
+	URI  string `xml:"http://www.w3.org/2005/Atom uri"`
+}
+
+// OreLink represents an Atom link; rel identifies the ORE relation
+type OreLink struct {
+	Rel    string `xml:"rel,attr"`
+	Href   string `xml:"href,attr"`
+	Title  string `xml:"title,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length string `xml:"length,attr,omitempty"`
+}
+
+// OreCategory represents an Atom category
+type OreCategory struct {
+	Scheme string `xml:"scheme,attr,omitempty"`
+	Term   string `xml:"term,attr"`
+	Label  string `xml:"label,attr,omitempty"`
+}
+
+// OreTriples holds the additional RDF statements about the aggregation
+type OreTriples struct {
+	Raw []byte `xml:",innerxml"`
+}
+
+// AggregationURI returns the URI of the aggregation described by the resource map
+func (o *ORE) AggregationURI() string {
+	for _, link := range o.Links {
+		if link.Rel == OreRelDescribes {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// AggregatedResources returns the links to the resources in the aggregation
+func (o *ORE) AggregatedResources() []OreLink {
+	var links []OreLink
+	for _, link := range o.Links {
+		if link.Rel == OreRelAggregates {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// MetadataORE represents the metadata wrapper for OAI-ORE
+type MetadataORE struct {
+	ORE *ORE   `xml:"http://www.w3.org/2005/Atom entry,omitempty"`
+	Raw []byte `xml:",innerxml"`
+}
+
+// RecordORE represents an OAI-PMH record with OAI-ORE metadata
+type RecordORE struct {
+	Header   Header      `xml:"header"`
+	Metadata MetadataORE `xml:"metadata"`
+	About    *About      `xml:"about,omitempty"`
+}
+
+// IsDeleted returns true if the record has been deleted from the repository
+func (r RecordORE) IsDeleted() bool {
+	return r.Header.IsDeleted()
+}
+
+// ListRecordsORE contains the list of OAI-ORE records from ListRecords verb
+type ListRecordsORE struct {
+	Records         []RecordORE      `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// GetRecordORE contains a single OAI-ORE record from GetRecord verb
+type GetRecordORE struct {
+	Record RecordORE `xml:"record"`
+}
+
+// OAIPMHResponseORE represents the OAI-PMH response with OAI-ORE metadata
+type OAIPMHResponseORE struct {
+	XMLName      xml.Name        `xml:"OAI-PMH"`
+	ResponseDate string          `xml:"responseDate"`
+	Request      OAIRequest      `xml:"request"`
+	ListRecords  *ListRecordsORE `xml:"ListRecords,omitempty"`
+	GetRecord    *GetRecordORE   `xml:"GetRecord,omitempty"`
+	Error        *OAIError       `xml:"error,omitempty"`
+}
+
+// OreResourceMap represents an extracted OAI-ORE resource map
+type OreResourceMap struct {
+	ResourceMapURI string        `json:"resource_map_uri"`
+	Title          string        `json:"title"`
+	Authors        []string      `json:"authors"`
+	Updated        string        `json:"updated"`
+	Aggregation    string        `json:"aggregation"`
+	AlternateURL   string        `json:"alternate_url"`
+	Resources      []OreResource `json:"resources"`
+}
+
+// OreResource is an aggregated resource such as a PDF or dataset file
+type OreResource struct {
+	URL      string `json:"url"`
+	Title    string `json:"title"`
+	MimeType string `json:"mime_type"`
+	Length   int64  `json:"length"`
+}
+
+// ExtractOreResourceMap extracts the aggregation and its aggregated resource URLs
+func (o *ORE) ExtractOreResourceMap() *OreResourceMap {
+	if o == nil {
+		return nil
+	}
+
+	resourceMap := &OreResourceMap{
+		ResourceMapURI: strings.TrimSpace(o.ID),
+		Title:          strings.TrimSpace(o.Title),
+		Authors:        []string{},
+		Updated:        strings.TrimSpace(o.Updated),
+		Aggregation:    o.AggregationURI(),
+		Resources:      []OreResource{},
+	}
+
+	for _, author := range o.Authors {
+		if name := strings.TrimSpace(author.Name); name != "" {
+			resourceMap.Authors = append(resourceMap.Authors, name)
+		}
+	}
+
+	for _, link := range o.Links {
+		if link.Rel == "alternate" && resourceMap.AlternateURL == "" {
+			resourceMap.AlternateURL = link.Href
+		}
+	}
+
+	for _, link := range o.AggregatedResources() {
+		resource := OreResource{URL: link.Href, Title: link.Title, MimeType: link.Type}
+		if length, err := strconv.ParseInt(link.Length, 10, 64); err == nil {
+			resource.Length = length
+		}
+		resourceMap.Resources = append(resourceMap.Resources, resource)
+	}
+
+	return resourceMap
+}
+
+// ExtractAllOreResourceMap extracts metadata from all OAI-ORE records in OAI-PMH response
+func (o *OAIPMHResponseORE) ExtractAllOreResourceMap() []*OreResourceMap {
+	var results []*OreResourceMap
+
+	for _, extractor := range o.GetRecords() {
+		if metadata := extractor.(*ORE).ExtractOreResourceMap(); metadata != nil {
+			results = append(results, metadata)
+		}
+	}
+
+	return results
+}
+
+// ParseOAIOREXML parses OAI-PMH XML data with OAI-ORE metadata from bytes
+func ParseOAIOREXML(data []byte) (*OAIPMHResponseORE, error) {
+	var oaiResp OAIPMHResponseORE
+	if err := xml.Unmarshal(data, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, oaiResp.Error
+	}
+
+	return &oaiResp, nil
+}
+
+// decodeOAIPMHResponseORE stream-decodes a OAI-ORE ListRecords or GetRecord response
+func decodeOAIPMHResponseORE(r io.Reader, page decodeOptions) (*OAIPMHResponseORE, error) {
+	env, records, err := decodeResponse(r, page, decodeRecord[RecordORE])
+	if err != nil {
+		return nil, err
+	}
+
+	oaiResp := &OAIPMHResponseORE{
+		XMLName:      xml.Name{Local: "OAI-PMH"},
+		ResponseDate: env.ResponseDate,
+		Request:      env.Request,
+	}
+
+	switch env.Verb {
+	case "ListRecords":
+		oaiResp.ListRecords = &ListRecordsORE{Records: records, ResumptionToken: env.ResumptionToken}
+	case "GetRecord":
+		if len(records) > 0 {
+			oaiResp.GetRecord = &GetRecordORE{Record: records[0]}
+		}
+	}
+
+	return oaiResp, nil
+}
+
+// Implement OAIResponse interface for OAIPMHResponseORE
+
+// GetRecords returns all records in the response as MetadataExtractor interface
+func (o *OAIPMHResponseORE) GetRecords() []MetadataExtractor {
+	var extractors []MetadataExtractor
+
+	for _, record := range o.harvestedRecords() {
+		if record.Metadata != nil {
+			extractors = append(extractors, record.Metadata)
+		}
+	}
+
+	return extractors
+}
+
+// GetResumptionToken returns the resumption token if available
+func (o *OAIPMHResponseORE) GetResumptionToken() string {
+	if o.ListRecords != nil && o.ListRecords.ResumptionToken != nil {
+		return o.ListRecords.ResumptionToken.Token
+	}
+	return ""
+}
+
+// GetResumptionTokenInfo returns the full resumption token if available
+func (o *OAIPMHResponseORE) GetResumptionTokenInfo() *ResumptionToken {
+	if o.ListRecords != nil {
+		return o.ListRecords.ResumptionToken
+	}
+	return nil
+}
+
+// HasError returns true if the response contains an error
+func (o *OAIPMHResponseORE) HasError() bool {
+	return o.Error != nil
+}
+
+// GetError returns the error information
+func (o *OAIPMHResponseORE) GetError() *OAIError {
+	return o.Error
+}
+
+// harvestedRecords returns all records in the response with their headers
+func (o *OAIPMHResponseORE) harvestedRecords() []HarvestedRecord {
+	var records []RecordORE
+	if o.ListRecords != nil {
+		records = o.ListRecords.Records
+	}
+	if o.GetRecord != nil {
+		records = append(records, o.GetRecord.Record)
+	}
+
+	results := make([]HarvestedRecord, 0, len(records))
+	for _, record := range records {
+		harvested := HarvestedRecord{Header: record.Header, Raw: record.Metadata.Raw, About: record.About}
+		if record.Metadata.ORE != nil {
+			harvested.Metadata = record.Metadata.ORE
+		}
+		results = append(results, harvested)
+	}
+
+	return results
+}
+
+// responseDate returns the responseDate of the response
+func (o *OAIPMHResponseORE) responseDate() string {
+	return o.ResponseDate
+}
+
+// Implement MetadataExtractor interface for ORE
+
+// ExtractMetadata extracts metadata from OAI-ORE record
+func (m *ORE) ExtractMetadata() interface{} {
+	return m.ExtractOreResourceMap()
+}
+
+// GetFormat returns the metadata format type
+func (m *ORE) GetFormat() MetadataFormat {
+	return FormatORE
+}
@@ -0,0 +1,76 @@
+package goharvest
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseOAIOREXML(t *testing.T) {
+	data, err := os.ReadFile("testdata/listrecords_ore.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAIOREXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIOREXML failed: %v", err)
+	}
+
+	records := resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if records[0].GetFormat() != FormatORE {
+		t.Errorf("Expected format oai_ore, got %s", records[0].GetFormat())
+	}
+
+	resourceMap, ok := records[0].ExtractMetadata().(*OreResourceMap)
+	if !ok {
+		t.Fatalf("Expected *OreResourceMap, got %T", records[0].ExtractMetadata())
+	}
+
+	if resourceMap.Aggregation != "http://example.org/handle/123456789/60/ore.xml#atom" {
+		t.Errorf("Unexpected aggregation '%s'", resourceMap.Aggregation)
+	}
+	if resourceMap.AlternateURL != "http://example.org/handle/123456789/60" {
+		t.Errorf("Unexpected alternate URL '%s'", resourceMap.AlternateURL)
+	}
+	if len(resourceMap.Authors) != 1 || resourceMap.Title == "" {
+		t.Errorf("Unexpected title/authors '%s' / %v", resourceMap.Title, resourceMap.Authors)
+	}
+
+	if len(resourceMap.Resources) != 2 {
+		t.Fatalf("Expected 2 aggregated resources, got %d", len(resourceMap.Resources))
+	}
+	pdf := resourceMap.Resources[0]
+	if !strings.HasSuffix(pdf.URL, "thesis.pdf") || pdf.MimeType != "application/pdf" || pdf.Length != 204800 {
+		t.Errorf("Unexpected first resource %+v", pdf)
+	}
+
+	ore := resp.ListRecords.Records[0].Metadata.ORE
+	if ore.Triples == nil || !strings.Contains(string(ore.Triples.Raw), "ORIGINAL") {
+		t.Error("Expected RDF triples to be preserved")
+	}
+}
+
+func TestUnifiedHarvestORE(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_ore.xml"})
+	client := NewClient(server.URL)
+
+	count := 0
+	err := client.Harvest("oai_ore", nil, func(response OAIResponse) error {
+		for _, record := range response.GetRecords() {
+			if resourceMap, ok := record.ExtractMetadata().(*OreResourceMap); ok {
+				count += len(resourceMap.Resources)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 aggregated resources, got %d", count)
+	}
+}
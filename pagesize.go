@@ -0,0 +1,54 @@
+package goharvest
+
+import (
+	"fmt"
+	"io"
+)
+
+// decodeOptions returns how the ListRecords page requested with resumptionToken is decoded
+func (c *OAIClient) decodeOptions(opts *HarvestOptions, resumptionToken string) decodeOptions {
+	if opts == nil {
+		return decodeOptions{}
+	}
+
+	page := decodeOptions{workers: opts.ExtractionWorkers}
+	if opts.MaxPageSize > 0 && opts.TruncateOversizedPages {
+		page.truncateAt = opts.MaxPageSize
+		page.truncated = func(kept, dropped int) {
+			c.log().Warn("page exceeds the maximum size, dropped its remaining records", "baseURL", c.BaseURL,
+				"resumptionToken", resumptionToken, "maxPageSize", opts.MaxPageSize, "kept", kept, "dropped", dropped)
+			if opts.run != nil {
+				opts.run.truncated.Add(1)
+				opts.run.dropped.Add(int64(dropped))
+			}
+			opts.emit(PageTruncated{ResumptionToken: resumptionToken, Records: kept, DroppedRecords: dropped})
+		}
+	}
+	return page
+}
+
+// limitPage wraps a ListRecords body to fail once it exceeds MaxPageSize. Truncated pages
+// are cut by the decoder instead, except when lenient mode or validation read them whole.
+func (o *HarvestOptions) limitPage(body io.ReadCloser) io.ReadCloser {
+	if o == nil || o.MaxPageSize <= 0 || (o.TruncateOversizedPages && !o.Lenient && o.Validation == nil) {
+		return body
+	}
+	return &pageLimitReader{ReadCloser: body, limit: o.MaxPageSize}
+}
+
+// pageLimitReader fails with ErrResponseTooLarge once more than limit bytes are read
+type pageLimitReader struct {
+	io.ReadCloser
+	limit int64
+	size  int64
+}
+
+// Read implements io.Reader
+func (p *pageLimitReader) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	p.size += int64(n)
+	if p.size > p.limit {
+		return 0, fmt.Errorf("%w: page exceeds the limit of %d bytes", ErrResponseTooLarge, p.limit)
+	}
+	return n, err
+}
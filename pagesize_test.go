@@ -0,0 +1,103 @@
+package goharvest
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeResponseTruncate(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "listrecords_dc_page1.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, workers := range []int{0, 4} {
+		var kept, dropped int
+		resp, err := decodeOAIPMHResponseDC(bytes.NewReader(data), decodeOptions{
+			workers:    workers,
+			truncateAt: 900,
+			truncated:  func(k, d int) { kept, dropped = k, d },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records := resp.ListRecords.Records
+		if len(records) != 1 || records[0].Header.Identifier != "oai:example.org:1" {
+			t.Errorf("workers=%d: expected the first record only, got %+v", workers, records)
+		}
+		if kept != 1 || dropped != 2 {
+			t.Errorf("workers=%d: expected 1 record kept and 2 dropped, got %d and %d", workers, kept, dropped)
+		}
+		if resp.GetResumptionToken() != "dc-page-2" {
+			t.Errorf("workers=%d: expected the resumption token of the truncated page, got %+v", workers, resp.GetResumptionToken())
+		}
+	}
+}
+
+func TestHarvestMaxPageSize(t *testing.T) {
+	fixtures := map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		server := newFixtureServer(t, fixtures)
+		records := 0
+		err := NewClient(server.URL).HarvestRecords("oai_dc", &HarvestOptions{MaxPageSize: 1000}, func(HarvestedRecord) error {
+			records++
+			return nil
+		})
+		if !errors.Is(err, ErrResponseTooLarge) || records != 0 {
+			t.Errorf("Expected ErrResponseTooLarge before any record, got %v after %d records", err, records)
+		}
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		server := newFixtureServer(t, fixtures)
+		var stats HarvestStats
+		var truncated []PageTruncated
+		opts := &HarvestOptions{
+			MaxPageSize:            1000,
+			TruncateOversizedPages: true,
+			Stats:                  &stats,
+			Observer: ObserverFunc(func(event HarvestEvent) {
+				if page, ok := event.(PageTruncated); ok {
+					truncated = append(truncated, page)
+				}
+			}),
+		}
+		var identifiers []string
+		err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(record HarvestedRecord) error {
+			identifiers = append(identifiers, record.Header.Identifier)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(identifiers) != 3 || identifiers[2] != "oai:example.org:5" {
+			t.Errorf("Expected the first 2 records and the second page, got %v", identifiers)
+		}
+		if stats.TruncatedPages != 1 || stats.DroppedRecords != 1 {
+			t.Errorf("Expected 1 truncated page with 1 dropped record, got %+v", stats)
+		}
+		if len(truncated) != 1 || truncated[0].ResumptionToken != "" || truncated[0].Records != 2 || truncated[0].DroppedRecords != 1 {
+			t.Errorf("Unexpected truncation events %+v", truncated)
+		}
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		server := newFixtureServer(t, fixtures)
+		opts := &HarvestOptions{MaxPageSize: 1000, TruncateOversizedPages: true, Lenient: true}
+		err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(HarvestedRecord) error {
+			return nil
+		})
+		if !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("Expected ErrResponseTooLarge for a page read whole, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package goharvest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ParseError is returned when a ListRecords or GetRecord response cannot be parsed
+type ParseError struct {
+	// URL is the request URL of the response
+	URL string
+	// Offset is the byte offset in the (UTF-8) response at which parsing failed
+	Offset int64
+	// DumpPath is the file the response was written to, when dumping is enabled
+	DumpPath string
+	Err      error
+}
+
+// Error implements the error interface
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("%v (at byte %d", e.Err, e.Offset)
+	if e.URL != "" {
+		msg += " of " + e.URL
+	}
+	if e.DumpPath != "" {
+		msg += ", response saved to " + e.DumpPath
+	}
+	return msg + ")"
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseFailureFunc receives a parse failure and the response body it happened in
+type ParseFailureFunc func(err *ParseError, body []byte)
+
+// WithParseFailureDump writes responses that fail to parse to files in dir, and names
+// the file in the returned *ParseError
+func WithParseFailureDump(dir string) ClientOption {
+	return func(c *OAIClient) {
+		c.ParseFailureDir = dir
+	}
+}
+
+// WithParseFailureHook calls fn with responses that fail to parse
+func WithParseFailureHook(fn ParseFailureFunc) ClientOption {
+	return func(c *OAIClient) {
+		c.OnParseFailure = fn
+	}
+}
+
+// responseBody is a response body that remembers its URL and, when parse failures are
+// dumped, the bytes read from it
+type responseBody struct {
+	io.ReadCloser
+	url      string
+	captured *bytes.Buffer
+}
+
+// Read implements io.Reader
+func (b *responseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.captured != nil {
+		b.captured.Write(p[:n])
+	}
+	return n, err
+}
+
+// newResponseBody wraps a response body read from url
+func (c *OAIClient) newResponseBody(body io.ReadCloser, url string) *responseBody {
+	b := &responseBody{ReadCloser: body, url: url}
+	if c.ParseFailureDir != "" || c.OnParseFailure != nil {
+		b.captured = &bytes.Buffer{}
+	}
+	return b
+}
+
+// findResponseBody returns the responseBody underneath r, or nil
+func findResponseBody(r io.Reader) *responseBody {
+	for {
+		switch b := r.(type) {
+		case *responseBody:
+			return b
+		case *countingReadCloser:
+			r = b.ReadCloser
+		case *pageLimitReader:
+			r = b.ReadCloser
+		default:
+			return nil
+		}
+	}
+}
+
+// parseFailure completes a *ParseError in err with the URL of body, and dumps the body
+// when configured. Other errors are returned unchanged.
+func (c *OAIClient) parseFailure(body io.Reader, err error) error {
+	var parseErr *ParseError
+	source := findResponseBody(body)
+	if source == nil || !errors.As(err, &parseErr) {
+		return err
+	}
+	parseErr.URL = source.url
+	if source.captured == nil || errors.Is(err, ErrResponseTooLarge) {
+		return err
+	}
+
+	// Dump the whole response, not just the part read before the failure
+	io.Copy(io.Discard, body)
+	data := source.captured.Bytes()
+
+	if c.ParseFailureDir != "" {
+		path, dumpErr := dumpResponse(c.ParseFailureDir, data)
+		if dumpErr != nil {
+			c.log().Warn("failed to save unparsable OAI-PMH response", "url", source.url, "error", dumpErr)
+		} else {
+			parseErr.DumpPath = path
+		}
+	}
+	if c.OnParseFailure != nil {
+		c.OnParseFailure(parseErr, data)
+	}
+	c.log().Warn("OAI-PMH response could not be parsed",
+		"url", source.url, "offset", parseErr.Offset, "dump", parseErr.DumpPath, "error", parseErr.Err)
+	return err
+}
+
+// dumpResponse writes data to a new file in dir and returns its path
+func dumpResponse(dir string, data []byte) (string, error) {
+	f, err := os.CreateTemp(dir, "parse-failure-*.xml")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
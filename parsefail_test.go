@@ -0,0 +1,89 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// truncatedPage is a ListRecords response cut off in the middle of a record
+const truncatedPage = `<?xml version="1.0" encoding="UTF-8"?>
+<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/">
+  <responseDate>2025-10-02T10:05:19Z</responseDate>
+  <request verb="ListRecords" metadataPrefix="oai_dc">http://example.org/oai</request>
+  <ListRecords>
+    <record>
+      <header>
+        <identifier>oai:example.org:1</identifier>
+        <datestamp>2025-01-15</datestamp>
+      </header>
+      <metadata>
+        <oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+          <dc:title>Cut off`
+
+func TestParseFailureDump(t *testing.T) {
+	server := serveDocument(t, truncatedPage)
+	dir := t.TempDir()
+
+	var hooked *ParseError
+	var hookedBody []byte
+	client := NewClient(server.URL, WithParseFailureDump(dir), WithParseFailureHook(func(err *ParseError, body []byte) {
+		hooked, hookedBody = err, body
+	}))
+
+	err := client.HarvestRecords("oai_dc", nil, func(HarvestedRecord) error { return nil })
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a *ParseError, got %v", err)
+	}
+	if !strings.Contains(parseErr.URL, "verb=ListRecords") || parseErr.Offset != int64(len(truncatedPage)) {
+		t.Errorf("Unexpected URL %q or offset %d", parseErr.URL, parseErr.Offset)
+	}
+	if !strings.Contains(err.Error(), "unexpected EOF") || !strings.Contains(err.Error(), parseErr.URL) {
+		t.Errorf("Expected the cause and URL in %q", err)
+	}
+
+	if filepath.Dir(parseErr.DumpPath) != dir || !strings.Contains(err.Error(), parseErr.DumpPath) {
+		t.Fatalf("Unexpected dump path %q", parseErr.DumpPath)
+	}
+	dumped, readErr := os.ReadFile(parseErr.DumpPath)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if string(dumped) != truncatedPage {
+		t.Errorf("Unexpected dump %q", dumped)
+	}
+	if hooked != parseErr || string(hookedBody) != truncatedPage {
+		t.Errorf("Unexpected hook call with %v and %q", hooked, hookedBody)
+	}
+}
+
+func TestParseFailureWholeBody(t *testing.T) {
+	page := strings.Replace(lenientTestPage, "<ListRecords>", "<ListRecords><record><header>", 1)
+	server := serveDocument(t, page)
+
+	var body []byte
+	client := NewClient(server.URL, WithParseFailureHook(func(_ *ParseError, b []byte) { body = b }))
+
+	_, err := client.GetRecord(context.Background(), "oai:example.org:1", "oai_dc")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.DumpPath != "" {
+		t.Fatalf("Expected a *ParseError without dump, got %v", err)
+	}
+	if parseErr.Offset >= int64(len(page)) || string(body) != page {
+		t.Errorf("Expected the whole body past offset %d, got %d bytes", parseErr.Offset, len(body))
+	}
+}
+
+func TestParseFailureWithoutDump(t *testing.T) {
+	server := serveDocument(t, truncatedPage)
+
+	err := NewClient(server.URL).HarvestRecords("oai_dc", nil, func(HarvestedRecord) error { return nil })
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.URL == "" || parseErr.DumpPath != "" {
+		t.Fatalf("Expected a *ParseError with URL and no dump, got %v", err)
+	}
+}
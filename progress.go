@@ -0,0 +1,259 @@
+package goharvest
+
+import (
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Progress describes the progress of a harvest after a page has been processed
+type Progress struct {
+	// Pages is the number of pages processed in this run
+	Pages int
+	// Records is the number of records (including deleted ones) processed in this run
+	Records int
+	// Cursor is the position of the processed records in the complete list, when advertised
+	Cursor int
+	// CompleteListSize is the total number of records, or 0 if not advertised
+	CompleteListSize int
+	// BytesDownloaded is the number of response bytes read in this run
+	BytesDownloaded int64
+	// Elapsed is the time since the harvest started
+	Elapsed time.Duration
+	// ETA is the estimated remaining time, or 0 if it cannot be estimated
+	ETA time.Duration
+}
+
+// ProgressFunc is invoked after each processed page
+type ProgressFunc func(progress Progress)
+
+// HarvestStats summarizes a completed (or failed) harvest
+type HarvestStats struct {
+	// Pages is the number of pages processed
+	Pages int
+	// Records is the number of records seen, including deleted ones
+	Records int
+	// DeletedRecords is the number of records with status="deleted"
+	DeletedRecords int
+	// SkippedRecords is the number of malformed records dropped in lenient mode
+	SkippedRecords int
+	// Bytes is the number of response bytes read
+	Bytes int64
+	// Retries is the number of pages requested again with the same resumption token after a
+	// transient failure
+	Retries int
+	// TokenRecoveries is the number of times the harvest restarted from the latest
+	// datestamp after a rejected resumption token
+	TokenRecoveries int
+	// TruncatedPages is the number of pages cut at HarvestOptions.MaxPageSize
+	TruncatedPages int
+	// DroppedRecords is the number of records past the cut of truncated pages
+	DroppedRecords int
+	// DuplicateRecords is the number of records not delivered by the per-record APIs
+	// because a record with the same identifier was delivered before
+	DuplicateRecords int
+	// UnchangedRecords is the number of records skipped by the per-record APIs because
+	// their content hash matched HarvestOptions.SkipUnchanged
+	UnchangedRecords int
+	// SetRecords is the number of records harvested from each set when harvesting
+	// HarvestOptions.Sets, including duplicates
+	SetRecords map[string]int
+	// Duration is the wall time of the harvest
+	Duration time.Duration
+	// PageTimeP50, PageTimeP90, PageTimeP99 and PageTimeMax are percentiles
+	// of the time taken to fetch and parse a page
+	PageTimeP50 time.Duration
+	PageTimeP90 time.Duration
+	PageTimeP99 time.Duration
+	PageTimeMax time.Duration
+	// Stopped is true if a callback ended the harvest with ErrStopHarvest
+	Stopped bool
+	// ResumptionToken is the token to continue a stopped harvest with, empty for a
+	// harvest of several Sets
+	ResumptionToken string
+}
+
+// harvestRun holds the running totals of a single harvest
+type harvestRun struct {
+	start time.Time
+	bytes atomic.Int64
+	// retries counts the pages requested again after a transient failure
+	retries atomic.Int64
+	// recoveries counts the restarts after a rejected resumption token
+	recoveries atomic.Int64
+	skipped    atomic.Int64
+	// truncated and dropped count the pages cut at MaxPageSize and their records lost
+	truncated atomic.Int64
+	dropped   atomic.Int64
+	pages     int
+	records   int
+	deleted   int
+
+	// dedup remembers the identifiers delivered so far when duplicates are suppressed
+	dedup      identifierFilter
+	duplicates int
+	unchanged  int
+	// perRecord is set for the per-record APIs, which count the records delivered
+	perRecord  bool
+	delivered  int
+	setRecords map[string]int
+
+	stopped   bool
+	stopToken string
+
+	mu            sync.Mutex
+	pageDurations []time.Duration
+
+	// observeMu serializes the events delivered to HarvestOptions.Observer
+	observeMu sync.Mutex
+	// pageMu serializes the processing of pages of sets harvested in parallel
+	pageMu sync.Mutex
+}
+
+// newHarvestRun starts tracking a harvest
+func newHarvestRun() *harvestRun {
+	return &harvestRun{start: time.Now()}
+}
+
+// pageProcessed records a processed page and returns the resulting progress
+func (r *harvestRun) pageProcessed(resp OAIResponse) Progress {
+	r.pages++
+	pageRecords := countRecords(resp)
+	r.records += pageRecords
+	r.deleted += countDeletedRecords(resp)
+
+	progress := Progress{
+		Pages:           r.pages,
+		Records:         r.records,
+		BytesDownloaded: r.bytes.Load(),
+		Elapsed:         time.Since(r.start),
+	}
+
+	progress.Cursor = r.records
+	if info := resp.GetResumptionTokenInfo(); info != nil {
+		progress.CompleteListSize = info.CompleteListSize
+		// The token cursor counts the records returned before this page
+		if info.Cursor > 0 || r.pages == 1 {
+			progress.Cursor = info.Cursor + pageRecords
+		}
+	}
+
+	if progress.CompleteListSize > 0 && r.records > 0 && progress.Cursor < progress.CompleteListSize {
+		perRecord := progress.Elapsed / time.Duration(r.records)
+		progress.ETA = perRecord * time.Duration(progress.CompleteListSize-progress.Cursor)
+	}
+
+	return progress
+}
+
+// duplicate reports whether a record with identifier was delivered before, if duplicates
+// are suppressed, and remembers it otherwise
+func (r *harvestRun) duplicate(identifier string) bool {
+	if r.dedup == nil || !r.dedup.seen(identifier) {
+		return false
+	}
+	r.duplicates++
+	return true
+}
+
+// stop records that the harvest was stopped before the page with token. A harvest of
+// several sets cannot be resumed from a token, so it reports none.
+func (r *harvestRun) stop(token string) {
+	r.pageMu.Lock()
+	defer r.pageMu.Unlock()
+	r.stopped = true
+	if r.setRecords == nil {
+		r.stopToken = token
+	}
+}
+
+// pageFetched records the time taken to fetch and parse a page
+func (r *harvestRun) pageFetched(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pageDurations = append(r.pageDurations, d)
+}
+
+// stats returns the summary of the harvest so far
+func (r *harvestRun) stats() HarvestStats {
+	stats := HarvestStats{
+		Pages:            r.pages,
+		Records:          r.records,
+		DeletedRecords:   r.deleted,
+		SkippedRecords:   int(r.skipped.Load()),
+		Bytes:            r.bytes.Load(),
+		Retries:          int(r.retries.Load()),
+		TokenRecoveries:  int(r.recoveries.Load()),
+		TruncatedPages:   int(r.truncated.Load()),
+		DroppedRecords:   int(r.dropped.Load()),
+		DuplicateRecords: r.duplicates,
+		UnchangedRecords: r.unchanged,
+		SetRecords:       maps.Clone(r.setRecords),
+		Duration:         time.Since(r.start),
+		Stopped:          r.stopped,
+		ResumptionToken:  r.stopToken,
+	}
+
+	r.mu.Lock()
+	durations := slices.Clone(r.pageDurations)
+	r.mu.Unlock()
+
+	if len(durations) > 0 {
+		slices.Sort(durations)
+		stats.PageTimeP50 = percentile(durations, 50)
+		stats.PageTimeP90 = percentile(durations, 90)
+		stats.PageTimeP99 = percentile(durations, 99)
+		stats.PageTimeMax = durations[len(durations)-1]
+	}
+
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// countDeletedRecords returns the number of deleted records in a response
+func countDeletedRecords(resp OAIResponse) int {
+	lister, ok := resp.(recordLister)
+	if !ok {
+		return 0
+	}
+
+	deleted := 0
+	for _, record := range lister.harvestedRecords() {
+		if record.IsDeleted() {
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// countRecords returns the number of records in a response, including deleted ones
+func countRecords(resp OAIResponse) int {
+	if lister, ok := resp.(recordLister); ok {
+		return len(lister.harvestedRecords())
+	}
+	return len(resp.GetRecords())
+}
+
+// countingReadCloser counts the bytes read from the underlying body
+type countingReadCloser struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+// Read implements io.Reader
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.count.Add(int64(n))
+	return n, err
+}
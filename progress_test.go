@@ -0,0 +1,95 @@
+package goharvest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHarvestProgress(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	var reports []Progress
+	opts := &HarvestOptions{
+		Progress: func(progress Progress) {
+			reports = append(reports, progress)
+		},
+	}
+
+	err := client.HarvestWithOptions("oai_dc", opts, func(OAIResponse) error {
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestWithOptions failed: %v", err)
+	}
+
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 progress reports, got %d", len(reports))
+	}
+
+	first := reports[0]
+	if first.Pages != 1 || first.Records != 3 || first.Cursor != 3 || first.CompleteListSize != 4 {
+		t.Errorf("Unexpected first progress: %+v", first)
+	}
+	if first.BytesDownloaded == 0 {
+		t.Error("Expected bytes downloaded to be counted")
+	}
+	if first.ETA <= 0 {
+		t.Errorf("Expected positive ETA after first page, got %s", first.ETA)
+	}
+
+	last := reports[1]
+	if last.Pages != 2 || last.Records != 4 || last.Cursor != 4 {
+		t.Errorf("Unexpected last progress: %+v", last)
+	}
+	if last.ETA != 0 {
+		t.Errorf("Expected zero ETA when complete, got %s", last.ETA)
+	}
+	if last.BytesDownloaded <= first.BytesDownloaded || last.Elapsed < first.Elapsed {
+		t.Errorf("Expected totals to grow: %+v then %+v", first, last)
+	}
+}
+
+func TestHarvestStats(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	stats := &HarvestStats{}
+	err := client.HarvestWithOptions("oai_dc", &HarvestOptions{Stats: stats}, func(OAIResponse) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestWithOptions failed: %v", err)
+	}
+
+	if stats.Pages != 2 || stats.Records != 4 || stats.DeletedRecords != 1 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	if stats.Bytes == 0 || stats.Duration == 0 {
+		t.Errorf("Expected bytes and duration to be recorded: %+v", stats)
+	}
+	if stats.PageTimeMax == 0 || stats.PageTimeP50 > stats.PageTimeMax {
+		t.Errorf("Unexpected page timings: %+v", stats)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	durations := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	if p := percentile(durations, 50); p != 5 {
+		t.Errorf("Expected p50 5, got %d", p)
+	}
+	if p := percentile(durations, 90); p != 9 {
+		t.Errorf("Expected p90 9, got %d", p)
+	}
+	if p := percentile(durations, 99); p != 10 {
+		t.Errorf("Expected p99 10, got %d", p)
+	}
+}
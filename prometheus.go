@@ -0,0 +1,198 @@
+package goharvest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// promMetric describes how a Telemetry metric is exposed to Prometheus
+type promMetric struct {
+	name   string
+	kind   string
+	help   string
+	labels []string
+}
+
+// promMetrics maps Telemetry metric names to their Prometheus exposition
+var promMetrics = map[string]promMetric{
+	MetricHarvestsActive: {"goharvest_harvests_active", "gauge", "Harvests currently running.", nil},
+	MetricPages:          {"goharvest_pages_total", "counter", "ListRecords pages fetched.", []string{"format"}},
+	MetricRecords:        {"goharvest_records_total", "counter", "Records harvested, including deleted records.", []string{"format"}},
+	MetricDeletedRecords: {"goharvest_deleted_records_total", "counter", "Deleted records harvested.", []string{"format"}},
+	MetricOAIErrors:      {"goharvest_oai_errors_total", "counter", "OAI-PMH error responses.", []string{"verb", "code"}},
+	MetricHTTPFailures:   {"goharvest_http_failures_total", "counter", "Failed requests and non-200 responses.", []string{"verb", "status"}},
+	MetricRetries:        {"goharvest_retries_total", "counter", "Retried requests.", []string{"verb"}},
+	MetricPageDuration:   {"goharvest_page_duration_seconds", "summary", "Time taken to fetch and parse a page.", []string{"format"}},
+}
+
+// promLabels maps attribute keys to Prometheus label names
+var promLabels = map[string]string{
+	AttrFormat:    "format",
+	AttrVerb:      "verb",
+	AttrErrorCode: "code",
+	AttrStatus:    "status",
+}
+
+// promSeries is the current value of one labelled series
+type promSeries struct {
+	value float64
+	count int64
+}
+
+// PrometheusExporter is a Telemetry that keeps harvest metrics in memory and serves them
+// in the Prometheus text exposition format, so a harvest daemon can be scraped without
+// goharvest depending on the Prometheus client library. It is not a prometheus.Collector;
+// it serves its own /metrics endpoint. Spans are discarded.
+//
+//	metrics := goharvest.NewPrometheusExporter()
+//	client := goharvest.NewClient(baseURL, goharvest.WithTelemetry(metrics))
+//	http.Handle("/metrics", metrics)
+type PrometheusExporter struct {
+	mu     sync.Mutex
+	series map[string]map[string]*promSeries // metric name -> rendered labels -> series
+}
+
+// NewPrometheusExporter creates an empty PrometheusExporter
+func NewPrometheusExporter() *PrometheusExporter {
+	return &PrometheusExporter{series: map[string]map[string]*promSeries{}}
+}
+
+// StartSpan implements Telemetry; the exporter does not trace
+func (p *PrometheusExporter) StartSpan(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+// Count implements Telemetry
+func (p *PrometheusExporter) Count(_ context.Context, name string, delta int64, attrs ...Attribute) {
+	p.observe(name, attrs, func(s *promSeries) { s.value += float64(delta) })
+}
+
+// Record implements Telemetry
+func (p *PrometheusExporter) Record(_ context.Context, name string, value float64, attrs ...Attribute) {
+	p.observe(name, attrs, func(s *promSeries) {
+		s.value += value
+		s.count++
+	})
+}
+
+// observe applies update to the series of a known metric
+func (p *PrometheusExporter) observe(name string, attrs []Attribute, update func(*promSeries)) {
+	metric, ok := promMetrics[name]
+	if !ok {
+		return
+	}
+	labels := metric.renderLabels(attrs)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	series := p.series[name]
+	if series == nil {
+		series = map[string]*promSeries{}
+		p.series[name] = series
+	}
+	s := series[labels]
+	if s == nil {
+		s = &promSeries{}
+		series[labels] = s
+	}
+	update(s)
+}
+
+// renderLabels formats the metric's labels from attrs, e.g. {format="oai_dc"}
+func (m promMetric) renderLabels(attrs []Attribute) string {
+	if len(m.labels) == 0 {
+		return ""
+	}
+	values := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		if label, ok := promLabels[attr.Key]; ok {
+			values[label] = fmt.Sprint(attr.Value)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, label := range m.labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(label)
+		b.WriteString(`="`)
+		b.WriteString(promEscaper.Replace(values[label]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var promEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteTo writes all metrics in the Prometheus text exposition format
+func (p *PrometheusExporter) WriteTo(w io.Writer) (int64, error) {
+	names := make([]string, 0, len(promMetrics))
+	for name := range promMetrics {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return promMetrics[names[i]].name < promMetrics[names[j]].name })
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	p.mu.Lock()
+	for _, name := range names {
+		metric := promMetrics[name]
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+
+		series := p.series[name]
+		if len(series) == 0 && len(metric.labels) == 0 {
+			fmt.Fprintf(bw, "%s 0\n", metric.name)
+			continue
+		}
+		labels := make([]string, 0, len(series))
+		for l := range series {
+			labels = append(labels, l)
+		}
+		sort.Strings(labels)
+		for _, l := range labels {
+			s := series[l]
+			if metric.kind == "summary" {
+				fmt.Fprintf(bw, "%s_sum%s %s\n%s_count%s %d\n", metric.name, l, formatPromValue(s.value), metric.name, l, s.count)
+				continue
+			}
+			fmt.Fprintf(bw, "%s%s %s\n", metric.name, l, formatPromValue(s.value))
+		}
+	}
+	p.mu.Unlock()
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP serves the metrics to a Prometheus scrape
+func (p *PrometheusExporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+func formatPromValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,65 @@
+package goharvest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrometheusExporter(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	metrics := NewPrometheusExporter()
+	client := NewClient(server.URL, WithTelemetry(metrics))
+
+	if err := client.HarvestRecords("oai_dc", nil, func(HarvestedRecord) error { return nil }); err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	metrics.Count(context.Background(), MetricOAIErrors, 2, Attr(AttrVerb, "ListRecords"), Attr(AttrErrorCode, `bad"Token`))
+	metrics.Count(context.Background(), MetricHTTPFailures, 1, Attr(AttrVerb, "Identify"), Attr(AttrStatus, 503))
+	metrics.Count(context.Background(), "unknown.metric", 1)
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected Content-Type %q", ct)
+	}
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE goharvest_harvests_active gauge",
+		"goharvest_harvests_active 0",
+		"# TYPE goharvest_pages_total counter",
+		`goharvest_pages_total{format="oai_dc"} 2`,
+		`goharvest_records_total{format="oai_dc"} 4`,
+		`goharvest_deleted_records_total{format="oai_dc"} 1`,
+		`goharvest_oai_errors_total{verb="ListRecords",code="bad\"Token"} 2`,
+		`goharvest_http_failures_total{verb="Identify",status="503"} 1`,
+		`goharvest_page_duration_seconds_count{format="oai_dc"} 2`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expected %q in\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, "unknown") {
+		t.Errorf("Expected unknown metrics to be ignored, got\n%s", body)
+	}
+}
+
+func TestPrometheusExporterEmpty(t *testing.T) {
+	var b strings.Builder
+	n, err := NewPrometheusExporter().WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(b.Len()) {
+		t.Errorf("WriteTo reported %d bytes, wrote %d", n, b.Len())
+	}
+	if !strings.Contains(b.String(), "goharvest_harvests_active 0\n") || strings.Contains(b.String(), "goharvest_pages_total{") {
+		t.Errorf("Unexpected empty exposition\n%s", b.String())
+	}
+}
@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jiharal/goharvest"
+)
+
+func TestHandlerCompliance(t *testing.T) {
+	server := httptest.NewServer(NewHandler(&testRepository{}, Options{PageSize: 2}))
+	defer server.Close()
+
+	report, err := goharvest.Validate(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !report.Compliant() || len(report.Failures()) != 0 {
+		t.Errorf("Expected the provider to be compliant:\n%s", report)
+	}
+	if report.PagesChecked < 10 || report.Sets != 2 {
+		t.Errorf("Unexpected report totals: %d pages, %d sets", report.PagesChecked, report.Sets)
+	}
+	if _, ok := report.Check("errors.granularity"); !ok {
+		t.Error("Expected the granularity argument check for a day-granularity repository")
+	}
+}
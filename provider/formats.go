@@ -0,0 +1,29 @@
+package provider
+
+import "github.com/jiharal/goharvest"
+
+// StandardFormats describes the schema and namespace of the metadata formats goharvest
+// can harvest, keyed by metadata prefix
+var StandardFormats = map[string]goharvest.MetadataFormatInfo{
+	"oai_dc":       {MetadataPrefix: "oai_dc", Schema: "http://www.openarchives.org/OAI/2.0/oai_dc.xsd", MetadataNamespace: "http://www.openarchives.org/OAI/2.0/oai_dc/"},
+	"marcxml":      {MetadataPrefix: "marcxml", Schema: "http://www.loc.gov/standards/marcxml/schema/MARC21slim.xsd", MetadataNamespace: "http://www.loc.gov/MARC21/slim"},
+	"marc21":       {MetadataPrefix: "marc21", Schema: "http://www.loc.gov/standards/marcxml/schema/MARC21slim.xsd", MetadataNamespace: "http://www.loc.gov/MARC21/slim"},
+	"oai_marc":     {MetadataPrefix: "oai_marc", Schema: "http://www.openarchives.org/OAI/1.1/oai_marc.xsd", MetadataNamespace: "http://www.openarchives.org/OAI/1.1/oai_marc"},
+	"mods":         {MetadataPrefix: "mods", Schema: "http://www.loc.gov/standards/mods/v3/mods-3-7.xsd", MetadataNamespace: "http://www.loc.gov/mods/v3"},
+	"qdc":          {MetadataPrefix: "qdc", Schema: "http://dublincore.org/schemas/xmls/qdc/2008/02/11/qualifieddc.xsd", MetadataNamespace: "http://purl.org/dc/terms/"},
+	"oai_datacite": {MetadataPrefix: "oai_datacite", Schema: "http://schema.datacite.org/oai/oai-1.1/oai.xsd", MetadataNamespace: "http://schema.datacite.org/oai/oai-1.1/"},
+	"datacite":     {MetadataPrefix: "datacite", Schema: "http://schema.datacite.org/meta/kernel-4/metadata.xsd", MetadataNamespace: "http://datacite.org/schema/kernel-4"},
+	"mets":         {MetadataPrefix: "mets", Schema: "http://www.loc.gov/standards/mets/mets.xsd", MetadataNamespace: "http://www.loc.gov/METS/"},
+	"oai_etdms":    {MetadataPrefix: "oai_etdms", Schema: "http://www.ndltd.org/standards/metadata/etdms/1.0/etdms.xsd", MetadataNamespace: "http://www.ndltd.org/standards/metadata/etdms/1.0/"},
+	"oai_ore":      {MetadataPrefix: "oai_ore", Schema: "http://www.kbcafe.com/rss/atom.xsd.xml", MetadataNamespace: "http://www.w3.org/2005/Atom"},
+	"lido":         {MetadataPrefix: "lido", Schema: "http://www.lido-schema.org/schema/v1.0/lido-v1.0.xsd", MetadataNamespace: "http://www.lido-schema.org"},
+	"ead":          {MetadataPrefix: "ead", Schema: "http://www.loc.gov/ead/ead.xsd", MetadataNamespace: "urn:isbn:1-931666-22-9"},
+}
+
+// formatInfo returns the standard description of a prefix, or one with only the prefix set
+func formatInfo(prefix string) goharvest.MetadataFormatInfo {
+	if info, ok := StandardFormats[prefix]; ok {
+		return info
+	}
+	return goharvest.MetadataFormatInfo{MetadataPrefix: prefix}
+}
@@ -0,0 +1,528 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/jiharal/goharvest"
+)
+
+// oaiHeader opens every response document
+const oaiHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.openarchives.org/OAI/2.0/ http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd">
+`
+
+// Options configures a Handler
+type Options struct {
+	// PageSize is the number of records, identifiers or sets per response (default 100)
+	PageSize int
+	// BaseURL is reported in responses; by default it is derived from the request
+	BaseURL string
+}
+
+// Handler is an http.Handler answering the six OAI-PMH verbs from a RecordRepository
+type Handler struct {
+	repo RecordRepository
+	opts Options
+}
+
+// NewHandler returns a handler serving repo
+func NewHandler(repo RecordRepository, opts Options) *Handler {
+	if opts.PageSize <= 0 {
+		opts.PageSize = 100
+	}
+	return &Handler{repo: repo, opts: opts}
+}
+
+// verbArguments lists the required and optional arguments of each verb;
+// resumptionToken is exclusive and handled separately
+var verbArguments = map[string]struct {
+	required []string
+	optional []string
+	paged    bool
+}{
+	"Identify":            {},
+	"ListMetadataFormats": {optional: []string{"identifier"}},
+	"ListSets":            {paged: true},
+	"ListIdentifiers":     {required: []string{"metadataPrefix"}, optional: []string{"from", "until", "set"}, paged: true},
+	"ListRecords":         {required: []string{"metadataPrefix"}, optional: []string{"from", "until", "set"}, paged: true},
+	"GetRecord":           {required: []string{"identifier", "metadataPrefix"}},
+}
+
+// request is a validated OAI-PMH request
+type request struct {
+	verb string
+	args map[string]string
+}
+
+// ServeHTTP answers GET and POST requests
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	baseURL := h.baseURL(r)
+	req, oaiErr := parseRequest(r)
+	if oaiErr != nil {
+		// Requests with bad verbs or arguments echo only the base URL
+		h.write(w, baseURL, nil, func(enc *xml.Encoder) error { return encodeErrors(enc, oaiErr) })
+		return
+	}
+
+	var body func(*xml.Encoder) error
+	var err error
+	ctx := r.Context()
+	switch req.verb {
+	case "Identify":
+		body, err = h.identify(ctx, baseURL)
+	case "ListMetadataFormats":
+		body, err = h.listMetadataFormats(ctx, req)
+	case "ListSets":
+		body, err = h.listSets(ctx, req)
+	case "ListIdentifiers", "ListRecords":
+		body, err = h.listRecords(ctx, req)
+	case "GetRecord":
+		body, err = h.getRecord(ctx, req)
+	}
+
+	var protocolErr *goharvest.OAIError
+	if errors.As(err, &protocolErr) {
+		body = func(enc *xml.Encoder) error { return encodeErrors(enc, protocolErr) }
+		if protocolErr.Code == goharvest.ErrorCodeBadArgument {
+			req = nil
+		}
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.write(w, baseURL, req, body)
+}
+
+// parseRequest validates the verb and its arguments
+func parseRequest(r *http.Request) (*request, *goharvest.OAIError) {
+	for key, values := range r.Form {
+		if len(values) > 1 {
+			return nil, oaiError(goharvest.ErrorCodeBadArgument, "repeated argument %s", key)
+		}
+	}
+
+	verb := r.Form.Get("verb")
+	spec, ok := verbArguments[verb]
+	if !ok {
+		if verb == "" {
+			return nil, oaiError(goharvest.ErrorCodeBadVerb, "missing verb")
+		}
+		return nil, oaiError(goharvest.ErrorCodeBadVerb, "illegal verb %s", verb)
+	}
+
+	req := &request{verb: verb, args: make(map[string]string)}
+	allowed := map[string]bool{"verb": true}
+	for _, name := range append(spec.required, spec.optional...) {
+		allowed[name] = true
+	}
+	if spec.paged {
+		allowed["resumptionToken"] = true
+	}
+	for key := range r.Form {
+		if !allowed[key] {
+			return nil, oaiError(goharvest.ErrorCodeBadArgument, "illegal argument %s for %s", key, verb)
+		}
+		if key != "verb" {
+			req.args[key] = r.Form.Get(key)
+		}
+	}
+
+	if _, ok := req.args["resumptionToken"]; ok {
+		if len(req.args) > 1 {
+			return nil, oaiError(goharvest.ErrorCodeBadArgument, "resumptionToken is an exclusive argument")
+		}
+		return req, nil
+	}
+	for _, name := range spec.required {
+		if req.args[name] == "" {
+			return nil, oaiError(goharvest.ErrorCodeBadArgument, "missing required argument %s", name)
+		}
+	}
+
+	return req, nil
+}
+
+// identify answers the Identify verb
+func (h *Handler) identify(ctx context.Context, baseURL string) (func(*xml.Encoder) error, error) {
+	identify, err := h.identifyInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if identify.BaseURL == "" {
+		identify.BaseURL = baseURL
+	}
+
+	return func(enc *xml.Encoder) error {
+		return enc.EncodeElement(identify, startElement("Identify"))
+	}, nil
+}
+
+// identifyInfo returns the repository description with protocol defaults filled in
+func (h *Handler) identifyInfo(ctx context.Context) (*goharvest.Identify, error) {
+	info, err := h.repo.Identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	identify := *info
+	if identify.ProtocolVersion == "" {
+		identify.ProtocolVersion = "2.0"
+	}
+	if identify.Granularity == "" {
+		identify.Granularity = goharvest.GranularitySecond
+	}
+	if identify.DeletedRecord == "" {
+		identify.DeletedRecord = goharvest.DeletedRecordNo
+	}
+	return &identify, nil
+}
+
+// listMetadataFormats answers the ListMetadataFormats verb
+func (h *Handler) listMetadataFormats(ctx context.Context, req *request) (func(*xml.Encoder) error, error) {
+	formats, err := h.repo.MetadataFormats(ctx, req.args["identifier"])
+	if err != nil {
+		return nil, err
+	}
+	if len(formats) == 0 {
+		return nil, oaiError(goharvest.ErrorCodeNoMetadataFormats, "no metadata formats available")
+	}
+
+	return func(enc *xml.Encoder) error {
+		return enc.EncodeElement(goharvest.ListMetadataFormats{MetadataFormats: formats}, startElement("ListMetadataFormats"))
+	}, nil
+}
+
+// listSets answers the ListSets verb
+func (h *Handler) listSets(ctx context.Context, req *request) (func(*xml.Encoder) error, error) {
+	offset := 0
+	if token, ok := req.args["resumptionToken"]; ok {
+		state, err := decodeToken(token)
+		if err != nil || state.Verb != req.verb {
+			return nil, oaiError(goharvest.ErrorCodeBadResumptionToken, "invalid resumption token")
+		}
+		offset = state.Offset
+	}
+
+	sets, err := h.repo.Sets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(sets) == 0 {
+		return nil, oaiError(goharvest.ErrorCodeNoSetHierarchy, "the repository does not support sets")
+	}
+	if offset > len(sets) {
+		return nil, oaiError(goharvest.ErrorCodeBadResumptionToken, "resumption token is past the end of the list")
+	}
+
+	end := min(offset+h.opts.PageSize, len(sets))
+	list := goharvest.ListSets{
+		Sets:            sets[offset:end],
+		ResumptionToken: nextToken(tokenState{Verb: req.verb}, offset, end, len(sets)),
+	}
+
+	return func(enc *xml.Encoder) error {
+		return enc.EncodeElement(list, startElement("ListSets"))
+	}, nil
+}
+
+// listRecords answers the ListRecords and ListIdentifiers verbs
+func (h *Handler) listRecords(ctx context.Context, req *request) (func(*xml.Encoder) error, error) {
+	state := tokenState{
+		Verb:   req.verb,
+		Prefix: req.args["metadataPrefix"],
+		Set:    req.args["set"],
+		From:   req.args["from"],
+		Until:  req.args["until"],
+	}
+	if token, ok := req.args["resumptionToken"]; ok {
+		decoded, err := decodeToken(token)
+		if err != nil || decoded.Verb != req.verb {
+			return nil, oaiError(goharvest.ErrorCodeBadResumptionToken, "invalid resumption token")
+		}
+		state = decoded
+	}
+
+	identify, err := h.identifyInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := Query{
+		MetadataPrefix:  state.Prefix,
+		Set:             state.Set,
+		Offset:          state.Offset,
+		Limit:           h.opts.PageSize,
+		IdentifiersOnly: req.verb == "ListIdentifiers",
+	}
+	if query.From, query.Until, err = parseDateRange(state.From, state.Until, identify.Granularity); err != nil {
+		return nil, err
+	}
+
+	formats, err := h.repo.MetadataFormats(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if !goharvest.SupportsMetadataPrefix(formats, state.Prefix) {
+		return nil, oaiError(goharvest.ErrorCodeCannotDisseminateFormat, "metadata format %s is not supported", state.Prefix)
+	}
+	if state.Set != "" {
+		sets, err := h.repo.Sets(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(sets) == 0 {
+			return nil, oaiError(goharvest.ErrorCodeNoSetHierarchy, "the repository does not support sets")
+		}
+	}
+
+	records, total, err := h.repo.Records(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		if state.Offset > 0 {
+			return nil, oaiError(goharvest.ErrorCodeBadResumptionToken, "resumption token is past the end of the list")
+		}
+		return nil, oaiError(goharvest.ErrorCodeNoRecordsMatch, "no records match the request")
+	}
+
+	token := nextToken(state, state.Offset, state.Offset+len(records), total)
+	return func(enc *xml.Encoder) error {
+		start := startElement(req.verb)
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, record := range records {
+			if req.verb == "ListIdentifiers" {
+				err = enc.EncodeElement(record.Header, startElement("header"))
+			} else {
+				err = enc.EncodeElement(newRecordXML(record), startElement("record"))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if token != nil {
+			if err := enc.EncodeElement(token, startElement("resumptionToken")); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	}, nil
+}
+
+// getRecord answers the GetRecord verb
+func (h *Handler) getRecord(ctx context.Context, req *request) (func(*xml.Encoder) error, error) {
+	record, err := h.repo.Record(ctx, req.args["identifier"], req.args["metadataPrefix"])
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, oaiError(goharvest.ErrorCodeIDDoesNotExist, "unknown identifier %s", req.args["identifier"])
+	}
+
+	return func(enc *xml.Encoder) error {
+		start := startElement("GetRecord")
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		if err := enc.EncodeElement(newRecordXML(*record), startElement("record")); err != nil {
+			return err
+		}
+		return enc.EncodeToken(start.End())
+	}, nil
+}
+
+// write sends a response document; req is nil for badVerb and badArgument errors
+func (h *Handler) write(w http.ResponseWriter, baseURL string, req *request, body func(*xml.Encoder) error) {
+	var buf bytes.Buffer
+	buf.WriteString(oaiHeader)
+	enc := xml.NewEncoder(&buf)
+
+	responseDate := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+	err := enc.EncodeElement(responseDate, startElement("responseDate"))
+	if err == nil {
+		start := startElement("request")
+		if req != nil {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "verb"}, Value: req.verb})
+			names := make([]string, 0, len(req.args))
+			for name := range req.args {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: req.args[name]})
+			}
+		}
+		err = enc.EncodeElement(baseURL, start)
+	}
+	if err == nil {
+		err = body(enc)
+	}
+	if err == nil {
+		err = enc.Flush()
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	buf.WriteString("\n</OAI-PMH>\n")
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// baseURL returns the configured base URL or the URL the request was sent to
+func (h *Handler) baseURL(r *http.Request) string {
+	if h.opts.BaseURL != "" {
+		return h.opts.BaseURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+// recordXML is the XML form of a record
+type recordXML struct {
+	Header   goharvest.Header `xml:"header"`
+	Metadata *innerXML        `xml:"metadata,omitempty"`
+}
+
+// innerXML writes pre-serialized XML as element content
+type innerXML struct {
+	Raw []byte `xml:",innerxml"`
+}
+
+// newRecordXML converts a record, omitting the metadata of deleted records
+func newRecordXML(record Record) recordXML {
+	out := recordXML{Header: record.Header}
+	if !record.Header.IsDeleted() && len(record.Metadata) > 0 {
+		out.Metadata = &innerXML{Raw: record.Metadata}
+	}
+	return out
+}
+
+// encodeErrors writes an OAI-PMH error element
+func encodeErrors(enc *xml.Encoder, err *goharvest.OAIError) error {
+	return enc.EncodeElement(err, startElement("error"))
+}
+
+// oaiError builds a protocol error
+func oaiError(code, format string, args ...interface{}) *goharvest.OAIError {
+	return &goharvest.OAIError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// startElement returns a start element in the default namespace
+func startElement(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+// tokenState is the query carried by a resumption token, so paging needs no server state
+type tokenState struct {
+	Verb   string `json:"v"`
+	Prefix string `json:"p,omitempty"`
+	Set    string `json:"s,omitempty"`
+	From   string `json:"f,omitempty"`
+	Until  string `json:"u,omitempty"`
+	Offset int    `json:"o"`
+}
+
+// nextToken returns the resumptionToken element of a page covering [offset, end) of total
+// items: a token for the next page, an empty token on the last page of a paged list, or nil
+// when the whole list fits in one response
+func nextToken(state tokenState, offset, end, total int) *goharvest.ResumptionToken {
+	if offset == 0 && end >= total {
+		return nil
+	}
+
+	token := &goharvest.ResumptionToken{CompleteListSize: total, Cursor: offset}
+	if end < total {
+		state.Offset = end
+		data, _ := json.Marshal(state)
+		token.Token = base64.RawURLEncoding.EncodeToString(data)
+	}
+	return token
+}
+
+// decodeToken parses a resumption token issued by nextToken
+func decodeToken(token string) (tokenState, error) {
+	var state tokenState
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, err
+	}
+	if state.Offset < 0 {
+		return state, fmt.Errorf("negative offset")
+	}
+	return state, nil
+}
+
+// parseDateRange validates from and until against the repository granularity. Until is
+// inclusive, so a day-granularity until covers the whole day.
+func parseDateRange(from, until, granularity string) (time.Time, time.Time, error) {
+	var fromTime, untilTime time.Time
+	var fromDay, untilDay bool
+	var err error
+
+	if from != "" {
+		if fromTime, fromDay, err = parseDatestamp(from, granularity); err != nil {
+			return fromTime, untilTime, err
+		}
+	}
+	if until != "" {
+		if untilTime, untilDay, err = parseDatestamp(until, granularity); err != nil {
+			return fromTime, untilTime, err
+		}
+		if untilDay {
+			untilTime = untilTime.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+	if from != "" && until != "" {
+		if fromDay != untilDay {
+			return fromTime, untilTime, oaiError(goharvest.ErrorCodeBadArgument, "from and until must have the same granularity")
+		}
+		if untilTime.Before(fromTime) {
+			return fromTime, untilTime, oaiError(goharvest.ErrorCodeNoRecordsMatch, "until is before from")
+		}
+	}
+
+	return fromTime, untilTime, nil
+}
+
+// parseDatestamp parses a from or until argument, reporting whether it has day granularity
+func parseDatestamp(value, granularity string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse("2006-01-02T15:04:05Z", value); err == nil {
+		if granularity == goharvest.GranularityDay {
+			return t, false, oaiError(goharvest.ErrorCodeBadArgument, "%s is finer than the repository granularity", value)
+		}
+		return t, false, nil
+	}
+	return time.Time{}, false, oaiError(goharvest.ErrorCodeBadArgument, "invalid datestamp %s", value)
+}
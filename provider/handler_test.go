@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiharal/goharvest"
+)
+
+// testRepository serves five Dublin Core records, the fourth deleted
+type testRepository struct {
+	queries []Query
+}
+
+func (r *testRepository) Identify(context.Context) (*goharvest.Identify, error) {
+	return &goharvest.Identify{
+		RepositoryName:    "Test Repository",
+		AdminEmail:        []string{"admin@example.org"},
+		EarliestDatestamp: "2024-01-01",
+		DeletedRecord:     goharvest.DeletedRecordPersistent,
+		Granularity:       goharvest.GranularityDay,
+	}, nil
+}
+
+func (r *testRepository) MetadataFormats(_ context.Context, identifier string) ([]goharvest.MetadataFormatInfo, error) {
+	if identifier != "" && r.find(identifier) == nil {
+		return nil, goharvest.ErrIDDoesNotExist
+	}
+	return []goharvest.MetadataFormatInfo{{
+		MetadataPrefix:    "oai_dc",
+		Schema:            "http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
+		MetadataNamespace: "http://www.openarchives.org/OAI/2.0/oai_dc/",
+	}}, nil
+}
+
+func (r *testRepository) Sets(context.Context) ([]goharvest.Set, error) {
+	return []goharvest.Set{{SetSpec: "buku", SetName: "Buku"}, {SetSpec: "jurnal", SetName: "Jurnal"}}, nil
+}
+
+func (r *testRepository) Records(_ context.Context, query Query) ([]Record, int, error) {
+	r.queries = append(r.queries, query)
+
+	var matches []Record
+	for _, record := range r.all() {
+		datestamp, _ := time.Parse("2006-01-02", record.Header.DateStamp)
+		if !query.From.IsZero() && datestamp.Before(query.From) || !query.Until.IsZero() && datestamp.After(query.Until) {
+			continue
+		}
+		if query.Set != "" && (len(record.Header.SetSpec) == 0 || record.Header.SetSpec[0] != query.Set) {
+			continue
+		}
+		matches = append(matches, record)
+	}
+
+	total := len(matches)
+	if query.Offset >= total {
+		return nil, total, nil
+	}
+	return matches[query.Offset:min(query.Offset+query.Limit, total)], total, nil
+}
+
+func (r *testRepository) Record(_ context.Context, identifier, metadataPrefix string) (*Record, error) {
+	if metadataPrefix != "oai_dc" {
+		return nil, goharvest.ErrCannotDisseminateFormat
+	}
+	return r.find(identifier), nil
+}
+
+func (r *testRepository) find(identifier string) *Record {
+	for _, record := range r.all() {
+		if record.Header.Identifier == identifier {
+			return &record
+		}
+	}
+	return nil
+}
+
+func (r *testRepository) all() []Record {
+	var records []Record
+	for i := 1; i <= 5; i++ {
+		record := Record{Header: goharvest.Header{
+			Identifier: fmt.Sprintf("oai:example.org:%d", i),
+			DateStamp:  fmt.Sprintf("2024-01-0%d", i),
+			SetSpec:    []string{"buku"},
+		}}
+		if i == 4 {
+			record.Header.Status = goharvest.HeaderStatusDeleted
+		} else {
+			record.Metadata = []byte(fmt.Sprintf(`<oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Buku %d</dc:title></oai_dc:dc>`, i))
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// get performs a request and returns the body
+func get(t *testing.T, server *httptest.Server, query string) string {
+	t.Helper()
+	resp, err := http.Get(server.URL + "/oai?" + query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status %d for %s", resp.StatusCode, query)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	return string(data)
+}
+
+func TestHandlerHarvestRoundTrip(t *testing.T) {
+	repo := &testRepository{}
+	server := httptest.NewServer(NewHandler(repo, Options{PageSize: 2}))
+	defer server.Close()
+
+	client := goharvest.NewClient(server.URL + "/oai")
+	var titles []string
+	deleted := 0
+	err := client.HarvestRecords("oai_dc", &goharvest.HarvestOptions{Set: "buku"}, func(record goharvest.HarvestedRecord) error {
+		if record.IsDeleted() {
+			deleted++
+			return nil
+		}
+		titles = append(titles, record.Metadata.(*goharvest.DublinCore).Title[0])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestRecords failed: %v", err)
+	}
+	if strings.Join(titles, ",") != "Buku 1,Buku 2,Buku 3,Buku 5" || deleted != 1 {
+		t.Errorf("Unexpected harvest %v (%d deleted)", titles, deleted)
+	}
+	if len(repo.queries) != 3 || repo.queries[2].Offset != 4 || repo.queries[2].Set != "buku" {
+		t.Errorf("Unexpected repository queries %+v", repo.queries)
+	}
+
+	identify, err := client.Identify(context.Background())
+	if err != nil || identify.BaseURL != server.URL+"/oai" || identify.ProtocolVersion != "2.0" {
+		t.Errorf("Unexpected Identify %+v, %v", identify, err)
+	}
+
+	sets, err := client.ListSets(context.Background())
+	if err != nil || len(sets) != 2 {
+		t.Errorf("Unexpected sets %+v, %v", sets, err)
+	}
+
+	resp, err := client.GetRecord(context.Background(), "oai:example.org:2", "oai_dc")
+	if err != nil || len(resp.GetRecords()) != 1 {
+		t.Errorf("GetRecord failed: %v", err)
+	}
+
+	if _, err := client.GetRecord(context.Background(), "oai:example.org:9", "oai_dc"); err == nil {
+		t.Error("Expected idDoesNotExist for unknown record")
+	}
+}
+
+func TestHandlerListIdentifiersDateRange(t *testing.T) {
+	server := httptest.NewServer(NewHandler(&testRepository{}, Options{}))
+	defer server.Close()
+
+	body := get(t, server, "verb=ListIdentifiers&metadataPrefix=oai_dc&from=2024-01-02&until=2024-01-04")
+	var doc struct {
+		Request struct {
+			Verb  string `xml:"verb,attr"`
+			From  string `xml:"from,attr"`
+			Until string `xml:"until,attr"`
+		} `xml:"request"`
+		Headers []goharvest.Header `xml:"ListIdentifiers>header"`
+		Token   *struct{}          `xml:"ListIdentifiers>resumptionToken"`
+	}
+	if err := xml.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Request.Verb != "ListIdentifiers" || doc.Request.From != "2024-01-02" || doc.Request.Until != "2024-01-04" {
+		t.Errorf("Unexpected request element %+v", doc.Request)
+	}
+	if len(doc.Headers) != 3 || !doc.Headers[2].IsDeleted() || doc.Token != nil {
+		t.Errorf("Unexpected headers %+v", doc.Headers)
+	}
+	if strings.Contains(body, "<metadata>") {
+		t.Error("ListIdentifiers must not include metadata")
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	server := httptest.NewServer(NewHandler(&testRepository{}, Options{}))
+	defer server.Close()
+
+	tests := []struct {
+		query string
+		code  string
+	}{
+		{"", goharvest.ErrorCodeBadVerb},
+		{"verb=Harvest", goharvest.ErrorCodeBadVerb},
+		{"verb=Identify&set=buku", goharvest.ErrorCodeBadArgument},
+		{"verb=ListRecords", goharvest.ErrorCodeBadArgument},
+		{"verb=ListRecords&metadataPrefix=oai_dc&metadataPrefix=mods", goharvest.ErrorCodeBadArgument},
+		{"verb=ListRecords&metadataPrefix=oai_dc&resumptionToken=x", goharvest.ErrorCodeBadArgument},
+		{"verb=ListRecords&metadataPrefix=oai_dc&from=2024-01-01T00:00:00Z", goharvest.ErrorCodeBadArgument},
+		{"verb=ListRecords&metadataPrefix=oai_dc&from=yesterday", goharvest.ErrorCodeBadArgument},
+		{"verb=ListRecords&metadataPrefix=mods", goharvest.ErrorCodeCannotDisseminateFormat},
+		{"verb=ListRecords&metadataPrefix=oai_dc&set=jurnal", goharvest.ErrorCodeNoRecordsMatch},
+		{"verb=ListRecords&resumptionToken=bogus", goharvest.ErrorCodeBadResumptionToken},
+		{"verb=GetRecord&identifier=oai:example.org:1&metadataPrefix=mods", goharvest.ErrorCodeCannotDisseminateFormat},
+		{"verb=GetRecord&identifier=oai:example.org:9&metadataPrefix=oai_dc", goharvest.ErrorCodeIDDoesNotExist},
+		{"verb=ListMetadataFormats&identifier=oai:example.org:9", goharvest.ErrorCodeIDDoesNotExist},
+	}
+	for _, tt := range tests {
+		body := get(t, server, tt.query)
+		var doc struct {
+			Request struct {
+				Verb string `xml:"verb,attr"`
+			} `xml:"request"`
+			Error goharvest.OAIError `xml:"error"`
+		}
+		if err := xml.Unmarshal([]byte(body), &doc); err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if doc.Error.Code != tt.code {
+			t.Errorf("%s: expected %s, got %q", tt.query, tt.code, doc.Error.Code)
+		}
+		if (tt.code == goharvest.ErrorCodeBadVerb || tt.code == goharvest.ErrorCodeBadArgument) && doc.Request.Verb != "" {
+			t.Errorf("%s: request element must not echo arguments", tt.query)
+		}
+	}
+
+	resp, err := http.PostForm(server.URL, url.Values{"verb": {"Identify"}})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("POST Identify failed: %v", err)
+	}
+	resp.Body.Close()
+}
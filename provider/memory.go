@@ -0,0 +1,260 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jiharal/goharvest"
+)
+
+// MemoryRepository is an in-memory RecordRepository for tests and demos. It can be filled
+// with Put or used directly as a harvest sink (Write, Delete, Flush, Close), so harvested
+// records can be republished as they arrive.
+type MemoryRepository struct {
+	mu       sync.RWMutex
+	identify goharvest.Identify
+	formats  map[string]goharvest.MetadataFormatInfo
+	sets     map[string]goharvest.Set
+	items    map[string]*memoryItem
+}
+
+// memoryItem is a stored record with its metadata per prefix
+type memoryItem struct {
+	header   goharvest.Header
+	metadata map[string][]byte
+}
+
+// NewMemoryRepository returns an empty repository described by identify
+func NewMemoryRepository(identify goharvest.Identify) *MemoryRepository {
+	if identify.DeletedRecord == "" {
+		identify.DeletedRecord = goharvest.DeletedRecordPersistent
+	}
+	return &MemoryRepository{
+		identify: identify,
+		formats:  make(map[string]goharvest.MetadataFormatInfo),
+		sets:     make(map[string]goharvest.Set),
+		items:    make(map[string]*memoryItem),
+	}
+}
+
+// AddFormat registers a metadata format; Put registers unknown prefixes automatically
+func (m *MemoryRepository) AddFormat(info goharvest.MetadataFormatInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.formats[info.MetadataPrefix] = info
+}
+
+// AddSet registers a set, e.g. to give it a name; Put registers unknown setSpecs automatically
+func (m *MemoryRepository) AddSet(set goharvest.Set) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sets[set.SetSpec] = set
+}
+
+// Put stores metadata of a record in one format, replacing the header. Other formats of
+// the record are kept; a deleted header drops all metadata.
+func (m *MemoryRepository) Put(header goharvest.Header, metadataPrefix string, metadata []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[header.Identifier]
+	if !ok || header.IsDeleted() {
+		item = &memoryItem{metadata: make(map[string][]byte)}
+		m.items[header.Identifier] = item
+	}
+	item.header = header
+	if !header.IsDeleted() && metadataPrefix != "" {
+		item.metadata[metadataPrefix] = metadata
+		if _, ok := m.formats[metadataPrefix]; !ok {
+			m.formats[metadataPrefix] = formatInfo(metadataPrefix)
+		}
+	}
+	for _, spec := range header.SetSpec {
+		if _, ok := m.sets[spec]; !ok {
+			m.sets[spec] = goharvest.Set{SetSpec: spec, SetName: spec}
+		}
+	}
+}
+
+// Write stores a harvested record with its raw metadata XML
+func (m *MemoryRepository) Write(record goharvest.HarvestedRecord) error {
+	prefix := ""
+	if record.Metadata != nil {
+		prefix = string(record.Metadata.GetFormat())
+	}
+	m.Put(record.Header, prefix, record.Raw)
+	return nil
+}
+
+// Delete marks a record as deleted, datestamped now
+func (m *MemoryRepository) Delete(identifier string) error {
+	m.mu.RLock()
+	var sets []string
+	if item, ok := m.items[identifier]; ok {
+		sets = item.header.SetSpec
+	}
+	m.mu.RUnlock()
+
+	m.Put(goharvest.Header{
+		Identifier: identifier,
+		DateStamp:  time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		Status:     goharvest.HeaderStatusDeleted,
+		SetSpec:    sets,
+	}, "", nil)
+	return nil
+}
+
+// Flush is a no-op
+func (m *MemoryRepository) Flush() error {
+	return nil
+}
+
+// Close is a no-op
+func (m *MemoryRepository) Close() error {
+	return nil
+}
+
+// Len returns the number of stored records, including deleted ones
+func (m *MemoryRepository) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.items)
+}
+
+// Identify returns the repository description, with the earliest datestamp computed
+// from the records when not set
+func (m *MemoryRepository) Identify(context.Context) (*goharvest.Identify, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	identify := m.identify
+	if identify.EarliestDatestamp == "" {
+		for _, item := range m.items {
+			if identify.EarliestDatestamp == "" || item.header.DateStamp < identify.EarliestDatestamp {
+				identify.EarliestDatestamp = item.header.DateStamp
+			}
+		}
+	}
+	return &identify, nil
+}
+
+// MetadataFormats lists all formats, or those a record is available in
+func (m *MemoryRepository) MetadataFormats(_ context.Context, identifier string) ([]goharvest.MetadataFormatInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var formats []goharvest.MetadataFormatInfo
+	if identifier == "" {
+		for _, info := range m.formats {
+			formats = append(formats, info)
+		}
+	} else {
+		item, ok := m.items[identifier]
+		if !ok {
+			return nil, goharvest.ErrIDDoesNotExist
+		}
+		for prefix := range item.metadata {
+			formats = append(formats, m.formats[prefix])
+		}
+	}
+
+	sort.Slice(formats, func(i, j int) bool { return formats[i].MetadataPrefix < formats[j].MetadataPrefix })
+	return formats, nil
+}
+
+// Sets lists the registered sets by setSpec
+func (m *MemoryRepository) Sets(context.Context) ([]goharvest.Set, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sets := make([]goharvest.Set, 0, len(m.sets))
+	for _, set := range m.sets {
+		sets = append(sets, set)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].SetSpec < sets[j].SetSpec })
+	return sets, nil
+}
+
+// Records returns matching records ordered by identifier
+func (m *MemoryRepository) Records(_ context.Context, query Query) ([]Record, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matches []*memoryItem
+	for _, item := range m.items {
+		if _, ok := item.metadata[query.MetadataPrefix]; !ok && !item.header.IsDeleted() {
+			continue
+		}
+		if query.Set != "" && !inSet(item.header.SetSpec, query.Set) {
+			continue
+		}
+		if !inDateRange(item.header.DateStamp, query.From, query.Until) {
+			continue
+		}
+		matches = append(matches, item)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].header.Identifier < matches[j].header.Identifier })
+
+	total := len(matches)
+	if query.Offset >= total {
+		return nil, total, nil
+	}
+	end := total
+	if query.Limit > 0 {
+		end = min(query.Offset+query.Limit, total)
+	}
+
+	records := make([]Record, 0, end-query.Offset)
+	for _, item := range matches[query.Offset:end] {
+		record := Record{Header: item.header}
+		if !query.IdentifiersOnly {
+			record.Metadata = item.metadata[query.MetadataPrefix]
+		}
+		records = append(records, record)
+	}
+	return records, total, nil
+}
+
+// Record returns one record in a metadata format
+func (m *MemoryRepository) Record(_ context.Context, identifier, metadataPrefix string) (*Record, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	item, ok := m.items[identifier]
+	if !ok {
+		return nil, goharvest.ErrIDDoesNotExist
+	}
+	metadata, ok := item.metadata[metadataPrefix]
+	if !ok && !item.header.IsDeleted() {
+		return nil, goharvest.ErrCannotDisseminateFormat
+	}
+	return &Record{Header: item.header, Metadata: metadata}, nil
+}
+
+// inSet reports whether a record with setSpecs belongs to set, including its subsets
+func inSet(setSpecs []string, set string) bool {
+	for _, spec := range setSpecs {
+		if spec == set || strings.HasPrefix(spec, set+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// inDateRange reports whether a datestamp lies within the inclusive bounds
+func inDateRange(datestamp string, from, until time.Time) bool {
+	if from.IsZero() && until.IsZero() {
+		return true
+	}
+
+	t, err := time.Parse("2006-01-02T15:04:05Z", datestamp)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02", datestamp); err != nil {
+			return false
+		}
+	}
+	return (from.IsZero() || !t.Before(from)) && (until.IsZero() || !t.After(until))
+}
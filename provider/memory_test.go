@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jiharal/goharvest"
+	"github.com/jiharal/goharvest/sink"
+)
+
+var _ sink.Sink = (*MemoryRepository)(nil)
+
+func TestMemoryRepositoryRepublish(t *testing.T) {
+	upstream := httptest.NewServer(NewHandler(&testRepository{}, Options{PageSize: 2}))
+	defer upstream.Close()
+
+	// Harvest upstream into the repository, then serve it again
+	repo := NewMemoryRepository(goharvest.Identify{RepositoryName: "Mirror"})
+	repo.AddSet(goharvest.Set{SetSpec: "buku", SetName: "Koleksi Buku"})
+	if err := goharvest.NewClient(upstream.URL).HarvestRecords("oai_dc", nil, repo.Write); err != nil {
+		t.Fatalf("Harvest into repository failed: %v", err)
+	}
+	if repo.Len() != 5 {
+		t.Fatalf("Expected 5 stored records, got %d", repo.Len())
+	}
+	if err := repo.Delete("oai:example.org:5"); err != nil {
+		t.Fatal(err)
+	}
+
+	mirror := httptest.NewServer(NewHandler(repo, Options{PageSize: 3}))
+	defer mirror.Close()
+
+	var live, deleted int
+	err := goharvest.NewClient(mirror.URL).HarvestRecords("oai_dc", &goharvest.HarvestOptions{Set: "buku"}, func(record goharvest.HarvestedRecord) error {
+		if record.IsDeleted() {
+			deleted++
+		} else if record.Metadata.(*goharvest.DublinCore).Title[0] == "" {
+			t.Errorf("Missing title for %s", record.Header.Identifier)
+		} else {
+			live++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Harvest from mirror failed: %v", err)
+	}
+	if live != 3 || deleted != 2 {
+		t.Errorf("Expected 3 live and 2 deleted records, got %d and %d", live, deleted)
+	}
+
+	ctx := context.Background()
+	identify, _ := repo.Identify(ctx)
+	if identify.EarliestDatestamp != "2024-01-01" || identify.DeletedRecord != goharvest.DeletedRecordPersistent {
+		t.Errorf("Unexpected Identify %+v", identify)
+	}
+	sets, _ := repo.Sets(ctx)
+	if len(sets) != 1 || sets[0].SetName != "Koleksi Buku" {
+		t.Errorf("Unexpected sets %+v", sets)
+	}
+	formats, _ := repo.MetadataFormats(ctx, "oai:example.org:1")
+	if len(formats) != 1 || formats[0].MetadataNamespace != "http://www.openarchives.org/OAI/2.0/oai_dc/" {
+		t.Errorf("Unexpected formats %+v", formats)
+	}
+	if _, err := repo.Record(ctx, "oai:example.org:1", "mods"); !errors.Is(err, goharvest.ErrCannotDisseminateFormat) {
+		t.Errorf("Expected cannotDisseminateFormat, got %v", err)
+	}
+	if _, err := repo.Record(ctx, "oai:example.org:9", "oai_dc"); !errors.Is(err, goharvest.ErrIDDoesNotExist) {
+		t.Errorf("Expected idDoesNotExist, got %v", err)
+	}
+}
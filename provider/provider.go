@@ -0,0 +1,50 @@
+// Package provider serves records over OAI-PMH, making goharvest usable on both sides of the
+// protocol, e.g. to re-expose harvested data from an aggregator.
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/jiharal/goharvest"
+)
+
+// Record is a record served by the provider
+type Record struct {
+	Header goharvest.Header
+	// Metadata is the serialized metadata element content in the requested format,
+	// e.g. an <oai_dc:dc> document; nil for deleted records
+	Metadata []byte
+}
+
+// Query selects the records of a ListRecords or ListIdentifiers request
+type Query struct {
+	MetadataPrefix string
+	// Set restricts the records to a setSpec (empty for all sets)
+	Set string
+	// From and Until bound the record datestamps inclusively (zero for no bound)
+	From  time.Time
+	Until time.Time
+	// Offset is the number of matching records to skip and Limit the page size
+	Offset int
+	Limit  int
+	// IdentifiersOnly is set for ListIdentifiers, where Metadata is not needed
+	IdentifiersOnly bool
+}
+
+// RecordRepository is the data source of a Handler. Methods may return goharvest OAI
+// errors (e.g. goharvest.ErrIDDoesNotExist), which are sent to the harvester as such;
+// other errors become HTTP 500 responses.
+type RecordRepository interface {
+	// Identify describes the repository; empty BaseURL, ProtocolVersion, Granularity and
+	// DeletedRecord are filled in by the handler
+	Identify(ctx context.Context) (*goharvest.Identify, error)
+	// MetadataFormats lists the formats of the repository, or of one record if identifier is set
+	MetadataFormats(ctx context.Context, identifier string) ([]goharvest.MetadataFormatInfo, error)
+	// Sets lists the set hierarchy, empty when the repository does not support sets
+	Sets(ctx context.Context) ([]goharvest.Set, error)
+	// Records returns one page of matching records ordered stably, and the total number of matches
+	Records(ctx context.Context, query Query) ([]Record, int, error)
+	// Record returns one record in a metadata format
+	Record(ctx context.Context, identifier, metadataPrefix string) (*Record, error)
+}
@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jiharal/goharvest"
+)
+
+// tableNamePattern restricts table names, which cannot be passed as query parameters
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLiteOptions configures a SQLiteRepository
+type SQLiteOptions struct {
+	// Table is the records table written by sink.SQLiteSink (default "records")
+	Table string
+	// Identify describes the repository
+	Identify goharvest.Identify
+	// Sets names the sets; setSpecs found in the table without an entry are named by their spec
+	Sets []goharvest.Set
+}
+
+// SQLiteRepository serves the records table maintained by sink.SQLiteSink, for a
+// harvest-then-republish pipeline. The sink must store the metadata XML, so run it with
+// IncludeRaw set; each record is served in the format it was harvested in.
+type SQLiteRepository struct {
+	db   *sql.DB
+	opts SQLiteOptions
+}
+
+// NewSQLiteRepository returns a repository reading the records table of db
+func NewSQLiteRepository(db *sql.DB, opts SQLiteOptions) (*SQLiteRepository, error) {
+	if opts.Table == "" {
+		opts.Table = "records"
+	}
+	if !tableNamePattern.MatchString(opts.Table) {
+		return nil, fmt.Errorf("invalid table name %q", opts.Table)
+	}
+	if opts.Identify.DeletedRecord == "" {
+		opts.Identify.DeletedRecord = goharvest.DeletedRecordPersistent
+	}
+	return &SQLiteRepository{db: db, opts: opts}, nil
+}
+
+// Identify returns the configured description, with the earliest datestamp read from
+// the table when not set
+func (s *SQLiteRepository) Identify(ctx context.Context) (*goharvest.Identify, error) {
+	identify := s.opts.Identify
+	if identify.EarliestDatestamp == "" {
+		var earliest sql.NullString
+		err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT MIN(datestamp) FROM %s`, s.opts.Table)).Scan(&earliest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read earliest datestamp: %w", err)
+		}
+		identify.EarliestDatestamp = earliest.String
+	}
+	return &identify, nil
+}
+
+// MetadataFormats lists the formats stored in the table, or the format of one record
+func (s *SQLiteRepository) MetadataFormats(ctx context.Context, identifier string) ([]goharvest.MetadataFormatInfo, error) {
+	var prefixes []string
+	if identifier == "" {
+		rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT DISTINCT format FROM %s WHERE format <> '' ORDER BY format`, s.opts.Table))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list formats: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var prefix string
+			if err := rows.Scan(&prefix); err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, prefix)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		var prefix string
+		err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT format FROM %s WHERE identifier = ?`, s.opts.Table), identifier).Scan(&prefix)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, goharvest.ErrIDDoesNotExist
+		}
+		if err != nil {
+			return nil, err
+		}
+		if prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	formats := make([]goharvest.MetadataFormatInfo, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		formats = append(formats, formatInfo(prefix))
+	}
+	return formats, nil
+}
+
+// Sets lists the configured sets and every setSpec found in the table
+func (s *SQLiteRepository) Sets(ctx context.Context) ([]goharvest.Set, error) {
+	sets := make(map[string]goharvest.Set)
+	for _, set := range s.opts.Sets {
+		sets[set.SetSpec] = set
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT DISTINCT set_specs FROM %s WHERE set_specs <> '[]'`, s.opts.Table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sets: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var specs []string
+		if err := json.Unmarshal([]byte(data), &specs); err != nil {
+			continue
+		}
+		for _, spec := range specs {
+			if _, ok := sets[spec]; !ok {
+				sets[spec] = goharvest.Set{SetSpec: spec, SetName: spec}
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	list := make([]goharvest.Set, 0, len(sets))
+	for _, set := range sets {
+		list = append(list, set)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].SetSpec < list[j].SetSpec })
+	return list, nil
+}
+
+// Records queries matching records ordered by identifier
+func (s *SQLiteRepository) Records(ctx context.Context, query Query) ([]Record, int, error) {
+	where := []string{"(format = ? OR deleted = 1)"}
+	args := []interface{}{query.MetadataPrefix}
+	if query.Set != "" {
+		// set_specs is a JSON array; match the set itself and its subsets
+		where = append(where, "(instr(set_specs, ?) > 0 OR instr(set_specs, ?) > 0)")
+		args = append(args, `"`+query.Set+`"`, `"`+query.Set+`:`)
+	}
+	if !query.From.IsZero() {
+		where = append(where, "datestamp >= ?")
+		args = append(args, fromDatestamp(query.From))
+	}
+	if !query.Until.IsZero() {
+		where = append(where, "datestamp <= ?")
+		args = append(args, query.Until.UTC().Format("2006-01-02T15:04:05Z"))
+	}
+	condition := strings.Join(where, " AND ")
+
+	var total int
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, s.opts.Table, condition), args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count records: %w", err)
+	}
+
+	columns := "identifier, datestamp, set_specs, deleted, raw"
+	if query.IdentifiersOnly {
+		columns = "identifier, datestamp, set_specs, deleted, NULL"
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY identifier LIMIT ? OFFSET ?`, columns, s.opts.Table, condition),
+		append(args, limit, query.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// Record returns one record, which is only available in the format it was harvested in
+func (s *SQLiteRepository) Record(ctx context.Context, identifier, metadataPrefix string) (*Record, error) {
+	row := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT identifier, datestamp, set_specs, deleted, raw, format FROM %s WHERE identifier = ?`, s.opts.Table), identifier)
+
+	var format string
+	record, err := scanRecord(row, &format)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, goharvest.ErrIDDoesNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if format != metadataPrefix && !record.Header.IsDeleted() {
+		return nil, goharvest.ErrCannotDisseminateFormat
+	}
+	return &record, nil
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRecord reads identifier, datestamp, set_specs, deleted and raw, plus any extra columns
+func scanRecord(row scanner, extra ...interface{}) (Record, error) {
+	var (
+		record   Record
+		setSpecs string
+		deleted  bool
+		raw      sql.NullString
+	)
+	dest := append([]interface{}{&record.Header.Identifier, &record.Header.DateStamp, &setSpecs, &deleted, &raw}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return record, err
+	}
+
+	if setSpecs != "" && setSpecs != "[]" {
+		if err := json.Unmarshal([]byte(setSpecs), &record.Header.SetSpec); err != nil {
+			return record, fmt.Errorf("invalid set_specs of record %s: %w", record.Header.Identifier, err)
+		}
+	}
+	if deleted {
+		record.Header.Status = goharvest.HeaderStatusDeleted
+	} else if raw.Valid {
+		record.Metadata = []byte(raw.String)
+	}
+	return record, nil
+}
+
+// fromDatestamp formats a lower bound so day-granularity datestamps on that day still match
+func fromDatestamp(t time.Time) string {
+	t = t.UTC()
+	if t.Equal(t.Truncate(24 * time.Hour)) {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02T15:04:05Z")
+}
@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jiharal/goharvest"
+)
+
+// scriptedDriver is a database/sql driver answering queries with canned rows, matched by
+// a substring of the query, and recording the queries it receives
+type scriptedDriver struct {
+	mu        sync.Mutex
+	responses []scriptedResponse
+	queries   []scriptedQuery
+}
+
+type scriptedResponse struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+type scriptedQuery struct {
+	Query string
+	Args  []driver.Value
+}
+
+var scriptedDriverCount atomic.Int64
+
+func openScriptedDB(responses ...scriptedResponse) (*sql.DB, *scriptedDriver) {
+	d := &scriptedDriver{responses: responses}
+	name := fmt.Sprintf("scripted-%d", scriptedDriverCount.Add(1))
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		panic(err)
+	}
+	return db, d
+}
+
+func (d *scriptedDriver) Open(string) (driver.Conn, error) { return &scriptedConn{d: d}, nil }
+
+type scriptedConn struct{ d *scriptedDriver }
+
+func (c *scriptedConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *scriptedConn) Close() error                        { return nil }
+func (c *scriptedConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *scriptedConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.d.queries = append(c.d.queries, scriptedQuery{Query: query, Args: values})
+
+	for _, response := range c.d.responses {
+		if strings.Contains(query, response.match) {
+			return &scriptedRows{columns: response.columns, rows: response.rows}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+type scriptedRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *scriptedRows) Columns() []string { return r.columns }
+func (r *scriptedRows) Close() error      { return nil }
+func (r *scriptedRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQLiteRepositoryRecords(t *testing.T) {
+	db, d := openScriptedDB(
+		scriptedResponse{match: "COUNT(*)", columns: []string{"count"}, rows: [][]driver.Value{{int64(3)}}},
+		scriptedResponse{match: "ORDER BY identifier", columns: []string{"identifier", "datestamp", "set_specs", "deleted", "raw"}, rows: [][]driver.Value{
+			{"oai:example.org:1", "2024-01-01", `["buku"]`, int64(0), "<oai_dc:dc/>"},
+			{"oai:example.org:2", "2024-01-02T10:00:00Z", `[]`, int64(1), nil},
+		}},
+	)
+	defer db.Close()
+
+	repo, err := NewSQLiteRepository(db, SQLiteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, total, err := repo.Records(context.Background(), Query{
+		MetadataPrefix: "oai_dc",
+		Set:            "buku",
+		From:           time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until:          time.Date(2024, 1, 2, 23, 59, 59, 0, time.UTC),
+		Offset:         0,
+		Limit:          2,
+	})
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	if total != 3 || len(records) != 2 {
+		t.Fatalf("Expected 2 of 3 records, got %d of %d", len(records), total)
+	}
+	if records[0].Header.SetSpec[0] != "buku" || string(records[0].Metadata) != "<oai_dc:dc/>" {
+		t.Errorf("Unexpected first record %+v", records[0])
+	}
+	if !records[1].Header.IsDeleted() || records[1].Metadata != nil {
+		t.Errorf("Expected deleted second record, got %+v", records[1])
+	}
+
+	query := d.queries[1]
+	if !strings.Contains(query.Query, "FROM records WHERE (format = ? OR deleted = 1) AND (instr(set_specs, ?) > 0") {
+		t.Errorf("Unexpected query %q", query.Query)
+	}
+	want := []driver.Value{"oai_dc", `"buku"`, `"buku:`, "2024-01-01", "2024-01-02T23:59:59Z", int64(2), int64(0)}
+	if fmt.Sprint(query.Args) != fmt.Sprint(want) {
+		t.Errorf("Unexpected args %v, want %v", query.Args, want)
+	}
+}
+
+func TestSQLiteRepositoryMetadata(t *testing.T) {
+	db, _ := openScriptedDB(
+		scriptedResponse{match: "MIN(datestamp)", columns: []string{"min"}, rows: [][]driver.Value{{"2023-05-01"}}},
+		scriptedResponse{match: "DISTINCT format", columns: []string{"format"}, rows: [][]driver.Value{{"marcxml"}, {"oai_dc"}}},
+		scriptedResponse{match: "DISTINCT set_specs", columns: []string{"set_specs"}, rows: [][]driver.Value{{`["buku","buku:fiksi"]`}, {`["jurnal"]`}}},
+		scriptedResponse{match: "WHERE identifier = ?", columns: []string{"identifier", "datestamp", "set_specs", "deleted", "raw", "format"}, rows: [][]driver.Value{
+			{"oai:example.org:1", "2024-01-01", `[]`, int64(0), "<record/>", "marcxml"},
+		}},
+	)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo, _ := NewSQLiteRepository(db, SQLiteOptions{
+		Identify: goharvest.Identify{RepositoryName: "Mirror"},
+		Sets:     []goharvest.Set{{SetSpec: "buku", SetName: "Buku"}},
+	})
+
+	identify, err := repo.Identify(ctx)
+	if err != nil || identify.EarliestDatestamp != "2023-05-01" {
+		t.Errorf("Unexpected Identify %+v, %v", identify, err)
+	}
+	formats, err := repo.MetadataFormats(ctx, "")
+	if err != nil || len(formats) != 2 || formats[0].MetadataNamespace != "http://www.loc.gov/MARC21/slim" {
+		t.Errorf("Unexpected formats %+v, %v", formats, err)
+	}
+	sets, err := repo.Sets(ctx)
+	if err != nil || len(sets) != 3 || sets[0].SetName != "Buku" || sets[1].SetSpec != "buku:fiksi" {
+		t.Errorf("Unexpected sets %+v, %v", sets, err)
+	}
+	record, err := repo.Record(ctx, "oai:example.org:1", "marcxml")
+	if err != nil || string(record.Metadata) != "<record/>" {
+		t.Errorf("Unexpected record %+v, %v", record, err)
+	}
+	if _, err := repo.Record(ctx, "oai:example.org:1", "oai_dc"); !errors.Is(err, goharvest.ErrCannotDisseminateFormat) {
+		t.Errorf("Expected cannotDisseminateFormat, got %v", err)
+	}
+	if _, err := NewSQLiteRepository(db, SQLiteOptions{Table: "x; DROP"}); err == nil {
+		t.Error("Expected invalid table name error")
+	}
+}
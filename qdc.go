@@ -0,0 +1,313 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// QualifiedDC represents Qualified Dublin Core metadata (dc elements plus dcterms refinements)
+type QualifiedDC struct {
+	XMLName xml.Name `xml:"qualifieddc"`
+
+	// Dublin Core element set
+	Title       []string `xml:"http://purl.org/dc/elements/1.1/ title"`
+	Creator     []string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Subject     []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	Description []string `xml:"http://purl.org/dc/elements/1.1/ description"`
+	Publisher   []string `xml:"http://purl.org/dc/elements/1.1/ publisher"`
+	Contributor []string `xml:"http://purl.org/dc/elements/1.1/ contributor"`
+	Date        []string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Type        []string `xml:"http://purl.org/dc/elements/1.1/ type"`
+	Format      []string `xml:"http://purl.org/dc/elements/1.1/ format"`
+	Identifier  []string `xml:"http://purl.org/dc/elements/1.1/ identifier"`
+	Source      []string `xml:"http://purl.org/dc/elements/1.1/ source"`
+	Language    []string `xml:"http://purl.org/dc/elements/1.1/ language"`
+	Relation    []string `xml:"http://purl.org/dc/elements/1.1/ relation"`
+	Coverage    []string `xml:"http://purl.org/dc/elements/1.1/ coverage"`
+	Rights      []string `xml:"http://purl.org/dc/elements/1.1/ rights"`
+
+	// DCMI Metadata Terms refinements
+	Alternative           []string `xml:"http://purl.org/dc/terms/ alternative"`
+	Abstract              []string `xml:"http://purl.org/dc/terms/ abstract"`
+	TableOfContents       []string `xml:"http://purl.org/dc/terms/ tableOfContents"`
+	Created               []string `xml:"http://purl.org/dc/terms/ created"`
+	Issued                []string `xml:"http://purl.org/dc/terms/ issued"`
+	Modified              []string `xml:"http://purl.org/dc/terms/ modified"`
+	Available             []string `xml:"http://purl.org/dc/terms/ available"`
+	DateAccepted          []string `xml:"http://purl.org/dc/terms/ dateAccepted"`
+	DateCopyrighted       []string `xml:"http://purl.org/dc/terms/ dateCopyrighted"`
+	DateSubmitted         []string `xml:"http://purl.org/dc/terms/ dateSubmitted"`
+	Extent                []string `xml:"http://purl.org/dc/terms/ extent"`
+	Medium                []string `xml:"http://purl.org/dc/terms/ medium"`
+	IsPartOf              []string `xml:"http://purl.org/dc/terms/ isPartOf"`
+	HasPart               []string `xml:"http://purl.org/dc/terms/ hasPart"`
+	IsVersionOf           []string `xml:"http://purl.org/dc/terms/ isVersionOf"`
+	HasVersion            []string `xml:"http://purl.org/dc/terms/ hasVersion"`
+	IsReferencedBy        []string `xml:"http://purl.org/dc/terms/ isReferencedBy"`
+	References            []string `xml:"http://purl.org/dc/terms/ references"`
+	BibliographicCitation []string `xml:"http://purl.org/dc/terms/ bibliographicCitation"`
+	Spatial               []string `xml:"http://purl.org/dc/terms/ spatial"`
+	Temporal              []string `xml:"http://purl.org/dc/terms/ temporal"`
+	AccessRights          []string `xml:"http://purl.org/dc/terms/ accessRights"`
+	License               []string `xml:"http://purl.org/dc/terms/ license"`
+	RightsHolder          []string `xml:"http://purl.org/dc/terms/ rightsHolder"`
+	Audience              []string `xml:"http://purl.org/dc/terms/ audience"`
+	Provenance            []string `xml:"http://purl.org/dc/terms/ provenance"`
+}
+
+// MetadataQDC represents the metadata wrapper for Qualified Dublin Core
+type MetadataQDC struct {
+	QDC *QualifiedDC `xml:"qualifieddc,omitempty"`
+	Raw []byte       `xml:",innerxml"`
+}
+
+// RecordQDC represents an OAI-PMH record with Qualified Dublin Core metadata
+type RecordQDC struct {
+	Header   Header      `xml:"header"`
+	Metadata MetadataQDC `xml:"metadata"`
+	About    *About      `xml:"about,omitempty"`
+}
+
+// IsDeleted returns true if the record has been deleted from the repository
+func (r RecordQDC) IsDeleted() bool {
+	return r.Header.IsDeleted()
+}
+
+// ListRecordsQDC contains the list of Qualified Dublin Core records from ListRecords verb
+type ListRecordsQDC struct {
+	Records         []RecordQDC      `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// GetRecordQDC contains a single Qualified Dublin Core record from GetRecord verb
+type GetRecordQDC struct {
+	Record RecordQDC `xml:"record"`
+}
+
+// OAIPMHResponseQDC represents the OAI-PMH response with Qualified Dublin Core metadata
+type OAIPMHResponseQDC struct {
+	XMLName      xml.Name        `xml:"OAI-PMH"`
+	ResponseDate string          `xml:"responseDate"`
+	Request      OAIRequest      `xml:"request"`
+	ListRecords  *ListRecordsQDC `xml:"ListRecords,omitempty"`
+	GetRecord    *GetRecordQDC   `xml:"GetRecord,omitempty"`
+	Error        *OAIError       `xml:"error,omitempty"`
+}
+
+// QDCMetadata represents extracted Qualified Dublin Core metadata.
+// The embedded DCMetadata holds the unqualified elements.
+type QDCMetadata struct {
+	DCMetadata
+	Alternative           []string `json:"alternative"`
+	Abstract              []string `json:"abstract"`
+	TableOfContents       []string `json:"table_of_contents"`
+	Created               []string `json:"created"`
+	DateIssued            []string `json:"date_issued"`
+	Modified              []string `json:"modified"`
+	Available             []string `json:"available"`
+	DateAccepted          []string `json:"date_accepted"`
+	DateCopyrighted       []string `json:"date_copyrighted"`
+	DateSubmitted         []string `json:"date_submitted"`
+	Extent                []string `json:"extent"`
+	Medium                []string `json:"medium"`
+	IsPartOf              []string `json:"is_part_of"`
+	HasPart               []string `json:"has_part"`
+	IsVersionOf           []string `json:"is_version_of"`
+	HasVersion            []string `json:"has_version"`
+	IsReferencedBy        []string `json:"is_referenced_by"`
+	References            []string `json:"references"`
+	BibliographicCitation []string `json:"bibliographic_citation"`
+	Spatial               []string `json:"spatial"`
+	Temporal              []string `json:"temporal"`
+	AccessRights          []string `json:"access_rights"`
+	License               []string `json:"license"`
+	RightsHolder          []string `json:"rights_holder"`
+	Audience              []string `json:"audience"`
+	Provenance            []string `json:"provenance"`
+}
+
+// ExtractQDCMetadata extracts Qualified Dublin Core metadata with deduplication
+func (q *QualifiedDC) ExtractQDCMetadata() *QDCMetadata {
+	if q == nil {
+		return nil
+	}
+
+	return &QDCMetadata{
+		DCMetadata: DCMetadata{
+			Title:       deduplicate(q.Title),
+			Creator:     deduplicate(q.Creator),
+			Subject:     deduplicate(q.Subject),
+			Description: deduplicate(q.Description),
+			Publisher:   deduplicate(q.Publisher),
+			Contributor: deduplicate(q.Contributor),
+			Date:        deduplicate(q.Date),
+			Type:        deduplicate(q.Type),
+			Format:      deduplicate(q.Format),
+			Identifier:  deduplicate(q.Identifier),
+			Source:      deduplicate(q.Source),
+			Language:    deduplicate(q.Language),
+			Relation:    deduplicate(q.Relation),
+			Coverage:    deduplicate(q.Coverage),
+			Rights:      deduplicate(q.Rights),
+		},
+		Alternative:           deduplicate(q.Alternative),
+		Abstract:              deduplicate(q.Abstract),
+		TableOfContents:       deduplicate(q.TableOfContents),
+		Created:               deduplicate(q.Created),
+		DateIssued:            deduplicate(q.Issued),
+		Modified:              deduplicate(q.Modified),
+		Available:             deduplicate(q.Available),
+		DateAccepted:          deduplicate(q.DateAccepted),
+		DateCopyrighted:       deduplicate(q.DateCopyrighted),
+		DateSubmitted:         deduplicate(q.DateSubmitted),
+		Extent:                deduplicate(q.Extent),
+		Medium:                deduplicate(q.Medium),
+		IsPartOf:              deduplicate(q.IsPartOf),
+		HasPart:               deduplicate(q.HasPart),
+		IsVersionOf:           deduplicate(q.IsVersionOf),
+		HasVersion:            deduplicate(q.HasVersion),
+		IsReferencedBy:        deduplicate(q.IsReferencedBy),
+		References:            deduplicate(q.References),
+		BibliographicCitation: deduplicate(q.BibliographicCitation),
+		Spatial:               deduplicate(q.Spatial),
+		Temporal:              deduplicate(q.Temporal),
+		AccessRights:          deduplicate(q.AccessRights),
+		License:               deduplicate(q.License),
+		RightsHolder:          deduplicate(q.RightsHolder),
+		Audience:              deduplicate(q.Audience),
+		Provenance:            deduplicate(q.Provenance),
+	}
+}
+
+// ExtractAllQDCMetadata extracts metadata from all Qualified Dublin Core records in OAI-PMH response
+func (o *OAIPMHResponseQDC) ExtractAllQDCMetadata() []*QDCMetadata {
+	var results []*QDCMetadata
+
+	for _, extractor := range o.GetRecords() {
+		if metadata := extractor.(*QualifiedDC).ExtractQDCMetadata(); metadata != nil {
+			results = append(results, metadata)
+		}
+	}
+
+	return results
+}
+
+// ParseOAIQDCXML parses OAI-PMH XML data with Qualified Dublin Core metadata from bytes
+func ParseOAIQDCXML(data []byte) (*OAIPMHResponseQDC, error) {
+	var oaiResp OAIPMHResponseQDC
+	if err := xml.Unmarshal(data, &oaiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	if oaiResp.Error != nil {
+		return nil, oaiResp.Error
+	}
+
+	return &oaiResp, nil
+}
+
+// decodeOAIPMHResponseQDC stream-decodes a Qualified Dublin Core ListRecords or GetRecord response
+func decodeOAIPMHResponseQDC(r io.Reader, page decodeOptions) (*OAIPMHResponseQDC, error) {
+	env, records, err := decodeResponse(r, page, decodeRecord[RecordQDC])
+	if err != nil {
+		return nil, err
+	}
+
+	oaiResp := &OAIPMHResponseQDC{
+		XMLName:      xml.Name{Local: "OAI-PMH"},
+		ResponseDate: env.ResponseDate,
+		Request:      env.Request,
+	}
+
+	switch env.Verb {
+	case "ListRecords":
+		oaiResp.ListRecords = &ListRecordsQDC{Records: records, ResumptionToken: env.ResumptionToken}
+	case "GetRecord":
+		if len(records) > 0 {
+			oaiResp.GetRecord = &GetRecordQDC{Record: records[0]}
+		}
+	}
+
+	return oaiResp, nil
+}
+
+// Implement OAIResponse interface for OAIPMHResponseQDC
+
+// GetRecords returns all records in the response as MetadataExtractor interface
+func (o *OAIPMHResponseQDC) GetRecords() []MetadataExtractor {
+	var extractors []MetadataExtractor
+
+	for _, record := range o.harvestedRecords() {
+		if record.Metadata != nil {
+			extractors = append(extractors, record.Metadata)
+		}
+	}
+
+	return extractors
+}
+
+// GetResumptionToken returns the resumption token if available
+func (o *OAIPMHResponseQDC) GetResumptionToken() string {
+	if o.ListRecords != nil && o.ListRecords.ResumptionToken != nil {
+		return o.ListRecords.ResumptionToken.Token
+	}
+	return ""
+}
+
+// GetResumptionTokenInfo returns the full resumption token if available
+func (o *OAIPMHResponseQDC) GetResumptionTokenInfo() *ResumptionToken {
+	if o.ListRecords != nil {
+		return o.ListRecords.ResumptionToken
+	}
+	return nil
+}
+
+// HasError returns true if the response contains an error
+func (o *OAIPMHResponseQDC) HasError() bool {
+	return o.Error != nil
+}
+
+// GetError returns the error information
+func (o *OAIPMHResponseQDC) GetError() *OAIError {
+	return o.Error
+}
+
+// harvestedRecords returns all records in the response with their headers
+func (o *OAIPMHResponseQDC) harvestedRecords() []HarvestedRecord {
+	var records []RecordQDC
+	if o.ListRecords != nil {
+		records = o.ListRecords.Records
+	}
+	if o.GetRecord != nil {
+		records = append(records, o.GetRecord.Record)
+	}
+
+	results := make([]HarvestedRecord, 0, len(records))
+	for _, record := range records {
+		harvested := HarvestedRecord{Header: record.Header, Raw: record.Metadata.Raw, About: record.About}
+		if record.Metadata.QDC != nil {
+			harvested.Metadata = record.Metadata.QDC
+		}
+		results = append(results, harvested)
+	}
+
+	return results
+}
+
+// responseDate returns the responseDate of the response
+func (o *OAIPMHResponseQDC) responseDate() string {
+	return o.ResponseDate
+}
+
+// Implement MetadataExtractor interface for QualifiedDC
+
+// ExtractMetadata extracts metadata from Qualified Dublin Core record
+func (m *QualifiedDC) ExtractMetadata() interface{} {
+	return m.ExtractQDCMetadata()
+}
+
+// GetFormat returns the metadata format type
+func (m *QualifiedDC) GetFormat() MetadataFormat {
+	return FormatQDC
+}
@@ -0,0 +1,74 @@
+package goharvest
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseOAIQDCXML(t *testing.T) {
+	data, err := os.ReadFile("testdata/listrecords_qdc.xml")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	resp, err := ParseOAIQDCXML(data)
+	if err != nil {
+		t.Fatalf("ParseOAIQDCXML failed: %v", err)
+	}
+
+	records := resp.GetRecords()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if records[0].GetFormat() != FormatQDC {
+		t.Errorf("Expected format qdc, got %s", records[0].GetFormat())
+	}
+
+	metadata, ok := records[0].ExtractMetadata().(*QDCMetadata)
+	if !ok {
+		t.Fatalf("Expected *QDCMetadata, got %T", records[0].ExtractMetadata())
+	}
+
+	if len(metadata.Title) != 1 || metadata.Title[0] != "Coastal erosion in southern Java" {
+		t.Errorf("Unexpected title %v", metadata.Title)
+	}
+	if len(metadata.Creator) != 1 {
+		t.Errorf("Expected deduplicated creator, got %v", metadata.Creator)
+	}
+	if len(metadata.Abstract) != 1 || len(metadata.Alternative) != 1 {
+		t.Errorf("Unexpected abstract/alternative %v / %v", metadata.Abstract, metadata.Alternative)
+	}
+	if len(metadata.DateIssued) != 1 || metadata.DateIssued[0] != "2021-06-15" {
+		t.Errorf("Unexpected dateIssued %v", metadata.DateIssued)
+	}
+	if len(metadata.Extent) != 1 || metadata.Extent[0] != "24 p." {
+		t.Errorf("Unexpected extent %v", metadata.Extent)
+	}
+	if len(metadata.IsPartOf) != 1 || len(metadata.BibliographicCitation) != 1 {
+		t.Errorf("Unexpected isPartOf/citation %v / %v", metadata.IsPartOf, metadata.BibliographicCitation)
+	}
+	if len(metadata.Spatial) != 1 || len(metadata.License) != 1 || len(metadata.DateAccepted) != 1 {
+		t.Errorf("Unexpected spatial/license/dateAccepted %v / %v / %v", metadata.Spatial, metadata.License, metadata.DateAccepted)
+	}
+}
+
+func TestUnifiedHarvestQDC(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_qdc.xml"})
+	client := NewClient(server.URL)
+
+	count := 0
+	err := client.Harvest("qdc", nil, func(response OAIResponse) error {
+		for _, record := range response.GetRecords() {
+			if _, ok := record.ExtractMetadata().(*QDCMetadata); ok {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 QDC record, got %d", count)
+	}
+}
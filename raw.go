@@ -0,0 +1,160 @@
+package goharvest
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+)
+
+// RawMetadata is untyped metadata delivered by HarvestRaw for any metadata prefix
+type RawMetadata struct {
+	Format MetadataFormat
+	XML    []byte
+}
+
+// ExtractMetadata returns the raw inner XML of the metadata element
+func (m *RawMetadata) ExtractMetadata() interface{} {
+	return m.XML
+}
+
+// GetFormat returns the metadata prefix the record was harvested with
+func (m *RawMetadata) GetFormat() MetadataFormat {
+	return m.Format
+}
+
+// MetadataRaw holds the metadata element of a record without interpreting it
+type MetadataRaw struct {
+	Raw []byte `xml:",innerxml"`
+}
+
+// RecordRaw represents an OAI-PMH record with untyped metadata
+type RecordRaw struct {
+	Header   Header       `xml:"header"`
+	Metadata *MetadataRaw `xml:"metadata,omitempty"`
+	About    *About       `xml:"about,omitempty"`
+}
+
+// ListRecordsRaw contains the list of untyped records from ListRecords verb
+type ListRecordsRaw struct {
+	Records         []RecordRaw      `xml:"record"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// OAIPMHResponseRaw represents an OAI-PMH response whose metadata is kept as raw XML
+type OAIPMHResponseRaw struct {
+	XMLName      xml.Name        `xml:"OAI-PMH"`
+	ResponseDate string          `xml:"responseDate"`
+	Request      OAIRequest      `xml:"request"`
+	ListRecords  *ListRecordsRaw `xml:"ListRecords,omitempty"`
+	Error        *OAIError       `xml:"error,omitempty"`
+}
+
+// HarvestRaw harvests records in any metadata format without typing them. The callback
+// receives each record's header and the raw inner XML of its metadata element in Raw,
+// so prefixes without a built-in parser can be harvested and parsed downstream.
+func (c *OAIClient) HarvestRaw(metadataPrefix string, opts *HarvestOptions, callback RecordCallback) error {
+	rawOpts := HarvestOptions{}
+	if opts != nil {
+		rawOpts = *opts
+	}
+	rawOpts.raw = true
+
+	return c.harvestRecords(context.Background(), metadataPrefix, &rawOpts, callback)
+}
+
+// listRecordsRequestRaw performs a ListRecords request and keeps metadata as raw XML
+func (c *OAIClient) listRecordsRequestRaw(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+	body, err := c.performListRecordsRequest(metadataPrefix, resumptionToken, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	resp, err := decodeOAIPMHResponseRaw(body, c.decodeOptions(opts, resumptionToken))
+	if err != nil {
+		return nil, c.parseFailure(body, err)
+	}
+	// Pages requested with a resumption token do not echo the prefix
+	resp.Request.MetadataPrefix = metadataPrefix
+	return resp, nil
+}
+
+// decodeOAIPMHResponseRaw stream-decodes a ListRecords response without typing the metadata
+func decodeOAIPMHResponseRaw(r io.Reader, page decodeOptions) (*OAIPMHResponseRaw, error) {
+	env, records, err := decodeResponse(r, page, decodeRecord[RecordRaw])
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAIPMHResponseRaw{
+		XMLName:      xml.Name{Local: "OAI-PMH"},
+		ResponseDate: env.ResponseDate,
+		Request:      env.Request,
+		ListRecords:  &ListRecordsRaw{Records: records, ResumptionToken: env.ResumptionToken},
+	}, nil
+}
+
+// Implement OAIResponse interface for OAIPMHResponseRaw
+
+// GetRecords returns all records with metadata as RawMetadata extractors
+func (o *OAIPMHResponseRaw) GetRecords() []MetadataExtractor {
+	var extractors []MetadataExtractor
+
+	for _, record := range o.harvestedRecords() {
+		if record.Metadata != nil {
+			extractors = append(extractors, record.Metadata)
+		}
+	}
+
+	return extractors
+}
+
+// GetResumptionToken returns the resumption token if available
+func (o *OAIPMHResponseRaw) GetResumptionToken() string {
+	if o.ListRecords != nil && o.ListRecords.ResumptionToken != nil {
+		return o.ListRecords.ResumptionToken.Token
+	}
+	return ""
+}
+
+// GetResumptionTokenInfo returns the full resumption token if available
+func (o *OAIPMHResponseRaw) GetResumptionTokenInfo() *ResumptionToken {
+	if o.ListRecords != nil {
+		return o.ListRecords.ResumptionToken
+	}
+	return nil
+}
+
+// HasError returns true if the response contains an error
+func (o *OAIPMHResponseRaw) HasError() bool {
+	return o.Error != nil
+}
+
+// GetError returns the error information
+func (o *OAIPMHResponseRaw) GetError() *OAIError {
+	return o.Error
+}
+
+// harvestedRecords returns all records in the response with their headers
+func (o *OAIPMHResponseRaw) harvestedRecords() []HarvestedRecord {
+	if o.ListRecords == nil {
+		return nil
+	}
+
+	results := make([]HarvestedRecord, 0, len(o.ListRecords.Records))
+	for _, record := range o.ListRecords.Records {
+		harvested := HarvestedRecord{Header: record.Header, About: record.About}
+		if record.Metadata != nil {
+			harvested.Raw = record.Metadata.Raw
+			harvested.Metadata = &RawMetadata{Format: MetadataFormat(o.Request.MetadataPrefix), XML: record.Metadata.Raw}
+		}
+		results = append(results, harvested)
+	}
+
+	return results
+}
+
+// responseDate returns the responseDate of the response
+func (o *OAIPMHResponseRaw) responseDate() string {
+	return o.ResponseDate
+}
@@ -0,0 +1,66 @@
+package goharvest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHarvestRaw(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	var records []HarvestedRecord
+	err := client.HarvestRaw("oai_dc", nil, func(record HarvestedRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestRaw failed: %v", err)
+	}
+
+	if len(records) != 4 {
+		t.Fatalf("Expected 4 records, got %d", len(records))
+	}
+
+	first := records[0]
+	if !strings.Contains(string(first.Raw), "<dc:title>Sistem Informasi Perpustakaan</dc:title>") {
+		t.Errorf("Expected raw metadata XML, got %q", first.Raw)
+	}
+	raw, ok := first.Metadata.(*RawMetadata)
+	if !ok || raw.GetFormat() != FormatOAIDC {
+		t.Errorf("Expected *RawMetadata with oai_dc format, got %+v", first.Metadata)
+	}
+
+	if !records[1].IsDeleted() || records[1].Metadata != nil || records[1].Raw != nil {
+		t.Errorf("Expected deleted record without metadata, got %+v", records[1])
+	}
+	if records[3].Page.Number != 2 || records[3].Metadata.GetFormat() != FormatOAIDC {
+		t.Errorf("Expected second page record to keep the prefix, got %+v", records[3])
+	}
+}
+
+func TestHarvestRawUnknownPrefix(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_lido.xml"})
+	client := NewClient(server.URL)
+
+	if err := client.Harvest("museumdat", nil, func(OAIResponse) error { return nil }); err == nil {
+		t.Fatal("Expected typed harvest of an unknown prefix to fail")
+	}
+
+	count := 0
+	err := client.HarvestRaw("museumdat", nil, func(record HarvestedRecord) error {
+		if xml, ok := record.Metadata.ExtractMetadata().([]byte); ok && strings.Contains(string(xml), "lido:lidoRecID") {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestRaw failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 raw record, got %d", count)
+	}
+}
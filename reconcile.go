@@ -0,0 +1,154 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// LocalIndex lists the records held downstream of a harvest, e.g. in a database or
+// search index, so they can be compared with the repository
+type LocalIndex interface {
+	// EachRecord calls fn with the header of every record held locally. The datestamp is
+	// the one the record was harvested with; records held as deleted may be skipped.
+	EachRecord(ctx context.Context, fn func(header Header) error) error
+}
+
+// ReconcileReport is the result of comparing a repository with a LocalIndex
+type ReconcileReport struct {
+	// Upstream is the number of records the repository lists, deleted ones excluded
+	Upstream int
+	// Local is the number of local records within the compared window and set
+	Local int
+	// Missing are the records the repository lists that are not held locally
+	Missing []Header
+	// Stale are the records the repository lists with a later datestamp than the local copy
+	Stale []Header
+	// Orphaned are the identifiers of local records the repository lists as deleted or
+	// does not list at all
+	Orphaned []string
+}
+
+// Consistent reports whether the local records match the repository
+func (r *ReconcileReport) Consistent() bool {
+	return len(r.Missing) == 0 && len(r.Stale) == 0 && len(r.Orphaned) == 0
+}
+
+// localRecord is a record of a LocalIndex and whether the repository listed it
+type localRecord struct {
+	datestamp string
+	inScope   bool
+	listed    bool
+}
+
+// Reconcile lists the identifiers of the repository in window (nil for all records) and
+// set (empty for all sets) with ListIdentifiers and compares them with the local records,
+// to verify that incremental harvests have not silently dropped records. Local records are
+// only reported as orphaned if their datestamp is within window and, when set is given,
+// they carry its setSpec.
+func (c *OAIClient) Reconcile(ctx context.Context, metadataPrefix string, window *DateRange, set string, local LocalIndex) (*ReconcileReport, error) {
+	from, until, err := windowBounds(window)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make(map[string]*localRecord)
+	report := &ReconcileReport{}
+	err = local.EachRecord(ctx, func(header Header) error {
+		if header.IsDeleted() {
+			return nil
+		}
+		record := &localRecord{datestamp: header.DateStamp}
+		record.inScope = (set == "" || slices.Contains(header.SetSpec, set)) && within(header.DateStamp, from, until)
+		if record.inScope {
+			report.Local++
+		}
+		records[header.Identifier] = record
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local records: %w", err)
+	}
+
+	err = c.harvestIdentifiers(ctx, metadataPrefix, window, set, func(header Header) error {
+		record, ok := records[header.Identifier]
+		if ok {
+			record.listed = true
+		}
+
+		switch {
+		case header.IsDeleted():
+			if ok {
+				report.Orphaned = append(report.Orphaned, header.Identifier)
+			}
+			return nil
+		case !ok:
+			report.Missing = append(report.Missing, header)
+		case datestampAfter(header.DateStamp, record.datestamp):
+			report.Stale = append(report.Stale, header)
+		}
+		report.Upstream++
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrNoRecordsMatch) {
+		return nil, err
+	}
+
+	for identifier, record := range records {
+		if record.inScope && !record.listed {
+			report.Orphaned = append(report.Orphaned, identifier)
+		}
+	}
+	slices.Sort(report.Orphaned)
+
+	c.log().Info("reconciliation finished", "baseURL", c.BaseURL, "metadataPrefix", metadataPrefix, "set", set,
+		"upstream", report.Upstream, "local", report.Local,
+		"missing", len(report.Missing), "stale", len(report.Stale), "orphaned", len(report.Orphaned))
+	return report, nil
+}
+
+// windowBounds returns the bounds of a date range as times, zero when open. A day
+// "until" bound includes the whole day.
+func windowBounds(window *DateRange) (time.Time, time.Time, error) {
+	if window == nil {
+		return time.Time{}, time.Time{}, nil
+	}
+	from, _, err := parseDateBound("from", window.From, window.FromTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	until, ok, err := parseDateBound("until", window.Until, window.UntilTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if ok && until.granularity == GranularityDay {
+		until.time = until.time.Add(24*time.Hour - time.Second)
+	}
+	return from.time, until.time, nil
+}
+
+// within reports whether a datestamp lies between from and until, which are open when
+// zero. Unparsable datestamps are treated as within.
+func within(datestamp string, from, until time.Time) bool {
+	t, _, err := parseDatestamp("datestamp", datestamp)
+	if err != nil {
+		return true
+	}
+	return (from.IsZero() || !t.Before(from)) && (until.IsZero() || !t.After(until))
+}
+
+// datestampAfter reports whether datestamp a is later than b. A day datestamp is compared
+// with the day of the other, and unparsable datestamps are compared as strings.
+func datestampAfter(a, b string) bool {
+	ta, ga, errA := parseDatestamp("datestamp", a)
+	tb, gb, errB := parseDatestamp("datestamp", b)
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	if ga == GranularityDay || gb == GranularityDay {
+		ta, tb = ta.Truncate(24*time.Hour), tb.Truncate(24*time.Hour)
+	}
+	return ta.After(tb)
+}
@@ -0,0 +1,99 @@
+package goharvest
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// localHeaders is a LocalIndex over a list of headers
+type localHeaders []Header
+
+func (l localHeaders) EachRecord(_ context.Context, fn func(Header) error) error {
+	for _, header := range l {
+		if err := fn(header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestReconcile(t *testing.T) {
+	// The repository lists records 1 and 3, and 2 as deleted
+	server := newFixtureServer(t, map[string]string{
+		"ListIdentifiers":            "listidentifiers_page1.xml",
+		"ListIdentifiers:ids-page-2": "listidentifiers_page2.xml",
+	})
+	local := localHeaders{
+		{Identifier: "oai:example.org:1", DateStamp: "2025-01-14", SetSpec: []string{"theses"}},
+		{Identifier: "oai:example.org:2", DateStamp: "2025-01-16T08:00:00Z", SetSpec: []string{"theses"}},
+		{Identifier: "oai:example.org:9", DateStamp: "2025-01-20", SetSpec: []string{"theses"}},
+		// Out of the window or the set
+		{Identifier: "oai:example.org:10", DateStamp: "2024-12-31", SetSpec: []string{"theses"}},
+		{Identifier: "oai:example.org:11", DateStamp: "2025-01-20", SetSpec: []string{"books"}},
+		// Held as deleted
+		{Identifier: "oai:example.org:12", DateStamp: "2025-01-20", SetSpec: []string{"theses"}, Status: HeaderStatusDeleted},
+	}
+
+	report, err := NewClient(server.URL).Reconcile(context.Background(), "oai_dc", &DateRange{From: "2025-01-01"}, "theses", local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Upstream != 2 || report.Local != 3 {
+		t.Errorf("Expected 2 upstream and 3 local records, got %+v", report)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].Identifier != "oai:example.org:3" {
+		t.Errorf("Expected record 3 to be missing, got %v", report.Missing)
+	}
+	if len(report.Stale) != 1 || report.Stale[0].Identifier != "oai:example.org:1" {
+		t.Errorf("Expected record 1 to be stale, got %v", report.Stale)
+	}
+	if want := []string{"oai:example.org:2", "oai:example.org:9"}; !slices.Equal(report.Orphaned, want) {
+		t.Errorf("Expected orphans %v, got %v", want, report.Orphaned)
+	}
+	if report.Consistent() {
+		t.Error("Expected the report to be inconsistent")
+	}
+
+	if query := server.Queries()[0]; query.Get("from") != "2025-01-01" || query.Get("set") != "theses" {
+		t.Errorf("Expected the window and set in the request, got %v", query)
+	}
+}
+
+func TestReconcileConsistent(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListIdentifiers":            "listidentifiers_page1.xml",
+		"ListIdentifiers:ids-page-2": "listidentifiers_page2.xml",
+	})
+	local := localHeaders{
+		{Identifier: "oai:example.org:1", DateStamp: "2025-01-15T10:00:00Z"},
+		{Identifier: "oai:example.org:3", DateStamp: "2025-01-17"},
+	}
+
+	report, err := NewClient(server.URL).Reconcile(context.Background(), "oai_dc", nil, "", local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Consistent() {
+		t.Errorf("Expected a consistent report, got %+v", report)
+	}
+}
+
+func TestReconcileNoRecordsMatch(t *testing.T) {
+	server := serveDocument(t, `<?xml version="1.0" encoding="UTF-8"?>
+<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/">
+  <responseDate>2025-10-02T10:05:19Z</responseDate>
+  <request verb="ListIdentifiers">http://example.org/oai</request>
+  <error code="noRecordsMatch">No matching records</error>
+</OAI-PMH>`)
+	local := localHeaders{{Identifier: "oai:example.org:1", DateStamp: "2025-02-01"}}
+
+	report, err := NewClient(server.URL).Reconcile(context.Background(), "oai_dc", &DateRange{From: "2025-01-01", Until: "2025-02-01"}, "", local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(report.Orphaned, []string{"oai:example.org:1"}) {
+		t.Errorf("Expected the local record to be orphaned, got %+v", report)
+	}
+}
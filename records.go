@@ -0,0 +1,191 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// HarvestedRecord is a single record delivered by the per-record harvest API
+type HarvestedRecord struct {
+	// Header contains the identifier, datestamp, status and setSpecs of the record
+	Header Header
+	// Metadata is the parsed metadata, nil when the record has no metadata (e.g. deleted records)
+	Metadata MetadataExtractor
+	// Raw contains the raw inner XML of the metadata element
+	Raw []byte
+	// About holds the about containers of the record, nil if it has none
+	About *About
+	// Hash is the ContentHash of the metadata when HarvestOptions.ContentHash or
+	// SkipUnchanged is set; all deleted records have the same hash
+	Hash string
+	// Page describes the response page the record was delivered in
+	Page PageInfo
+}
+
+// PageInfo describes a ListRecords response page
+type PageInfo struct {
+	// Number is the 1-based page number within the harvest
+	Number int
+	// ResponseDate is the responseDate of the page
+	ResponseDate string
+	// ResumptionToken is the token returned with the page, nil on the last page
+	ResumptionToken *ResumptionToken
+}
+
+// IsDeleted returns true if the record has been deleted from the repository.
+// Deleted records carry no metadata, only their header.
+func (r HarvestedRecord) IsDeleted() bool {
+	return r.Header.IsDeleted()
+}
+
+// RecordCallback is the callback function type for per-record harvest operations
+type RecordCallback func(record HarvestedRecord) error
+
+// recordLister is implemented by responses that can list their records with headers
+type recordLister interface {
+	harvestedRecords() []HarvestedRecord
+	responseDate() string
+}
+
+// HarvestRecords harvests OAI-PMH records and invokes callback once per record
+func (c *OAIClient) HarvestRecords(metadataPrefix string, opts *HarvestOptions, callback RecordCallback) error {
+	return c.harvestRecords(context.Background(), metadataPrefix, opts, callback)
+}
+
+// Records returns an iterator over all harvested records for use with range-over-func.
+// Breaking out of the loop stops the harvest like ErrStopHarvest; a harvest error is
+// yielded as the last element. An error after the loop was broken, e.g. from saving the
+// checkpoint, can no longer be yielded and is logged instead.
+func (c *OAIClient) Records(ctx context.Context, metadataPrefix string, opts *HarvestOptions) iter.Seq2[HarvestedRecord, error] {
+	return func(yield func(HarvestedRecord, error) bool) {
+		broken := false
+		err := c.harvestRecords(ctx, metadataPrefix, opts, func(record HarvestedRecord) error {
+			if !yield(record, nil) {
+				broken = true
+				return ErrStopHarvest
+			}
+			return nil
+		})
+
+		switch {
+		case err == nil:
+		case broken:
+			c.log().Warn("harvest failed after the record loop stopped", "baseURL", c.BaseURL, "error", err)
+		default:
+			yield(HarvestedRecord{}, err)
+		}
+	}
+}
+
+// RecordResult is a harvested record or a harvest error delivered by HarvestChan
+type RecordResult struct {
+	Record HarvestedRecord
+	Err    error
+}
+
+// HarvestChan harvests records in a background goroutine and delivers them on the returned
+// channel, which is closed when the harvest ends. The channel buffers up to bufferSize records,
+// so a slow consumer throttles fetching. Cancel ctx to stop the harvest early.
+func (c *OAIClient) HarvestChan(ctx context.Context, metadataPrefix string, opts *HarvestOptions, bufferSize int) <-chan RecordResult {
+	results := make(chan RecordResult, bufferSize)
+
+	go func() {
+		defer close(results)
+
+		for record, err := range c.Records(ctx, metadataPrefix, opts) {
+			select {
+			case results <- RecordResult{Record: record, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+// errStoppedInPage is returned by forEachRecord when the harvest stops before the last
+// record of a page, so that the page is requested again when the harvest is resumed
+var errStoppedInPage = fmt.Errorf("%w partway through a page", ErrStopHarvest)
+
+// stopAt returns the error stopping a harvest at records[i], with the records after it
+// not delivered
+func stopAt(records []HarvestedRecord, i int) error {
+	if i < len(records)-1 {
+		return errStoppedInPage
+	}
+	return ErrStopHarvest
+}
+
+// forEachRecord invokes callback for each record of a response page, skipping deleted
+// records only when opts.SkipDeleted is set, records already delivered by the run and
+// records unchanged since they were stored in opts.SkipUnchanged
+func forEachRecord(response OAIResponse, page int, opts *HarvestOptions, callback RecordCallback) error {
+	lister, ok := response.(recordLister)
+	if !ok {
+		return nil
+	}
+
+	info := PageInfo{
+		Number:          page,
+		ResponseDate:    lister.responseDate(),
+		ResumptionToken: response.GetResumptionTokenInfo(),
+	}
+
+	records := lister.harvestedRecords()
+	for i, record := range records {
+		// Sets harvested in parallel can reach the limit on another page
+		if opts != nil && opts.run != nil && opts.MaxRecords > 0 && opts.run.delivered >= opts.MaxRecords {
+			return errStoppedInPage
+		}
+		if opts != nil && opts.listed != nil {
+			opts.listed(record.Header)
+		}
+		if opts != nil && opts.SkipDeleted && record.IsDeleted() {
+			continue
+		}
+		if opts != nil && opts.run != nil && opts.run.duplicate(record.Header.Identifier) {
+			continue
+		}
+
+		if opts != nil && (opts.ContentHash || opts.SkipUnchanged != nil) {
+			record.Hash = recordHash(record)
+		}
+		if opts != nil && opts.SkipUnchanged != nil {
+			stored, err := opts.SkipUnchanged.Hash(record.Header.Identifier)
+			if err != nil {
+				return fmt.Errorf("failed to load content hash: %w", err)
+			}
+			if stored == record.Hash {
+				if opts.run != nil {
+					opts.run.unchanged++
+				}
+				continue
+			}
+		}
+
+		record.Page = info
+		if err := callback(record); err != nil {
+			if errors.Is(err, ErrStopHarvest) {
+				return stopAt(records, i)
+			}
+			return err
+		}
+		if opts != nil && opts.run != nil {
+			opts.run.delivered++
+		}
+
+		if opts != nil && opts.SkipUnchanged != nil {
+			if err := opts.SkipUnchanged.SetHash(record.Header.Identifier, record.Hash); err != nil {
+				return fmt.Errorf("failed to store content hash: %w", err)
+			}
+		}
+		if opts != nil && opts.run != nil && opts.MaxRecords > 0 && opts.run.delivered >= opts.MaxRecords {
+			return stopAt(records, i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHarvestRecords(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	var records []HarvestedRecord
+	err := client.HarvestRecords("oai_dc", nil, func(record HarvestedRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestRecords failed: %v", err)
+	}
+
+	if len(records) != 4 {
+		t.Fatalf("Expected 4 records including the deleted one, got %d", len(records))
+	}
+
+	first := records[0]
+	if first.Header.Identifier != "oai:example.org:1" {
+		t.Errorf("Unexpected first identifier '%s'", first.Header.Identifier)
+	}
+	if dcMeta, ok := first.Metadata.ExtractMetadata().(*DCMetadata); !ok || dcMeta.Title[0] != "Sistem Informasi Perpustakaan" {
+		t.Errorf("Unexpected first record metadata: %+v", first.Metadata)
+	}
+	if !strings.Contains(string(first.Raw), "Sistem Informasi Perpustakaan") {
+		t.Errorf("Expected raw metadata XML, got %q", first.Raw)
+	}
+	if first.Page.Number != 1 || first.Page.ResumptionToken == nil || first.Page.ResumptionToken.CompleteListSize != 4 {
+		t.Errorf("Unexpected page info for first record: %+v", first.Page)
+	}
+
+	if records[1].Metadata != nil {
+		t.Errorf("Expected nil metadata for deleted record, got %+v", records[1].Metadata)
+	}
+
+	if last := records[3]; last.Page.Number != 2 || last.Page.ResponseDate != "2025-10-02T10:05:20Z" {
+		t.Errorf("Unexpected page info for last record: %+v", last.Page)
+	}
+}
+
+func TestRecordsIterator(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	var identifiers []string
+	for record, err := range client.Records(context.Background(), "oai_dc", nil) {
+		if err != nil {
+			t.Fatalf("Records failed: %v", err)
+		}
+		identifiers = append(identifiers, record.Header.Identifier)
+	}
+	if len(identifiers) != 4 {
+		t.Errorf("Expected 4 records, got %d", len(identifiers))
+	}
+
+	// Breaking out of the loop stops the harvest before the second page is fetched
+	requestsBefore := len(server.Queries())
+	for record, err := range client.Records(context.Background(), "oai_dc", nil) {
+		if err != nil {
+			t.Fatalf("Records failed: %v", err)
+		}
+		if record.Header.Identifier == "oai:example.org:1" {
+			break
+		}
+	}
+	if requests := len(server.Queries()) - requestsBefore; requests != 1 {
+		t.Errorf("Expected 1 request after break, got %d", requests)
+	}
+}
+
+// failingCheckpointer fails every Save
+type failingCheckpointer struct{}
+
+func (failingCheckpointer) Save(*HarvestState) error     { return errors.New("disk full") }
+func (failingCheckpointer) Load() (*HarvestState, error) { return nil, nil }
+func (failingCheckpointer) Clear() error                 { return nil }
+
+func TestRecordsBreakWithFailingCheckpoint(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	// The checkpoint of the stopped page fails after the loop body returned; the error
+	// must not be yielded to the finished loop
+	count := 0
+	for _, err := range client.Records(context.Background(), "oai_dc", &HarvestOptions{Checkpointer: failingCheckpointer{}}) {
+		if err != nil {
+			t.Fatalf("Records failed: %v", err)
+		}
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 record, got %d", count)
+	}
+}
+
+func TestRecordsIteratorError(t *testing.T) {
+	client := NewClient("http://example.org/oai")
+
+	var gotErr error
+	for _, err := range client.Records(context.Background(), "unknown", nil) {
+		gotErr = err
+	}
+	if gotErr == nil {
+		t.Error("Expected error for unsupported metadata format")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	server := newFixtureServer(t, map[string]string{"ListRecords": "listrecords_dc_page1.xml"})
+	for _, err := range NewClient(server.URL).Records(ctx, "oai_dc", nil) {
+		gotErr = err
+	}
+	if !errors.Is(gotErr, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", gotErr)
+	}
+}
+
+func TestHarvestChan(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	count := 0
+	for result := range client.HarvestChan(context.Background(), "oai_dc", nil, 1) {
+		if result.Err != nil {
+			t.Fatalf("HarvestChan failed: %v", result.Err)
+		}
+		count++
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 records, got %d", count)
+	}
+
+	// Cancelling the context stops the producer and closes the channel
+	ctx, cancel := context.WithCancel(context.Background())
+	results := client.HarvestChan(ctx, "oai_dc", nil, 0)
+	<-results
+	cancel()
+	for range results {
+	}
+}
+
+func TestHarvestRecordsDeleted(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	var deleted []string
+	err := client.HarvestRecords("oai_dc", nil, func(record HarvestedRecord) error {
+		if record.IsDeleted() {
+			deleted = append(deleted, record.Header.Identifier)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestRecords failed: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "oai:example.org:2" {
+		t.Errorf("Expected deleted record oai:example.org:2 to be delivered, got %v", deleted)
+	}
+
+	count := 0
+	err = client.HarvestRecords("oai_dc", &HarvestOptions{SkipDeleted: true}, func(record HarvestedRecord) error {
+		if record.IsDeleted() {
+			t.Errorf("Expected deleted record %s to be skipped", record.Header.Identifier)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestRecords failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 non-deleted records, got %d", count)
+	}
+}
+
+func TestHarvestRecordsStopResume(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords:dc-page-1": "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	client := NewClient(server.URL)
+	checkpointer := NewFileCheckpointer(filepath.Join(t.TempDir(), "harvest.json"))
+
+	var stats HarvestStats
+	var identifiers []string
+	opts := &HarvestOptions{ResumptionToken: "dc-page-1", Checkpointer: checkpointer, Stats: &stats}
+	err := client.HarvestRecords("oai_dc", opts, func(record HarvestedRecord) error {
+		identifiers = append(identifiers, record.Header.Identifier)
+		if record.Header.Identifier == "oai:example.org:2" {
+			return ErrStopHarvest
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Stopped || stats.ResumptionToken != "dc-page-1" {
+		t.Errorf("Expected to stop at the token of the current page, got %+v", stats)
+	}
+	if state, err := checkpointer.Load(); err != nil || state == nil || state.ResumptionToken != "dc-page-1" {
+		t.Errorf("Expected a checkpoint at the current page, got %+v, %v", state, err)
+	}
+
+	// Breaking out of the iterator stops the same way
+	var iterStats HarvestStats
+	for record, err := range client.Records(context.Background(), "oai_dc", &HarvestOptions{ResumptionToken: "dc-page-1", Stats: &iterStats}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Header.Identifier == "oai:example.org:1" {
+			break
+		}
+	}
+	if !iterStats.Stopped || iterStats.ResumptionToken != "dc-page-1" {
+		t.Errorf("Expected the iterator to stop at the token of the current page, got %+v", iterStats)
+	}
+
+	identifiers = nil
+	err = client.HarvestRecords("oai_dc", &HarvestOptions{ResumptionToken: stats.ResumptionToken}, func(record HarvestedRecord) error {
+		identifiers = append(identifiers, record.Header.Identifier)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"oai:example.org:1", "oai:example.org:2", "oai:example.org:4", "oai:example.org:5"}
+	if !slices.Equal(identifiers, expected) {
+		t.Errorf("Expected the resumed harvest to deliver the page again, got %v", identifiers)
+	}
+}
@@ -0,0 +1,114 @@
+package goharvest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ErrPageNotArchived is returned by a ReplayClient for requests missing from its archive
+var ErrPageNotArchived = errors.New("response not archived")
+
+// ArchiveReader lists and opens the pages of an archive. DirArchive implements it.
+type ArchiveReader interface {
+	Pages() ([]ArchivedPage, error)
+	Open(page ArchivedPage) (io.ReadCloser, error)
+}
+
+// ReplayClient is an OAIClient that answers requests from archived response pages
+// instead of HTTP, so extraction can be developed and re-run against a captured harvest.
+// Requests are matched by verb and arguments; when a request was archived several times,
+// the latest page is served.
+type ReplayClient struct {
+	*OAIClient
+}
+
+// replayBaseURL is the base URL of replay clients; no request leaves the process
+const replayBaseURL = "http://replay.invalid/oai"
+
+// NewReplayClient returns a client replaying the pages of archive
+func NewReplayClient(archive ArchiveReader, opts ...ClientOption) (*ReplayClient, error) {
+	pages, err := archive.Pages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	transport := &replayTransport{archive: archive, pages: make(map[string]ArchivedPage, len(pages))}
+	for _, page := range pages {
+		transport.pages[replayKey(page.Verb, page.Args)] = page
+	}
+
+	client := NewClient(replayBaseURL, opts...)
+	client.HTTPClient = &http.Client{Transport: transport}
+
+	return &ReplayClient{OAIClient: client}, nil
+}
+
+// replayTransport serves archived pages as HTTP responses
+type replayTransport struct {
+	archive ArchiveReader
+	pages   map[string]ArchivedPage
+}
+
+// RoundTrip returns the archived page matching the request
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Form holds the query of GET requests and the body of POST requests
+	if err := req.ParseForm(); err != nil {
+		return nil, fmt.Errorf("failed to parse request: %w", err)
+	}
+	query := req.Form
+	verb := query.Get("verb")
+	args := make(map[string]string, len(query))
+	for key := range query {
+		if key != "verb" && query.Get(key) != "" {
+			args[key] = query.Get(key)
+		}
+	}
+
+	key := replayKey(verb, args)
+	page, ok := t.pages[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPageNotArchived, key)
+	}
+
+	body, err := t.archive.Open(page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": {"text/xml; charset=utf-8"}},
+		Body:       body,
+		Request:    req,
+	}, nil
+}
+
+// replayKey identifies a request as "verb?arg=value&..." with sorted arguments
+func replayKey(verb string, args map[string]string) string {
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(verb)
+	for i, key := range keys {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.QueryEscape(key) + "=" + url.QueryEscape(args[key]))
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReplayClient(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+		"Identify":              "identify_response.xml",
+	})
+	archive, err := NewDirArchive(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	live := NewClient(server.URL, WithArchive(archive))
+	var want []string
+	err = live.HarvestRecords("oai_dc", nil, func(record HarvestedRecord) error {
+		want = append(want, record.Header.Identifier)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Live harvest failed: %v", err)
+	}
+	if _, err := live.Identify(context.Background()); err != nil {
+		t.Fatalf("Live Identify failed: %v", err)
+	}
+	server.Close()
+
+	replay, err := NewReplayClient(archive)
+	if err != nil {
+		t.Fatalf("NewReplayClient failed: %v", err)
+	}
+
+	var got []string
+	err = replay.HarvestRecords("oai_dc", nil, func(record HarvestedRecord) error {
+		got = append(got, record.Header.Identifier)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replayed harvest failed: %v", err)
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[3] != want[3] {
+		t.Errorf("Replayed records %v, want %v", got, want)
+	}
+
+	identify, err := replay.Identify(context.Background())
+	if err != nil || identify.RepositoryName == "" {
+		t.Errorf("Replayed Identify failed: %v", err)
+	}
+
+	posted, err := NewReplayClient(archive, WithPOST())
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	err = posted.HarvestRecords("oai_dc", nil, func(HarvestedRecord) error {
+		count++
+		return nil
+	})
+	if err != nil || count != len(want) {
+		t.Errorf("Replayed POST harvest returned %d records: %v", count, err)
+	}
+
+	err = replay.HarvestRecords("oai_dc", &HarvestOptions{Set: "other"}, func(HarvestedRecord) error { return nil })
+	if !errors.Is(err, ErrPageNotArchived) {
+		t.Errorf("Expected ErrPageNotArchived, got %v", err)
+	}
+}
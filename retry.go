@@ -0,0 +1,117 @@
+package goharvest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+)
+
+// Defaults of TokenRetryOptions
+const (
+	DefaultTokenRetries         = 3
+	DefaultTokenRetryBackoff    = time.Second
+	DefaultTokenRetryMaxBackoff = 30 * time.Second
+)
+
+// ErrTokenInvalidated is returned when the repository rejects a resumption token on a
+// retry, after it invalidated the token on the failed request. It wraps the
+// badResumptionToken error.
+var ErrTokenInvalidated = errors.New("resumption token invalidated by a failed request")
+
+// StatusError is returned when the repository answers with an HTTP status other than 200
+type StatusError struct {
+	StatusCode int
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// TokenRetryOptions configures the retry of ListRecords pages that failed with a timeout, a
+// refused or reset connection or a 5xx status. The page is requested again with the same
+// resumption token, so the harvest continues where it was. These retries are capped
+// separately from any retries of single connections done by middleware.
+type TokenRetryOptions struct {
+	// MaxRetries is the number of times a page is requested again (default DefaultTokenRetries)
+	MaxRetries int
+	// Backoff is the delay before the first retry, doubled for each further one
+	// (default DefaultTokenRetryBackoff)
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries (default DefaultTokenRetryMaxBackoff)
+	MaxBackoff time.Duration
+}
+
+// withDefaults returns the options with defaults for unset fields
+func (o TokenRetryOptions) withDefaults() TokenRetryOptions {
+	if o.MaxRetries <= 0 {
+		o.MaxRetries = DefaultTokenRetries
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = DefaultTokenRetryBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = DefaultTokenRetryMaxBackoff
+	}
+	return o
+}
+
+// transient reports whether a failed page fetch may succeed when repeated: a 5xx status,
+// a timeout, or a connection refused or reset. Other request failures, such as certificate
+// errors or unsupported URLs, fail the same way every time.
+func transient(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode >= 500
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retriedPages wraps a page parser to request a page again with the same resumption
+// token after a transient failure, as configured by HarvestOptions.TokenRetry
+func (c *OAIClient) retriedPages(parser pageParser) pageParser {
+	return func(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+		resp, err := parser(metadataPrefix, resumptionToken, opts)
+		if opts == nil || opts.TokenRetry == nil {
+			return resp, err
+		}
+
+		retry := opts.TokenRetry.withDefaults()
+		ctx := opts.context()
+		delay := retry.Backoff
+		for attempt := 1; err != nil && transient(err) && ctx.Err() == nil; attempt++ {
+			if attempt > retry.MaxRetries {
+				return nil, fmt.Errorf("page failed after %d retries: %w", retry.MaxRetries, err)
+			}
+
+			opts.emit(RetryScheduled{Verb: "ListRecords", ResumptionToken: resumptionToken, Attempt: attempt, Delay: delay, Err: err})
+			c.log().Warn("page fetch failed, retrying with the same resumption token", "baseURL", c.BaseURL,
+				"resumptionToken", resumptionToken, "attempt", attempt, "delay", delay, "error", err)
+			c.telemetry().Count(ctx, MetricRetries, 1, Attr(AttrVerb, "ListRecords"))
+			if opts.run != nil {
+				opts.run.retries.Add(1)
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+			delay = min(2*delay, retry.MaxBackoff)
+
+			resp, err = parser(metadataPrefix, resumptionToken, opts)
+			if resumptionToken != "" && errors.Is(err, ErrBadResumptionToken) {
+				return nil, fmt.Errorf("%w: %w", ErrTokenInvalidated, err)
+			}
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,170 @@
+package goharvest
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flakyServer serves the first page of the Dublin Core fixtures, and answers the requests
+// for the second page with the given responses in turn: an HTTP status or a fixture file
+func flakyServer(t *testing.T, responses ...string) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file := "listrecords_dc_page1.xml"
+		if r.URL.Query().Get("resumptionToken") == "dc-page-2" {
+			mu.Lock()
+			response := responses[0]
+			if len(responses) > 1 {
+				responses = responses[1:]
+			}
+			mu.Unlock()
+
+			if status, err := strconv.Atoi(response); err == nil {
+				http.Error(w, "unavailable", status)
+				return
+			}
+			file = response
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", file))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHarvestTokenRetry(t *testing.T) {
+	server := flakyServer(t, "503", "listrecords_dc_page2.xml")
+	// The first request for the second page fails before reaching the server
+	var reset sync.Once
+	dropConnection := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			var err error
+			if req.URL.Query().Get("resumptionToken") == "dc-page-2" {
+				reset.Do(func() {
+					err = &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+				})
+			}
+			if err != nil {
+				return nil, err
+			}
+			return next.Do(req)
+		})
+	}
+
+	var stats HarvestStats
+	var retries []RetryScheduled
+	opts := &HarvestOptions{
+		TokenRetry: &TokenRetryOptions{Backoff: time.Millisecond},
+		Stats:      &stats,
+		Observer: ObserverFunc(func(event HarvestEvent) {
+			if retry, ok := event.(RetryScheduled); ok {
+				retries = append(retries, retry)
+			}
+		}),
+	}
+	records := 0
+	err := NewClient(server.URL, WithMiddleware(dropConnection)).HarvestRecords("oai_dc", opts, func(HarvestedRecord) error {
+		records++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if records != 4 || stats.Retries != 2 {
+		t.Errorf("Expected 4 records after 2 retries, got %d and %+v", records, stats)
+	}
+	if len(retries) != 2 || retries[1].Attempt != 2 || retries[1].Delay != 2*time.Millisecond || retries[1].ResumptionToken != "dc-page-2" {
+		t.Errorf("Unexpected retry events %+v", retries)
+	}
+	var netErr net.Error
+	var status *StatusError
+	if !errors.As(retries[0].Err, &netErr) || !errors.As(retries[1].Err, &status) || status.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the network error and the 503 status, got %v", retries)
+	}
+}
+
+func TestHarvestTokenRetryFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []string
+		retry     *TokenRetryOptions
+		retries   int
+		check     func(error) bool
+	}{
+		{"exhausted", []string{"500"}, &TokenRetryOptions{MaxRetries: 2, Backoff: time.Millisecond}, 2, func(err error) bool {
+			var status *StatusError
+			return errors.As(err, &status) && status.StatusCode == http.StatusInternalServerError
+		}},
+		{"invalidated", []string{"503", "listrecords_badresumptiontoken.xml"}, &TokenRetryOptions{Backoff: time.Millisecond}, 1, func(err error) bool {
+			return errors.Is(err, ErrTokenInvalidated) && errors.Is(err, ErrBadResumptionToken)
+		}},
+		{"not transient", []string{"404", "listrecords_dc_page2.xml"}, &TokenRetryOptions{Backoff: time.Millisecond}, 0, func(err error) bool {
+			var status *StatusError
+			return errors.As(err, &status) && status.StatusCode == http.StatusNotFound
+		}},
+		{"disabled", []string{"503", "listrecords_dc_page2.xml"}, nil, 0, func(err error) bool {
+			var status *StatusError
+			return errors.As(err, &status)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := flakyServer(t, tt.responses...)
+
+			var stats HarvestStats
+			err := NewClient(server.URL).HarvestRecords("oai_dc", &HarvestOptions{TokenRetry: tt.retry, Stats: &stats}, func(HarvestedRecord) error {
+				return nil
+			})
+			if err == nil || !tt.check(err) {
+				t.Errorf("Unexpected error %v", err)
+			}
+			if stats.Retries != tt.retries {
+				t.Errorf("Expected %d retries, got %d", tt.retries, stats.Retries)
+			}
+		})
+	}
+}
+
+func TestHarvestTokenRetryPermanentErrors(t *testing.T) {
+	// The test server's certificate is not trusted by the default client
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(tlsServer.Close)
+
+	for name, baseURL := range map[string]string{
+		"certificate": tlsServer.URL,
+		"scheme":      "ftp://example.org/oai",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var stats HarvestStats
+			opts := &HarvestOptions{TokenRetry: &TokenRetryOptions{Backoff: time.Millisecond}, Stats: &stats}
+			err := NewClient(baseURL, WithHTTPClient(&http.Client{})).HarvestRecords("oai_dc", opts, func(HarvestedRecord) error {
+				return nil
+			})
+			if err == nil || transient(err) {
+				t.Errorf("Expected a permanent error, got %v", err)
+			}
+			if stats.Retries != 0 {
+				t.Errorf("Expected no retries, got %d", stats.Retries)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+)
+
+// Sample returns up to n records of the repository with their parsed metadata, fetching
+// only the pages needed, to evaluate a repository's data before harvesting it. Deleted
+// records are skipped. If the repository lists no records without a set, or refuses such
+// a request, its sets are sampled in turn until n records are found; sets that fail are
+// skipped.
+func (c *OAIClient) Sample(ctx context.Context, metadataPrefix string, n int) ([]HarvestedRecord, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	var records []HarvestedRecord
+	seen := make(map[string]bool)
+	sample := func(set string) error {
+		opts := &HarvestOptions{Set: set, MaxRecords: n - len(records), SkipDeleted: true, IgnoreNoRecordsMatch: true}
+		return c.harvestRecords(ctx, metadataPrefix, opts, func(record HarvestedRecord) error {
+			if !seen[record.Header.Identifier] {
+				seen[record.Header.Identifier] = true
+				records = append(records, record)
+			}
+			return nil
+		})
+	}
+
+	var oaiErr *OAIError
+	err := sample("")
+	if err != nil && !errors.As(err, &oaiErr) {
+		return nil, err
+	}
+	if len(records) > 0 {
+		return records, err
+	}
+
+	// A repository without sets has no other records to sample
+	sets, setsErr := c.ListSets(ctx)
+	if err == nil {
+		err = setsErr
+	}
+	if len(sets) == 0 {
+		return nil, err
+	}
+
+	for _, set := range sets {
+		if len(records) >= n {
+			break
+		}
+		if err := sample(set.SetSpec); err != nil {
+			if ctx.Err() != nil {
+				return records, ctx.Err()
+			}
+			c.log().Warn("failed to sample set", "baseURL", c.BaseURL, "set", set.SetSpec, "error", err)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,52 @@
+package goharvest
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+
+	records, err := NewClient(server.URL).Sample(context.Background(), "oai_dc", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Header.Identifier != "oai:example.org:1" || records[1].Header.Identifier != "oai:example.org:4" {
+		t.Fatalf("Expected records 1 and 4 without the deleted one, got %v", records)
+	}
+	if records[0].Metadata == nil || records[0].Metadata.ExtractMetadata() == nil {
+		t.Errorf("Expected parsed metadata, got %+v", records[0])
+	}
+	if len(server.Queries()) != 1 {
+		t.Errorf("Expected a single request, got %d", len(server.Queries()))
+	}
+}
+
+func TestSampleAcrossSets(t *testing.T) {
+	// Records are only served per set, and books:fiction fails
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":          "listrecords_norecordsmatch.xml",
+		"ListRecords@books":    "listrecords_dc_duplicates.xml",
+		"ListRecords@theses":   "listrecords_dc_page2.xml",
+		"ListSets":             "listsets_page1.xml",
+		"ListSets:sets-page-2": "listsets_page2.xml",
+	})
+
+	records, err := NewClient(server.URL).Sample(context.Background(), "oai_dc", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var identifiers []string
+	for _, record := range records {
+		identifiers = append(identifiers, record.Header.Identifier)
+	}
+	if !slices.Equal(identifiers, []string{"oai:example.org:4", "oai:example.org:5"}) {
+		t.Errorf("Expected records 4 and 5 from the sets, got %v", identifiers)
+	}
+}
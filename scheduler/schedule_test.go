@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	// A Wednesday
+	base := time.Date(2025, 1, 15, 10, 30, 45, 0, jakarta)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 15, 10, 31, 0, 0, jakarta)},
+		{"0 2 * * *", time.Date(2025, 1, 16, 2, 0, 0, 0, jakarta)},
+		{"*/15 * * * *", time.Date(2025, 1, 15, 10, 45, 0, 0, jakarta)},
+		{"5,35 9-17 * * *", time.Date(2025, 1, 15, 10, 35, 0, 0, jakarta)},
+		{"0 0 * * sun", time.Date(2025, 1, 19, 0, 0, 0, 0, jakarta)},
+		{"0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, jakarta)},
+		{"0 3 1 * *", time.Date(2025, 2, 1, 3, 0, 0, 0, jakarta)},
+		{"0 3 1 * mon", time.Date(2025, 1, 20, 3, 0, 0, 0, jakarta)},
+		{"30 4 29 feb *", time.Date(2028, 2, 29, 4, 30, 0, 0, jakarta)},
+		{"@daily", time.Date(2025, 1, 16, 0, 0, 0, 0, jakarta)},
+		{"@hourly", time.Date(2025, 1, 15, 11, 0, 0, 0, jakarta)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, jakarta)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := schedule.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", base, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestCronWithoutMatch(t *testing.T) {
+	schedule, err := ParseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Expected no run on February 31, got %v", next)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 30, 45, 0, time.UTC)
+	if got := Every(90 * time.Minute).Next(base); !got.Equal(base.Add(90 * time.Minute)) {
+		t.Errorf("Unexpected next run %v", got)
+	}
+}
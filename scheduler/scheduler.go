@@ -0,0 +1,276 @@
+// Package scheduler runs recurring harvest jobs into sinks, for long-running aggregator
+// daemons. Each job runs on an interval or cron schedule, never overlaps with itself and
+// reports its status.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/jiharal/goharvest"
+	"github.com/jiharal/goharvest/sink"
+)
+
+var (
+	// ErrJobRunning is returned by Trigger while the job is running
+	ErrJobRunning = errors.New("job is already running")
+	// ErrUnknownJob is returned by Trigger for a job that was not added
+	ErrUnknownJob = errors.New("unknown job")
+)
+
+// Job is a recurring harvest of one endpoint and metadata prefix into a sink
+type Job struct {
+	// Name identifies the job in status reports and logs
+	Name           string
+	Client         *goharvest.OAIClient
+	MetadataPrefix string
+	// Sets are harvested one after the other (empty for all records)
+	Sets []string
+	// Sink receives the records and is flushed after each run
+	Sink     sink.Sink
+	Schedule Schedule
+	// Jitter delays each run by a random duration up to this value, so that jobs with the
+	// same schedule do not hit their repositories at once
+	Jitter time.Duration
+	// Store makes the runs incremental: each run harvests the records changed since the
+	// last successful one (nil to harvest everything every time)
+	Store goharvest.IncrementalStore
+	// Options are applied to every harvest; Set is taken from Sets
+	Options goharvest.HarvestOptions
+}
+
+// JobStatus reports the state of a job
+type JobStatus struct {
+	Name    string
+	Running bool
+	// NextRun is when the job runs next (zero while running or once the schedule ends)
+	NextRun time.Time
+	// LastStart and LastEnd bound the last run
+	LastStart time.Time
+	LastEnd   time.Time
+	// LastRecords is the number of records written by the last run
+	LastRecords int
+	// LastError is the error of the last run, nil if it succeeded
+	LastError error
+	// Runs and Failures count the finished runs and the failed ones among them
+	Runs     int
+	Failures int
+	// Skipped counts runs not started because the previous one was still running
+	Skipped int
+}
+
+// jobState is a job and its status
+type jobState struct {
+	job    Job
+	status JobStatus
+}
+
+// Scheduler runs jobs on their schedules
+type Scheduler struct {
+	// Logger receives a message for each run (nil for no logging)
+	Logger *slog.Logger
+
+	mu   sync.Mutex
+	jobs []*jobState
+}
+
+// New creates a scheduler without jobs
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add adds a job. Jobs added while Run is running start with the next Run.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Client == nil || job.MetadataPrefix == "" || job.Sink == nil || job.Schedule == nil {
+		return fmt.Errorf("job %q needs a name, client, metadata prefix, sink and schedule", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.jobs {
+		if existing.job.Name == job.Name {
+			return fmt.Errorf("a job named %q already exists", job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &jobState{job: job, status: JobStatus{Name: job.Name}})
+	return nil
+}
+
+// Status returns the status of every job, in the order they were added
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, len(s.jobs))
+	for i, js := range s.jobs {
+		statuses[i] = js.status
+	}
+	return statuses
+}
+
+// Run runs the jobs on their schedules until ctx is cancelled, then waits for running
+// harvests, which see the cancellation, to return
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	jobs := append([]*jobState(nil), s.jobs...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, js := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, js)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// Trigger runs the named job now, outside its schedule, and returns its error
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	s.mu.Lock()
+	var found *jobState
+	for _, js := range s.jobs {
+		if js.job.Name == name {
+			found = js
+		}
+	}
+	s.mu.Unlock()
+
+	if found == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	return s.run(ctx, found)
+}
+
+// loop runs a job each time its schedule fires
+func (s *Scheduler) loop(ctx context.Context, js *jobState) {
+	for {
+		next := js.job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+		if js.job.Jitter > 0 {
+			next = next.Add(rand.N(js.job.Jitter))
+		}
+
+		s.mu.Lock()
+		js.status.NextRun = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(ctx, js)
+	}
+}
+
+// run runs a job unless it is already running
+func (s *Scheduler) run(ctx context.Context, js *jobState) error {
+	s.mu.Lock()
+	if js.status.Running {
+		js.status.Skipped++
+		s.mu.Unlock()
+		s.log().Warn("scheduled harvest skipped, previous run still running", "job", js.job.Name)
+		return ErrJobRunning
+	}
+	start := time.Now()
+	js.status.Running = true
+	js.status.NextRun = time.Time{}
+	js.status.LastStart = start
+	s.mu.Unlock()
+
+	records, err := js.job.harvest(ctx)
+
+	s.mu.Lock()
+	js.status.Running = false
+	js.status.LastEnd = time.Now()
+	js.status.LastRecords = records
+	js.status.LastError = err
+	js.status.Runs++
+	if err != nil {
+		js.status.Failures++
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		s.log().Error("scheduled harvest failed", "job", js.job.Name, "records", records, "duration", time.Since(start), "error", err)
+	} else {
+		s.log().Info("scheduled harvest finished", "job", js.job.Name, "records", records, "duration", time.Since(start))
+	}
+	return err
+}
+
+// log returns the scheduler's logger, or one that discards everything
+func (s *Scheduler) log() *slog.Logger {
+	if s.Logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return s.Logger
+}
+
+// harvest runs every set of the job into its sink and returns the number of records
+// written. A failed set does not stop the others.
+func (j *Job) harvest(ctx context.Context) (int, error) {
+	sets := j.Sets
+	if len(sets) == 0 {
+		sets = []string{""}
+	}
+
+	records := 0
+	write := func(record goharvest.HarvestedRecord) error {
+		if err := j.Sink.Write(record); err != nil {
+			return err
+		}
+		records++
+		return nil
+	}
+
+	var errs []error
+	for _, set := range sets {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		var err error
+		if j.Store != nil {
+			harvester := goharvest.NewIncrementalHarvester(j.Client, j.Store)
+			harvester.Options = j.Options
+			err = harvester.Harvest(ctx, j.MetadataPrefix, set, write)
+		} else {
+			opts := j.Options
+			opts.Set = set
+			for record, harvestErr := range j.Client.Records(ctx, j.MetadataPrefix, &opts) {
+				if err = harvestErr; err == nil {
+					err = write(record)
+				}
+				if err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			if set != "" {
+				err = fmt.Errorf("set %s: %w", set, err)
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	if err := j.Sink.Flush(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to flush sink: %w", err))
+	}
+	return records, errors.Join(errs...)
+}
@@ -0,0 +1,191 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jiharal/goharvest"
+	"github.com/jiharal/goharvest/provider"
+)
+
+// newRepositoryServer serves a repository with three Dublin Core records in sets a and b
+func newRepositoryServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	repo := provider.NewMemoryRepository(goharvest.Identify{
+		RepositoryName: "Scheduler Test",
+		Granularity:    goharvest.GranularitySecond,
+	})
+	for i, set := range []string{"a", "a", "b"} {
+		header := goharvest.Header{
+			Identifier: fmt.Sprintf("oai:example.org:%d", i+1),
+			DateStamp:  "2025-01-01T00:00:00Z",
+			SetSpec:    []string{set},
+		}
+		metadata := fmt.Sprintf(`<oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Record %d</dc:title></oai_dc:dc>`, i+1)
+		repo.Put(header, "oai_dc", []byte(metadata))
+	}
+	server := httptest.NewServer(provider.NewHandler(repo, provider.Options{PageSize: 2}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// blockingSink holds each write until release is closed
+type blockingSink struct {
+	*provider.MemoryRepository
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingSink) Write(record goharvest.HarvestedRecord) error {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-b.release
+	return b.MemoryRepository.Write(record)
+}
+
+func TestSchedulerRunsJobs(t *testing.T) {
+	server := newRepositoryServer(t)
+	target := provider.NewMemoryRepository(goharvest.Identify{})
+
+	s := New()
+	err := s.Add(Job{
+		Name:           "example",
+		Client:         goharvest.NewClient(server.URL),
+		MetadataPrefix: "oai_dc",
+		Sets:           []string{"a", "b"},
+		Sink:           target,
+		Schedule:       Every(10 * time.Millisecond),
+		Store:          goharvest.NewFileIncrementalStore(filepath.Join(t.TempDir(), "state.json")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	for s.Status()[0].Runs < 2 && ctx.Err() == nil {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// A run in progress at shutdown fails with the cancellation
+	status := s.Status()[0]
+	if status.Runs-status.Failures < 2 || status.LastError != nil && !errors.Is(status.LastError, context.Canceled) {
+		t.Fatalf("Unexpected status %+v", status)
+	}
+	if status.Running || status.LastStart.IsZero() || status.LastEnd.Before(status.LastStart) {
+		t.Errorf("Unexpected run times %+v", status)
+	}
+	if target.Len() != 3 {
+		t.Errorf("Expected 3 records in the sink, got %d", target.Len())
+	}
+}
+
+func TestSchedulerReportsFailures(t *testing.T) {
+	server := newRepositoryServer(t)
+
+	s := New()
+	err := s.Add(Job{
+		Name:           "unknown-format",
+		Client:         goharvest.NewClient(server.URL),
+		MetadataPrefix: "marc21",
+		Sink:           provider.NewMemoryRepository(goharvest.Identify{}),
+		Schedule:       Every(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Trigger(context.Background(), "unknown-format"); err == nil {
+		t.Fatal("Expected the harvest to fail")
+	}
+	status := s.Status()[0]
+	if status.Runs != 1 || status.Failures != 1 || status.LastError == nil {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	if err := s.Trigger(context.Background(), "missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Expected ErrUnknownJob, got %v", err)
+	}
+}
+
+func TestSchedulerPreventsOverlap(t *testing.T) {
+	server := newRepositoryServer(t)
+	target := &blockingSink{
+		MemoryRepository: provider.NewMemoryRepository(goharvest.Identify{}),
+		started:          make(chan struct{}, 1),
+		release:          make(chan struct{}),
+	}
+
+	s := New()
+	err := s.Add(Job{
+		Name:           "slow",
+		Client:         goharvest.NewClient(server.URL),
+		MetadataPrefix: "oai_dc",
+		Sink:           target,
+		Schedule:       Every(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Trigger(context.Background(), "slow")
+	}()
+	<-target.started
+
+	if !s.Status()[0].Running {
+		t.Error("Expected the job to be running")
+	}
+	if err := s.Trigger(context.Background(), "slow"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("Expected ErrJobRunning, got %v", err)
+	}
+
+	close(target.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	status := s.Status()[0]
+	if status.Runs != 1 || status.Skipped != 1 || status.LastRecords != 3 {
+		t.Errorf("Unexpected status %+v", status)
+	}
+}
+
+func TestSchedulerAddValidation(t *testing.T) {
+	job := Job{
+		Name:           "example",
+		Client:         goharvest.NewClient("http://example.org/oai"),
+		MetadataPrefix: "oai_dc",
+		Sink:           provider.NewMemoryRepository(goharvest.Identify{}),
+		Schedule:       Every(time.Hour),
+	}
+
+	s := New()
+	if err := s.Add(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(job); err == nil {
+		t.Error("Expected a duplicate name to be rejected")
+	}
+
+	job.Name = "without-schedule"
+	job.Schedule = nil
+	if err := s.Add(job); err == nil {
+		t.Error("Expected a job without schedule to be rejected")
+	}
+}
@@ -0,0 +1,482 @@
+package goharvest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SchemaViolation is one problem found while validating a document against a schema
+type SchemaViolation struct {
+	// Path locates the offending element, e.g. "/OAI-PMH/ListRecords/record[2]/header/datestamp"
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String formats the violation as "path: message"
+func (v SchemaViolation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// SchemaValidator validates an XML document. The bundled OAIPMHSchema, OAIDCSchema and
+// MARCXMLSchema implement it; wrap a full XSD engine (e.g. libxml2) to use other schemas.
+type SchemaValidator interface {
+	ValidateXML(data []byte) []SchemaViolation
+}
+
+// SchemaValidatorFunc adapts a function to the SchemaValidator interface
+type SchemaValidatorFunc func(data []byte) []SchemaViolation
+
+// ValidateXML calls f(data)
+func (f SchemaValidatorFunc) ValidateXML(data []byte) []SchemaViolation {
+	return f(data)
+}
+
+// xmlNode is an element of a parsed document, with its byte range in the source
+type xmlNode struct {
+	name       xml.Name
+	attrs      []xml.Attr
+	children   []*xmlNode
+	text       string
+	start, end int64
+}
+
+// attr returns the value of an attribute by local name
+func (n *xmlNode) attr(local string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Local == local && a.Name.Space != "xmlns" && a.Name.Local != "xmlns" {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// parseXMLTree parses a document into a tree of elements
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root *xmlNode
+	var stack []*xmlNode
+	var text []*strings.Builder
+
+	for {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name, attrs: t.Attr, start: offset}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+			text = append(text, &strings.Builder{})
+		case xml.EndElement:
+			node := stack[len(stack)-1]
+			node.text = text[len(text)-1].String()
+			node.end = dec.InputOffset()
+			stack = stack[:len(stack)-1]
+			text = text[:len(text)-1]
+		case xml.CharData:
+			if len(text) > 0 {
+				text[len(text)-1].Write(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("document has no root element")
+	}
+	return root, nil
+}
+
+// schemaParticle is an element allowed in a content model, occurring min to max times
+// (max 0 means unbounded)
+type schemaParticle struct {
+	name     xml.Name
+	min, max int
+}
+
+// schemaAttribute constrains an attribute
+type schemaAttribute struct {
+	required bool
+	pattern  *regexp.Regexp
+	values   []string
+}
+
+// schemaElement declares the content of an element: an ordered sequence or an unordered
+// choice of child elements, or text matching a pattern or enumeration
+type schemaElement struct {
+	sequence []schemaParticle
+	choice   []schemaParticle
+	// exactlyOne lists child names of which exactly one kind must be present
+	exactlyOne []string
+	// any accepts any content, which is left to other validators
+	any bool
+	// textOnly forbids child elements
+	textOnly bool
+	pattern  *regexp.Regexp
+	values   []string
+	// preserve matches the pattern against the text including surrounding whitespace
+	preserve bool
+	attrs    map[string]schemaAttribute
+}
+
+// xmlSchema is a schema compiled into Go declarations. It implements the XSD features the
+// bundled schemas use (sequences, choices, cardinality, enumerations and patterns), not
+// the whole XSD language.
+type xmlSchema struct {
+	name     string
+	roots    []xml.Name
+	elements map[xml.Name]*schemaElement
+}
+
+// ValidateXML parses and validates a document
+func (s *xmlSchema) ValidateXML(data []byte) []SchemaViolation {
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return []SchemaViolation{{Path: "/", Message: "not well-formed: " + err.Error()}}
+	}
+	return s.validateRoot(root)
+}
+
+// validateRoot validates a parsed document
+func (s *xmlSchema) validateRoot(root *xmlNode) []SchemaViolation {
+	path := "/" + root.name.Local
+	if !slices.Contains(s.roots, root.name) {
+		return []SchemaViolation{{Path: path, Message: fmt.Sprintf("root element {%s}%s is not a %s document", root.name.Space, root.name.Local, s.name)}}
+	}
+
+	var violations []SchemaViolation
+	s.validate(root, path, &violations)
+	return violations
+}
+
+// validate checks an element and its descendants
+func (s *xmlSchema) validate(n *xmlNode, path string, out *[]SchemaViolation) {
+	decl, ok := s.elements[n.name]
+	if !ok {
+		return
+	}
+	report := func(format string, args ...interface{}) {
+		*out = append(*out, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for name, attr := range decl.attrs {
+		value, present := n.attr(name)
+		switch {
+		case !present && attr.required:
+			report("missing required attribute %s", name)
+		case present && attr.values != nil && !slices.Contains(attr.values, strings.TrimSpace(value)):
+			report("attribute %s=%q is not one of %s", name, value, strings.Join(attr.values, ", "))
+		case present && attr.pattern != nil && !attr.pattern.MatchString(value):
+			report("attribute %s=%q does not match %s", name, value, patternSource(attr.pattern))
+		}
+	}
+	if decl.any {
+		return
+	}
+
+	text := n.text
+	if !decl.preserve {
+		text = strings.TrimSpace(text)
+	}
+	if len(decl.sequence) == 0 && len(decl.choice) == 0 {
+		if len(n.children) > 0 {
+			report("unexpected element %s in text-only element", n.children[0].name.Local)
+		}
+		if decl.values != nil && !slices.Contains(decl.values, text) {
+			report("value %q is not one of %s", text, strings.Join(decl.values, ", "))
+		} else if decl.pattern != nil && !decl.pattern.MatchString(text) {
+			report("value %q does not match %s", text, patternSource(decl.pattern))
+		}
+		return
+	}
+	if strings.TrimSpace(n.text) != "" {
+		report("unexpected text %q in element-only content", truncate(strings.TrimSpace(n.text), 40))
+	}
+
+	if len(decl.sequence) > 0 {
+		s.validateSequence(n, decl.sequence, report)
+	} else {
+		s.validateChoice(n, decl.choice, report)
+	}
+	if decl.exactlyOne != nil {
+		present := map[string]bool{}
+		for _, child := range n.children {
+			if slices.Contains(decl.exactlyOne, child.name.Local) {
+				present[child.name.Local] = true
+			}
+		}
+		if len(present) != 1 {
+			report("expected exactly one of %s", strings.Join(decl.exactlyOne, ", "))
+		}
+	}
+
+	counts := map[xml.Name]int{}
+	totals := map[xml.Name]int{}
+	for _, child := range n.children {
+		totals[child.name]++
+	}
+	for _, child := range n.children {
+		counts[child.name]++
+		childPath := path + "/" + child.name.Local
+		if totals[child.name] > 1 {
+			childPath += fmt.Sprintf("[%d]", counts[child.name])
+		}
+		s.validate(child, childPath, out)
+	}
+}
+
+// validateSequence checks children against an ordered content model
+func (s *xmlSchema) validateSequence(n *xmlNode, sequence []schemaParticle, report func(string, ...interface{})) {
+	i, count := 0, 0
+	for _, child := range n.children {
+		for i < len(sequence) && sequence[i].name != child.name {
+			if count < sequence[i].min {
+				report("missing element %s", sequence[i].name.Local)
+			}
+			i, count = i+1, 0
+		}
+		if i == len(sequence) {
+			report("unexpected element %s", child.name.Local)
+			return
+		}
+		count++
+		if sequence[i].max > 0 && count > sequence[i].max {
+			report("too many %s elements", child.name.Local)
+		}
+	}
+	for ; i < len(sequence); i, count = i+1, 0 {
+		if count < sequence[i].min {
+			report("missing element %s", sequence[i].name.Local)
+		}
+	}
+}
+
+// validateChoice checks children against an unordered content model
+func (s *xmlSchema) validateChoice(n *xmlNode, choice []schemaParticle, report func(string, ...interface{})) {
+	counts := map[xml.Name]int{}
+	for _, child := range n.children {
+		allowed := false
+		for _, p := range choice {
+			if p.name == child.name {
+				allowed = true
+			}
+		}
+		if !allowed {
+			report("unexpected element {%s}%s", child.name.Space, child.name.Local)
+			continue
+		}
+		counts[child.name]++
+	}
+	for _, p := range choice {
+		if counts[p.name] < p.min {
+			report("missing element %s", p.name.Local)
+		}
+		if p.max > 0 && counts[p.name] > p.max {
+			report("too many %s elements", p.name.Local)
+		}
+	}
+}
+
+// patternSource returns a pattern as written in the schema, without the anchoring
+func patternSource(re *regexp.Regexp) string {
+	return strings.TrimSuffix(strings.TrimPrefix(re.String(), "^(?:"), ")$")
+}
+
+// truncate shortens s to n bytes for messages
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// ValidationError reports the schema violations of one record, or of the response envelope
+// when Identifier is empty
+type ValidationError struct {
+	// Identifier of the offending record ("" for violations outside any record)
+	Identifier string
+	// Schema is "OAI-PMH" for envelope violations, otherwise the metadata prefix
+	Schema string
+	// ResumptionToken that requested the page ("" for the first page)
+	ResumptionToken string
+	Violations      []SchemaViolation
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	subject := "response"
+	if e.Identifier != "" {
+		subject = "record " + e.Identifier
+	}
+	msg := fmt.Sprintf("%s is not valid %s", subject, e.Schema)
+	if len(e.Violations) > 0 {
+		msg += ": " + e.Violations[0].String()
+	}
+	if len(e.Violations) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(e.Violations)-1)
+	}
+	return msg
+}
+
+// ValidationOptions configures schema validation of harvested ListRecords pages
+type ValidationOptions struct {
+	// Response validates the response envelope (nil for OAIPMHSchema)
+	Response SchemaValidator
+	// SkipResponse disables envelope validation
+	SkipResponse bool
+	// Metadata maps metadata prefixes to validators, overriding the bundled defaults
+	// (oai_dc, marcxml and marc21). A nil entry disables validation for that prefix.
+	// Validators other than the bundled ones receive the metadata element as it appears
+	// in the response; namespace declarations made on enclosing elements are not copied.
+	Metadata map[string]SchemaValidator
+	// OnInvalid is called for each invalid record, and once for envelope violations
+	// outside records. Returning nil continues the harvest; returning an error aborts it.
+	// A nil OnInvalid aborts the harvest with the *ValidationError.
+	OnInvalid func(*ValidationError) error
+}
+
+// metadataValidator returns the validator for a metadata prefix, or nil
+func (o *ValidationOptions) metadataValidator(prefix string) SchemaValidator {
+	if v, ok := o.Metadata[prefix]; ok {
+		return v
+	}
+	switch MetadataFormat(prefix) {
+	case FormatOAIDC:
+		return OAIDCSchema
+	case FormatMARCXML, FormatMARC21:
+		return MARCXMLSchema
+	}
+	return nil
+}
+
+// report logs a validation error and passes it to OnInvalid
+func (o *ValidationOptions) report(logger *slog.Logger, err *ValidationError) error {
+	logger.Warn("OAI-PMH response failed schema validation",
+		"identifier", err.Identifier, "schema", err.Schema, "resumptionToken", err.ResumptionToken,
+		"violations", len(err.Violations), "error", err.Error())
+	if o.OnInvalid == nil {
+		return err
+	}
+	return o.OnInvalid(err)
+}
+
+// validatePage validates a ListRecords response and the metadata of each of its records
+func (o *ValidationOptions) validatePage(data []byte, metadataPrefix, resumptionToken string, logger *slog.Logger) error {
+	root, err := parseXMLTree(data)
+	if err != nil {
+		if o.SkipResponse {
+			return nil
+		}
+		return o.report(logger, &ValidationError{
+			Schema:          "OAI-PMH",
+			ResumptionToken: resumptionToken,
+			Violations:      []SchemaViolation{{Path: "/", Message: "not well-formed: " + err.Error()}},
+		})
+	}
+
+	var envelope []SchemaViolation
+	if !o.SkipResponse {
+		validator := o.Response
+		if validator == nil {
+			validator = OAIPMHSchema
+		}
+		envelope = validateNode(validator, data, root, "")
+	}
+
+	var records []*xmlNode
+	recordsPath := "/" + root.name.Local + "/ListRecords/record"
+	for _, child := range root.children {
+		if child.name.Local == "ListRecords" {
+			for _, record := range child.children {
+				if record.name.Local == "record" {
+					records = append(records, record)
+				}
+			}
+		}
+	}
+
+	for i, record := range records {
+		path := recordsPath
+		if len(records) > 1 {
+			path += fmt.Sprintf("[%d]", i+1)
+		}
+		identifier := ""
+		var metadata *xmlNode
+		for _, child := range record.children {
+			switch child.name.Local {
+			case "header":
+				for _, field := range child.children {
+					if field.name.Local == "identifier" {
+						identifier = strings.TrimSpace(field.text)
+					}
+				}
+			case "metadata":
+				if len(child.children) > 0 {
+					metadata = child.children[0]
+				}
+			}
+		}
+
+		var own []SchemaViolation
+		envelope = slices.DeleteFunc(envelope, func(v SchemaViolation) bool {
+			if v.Path == path || strings.HasPrefix(v.Path, path+"/") {
+				own = append(own, v)
+				return true
+			}
+			return false
+		})
+		schema := "OAI-PMH"
+		if metadata != nil {
+			if validator := o.metadataValidator(metadataPrefix); validator != nil {
+				if violations := validateNode(validator, data, metadata, path+"/metadata"); len(violations) > 0 {
+					own = append(own, violations...)
+					schema = metadataPrefix
+				}
+			}
+		}
+
+		if len(own) > 0 {
+			err := o.report(logger, &ValidationError{Identifier: identifier, Schema: schema, ResumptionToken: resumptionToken, Violations: own})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(envelope) > 0 {
+		return o.report(logger, &ValidationError{Schema: "OAI-PMH", ResumptionToken: resumptionToken, Violations: envelope})
+	}
+	return nil
+}
+
+// validateNode validates an element with a bundled schema, or its source bytes with any
+// other validator, and prefixes violation paths with the element's location
+func validateNode(validator SchemaValidator, data []byte, n *xmlNode, path string) []SchemaViolation {
+	var violations []SchemaViolation
+	if schema, ok := validator.(*xmlSchema); ok {
+		violations = schema.validateRoot(n)
+	} else {
+		violations = validator.ValidateXML(data[n.start:n.end])
+	}
+
+	for i := range violations {
+		violations[i].Path = path + violations[i].Path
+	}
+	return violations
+}
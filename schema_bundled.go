@@ -0,0 +1,226 @@
+package goharvest
+
+import (
+	"encoding/xml"
+	"regexp"
+)
+
+// Namespaces of the bundled schemas
+const (
+	oaiDCNamespace   = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	dcNamespace      = "http://purl.org/dc/elements/1.1/"
+	marcxmlNamespace = "http://www.loc.gov/MARC21/slim"
+)
+
+// Bundled schemas, compiled from OAI-PMH.xsd, oai_dc.xsd and MARC21slim.xsd
+var (
+	// OAIPMHSchema validates OAI-PMH 2.0 response envelopes; metadata and about
+	// containers are left to the metadata schemas
+	OAIPMHSchema SchemaValidator = oaiPMHSchema
+	// OAIDCSchema validates oai_dc:dc metadata records
+	OAIDCSchema SchemaValidator = oaiDCSchema
+	// MARCXMLSchema validates MARC 21 slim records and collections
+	MARCXMLSchema SchemaValidator = marcxmlSchema
+)
+
+// anchored compiles a pattern that must match the whole value, as XSD patterns do
+func anchored(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(`^(?:` + pattern + `)$`)
+}
+
+// one, optional and many build content model particles
+func one(space, local string) schemaParticle {
+	return schemaParticle{name: xml.Name{Space: space, Local: local}, min: 1, max: 1}
+}
+
+func optional(space, local string) schemaParticle {
+	return schemaParticle{name: xml.Name{Space: space, Local: local}, max: 1}
+}
+
+func many(space, local string, min int) schemaParticle {
+	return schemaParticle{name: xml.Name{Space: space, Local: local}, min: min}
+}
+
+var (
+	utcDatetimePattern = anchored(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z`)
+	datestampPattern   = anchored(`\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}:\d{2}Z)?`)
+	prefixPattern      = anchored(`[A-Za-z0-9\-_\.!~\*'\(\)]+`)
+	nonEmptyPattern    = anchored(`.+`)
+)
+
+var oaiPMHSchema = func() *xmlSchema {
+	ns := OAINamespace
+	verbs := []string{"error", "Identify", "ListMetadataFormats", "ListSets", "GetRecord", "ListIdentifiers", "ListRecords"}
+	text := func(pattern *regexp.Regexp) *schemaElement {
+		return &schemaElement{pattern: pattern}
+	}
+	name := func(local string) xml.Name {
+		return xml.Name{Space: ns, Local: local}
+	}
+
+	return &xmlSchema{
+		name:  "OAI-PMH",
+		roots: []xml.Name{name("OAI-PMH")},
+		elements: map[xml.Name]*schemaElement{
+			name("OAI-PMH"): {
+				sequence: []schemaParticle{
+					one(ns, "responseDate"), one(ns, "request"), many(ns, "error", 0),
+					optional(ns, "Identify"), optional(ns, "ListMetadataFormats"), optional(ns, "ListSets"),
+					optional(ns, "GetRecord"), optional(ns, "ListIdentifiers"), optional(ns, "ListRecords"),
+				},
+				exactlyOne: verbs,
+			},
+			name("responseDate"): text(utcDatetimePattern),
+			name("request"): {
+				attrs: map[string]schemaAttribute{
+					"verb":           {values: verbs[1:]},
+					"identifier":     {pattern: nonEmptyPattern},
+					"metadataPrefix": {pattern: prefixPattern},
+					"from":           {pattern: datestampPattern},
+					"until":          {pattern: datestampPattern},
+					"set":            {pattern: setSpecPattern},
+				},
+			},
+			name("error"): {
+				attrs: map[string]schemaAttribute{
+					"code": {required: true, values: []string{
+						ErrorCodeCannotDisseminateFormat, ErrorCodeIDDoesNotExist, ErrorCodeBadArgument,
+						ErrorCodeBadVerb, ErrorCodeNoMetadataFormats, ErrorCodeNoRecordsMatch,
+						ErrorCodeBadResumptionToken, ErrorCodeNoSetHierarchy,
+					}},
+				},
+			},
+			name("Identify"): {
+				sequence: []schemaParticle{
+					one(ns, "repositoryName"), one(ns, "baseURL"), one(ns, "protocolVersion"),
+					many(ns, "adminEmail", 1), one(ns, "earliestDatestamp"), one(ns, "deletedRecord"),
+					one(ns, "granularity"), many(ns, "compression", 0), many(ns, "description", 0),
+				},
+			},
+			name("repositoryName"):    text(nil),
+			name("baseURL"):           text(nonEmptyPattern),
+			name("protocolVersion"):   {values: []string{"2.0"}},
+			name("adminEmail"):        text(anchored(`\S+@(\S+\.)+\S+`)),
+			name("earliestDatestamp"): text(datestampPattern),
+			name("deletedRecord"):     {values: []string{DeletedRecordNo, DeletedRecordPersistent, DeletedRecordTransient}},
+			name("granularity"):       {values: []string{GranularityDay, GranularitySecond}},
+			name("compression"):       text(nil),
+			name("description"):       {any: true},
+			name("ListMetadataFormats"): {
+				sequence: []schemaParticle{many(ns, "metadataFormat", 1)},
+			},
+			name("metadataFormat"): {
+				sequence: []schemaParticle{one(ns, "metadataPrefix"), one(ns, "schema"), one(ns, "metadataNamespace")},
+			},
+			name("metadataPrefix"):    text(prefixPattern),
+			name("schema"):            text(nonEmptyPattern),
+			name("metadataNamespace"): text(nonEmptyPattern),
+			name("ListSets"): {
+				sequence: []schemaParticle{many(ns, "set", 1), optional(ns, "resumptionToken")},
+			},
+			name("set"): {
+				sequence: []schemaParticle{one(ns, "setSpec"), one(ns, "setName"), many(ns, "setDescription", 0)},
+			},
+			name("setSpec"):        text(setSpecPattern),
+			name("setName"):        text(nil),
+			name("setDescription"): {any: true},
+			name("GetRecord"): {
+				sequence: []schemaParticle{one(ns, "record")},
+			},
+			name("ListRecords"): {
+				sequence: []schemaParticle{many(ns, "record", 1), optional(ns, "resumptionToken")},
+			},
+			name("ListIdentifiers"): {
+				sequence: []schemaParticle{many(ns, "header", 1), optional(ns, "resumptionToken")},
+			},
+			name("record"): {
+				sequence: []schemaParticle{one(ns, "header"), optional(ns, "metadata"), many(ns, "about", 0)},
+			},
+			name("header"): {
+				sequence: []schemaParticle{one(ns, "identifier"), one(ns, "datestamp"), many(ns, "setSpec", 0)},
+				attrs: map[string]schemaAttribute{
+					"status": {values: []string{"deleted"}},
+				},
+			},
+			name("identifier"): text(nonEmptyPattern),
+			name("datestamp"):  text(datestampPattern),
+			name("metadata"):   {any: true},
+			name("about"):      {any: true},
+			name("resumptionToken"): {
+				attrs: map[string]schemaAttribute{
+					"expirationDate":   {pattern: utcDatetimePattern},
+					"completeListSize": {pattern: anchored(`\d+`)},
+					"cursor":           {pattern: anchored(`\d+`)},
+				},
+			},
+		},
+	}
+}()
+
+var oaiDCSchema = func() *xmlSchema {
+	schema := &xmlSchema{
+		name:     "oai_dc",
+		roots:    []xml.Name{{Space: oaiDCNamespace, Local: "dc"}},
+		elements: map[xml.Name]*schemaElement{},
+	}
+
+	var choice []schemaParticle
+	for _, local := range []string{
+		"title", "creator", "subject", "description", "publisher", "contributor", "date", "type",
+		"format", "identifier", "source", "language", "relation", "coverage", "rights",
+	} {
+		choice = append(choice, many(dcNamespace, local, 0))
+		schema.elements[xml.Name{Space: dcNamespace, Local: local}] = &schemaElement{}
+	}
+	schema.elements[xml.Name{Space: oaiDCNamespace, Local: "dc"}] = &schemaElement{choice: choice}
+
+	return schema
+}()
+
+var marcxmlSchema = func() *xmlSchema {
+	ns := marcxmlNamespace
+	name := func(local string) xml.Name {
+		return xml.Name{Space: ns, Local: local}
+	}
+	indicator := schemaAttribute{required: true, pattern: anchored(`[\da-z ]`)}
+
+	return &xmlSchema{
+		name:  "MARC21slim",
+		roots: []xml.Name{name("collection"), name("record")},
+		elements: map[xml.Name]*schemaElement{
+			name("collection"): {
+				choice: []schemaParticle{many(ns, "record", 0)},
+			},
+			name("record"): {
+				sequence: []schemaParticle{one(ns, "leader"), many(ns, "controlfield", 0), many(ns, "datafield", 0)},
+				attrs: map[string]schemaAttribute{
+					"type": {values: []string{"Bibliographic", "Authority", "Holdings", "Classification", "Community"}},
+				},
+			},
+			name("leader"): {
+				pattern:  anchored(`[\d ]{5}[\dA-Za-z ][\dA-Za-z][\dA-Za-z ]{3}(2| )(2| )[\d ]{5}[\dA-Za-z ]{3}(4500|    )`),
+				preserve: true,
+			},
+			name("controlfield"): {
+				preserve: true,
+				attrs: map[string]schemaAttribute{
+					"tag": {required: true, pattern: anchored(`00[1-9A-Za-z]`)},
+				},
+			},
+			name("datafield"): {
+				sequence: []schemaParticle{many(ns, "subfield", 1)},
+				attrs: map[string]schemaAttribute{
+					"tag":  {required: true, pattern: anchored(`(0([1-9A-Z][0-9A-Z])|0([1-9a-z][0-9a-z]))|(([1-9A-Z][0-9A-Z]{2})|([1-9a-z][0-9a-z]{2}))`)},
+					"ind1": indicator,
+					"ind2": indicator,
+				},
+			},
+			name("subfield"): {
+				preserve: true,
+				attrs: map[string]schemaAttribute{
+					"code": {required: true, pattern: anchored(`[\dA-Za-z!"#$%&'()*+,\-./:;<=>?{}_^` + "`" + `~\[\]\\]`)},
+				},
+			},
+		},
+	}
+}()
@@ -0,0 +1,297 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const schemaTestRecord = `<?xml version="1.0" encoding="UTF-8"?>
+<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/">
+  <responseDate>2025-10-02T10:05:19Z</responseDate>
+  <request verb="ListRecords" metadataPrefix="oai_dc">http://example.org/oai</request>
+  <ListRecords>
+    <record>
+      <header>
+        <identifier>oai:example.org:1</identifier>
+        <datestamp>2025-01-15</datestamp>
+      </header>
+      <metadata>
+        <oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+          <dc:title>Sistem Informasi Perpustakaan</dc:title>
+        </oai_dc:dc>
+      </metadata>
+    </record>
+    <record>
+      <header>
+        <identifier>oai:example.org:2</identifier>
+        <datestamp>2025-01-16</datestamp>
+      </header>
+      <metadata>
+        <oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+          <dc:titel>Analisis Kebutuhan Pemustaka</dc:titel>
+        </oai_dc:dc>
+      </metadata>
+    </record>
+  </ListRecords>
+</OAI-PMH>`
+
+func TestOAIPMHSchemaAcceptsFixtures(t *testing.T) {
+	for _, file := range []string{
+		"identify_response.xml",
+		"listmetadataformats_response.xml",
+		"listsets_page1.xml",
+		"listidentifiers_page1.xml",
+		"listrecords_dc_page1.xml",
+		"listrecords_dc_page2.xml",
+		"listrecords_mods.xml",
+		"getrecord_oai_dc.xml",
+	} {
+		data, err := os.ReadFile(filepath.Join("testdata", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if violations := OAIPMHSchema.ValidateXML(data); len(violations) > 0 {
+			t.Errorf("%s: unexpected violations %v", file, violations)
+		}
+	}
+}
+
+func TestOAIPMHSchemaViolations(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "listrecords_dc_page1.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := string(data)
+
+	tests := []struct {
+		name    string
+		doc     string
+		path    string
+		message string
+	}{
+		{"bad datestamp", strings.Replace(valid, "2025-01-15", "15/01/2025", 1), "/OAI-PMH/ListRecords/record[1]/header/datestamp", "does not match"},
+		{"bad status", strings.Replace(valid, `status="deleted"`, `status="removed"`, 1), "/OAI-PMH/ListRecords/record[2]/header", "status"},
+		{"missing responseDate", strings.Replace(valid, "<responseDate>2025-10-02T10:05:19Z</responseDate>", "", 1), "/OAI-PMH", "missing element responseDate"},
+		{"misordered header", strings.Replace(valid, "<identifier>oai:example.org:1</identifier>\n        <datestamp>2025-01-15</datestamp>", "<datestamp>2025-01-15</datestamp>\n        <identifier>oai:example.org:1</identifier>", 1), "/OAI-PMH/ListRecords/record[1]/header", "unexpected element identifier"},
+		{"bad setSpec", strings.Replace(valid, "<setSpec>theses</setSpec>", "<setSpec>theses collection</setSpec>", 1), "/OAI-PMH/ListRecords/record[1]/header/setSpec", "does not match"},
+		{"bad cursor", strings.Replace(valid, `cursor="0"`, `cursor="first"`, 1), "/OAI-PMH/ListRecords/resumptionToken", "cursor"},
+		{"wrong namespace", strings.Replace(valid, "http://www.openarchives.org/OAI/2.0/\"", "http://www.openarchives.org/OAI/1.1/\"", 1), "/OAI-PMH", "is not a OAI-PMH document"},
+		{"not well-formed", valid[:200], "/", "not well-formed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := OAIPMHSchema.ValidateXML([]byte(tt.doc))
+			for _, v := range violations {
+				if v.Path == tt.path && strings.Contains(v.Message, tt.message) {
+					return
+				}
+			}
+			t.Errorf("expected violation %q at %s, got %v", tt.message, tt.path, violations)
+		})
+	}
+}
+
+func TestOAIPMHSchemaErrorResponse(t *testing.T) {
+	doc := `<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/">
+  <responseDate>2025-10-02T10:05:19Z</responseDate>
+  <request>http://example.org/oai</request>
+  <error code="badVerb">Illegal verb</error>
+</OAI-PMH>`
+	if violations := OAIPMHSchema.ValidateXML([]byte(doc)); len(violations) > 0 {
+		t.Errorf("unexpected violations %v", violations)
+	}
+
+	invalid := strings.Replace(doc, "badVerb", "unknownError", 1)
+	if violations := OAIPMHSchema.ValidateXML([]byte(invalid)); len(violations) != 1 || !strings.Contains(violations[0].Message, "code") {
+		t.Errorf("expected one error code violation, got %v", violations)
+	}
+
+	both := strings.Replace(doc, "</OAI-PMH>", "<ListRecords/></OAI-PMH>", 1)
+	if violations := OAIPMHSchema.ValidateXML([]byte(both)); len(violations) == 0 {
+		t.Error("expected violation for error and ListRecords in one response")
+	}
+}
+
+func TestOAIDCSchema(t *testing.T) {
+	valid := `<oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <dc:title xml:lang="id">Sistem Informasi</dc:title><dc:creator>Santoso, Budi</dc:creator><dc:title>Second title</dc:title>
+</oai_dc:dc>`
+	if violations := OAIDCSchema.ValidateXML([]byte(valid)); len(violations) > 0 {
+		t.Errorf("unexpected violations %v", violations)
+	}
+
+	invalid := strings.Replace(valid, "<dc:creator>Santoso, Budi</dc:creator>", "<dc:author>Santoso, Budi</dc:author><dc:date><b>2024</b></dc:date>", 1)
+	violations := OAIDCSchema.ValidateXML([]byte(invalid))
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %v", violations)
+	}
+	if violations[0].Path != "/dc" || !strings.Contains(violations[0].Message, "author") {
+		t.Errorf("unexpected violation %v", violations[0])
+	}
+	if violations[1].Path != "/dc/date" {
+		t.Errorf("unexpected violation %v", violations[1])
+	}
+}
+
+func TestMARCXMLSchema(t *testing.T) {
+	valid := `<record xmlns="http://www.loc.gov/MARC21/slim" type="Bibliographic">
+  <leader>00000nam a2200000 a 4500</leader>
+  <controlfield tag="001">14</controlfield>
+  <controlfield tag="008">240101s2024    io            000 0 ind d</controlfield>
+  <datafield tag="245" ind1="1" ind2="0"><subfield code="a">Sistem Informasi</subfield></datafield>
+</record>`
+	if violations := MARCXMLSchema.ValidateXML([]byte(valid)); len(violations) > 0 {
+		t.Errorf("unexpected violations %v", violations)
+	}
+
+	// The Balai Layanan Perpustakaan fixture puts control fields in datafields and uses
+	// "#" for blank indicators
+	data, err := os.ReadFile(filepath.Join("testdata", "getrecord_marcxml.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := parseXMLTree(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := root.children[2].children[0].children[1].children[0]
+	violations := validateNode(MARCXMLSchema, data, record, "")
+	var tag, indicator bool
+	for _, v := range violations {
+		tag = tag || strings.Contains(v.Message, `tag="001"`)
+		indicator = indicator || strings.Contains(v.Message, `="#"`)
+	}
+	if !tag || !indicator {
+		t.Errorf("expected tag and indicator violations, got %v", violations)
+	}
+}
+
+func serveDocument(t *testing.T, doc string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.Write([]byte(doc))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHarvestValidationValidPages(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+
+	var invalid []*ValidationError
+	opts := &HarvestOptions{Validation: &ValidationOptions{
+		OnInvalid: func(err *ValidationError) error {
+			invalid = append(invalid, err)
+			return nil
+		},
+	}}
+
+	count := 0
+	err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(record HarvestedRecord) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestRecords failed: %v", err)
+	}
+	if count != 4 || len(invalid) != 0 {
+		t.Errorf("expected 4 records and no violations, got %d records and %v", count, invalid)
+	}
+}
+
+func TestHarvestValidationReportsRecords(t *testing.T) {
+	server := serveDocument(t, strings.Replace(schemaTestRecord, "2025-01-15", "2025-1-15", 1))
+
+	var invalid []*ValidationError
+	opts := &HarvestOptions{Validation: &ValidationOptions{
+		OnInvalid: func(err *ValidationError) error {
+			invalid = append(invalid, err)
+			return nil
+		},
+	}}
+
+	count := 0
+	err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(record HarvestedRecord) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HarvestRecords failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected invalid records to be delivered, got %d", count)
+	}
+
+	if len(invalid) != 2 {
+		t.Fatalf("expected 2 invalid records, got %v", invalid)
+	}
+	if invalid[0].Identifier != "oai:example.org:1" || invalid[0].Schema != "OAI-PMH" ||
+		invalid[0].Violations[0].Path != "/OAI-PMH/ListRecords/record[1]/header/datestamp" {
+		t.Errorf("unexpected envelope error %+v", invalid[0])
+	}
+	if invalid[1].Identifier != "oai:example.org:2" || invalid[1].Schema != "oai_dc" ||
+		invalid[1].Violations[0].Path != "/OAI-PMH/ListRecords/record[2]/metadata/dc" {
+		t.Errorf("unexpected metadata error %+v", invalid[1])
+	}
+}
+
+func TestHarvestValidationAborts(t *testing.T) {
+	server := serveDocument(t, schemaTestRecord)
+
+	err := NewClient(server.URL).HarvestRecords("oai_dc", &HarvestOptions{Validation: &ValidationOptions{}}, func(record HarvestedRecord) error {
+		t.Error("callback called for a page that failed validation")
+		return nil
+	})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	if validationErr.Identifier != "oai:example.org:2" {
+		t.Errorf("unexpected identifier %q", validationErr.Identifier)
+	}
+	if !strings.Contains(err.Error(), "record oai:example.org:2 is not valid oai_dc") {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestHarvestValidationCustomValidator(t *testing.T) {
+	server := serveDocument(t, schemaTestRecord)
+
+	var fragments []string
+	opts := &HarvestOptions{Validation: &ValidationOptions{
+		SkipResponse: true,
+		Metadata: map[string]SchemaValidator{
+			"oai_dc": SchemaValidatorFunc(func(data []byte) []SchemaViolation {
+				fragments = append(fragments, string(data))
+				return nil
+			}),
+		},
+	}}
+
+	err := NewClient(server.URL).HarvestContext(context.Background(), "oai_dc", opts, func(response OAIResponse) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("harvest failed: %v", err)
+	}
+	if len(fragments) != 2 || !strings.HasPrefix(fragments[0], "<oai_dc:dc") || !strings.HasSuffix(fragments[0], "</oai_dc:dc>") {
+		t.Errorf("unexpected fragments %q", fragments)
+	}
+
+	opts.Validation.Metadata["oai_dc"] = nil
+	if err := NewClient(server.URL).HarvestWithOptions("oai_dc", opts, func(OAIResponse) error { return nil }); err != nil {
+		t.Errorf("expected disabled validation to pass, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package goharvest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Default limits applied by NewClient
+const (
+	// DefaultMaxResponseSize is the largest response body read, after decompression
+	DefaultMaxResponseSize = 512 << 20
+	// DefaultMaxXMLDepth is the deepest element nesting accepted
+	DefaultMaxXMLDepth = 256
+)
+
+// Errors returned when a response exceeds a limit or contains a DTD. Harvesting untrusted
+// endpoints should not expose callers to decompression bombs, runaway nesting or entity
+// expansion, so responses are checked while they are read.
+var (
+	ErrResponseTooLarge = errors.New("response body too large")
+	ErrXMLTooDeep       = errors.New("XML nesting too deep")
+	// ErrDTDNotAllowed is returned for documents with a DOCTYPE or ENTITY declaration,
+	// which OAI-PMH responses never need
+	ErrDTDNotAllowed = errors.New("XML document type declarations are not allowed")
+)
+
+// WithMaxResponseSize limits the size of response bodies (0 for no limit)
+func WithMaxResponseSize(n int64) ClientOption {
+	return func(c *OAIClient) {
+		c.MaxResponseSize = n
+	}
+}
+
+// WithMaxXMLDepth limits the element nesting of responses (0 for no limit)
+func WithMaxXMLDepth(n int) ClientOption {
+	return func(c *OAIClient) {
+		c.MaxXMLDepth = n
+	}
+}
+
+// guardBody wraps a response body with the client's limits
+func (c *OAIClient) guardBody(body io.ReadCloser) io.ReadCloser {
+	return &guardReader{ReadCloser: body, maxSize: c.MaxResponseSize, maxDepth: c.MaxXMLDepth}
+}
+
+// guardState is the lexical context of a guardReader
+type guardState int
+
+const (
+	guardText     guardState = iota
+	guardOpen                // after '<'
+	guardStartTag            // inside a start tag
+	guardEndTag              // inside an end tag
+	guardDecl                // after "<!", deciding between comment, CDATA and declaration
+	guardComment
+	guardCDATA
+	guardPI
+	guardOtherDecl
+)
+
+// guardReader enforces size and depth limits and rejects DTDs while a body is read.
+// It tokenizes just enough XML to count element nesting, so that limits hold for every
+// parser reading the body, including xml.Unmarshal.
+type guardReader struct {
+	io.ReadCloser
+	maxSize  int64
+	maxDepth int
+
+	size  int64
+	depth int
+	state guardState
+	quote byte
+	prev  [2]byte
+	decl  []byte
+	err   error
+}
+
+// Read implements io.Reader
+func (g *guardReader) Read(p []byte) (int, error) {
+	if g.err != nil {
+		return 0, g.err
+	}
+
+	n, err := g.ReadCloser.Read(p)
+	g.size += int64(n)
+	if g.maxSize > 0 && g.size > g.maxSize {
+		g.err = fmt.Errorf("%w: limit is %d bytes", ErrResponseTooLarge, g.maxSize)
+		return 0, g.err
+	}
+	for _, b := range p[:n] {
+		if g.err = g.scan(b); g.err != nil {
+			return 0, g.err
+		}
+	}
+	return n, err
+}
+
+// scan advances the tokenizer by one byte
+func (g *guardReader) scan(b byte) error {
+	prev := g.prev
+	g.prev = [2]byte{prev[1], b}
+
+	switch g.state {
+	case guardText:
+		if b == '<' {
+			g.state = guardOpen
+		}
+	case guardOpen:
+		switch b {
+		case '/':
+			g.state = guardEndTag
+		case '?':
+			g.state = guardPI
+		case '!':
+			g.state = guardDecl
+			g.decl = g.decl[:0]
+		default:
+			g.state = guardStartTag
+			g.depth++
+			if g.maxDepth > 0 && g.depth > g.maxDepth {
+				return fmt.Errorf("%w: limit is %d levels", ErrXMLTooDeep, g.maxDepth)
+			}
+		}
+	case guardStartTag:
+		switch {
+		case g.quote != 0:
+			if b == g.quote {
+				g.quote = 0
+			}
+		case b == '"' || b == '\'':
+			g.quote = b
+		case b == '>':
+			if prev[1] == '/' {
+				g.depth--
+			}
+			g.state = guardText
+		}
+	case guardEndTag:
+		if b == '>' {
+			g.depth--
+			g.state = guardText
+		}
+	case guardDecl:
+		g.decl = append(g.decl, b)
+		switch string(g.decl) {
+		case "--":
+			g.state = guardComment
+		case "[CDATA[":
+			g.state = guardCDATA
+		case "DOCTYPE", "ENTITY":
+			return ErrDTDNotAllowed
+		default:
+			if !isPrefixOfAny(g.decl, "--", "[CDATA[", "DOCTYPE", "ENTITY") {
+				g.state = guardOtherDecl
+			}
+		}
+	case guardComment:
+		if b == '>' && prev == [2]byte{'-', '-'} {
+			g.state = guardText
+		}
+	case guardCDATA:
+		if b == '>' && prev == [2]byte{']', ']'} {
+			g.state = guardText
+		}
+	case guardPI:
+		if b == '>' && prev[1] == '?' {
+			g.state = guardText
+		}
+	case guardOtherDecl:
+		if b == '>' {
+			g.state = guardText
+		}
+	}
+	return nil
+}
+
+// isPrefixOfAny reports whether b is a prefix of one of the candidates
+func isPrefixOfAny(b []byte, candidates ...string) bool {
+	for _, c := range candidates {
+		if len(b) <= len(c) && c[:len(b)] == string(b) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestGuardReaderTracksDepth(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, "")
+
+	for _, file := range files {
+		data := []byte(`<?xml version="1.0"?>
+<!-- <unclosed> comment -->
+<a x="1 > 0" y='/>'><b/><c><![CDATA[<d><e>]]></c><?pi <f>?><g attr="v"/></a>`)
+		if file != "" {
+			if data, err = os.ReadFile(file); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		g := &guardReader{ReadCloser: io.NopCloser(iotest.OneByteReader(strings.NewReader(string(data))))}
+		if _, err := io.ReadAll(g); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if g.depth != 0 || g.state != guardText {
+			t.Errorf("%s: expected depth 0 in text, got depth %d in state %d", file, g.depth, g.state)
+		}
+	}
+}
+
+func TestGuardReaderLimits(t *testing.T) {
+	deep := strings.Repeat("<a>", 20) + strings.Repeat("</a>", 20)
+	tests := []struct {
+		name     string
+		doc      string
+		maxSize  int64
+		maxDepth int
+		want     error
+	}{
+		{"too deep", deep, 0, 10, ErrXMLTooDeep},
+		{"deep enough", deep, 0, 20, nil},
+		{"too large", deep, 100, 0, ErrResponseTooLarge},
+		{"doctype", `<?xml version="1.0"?><!DOCTYPE lolz [<!ENTITY lol "lol">]><lolz>&lol;</lolz>`, 0, 0, ErrDTDNotAllowed},
+		{"entity in comment", `<a><!-- <!DOCTYPE a> --></a>`, 0, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &guardReader{ReadCloser: io.NopCloser(strings.NewReader(tt.doc)), maxSize: tt.maxSize, maxDepth: tt.maxDepth}
+			_, err := io.ReadAll(g)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestClientLimits(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords": "listrecords_dc_page1.xml",
+		"Identify":    "identify_response.xml",
+	})
+	harvest := func(client *OAIClient) error {
+		return client.HarvestWithOptions("oai_dc", nil, func(OAIResponse) error { return ErrStopHarvest })
+	}
+
+	if err := harvest(NewClient(server.URL)); err != nil {
+		t.Fatalf("harvest with default limits failed: %v", err)
+	}
+	if err := harvest(NewClient(server.URL, WithMaxXMLDepth(4))); !errors.Is(err, ErrXMLTooDeep) {
+		t.Errorf("expected ErrXMLTooDeep, got %v", err)
+	}
+	if err := harvest(NewClient(server.URL, WithMaxResponseSize(512))); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge, got %v", err)
+	}
+	if _, err := NewClient(server.URL, WithMaxResponseSize(512)).Identify(context.Background()); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge from Identify, got %v", err)
+	}
+}
+
+func TestClientRejectsDTD(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "listrecords_dc_page1.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := strings.Replace(string(data), "?>", "?>\n<!DOCTYPE OAI-PMH [<!ENTITY xxe SYSTEM \"file:///etc/passwd\">]>", 1)
+	server := serveDocument(t, doc)
+
+	err = NewClient(server.URL).HarvestWithOptions("oai_dc", nil, func(OAIResponse) error { return nil })
+	if !errors.Is(err, ErrDTDNotAllowed) {
+		t.Errorf("expected ErrDTDNotAllowed, got %v", err)
+	}
+}
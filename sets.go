@@ -0,0 +1,165 @@
+package goharvest
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ListSetsResponse represents the OAI-PMH response to the ListSets verb
+type ListSetsResponse struct {
+	XMLName      xml.Name   `xml:"OAI-PMH"`
+	ResponseDate string     `xml:"responseDate"`
+	Request      OAIRequest `xml:"request"`
+	ListSets     *ListSets  `xml:"ListSets,omitempty"`
+	Error        *OAIError  `xml:"error,omitempty"`
+}
+
+// ListSets contains the list of sets from ListSets verb
+type ListSets struct {
+	Sets            []Set            `xml:"set"`
+	ResumptionToken *ResumptionToken `xml:"resumptionToken,omitempty"`
+}
+
+// Set represents a set in the repository's set hierarchy
+type Set struct {
+	SetSpec        string        `xml:"setSpec"`
+	SetName        string        `xml:"setName"`
+	SetDescription []Description `xml:"setDescription,omitempty"`
+}
+
+// SetCallback is the callback function type for streaming sets
+type SetCallback func(set Set) error
+
+// ListSets retrieves all sets of the repository, following resumption tokens
+func (c *OAIClient) ListSets(ctx context.Context) ([]Set, error) {
+	var sets []Set
+
+	err := c.ListSetsFunc(ctx, func(set Set) error {
+		sets = append(sets, set)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
+
+// ListSetsFunc streams all sets of the repository to callback, following resumption tokens.
+// Repositories without set support (noSetHierarchy) yield no sets.
+func (c *OAIClient) ListSetsFunc(ctx context.Context, callback SetCallback) error {
+	resumptionToken := ""
+
+	for {
+		body, err := c.performRequest(ctx, "ListSets", "resumptionToken", resumptionToken)
+		if err != nil {
+			return err
+		}
+
+		var oaiResp ListSetsResponse
+		if err := xml.Unmarshal(body, &oaiResp); err != nil {
+			return fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		if oaiResp.Error != nil {
+			if errors.Is(oaiResp.Error, ErrNoSetHierarchy) {
+				return nil
+			}
+			return oaiResp.Error
+		}
+
+		if oaiResp.ListSets == nil {
+			return nil
+		}
+
+		for _, set := range oaiResp.ListSets.Sets {
+			if err := callback(set); err != nil {
+				return fmt.Errorf("callback error: %w", err)
+			}
+		}
+
+		if oaiResp.ListSets.ResumptionToken == nil || oaiResp.ListSets.ResumptionToken.Token == "" {
+			return nil
+		}
+
+		resumptionToken = oaiResp.ListSets.ResumptionToken.Token
+	}
+}
+
+// harvestSets harvests each of opts.Sets, opts.ParallelSets at a time, and delivers the
+// pages of all of them to callback. The first failing set ends the harvest.
+func (c *OAIClient) harvestSets(metadataPrefix string, opts *HarvestOptions, callback HarvestCallback) error {
+	if opts.Checkpointer != nil {
+		return errors.New("a harvest of several sets cannot be checkpointed")
+	}
+	if opts.resumptionToken != "" {
+		return errors.New("a harvest of several sets cannot start from a resumption token")
+	}
+	if len(opts.Sets) > 1 && opts.run.dedup == nil {
+		opts.run.dedup = newHashFilter()
+	}
+	opts.run.setRecords = make(map[string]int, len(opts.Sets))
+
+	ctx, cancel := context.WithCancel(opts.context())
+	defer cancel()
+
+	slots := make(chan struct{}, max(opts.ParallelSets, 1))
+	var stopped atomic.Bool
+	var errOnce sync.Once
+	var firstErr error
+	var wg sync.WaitGroup
+
+	for _, set := range opts.Sets {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		opts.run.pageMu.Lock()
+		limited := opts.limitReached()
+		opts.run.pageMu.Unlock()
+		if ctx.Err() != nil || stopped.Load() || limited || opts.control.stopped() {
+			break
+		}
+
+		setOpts := *opts
+		setOpts.Set = set
+		setOpts.Sets = nil
+		setOpts.ctx = ctx
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			// Pages are processed one at a time, see processPage
+			err := c.harvestFormat(metadataPrefix, &setOpts, func(response OAIResponse) error {
+				if stopped.Load() || opts.limitReached() {
+					return ErrStopHarvest
+				}
+				opts.run.setRecords[set] += countRecords(response)
+				err := callback(response)
+				if errors.Is(err, ErrStopHarvest) {
+					stopped.Store(true)
+				}
+				return err
+			})
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("set %s: %w", set, err)
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr == nil && !stopped.Load() {
+		// Sets not started because the harvest was cancelled
+		return opts.context().Err()
+	}
+	return firstErr
+}
@@ -0,0 +1,172 @@
+package goharvest
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestListSets(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListSets":             "listsets_page1.xml",
+		"ListSets:sets-page-2": "listsets_page2.xml",
+	})
+	client := NewClient(server.URL)
+
+	sets, err := client.ListSets(context.Background())
+	if err != nil {
+		t.Fatalf("ListSets failed: %v", err)
+	}
+
+	if len(sets) != 3 {
+		t.Fatalf("Expected 3 sets across both pages, got %d", len(sets))
+	}
+	if sets[1].SetSpec != "books:fiction" || sets[1].SetName != "Fiction" {
+		t.Errorf("Unexpected second set: %+v", sets[1])
+	}
+	if len(sets[1].SetDescription) != 1 {
+		t.Errorf("Expected 1 set description, got %d", len(sets[1].SetDescription))
+	}
+	if sets[2].SetSpec != "theses" {
+		t.Errorf("Expected last set 'theses', got '%s'", sets[2].SetSpec)
+	}
+}
+
+func TestListSetsNoSetHierarchy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<OAI-PMH><error code="noSetHierarchy">Sets not supported</error></OAI-PMH>`))
+	}))
+	defer server.Close()
+
+	sets, err := NewClient(server.URL).ListSets(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error for noSetHierarchy, got %v", err)
+	}
+	if len(sets) != 0 {
+		t.Errorf("Expected no sets, got %d", len(sets))
+	}
+}
+
+// multiSetFixtures serves set theses with records 1, 2 (deleted), 4 and 5 over two pages
+// and set articles with record 5 only
+var multiSetFixtures = map[string]string{
+	"ListRecords@theses":    "listrecords_dc_page1.xml",
+	"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	"ListRecords@articles":  "listrecords_dc_page2.xml",
+}
+
+func TestHarvestSets(t *testing.T) {
+	for _, parallel := range []int{0, 2} {
+		t.Run(fmt.Sprintf("parallel=%d", parallel), func(t *testing.T) {
+			server := newFixtureServer(t, multiSetFixtures)
+
+			var stats HarvestStats
+			opts := &HarvestOptions{Sets: []string{"theses", "articles"}, ParallelSets: parallel, Stats: &stats}
+			var identifiers []string
+			err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(record HarvestedRecord) error {
+				identifiers = append(identifiers, record.Header.Identifier)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			slices.Sort(identifiers)
+			if want := []string{"oai:example.org:1", "oai:example.org:2", "oai:example.org:4", "oai:example.org:5"}; !slices.Equal(identifiers, want) {
+				t.Errorf("Expected each record once, got %v", identifiers)
+			}
+			if stats.Records != 5 || stats.DuplicateRecords != 1 || stats.Pages != 3 {
+				t.Errorf("Unexpected stats %+v", stats)
+			}
+			if want := map[string]int{"theses": 4, "articles": 1}; !maps.Equal(stats.SetRecords, want) {
+				t.Errorf("Expected per-set counts %v, got %v", want, stats.SetRecords)
+			}
+
+			sets := map[string]bool{}
+			for _, query := range server.Queries() {
+				sets[query.Get("set")] = true
+			}
+			if !sets["theses"] || !sets["articles"] {
+				t.Errorf("Expected both sets to be requested, got %v", server.Queries())
+			}
+		})
+	}
+}
+
+func TestHarvestSetsFailure(t *testing.T) {
+	server := newFixtureServer(t, multiSetFixtures)
+
+	err := NewClient(server.URL).HarvestRecords("oai_dc", &HarvestOptions{Sets: []string{"missing", "theses"}}, func(HarvestedRecord) error {
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "set missing") {
+		t.Fatalf("Expected the failing set to be reported, got %v", err)
+	}
+	if len(server.Queries()) != 1 {
+		t.Errorf("Expected the harvest to end with the failing set, got %v", server.Queries())
+	}
+
+	opts := &HarvestOptions{Sets: []string{"theses"}, Checkpointer: NewFileCheckpointer(filepath.Join(t.TempDir(), "checkpoint.json"))}
+	if err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(HarvestedRecord) error { return nil }); err == nil {
+		t.Error("Expected checkpointing to be rejected")
+	}
+}
+
+func TestHarvestSetsStop(t *testing.T) {
+	server := newFixtureServer(t, multiSetFixtures)
+
+	var stats HarvestStats
+	opts := &HarvestOptions{Sets: []string{"theses", "articles"}, Stats: &stats}
+	err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(HarvestedRecord) error {
+		return ErrStopHarvest
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Stopped || len(server.Queries()) != 1 {
+		t.Errorf("Expected the harvest to stop after the first page, got %+v and %d requests", stats, len(server.Queries()))
+	}
+}
+
+func TestHarvestSetsLimit(t *testing.T) {
+	server := newFixtureServer(t, multiSetFixtures)
+
+	count := 0
+	opts := &HarvestOptions{Sets: []string{"articles", "theses"}, MaxRecords: 1}
+	err := NewClient(server.URL).HarvestRecords("oai_dc", opts, func(HarvestedRecord) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || len(server.Queries()) != 1 {
+		t.Errorf("Expected the harvest to end with the first set, got %d records and %v", count, server.Queries())
+	}
+}
+
+func TestHarvestParallelSetsStop(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+
+	var stats HarvestStats
+	opts := &HarvestOptions{Sets: []string{"theses", "articles"}, ParallelSets: 2, Stats: &stats}
+	err := NewClient(server.URL).HarvestContext(context.Background(), "oai_dc", opts, func(OAIResponse) error {
+		return ErrStopHarvest
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Neither set's token can resume a harvest of several sets
+	if !stats.Stopped || stats.ResumptionToken != "" {
+		t.Errorf("Expected a stopped harvest without a resumption token, got %+v", stats)
+	}
+}
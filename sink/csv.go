@@ -0,0 +1,195 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jiharal/goharvest"
+)
+
+// CSVColumn selects one column of a CSVWriter
+type CSVColumn struct {
+	// Header is the column name in the header row (defaults to Field)
+	Header string
+	// Field is a dotted path into the JSONL form of the record, e.g. "identifier",
+	// "metadata.title" or "metadata.main_author.name". Arrays along the path are flattened.
+	Field string
+	// Extract computes the values directly and takes precedence over Field
+	Extract func(record goharvest.HarvestedRecord) []string
+}
+
+// DefaultCSVColumns are used when CSVOptions.Columns is empty
+var DefaultCSVColumns = []CSVColumn{
+	{Field: "identifier"},
+	{Field: "datestamp"},
+	{Field: "deleted"},
+	{Field: "metadata.title"},
+}
+
+// CSVOptions configures a CSVWriter
+type CSVOptions struct {
+	// Columns are the columns to write (DefaultCSVColumns when empty)
+	Columns []CSVColumn
+	// Comma is the field separator; use '\t' for TSV (default ',')
+	Comma rune
+	// Join separates the values of repeated fields within a cell (default "; ")
+	Join string
+	// NoHeader omits the header row
+	NoHeader bool
+}
+
+// CSVWriter writes harvested records as CSV or TSV rows
+type CSVWriter struct {
+	mu            sync.Mutex
+	opts          CSVOptions
+	csv           *csv.Writer
+	headerWritten bool
+}
+
+// NewCSVWriter returns a writer for CSV rows on w
+func NewCSVWriter(w io.Writer, opts CSVOptions) *CSVWriter {
+	if len(opts.Columns) == 0 {
+		opts.Columns = DefaultCSVColumns
+	}
+	if opts.Join == "" {
+		opts.Join = "; "
+	}
+
+	writer := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		writer.Comma = opts.Comma
+	}
+
+	return &CSVWriter{opts: opts, csv: writer, headerWritten: opts.NoHeader}
+}
+
+// Write writes one record as a row
+func (w *CSVWriter) Write(record goharvest.HarvestedRecord) error {
+	var doc interface{}
+	if w.needsDocument() {
+		var err error
+		if doc, err = jsonDocument(NewJSONLRecord(record, false)); err != nil {
+			return fmt.Errorf("failed to encode record %s: %w", record.Header.Identifier, err)
+		}
+	}
+
+	row := make([]string, len(w.opts.Columns))
+	for i, column := range w.opts.Columns {
+		var values []string
+		if column.Extract != nil {
+			values = column.Extract(record)
+		} else {
+			values = lookupPath(doc, strings.Split(column.Field, "."))
+		}
+		row[i] = strings.Join(values, w.opts.Join)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.headerWritten {
+		header := make([]string, len(w.opts.Columns))
+		for i, column := range w.opts.Columns {
+			header[i] = column.Header
+			if header[i] == "" {
+				header[i] = column.Field
+			}
+		}
+		if err := w.csv.Write(header); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	return w.csv.Write(row)
+}
+
+// Delete writes a deleted row for identifier
+func (w *CSVWriter) Delete(identifier string) error {
+	return w.Write(Tombstone(identifier))
+}
+
+// Callback returns a goharvest.RecordCallback writing each record
+func (w *CSVWriter) Callback() goharvest.RecordCallback {
+	return w.Write
+}
+
+// Flush writes buffered rows to the underlying writer
+func (w *CSVWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// Close flushes the writer; the underlying io.Writer is left open
+func (w *CSVWriter) Close() error {
+	return w.Flush()
+}
+
+// needsDocument reports whether any column reads a field path
+func (w *CSVWriter) needsDocument() bool {
+	for _, column := range w.opts.Columns {
+		if column.Extract == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonDocument converts v into generic JSON values so fields can be looked up by name
+func jsonDocument(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	err = decoder.Decode(&doc)
+	return doc, err
+}
+
+// lookupPath returns the scalar values at path, flattening arrays
+func lookupPath(value interface{}, path []string) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, lookupPath(item, path)...)
+		}
+		return values
+	case map[string]interface{}:
+		if len(path) == 0 {
+			data, _ := json.Marshal(v)
+			return []string{string(data)}
+		}
+		return lookupPath(v[path[0]], path[1:])
+	}
+
+	if len(path) > 0 {
+		return nil
+	}
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	case json.Number:
+		return []string{v.String()}
+	}
+	return []string{fmt.Sprint(value)}
+}
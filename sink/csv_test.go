@@ -0,0 +1,96 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jiharal/goharvest"
+)
+
+func TestCSVWriterDefaultColumns(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, CSVOptions{})
+	for _, record := range testRecords() {
+		if err := w.Write(record); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	want := [][]string{
+		{"identifier", "datestamp", "deleted", "metadata.title"},
+		{"oai:example.org:1", "2024-01-01", "", "Bumi manusia"},
+		{"oai:example.org:2", "2024-01-02", "", ""},
+		{"oai:example.org:3", "2024-01-03", "true", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Rows = %q, want %q", rows, want)
+	}
+}
+
+func TestCSVWriterColumnsAndTSV(t *testing.T) {
+	record := goharvest.HarvestedRecord{
+		Header: goharvest.Header{Identifier: "oai:example.org:10"},
+		Metadata: &goharvest.DublinCore{
+			Title:   []string{"Sejarah \"Jawa\", jilid 1"},
+			Creator: []string{"Sari, Dewi", "Putra, Adi"},
+		},
+	}
+
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, CSVOptions{
+		Comma: '\t',
+		Join:  " | ",
+		Columns: []CSVColumn{
+			{Header: "id", Field: "identifier"},
+			{Header: "title", Field: "metadata.title"},
+			{Header: "creators", Field: "metadata.creator"},
+			{Header: "sets", Extract: func(r goharvest.HarvestedRecord) []string { return []string{"n/a"} }},
+		},
+	})
+	if err := w.Write(record); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	w.Flush()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "id\ttitle\tcreators\tsets" {
+		t.Errorf("Unexpected header %q", lines[0])
+	}
+	if lines[1] != "oai:example.org:10\t\"Sejarah \"\"Jawa\"\", jilid 1\"\tSari, Dewi | Putra, Adi\tn/a" {
+		t.Errorf("Unexpected row %q", lines[1])
+	}
+}
+
+func TestCSVWriterNestedFieldsWithoutHeader(t *testing.T) {
+	marc := &goharvest.MARCRecord{
+		DataFields: []goharvest.DataField{
+			{Tag: "100", Ind1: "1", Subfields: []goharvest.Subfield{{Code: "a", Value: "Toer, Pramoedya Ananta"}}},
+			{Tag: "650", Ind2: "0", Subfields: []goharvest.Subfield{{Code: "a", Value: "Fiksi"}}},
+			{Tag: "650", Ind2: "0", Subfields: []goharvest.Subfield{{Code: "a", Value: "Sejarah"}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, CSVOptions{
+		NoHeader: true,
+		Columns:  []CSVColumn{{Field: "metadata.main_author.name"}, {Field: "metadata.subjects.heading"}},
+	})
+	if err := w.Write(goharvest.HarvestedRecord{Metadata: marc}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	w.Close()
+
+	if got := buf.String(); got != "\"Toer, Pramoedya Ananta\",Fiksi; Sejarah\n" {
+		t.Errorf("Unexpected output %q", got)
+	}
+}
@@ -0,0 +1,224 @@
+// Package sink writes harvested records to files, databases and message brokers.
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jiharal/goharvest"
+)
+
+// JSONLRecord is the line written for each harvested record
+type JSONLRecord struct {
+	Identifier string                   `json:"identifier"`
+	Datestamp  string                   `json:"datestamp"`
+	SetSpecs   []string                 `json:"set_specs,omitempty"`
+	Deleted    bool                     `json:"deleted,omitempty"`
+	Format     goharvest.MetadataFormat `json:"format,omitempty"`
+	Metadata   interface{}              `json:"metadata,omitempty"`
+	Raw        string                   `json:"raw,omitempty"`
+}
+
+// NewJSONLRecord converts a harvested record to its JSONL form. The extracted metadata
+// (e.g. *goharvest.BookMetadata, *goharvest.DCMetadata) is used, except for raw harvests
+// whose XML always goes to Raw; includeRaw also keeps the raw XML of typed records.
+func NewJSONLRecord(record goharvest.HarvestedRecord, includeRaw bool) JSONLRecord {
+	line := JSONLRecord{
+		Identifier: record.Header.Identifier,
+		Datestamp:  record.Header.DateStamp,
+		SetSpecs:   record.Header.SetSpec,
+		Deleted:    record.IsDeleted(),
+	}
+
+	if record.Metadata != nil {
+		line.Format = record.Metadata.GetFormat()
+		if raw, ok := record.Metadata.(*goharvest.RawMetadata); ok {
+			line.Raw = string(raw.XML)
+		} else {
+			line.Metadata = record.Metadata.ExtractMetadata()
+		}
+	}
+	if includeRaw && line.Raw == "" {
+		line.Raw = string(record.Raw)
+	}
+
+	return line
+}
+
+// JSONLOptions configures a file-backed JSONLWriter
+type JSONLOptions struct {
+	// IncludeRaw keeps the raw metadata XML of typed records in the "raw" property
+	IncludeRaw bool
+	// MaxBytes starts a new file once the current one reaches this size (0 = no limit)
+	MaxBytes int64
+	// MaxRecords starts a new file after this many records (0 = no limit)
+	MaxRecords int
+}
+
+// JSONLWriter streams harvested records as JSON lines, optionally rotating files
+type JSONLWriter struct {
+	mu      sync.Mutex
+	opts    JSONLOptions
+	pattern string
+	files   []string
+
+	out     io.Writer
+	closer  io.Closer
+	buf     *bufio.Writer
+	bytes   int64
+	records int
+}
+
+// NewJSONLWriter returns a writer streaming JSON lines to w, without rotation
+func NewJSONLWriter(w io.Writer, opts JSONLOptions) *JSONLWriter {
+	opts.MaxBytes, opts.MaxRecords = 0, 0
+	return &JSONLWriter{opts: opts, out: w, buf: bufio.NewWriter(w)}
+}
+
+// NewJSONLFileWriter returns a writer streaming JSON lines to files. With rotation enabled,
+// path is a pattern with one integer verb ("harvest-%04d.jsonl"); when it has none, "-%04d"
+// is inserted before the extension. Without rotation path is used as is.
+func NewJSONLFileWriter(path string, opts JSONLOptions) (*JSONLWriter, error) {
+	w := &JSONLWriter{opts: opts, pattern: path}
+	if w.rotating() && !strings.Contains(path, "%") {
+		ext := filepath.Ext(path)
+		w.pattern = strings.TrimSuffix(path, ext) + "-%04d" + ext
+	}
+
+	if err := w.openNext(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes one record as a JSON line
+func (w *JSONLWriter) Write(record goharvest.HarvestedRecord) error {
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(NewJSONLRecord(record, w.opts.IncludeRaw)); err != nil {
+		return fmt.Errorf("failed to encode record %s: %w", record.Header.Identifier, err)
+	}
+	data := line.Bytes()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf == nil {
+		return fmt.Errorf("jsonl writer is closed")
+	}
+	if w.shouldRotate(int64(len(data))) {
+		if err := w.closeCurrent(); err != nil {
+			return err
+		}
+		if err := w.openNext(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.buf.Write(data)
+	w.bytes += int64(n)
+	w.records++
+	return err
+}
+
+// Delete writes a deleted line for identifier
+func (w *JSONLWriter) Delete(identifier string) error {
+	return w.Write(Tombstone(identifier))
+}
+
+// Callback returns a goharvest.RecordCallback writing each record
+func (w *JSONLWriter) Callback() goharvest.RecordCallback {
+	return w.Write
+}
+
+// Flush writes buffered lines to the underlying writer
+func (w *JSONLWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf == nil {
+		return nil
+	}
+	return w.buf.Flush()
+}
+
+// Close flushes and closes the current file. Writers created with NewJSONLWriter
+// only flush, leaving the io.Writer open.
+func (w *JSONLWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf == nil {
+		return nil
+	}
+	err := w.closeCurrent()
+	w.buf = nil
+	return err
+}
+
+// Files returns the paths of the files written so far
+func (w *JSONLWriter) Files() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]string(nil), w.files...)
+}
+
+// rotating reports whether file rotation is enabled
+func (w *JSONLWriter) rotating() bool {
+	return w.opts.MaxBytes > 0 || w.opts.MaxRecords > 0
+}
+
+// shouldRotate reports whether a line of size n must go to a new file
+func (w *JSONLWriter) shouldRotate(n int64) bool {
+	if w.pattern == "" || w.records == 0 {
+		return false
+	}
+	if w.opts.MaxRecords > 0 && w.records >= w.opts.MaxRecords {
+		return true
+	}
+	return w.opts.MaxBytes > 0 && w.bytes+n > w.opts.MaxBytes
+}
+
+// openNext opens the next file of a file-backed writer
+func (w *JSONLWriter) openNext() error {
+	path := w.pattern
+	if w.rotating() {
+		path = fmt.Sprintf(w.pattern, len(w.files)+1)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	w.files = append(w.files, path)
+	w.out, w.closer = f, f
+	w.buf = bufio.NewWriter(f)
+	w.bytes, w.records = 0, 0
+	return nil
+}
+
+// closeCurrent flushes the buffer and closes the current file, if any
+func (w *JSONLWriter) closeCurrent() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if w.closer != nil {
+		return w.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jiharal/goharvest"
+)
+
+func testRecords() []goharvest.HarvestedRecord {
+	return []goharvest.HarvestedRecord{
+		{
+			Header:   goharvest.Header{Identifier: "oai:example.org:1", DateStamp: "2024-01-01", SetSpec: []string{"buku"}},
+			Metadata: &goharvest.DublinCore{Title: []string{"Bumi manusia"}},
+			Raw:      []byte("<oai_dc:dc>...</oai_dc:dc>"),
+		},
+		{
+			Header:   goharvest.Header{Identifier: "oai:example.org:2", DateStamp: "2024-01-02"},
+			Metadata: &goharvest.RawMetadata{Format: "oai_ead", XML: []byte("<ead/>")},
+		},
+		{
+			Header: goharvest.Header{Identifier: "oai:example.org:3", DateStamp: "2024-01-03", Status: goharvest.HeaderStatusDeleted},
+		},
+	}
+}
+
+func TestJSONLWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONLWriter(&buf, JSONLOptions{})
+	for _, record := range testRecords() {
+		if err := w.Write(record); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var lines []JSONLRecord
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line JSONLRecord
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Invalid JSON line %s: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	if lines[0].Format != goharvest.FormatOAIDC || lines[0].Metadata == nil || lines[0].Raw != "" || lines[0].SetSpecs[0] != "buku" {
+		t.Errorf("Unexpected DC line %+v", lines[0])
+	}
+	if lines[1].Raw != "<ead/>" || lines[1].Metadata != nil {
+		t.Errorf("Unexpected raw line %+v", lines[1])
+	}
+	if !lines[2].Deleted || lines[2].Metadata != nil {
+		t.Errorf("Unexpected deleted line %+v", lines[2])
+	}
+	if err := w.Write(testRecords()[0]); err == nil {
+		t.Error("Expected error writing to a closed writer")
+	}
+}
+
+func TestJSONLFileWriterRotation(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewJSONLFileWriter(filepath.Join(dir, "out", "harvest.jsonl"), JSONLOptions{MaxRecords: 2, IncludeRaw: true})
+	if err != nil {
+		t.Fatalf("NewJSONLFileWriter failed: %v", err)
+	}
+
+	callback := w.Callback()
+	for i := 0; i < 5; i++ {
+		if err := callback(testRecords()[0]); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	files := w.Files()
+	if len(files) != 3 || filepath.Base(files[0]) != "harvest-0001.jsonl" || filepath.Base(files[2]) != "harvest-0003.jsonl" {
+		t.Fatalf("Unexpected files %v", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if bytes.Count(data, []byte("\n")) != 2 || !bytes.Contains(data, []byte(`"raw":"<oai_dc:dc>...`)) {
+		t.Errorf("Unexpected first file %s", data)
+	}
+}
+
+func TestJSONLFileWriterMaxBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "part-%d.jsonl")
+	w, err := NewJSONLFileWriter(path, JSONLOptions{MaxBytes: 10})
+	if err != nil {
+		t.Fatalf("NewJSONLFileWriter failed: %v", err)
+	}
+	for _, record := range testRecords() {
+		if err := w.Write(record); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	w.Close()
+
+	// every line exceeds MaxBytes, so each gets its own file
+	if files := w.Files(); len(files) != 3 || filepath.Base(files[1]) != "part-2.jsonl" {
+		t.Errorf("Unexpected files %v", files)
+	}
+}
@@ -0,0 +1,219 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jiharal/goharvest"
+)
+
+// Message is one record published to a message broker
+type Message struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// Publisher sends messages to a broker. Adapt a Kafka writer (e.g. segmentio/kafka-go,
+// franz-go) or a NATS connection to it; if the publisher also has a Flush() error or
+// Close() error method, MessageSink calls it from Flush and Close.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// PublisherFunc adapts a function to the Publisher interface
+type PublisherFunc func(ctx context.Context, msg Message) error
+
+// Publish calls f(ctx, msg)
+func (f PublisherFunc) Publish(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+// PayloadEncoder turns a record into a message value
+type PayloadEncoder interface {
+	ContentType() string
+	Encode(record JSONLRecord) ([]byte, error)
+}
+
+// JSONPayload encodes records exactly like JSONLWriter lines
+type JSONPayload struct{}
+
+// ContentType returns "application/json"
+func (JSONPayload) ContentType() string { return "application/json" }
+
+// Encode marshals the record as JSON
+func (JSONPayload) Encode(record JSONLRecord) ([]byte, error) {
+	return json.Marshal(record)
+}
+
+// RecordAvroSchema is the Avro schema matching FixedSchemaPayload, for registering the
+// topic in a schema registry and converting payloads with an Avro codec
+const RecordAvroSchema = `{
+  "type": "record",
+  "name": "HarvestedRecord",
+  "namespace": "org.openarchives.oai",
+  "fields": [
+    {"name": "identifier", "type": "string"},
+    {"name": "datestamp", "type": "string"},
+    {"name": "set_specs", "type": {"type": "array", "items": "string"}},
+    {"name": "deleted", "type": "boolean"},
+    {"name": "format", "type": "string"},
+    {"name": "metadata", "type": ["null", "string"], "default": null},
+    {"name": "raw", "type": ["null", "string"], "default": null}
+  ]
+}`
+
+// FixedSchemaPayload encodes records with every field present and the metadata as a JSON
+// string, so each payload has the same shape and maps directly onto RecordAvroSchema
+type FixedSchemaPayload struct{}
+
+// fixedSchemaRecord is the FixedSchemaPayload form of a record
+type fixedSchemaRecord struct {
+	Identifier string   `json:"identifier"`
+	Datestamp  string   `json:"datestamp"`
+	SetSpecs   []string `json:"set_specs"`
+	Deleted    bool     `json:"deleted"`
+	Format     string   `json:"format"`
+	Metadata   *string  `json:"metadata"`
+	Raw        *string  `json:"raw"`
+}
+
+// ContentType returns "application/json"
+func (FixedSchemaPayload) ContentType() string { return "application/json" }
+
+// Encode marshals the record in the fixed schema
+func (FixedSchemaPayload) Encode(record JSONLRecord) ([]byte, error) {
+	out := fixedSchemaRecord{
+		Identifier: record.Identifier,
+		Datestamp:  record.Datestamp,
+		SetSpecs:   append([]string{}, record.SetSpecs...),
+		Deleted:    record.Deleted,
+		Format:     string(record.Format),
+	}
+	if record.Metadata != nil {
+		metadata, err := json.Marshal(record.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		s := string(metadata)
+		out.Metadata = &s
+	}
+	if record.Raw != "" {
+		out.Raw = &record.Raw
+	}
+	return json.Marshal(out)
+}
+
+// MessageOptions configures a MessageSink
+type MessageOptions struct {
+	// Topic is the Kafka topic or NATS subject (default "oai.records")
+	Topic string
+	// TopicFunc picks the topic per record, e.g. by set; an empty result falls back to Topic
+	TopicFunc func(record goharvest.HarvestedRecord) string
+	// Encoder builds message values (default JSONPayload)
+	Encoder PayloadEncoder
+	// IncludeRaw also sends the raw metadata XML of typed records
+	IncludeRaw bool
+	// NullTombstones publishes deleted records with a nil value, as Kafka log compaction expects
+	NullTombstones bool
+}
+
+// MessageSink publishes each harvested record as a message keyed by OAI identifier.
+// Deleted records are published too, flagged in the payload and the "oai-deleted" header.
+type MessageSink struct {
+	pub  Publisher
+	opts MessageOptions
+}
+
+// NewMessageSink returns a sink publishing through pub
+func NewMessageSink(pub Publisher, opts MessageOptions) *MessageSink {
+	if opts.Topic == "" {
+		opts.Topic = "oai.records"
+	}
+	if opts.Encoder == nil {
+		opts.Encoder = JSONPayload{}
+	}
+	return &MessageSink{pub: pub, opts: opts}
+}
+
+// Write publishes one record
+func (s *MessageSink) Write(record goharvest.HarvestedRecord) error {
+	msg, err := s.message(record)
+	if err != nil {
+		return err
+	}
+	if err := s.pub.Publish(context.Background(), msg); err != nil {
+		return fmt.Errorf("failed to publish record %s: %w", record.Header.Identifier, err)
+	}
+	return nil
+}
+
+// Delete publishes a tombstone for identifier
+func (s *MessageSink) Delete(identifier string) error {
+	return s.Write(Tombstone(identifier))
+}
+
+// Callback returns a goharvest.RecordCallback publishing each record
+func (s *MessageSink) Callback() goharvest.RecordCallback {
+	return s.Write
+}
+
+// Flush flushes the publisher if it buffers messages
+func (s *MessageSink) Flush() error {
+	if f, ok := s.pub.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close flushes and closes the publisher if it supports it
+func (s *MessageSink) Close() error {
+	if err := s.Flush(); err != nil {
+		return err
+	}
+	if c, ok := s.pub.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// message builds the message for a record
+func (s *MessageSink) message(record goharvest.HarvestedRecord) (Message, error) {
+	line := NewJSONLRecord(record, s.opts.IncludeRaw)
+
+	topic := ""
+	if s.opts.TopicFunc != nil {
+		topic = s.opts.TopicFunc(record)
+	}
+	if topic == "" {
+		topic = s.opts.Topic
+	}
+
+	msg := Message{
+		Topic: topic,
+		Key:   []byte(line.Identifier),
+		Headers: map[string]string{
+			"content-type":  s.opts.Encoder.ContentType(),
+			"oai-datestamp": line.Datestamp,
+		},
+	}
+	if line.Format != "" {
+		msg.Headers["oai-format"] = string(line.Format)
+	}
+	if line.Deleted {
+		msg.Headers["oai-deleted"] = "true"
+		if s.opts.NullTombstones {
+			return msg, nil
+		}
+	}
+
+	value, err := s.opts.Encoder.Encode(line)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to encode record %s: %w", line.Identifier, err)
+	}
+	msg.Value = value
+	return msg, nil
+}
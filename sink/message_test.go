@@ -0,0 +1,110 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jiharal/goharvest"
+)
+
+type recordingPublisher struct {
+	messages []Message
+	flushed  int
+	closed   bool
+	err      error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, msg Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *recordingPublisher) Flush() error { p.flushed++; return nil }
+func (p *recordingPublisher) Close() error { p.closed = true; return nil }
+
+func TestMessageSink(t *testing.T) {
+	pub := &recordingPublisher{}
+	s := NewMessageSink(pub, MessageOptions{
+		TopicFunc: func(record goharvest.HarvestedRecord) string {
+			if len(record.Header.SetSpec) > 0 {
+				return "oai." + record.Header.SetSpec[0]
+			}
+			return ""
+		},
+		NullTombstones: true,
+	})
+	for _, record := range testRecords() {
+		if err := s.Write(record); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if pub.flushed != 1 || !pub.closed {
+		t.Errorf("Expected publisher to be flushed and closed, got %d/%v", pub.flushed, pub.closed)
+	}
+
+	if len(pub.messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(pub.messages))
+	}
+	first := pub.messages[0]
+	if first.Topic != "oai.buku" || string(first.Key) != "oai:example.org:1" || first.Headers["content-type"] != "application/json" {
+		t.Errorf("Unexpected first message %+v", first)
+	}
+	var line JSONLRecord
+	if err := json.Unmarshal(first.Value, &line); err != nil || line.Identifier != "oai:example.org:1" {
+		t.Errorf("Unexpected payload %s (%v)", first.Value, err)
+	}
+	if pub.messages[1].Topic != "oai.records" || pub.messages[1].Headers["oai-format"] != "oai_ead" {
+		t.Errorf("Unexpected second message %+v", pub.messages[1])
+	}
+	tombstone := pub.messages[2]
+	if tombstone.Value != nil || tombstone.Headers["oai-deleted"] != "true" {
+		t.Errorf("Expected null tombstone, got %+v", tombstone)
+	}
+}
+
+func TestMessageSinkFixedSchemaPayload(t *testing.T) {
+	var got []Message
+	s := NewMessageSink(PublisherFunc(func(_ context.Context, msg Message) error {
+		got = append(got, msg)
+		return nil
+	}), MessageOptions{Topic: "harvest", Encoder: FixedSchemaPayload{}})
+
+	for _, record := range testRecords() {
+		if err := s.Write(record); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(got[0].Value, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 7 || !strings.Contains(fields["metadata"].(string), "Bumi manusia") || fields["raw"] != nil {
+		t.Errorf("Unexpected fixed schema payload %v", fields)
+	}
+	if !strings.Contains(string(got[2].Value), `"deleted":true`) || !strings.Contains(string(got[2].Value), `"set_specs":[]`) {
+		t.Errorf("Unexpected tombstone payload %s", got[2].Value)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(RecordAvroSchema), &schema); err != nil || len(schema["fields"].([]interface{})) != 7 {
+		t.Errorf("RecordAvroSchema does not match the payload: %v", err)
+	}
+}
+
+func TestMessageSinkPublishError(t *testing.T) {
+	s := NewMessageSink(&recordingPublisher{err: errors.New("broker down")}, MessageOptions{})
+	err := s.Write(testRecords()[0])
+	if err == nil || !strings.Contains(err.Error(), "oai:example.org:1") {
+		t.Errorf("Expected wrapped publish error, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jiharal/goharvest"
+)
+
+// postgresTablePattern allows an optionally schema-qualified table name
+var postgresTablePattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*\.)?[A-Za-z_][A-Za-z0-9_]*$`)
+
+// postgresColumns are the columns written per record
+const postgresColumns = 8
+
+// maxPostgresBatch keeps a batch within PostgreSQL's 65535 bind parameter limit
+const maxPostgresBatch = 65535 / postgresColumns
+
+// PostgresOptions configures a PostgresSink
+type PostgresOptions struct {
+	// Table is the records table, optionally schema-qualified (default "oai_records")
+	Table string
+	// BatchSize is the number of records per INSERT (default 500, at most 8191)
+	BatchSize int
+	// IncludeRaw also stores the raw metadata XML of typed records
+	IncludeRaw bool
+}
+
+// PostgresSink mirrors harvested records into PostgreSQL through database/sql with any
+// PostgreSQL driver (e.g. github.com/jackc/pgx/v5/stdlib or github.com/lib/pq).
+// Records are buffered and upserted in batches by OAI identifier, metadata is stored as
+// JSONB and deleted records are kept as tombstones. Call Flush or Close after the harvest.
+type PostgresSink struct {
+	db   *sql.DB
+	opts PostgresOptions
+
+	mu      sync.Mutex
+	pending []recordRow
+}
+
+// NewPostgresSink creates the records table if needed and returns the sink
+func NewPostgresSink(ctx context.Context, db *sql.DB, opts PostgresOptions) (*PostgresSink, error) {
+	if opts.Table == "" {
+		opts.Table = "oai_records"
+	}
+	if !postgresTablePattern.MatchString(opts.Table) {
+		return nil, fmt.Errorf("invalid table name %q", opts.Table)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.BatchSize > maxPostgresBatch {
+		opts.BatchSize = maxPostgresBatch
+	}
+
+	indexName := strings.ReplaceAll(opts.Table, ".", "_") + "_datestamp"
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	identifier TEXT PRIMARY KEY,
+	datestamp TEXT NOT NULL,
+	set_specs JSONB NOT NULL DEFAULT '[]',
+	format TEXT NOT NULL DEFAULT '',
+	deleted BOOLEAN NOT NULL DEFAULT FALSE,
+	metadata JSONB,
+	raw TEXT,
+	harvested_at TIMESTAMPTZ NOT NULL
+)`, opts.Table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (datestamp)`, indexName, opts.Table),
+	}
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return nil, fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+
+	return &PostgresSink{db: db, opts: opts}, nil
+}
+
+// Write buffers one record, flushing when the batch is full
+func (s *PostgresSink) Write(record goharvest.HarvestedRecord) error {
+	row, err := newRecordRow(record, s.opts.IncludeRaw)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, row)
+	if len(s.pending) >= s.opts.BatchSize {
+		return s.flushLocked(context.Background())
+	}
+	return nil
+}
+
+// Delete buffers a tombstone for identifier, like Write
+func (s *PostgresSink) Delete(identifier string) error {
+	return s.Write(Tombstone(identifier))
+}
+
+// Callback returns a goharvest.RecordCallback writing each record
+func (s *PostgresSink) Callback() goharvest.RecordCallback {
+	return s.Write
+}
+
+// Flush upserts the buffered records in one transaction
+func (s *PostgresSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.flushLocked(context.Background())
+}
+
+// Close flushes the buffered records; the caller owns the *sql.DB
+func (s *PostgresSink) Close() error {
+	return s.Flush()
+}
+
+// flushLocked writes the pending batch; s.mu must be held
+func (s *PostgresSink) flushLocked(ctx context.Context) error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	rows := latestRows(s.pending)
+	query, args := s.upsertStatement(rows)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin batch: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to store batch of %d records: %w", len(rows), err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+
+	s.pending = s.pending[:0]
+	return nil
+}
+
+// upsertStatement builds a multi-row INSERT ... ON CONFLICT for rows
+func (s *PostgresSink) upsertStatement(rows []recordRow) (string, []interface{}) {
+	var query strings.Builder
+	args := make([]interface{}, 0, len(rows)*postgresColumns)
+
+	fmt.Fprintf(&query, "INSERT INTO %s (identifier, datestamp, set_specs, format, deleted, metadata, raw, harvested_at) VALUES ", s.opts.Table)
+	for i, row := range rows {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		n := i * postgresColumns
+		fmt.Fprintf(&query, "($%d, $%d, $%d::jsonb, $%d, $%d, $%d::jsonb, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8)
+		args = append(args, row.Identifier, row.Datestamp, row.SetSpecs, row.Format, row.Deleted, row.Metadata, row.Raw, row.HarvestedAt)
+	}
+	fmt.Fprintf(&query, ` ON CONFLICT (identifier) DO UPDATE SET
+	datestamp = EXCLUDED.datestamp,
+	set_specs = EXCLUDED.set_specs,
+	format = EXCLUDED.format,
+	deleted = EXCLUDED.deleted,
+	metadata = EXCLUDED.metadata,
+	raw = EXCLUDED.raw,
+	harvested_at = EXCLUDED.harvested_at
+WHERE %s.datestamp <= EXCLUDED.datestamp`, s.opts.Table)
+
+	return query.String(), args
+}
+
+// latestRows keeps one row per identifier, since PostgreSQL rejects an INSERT ... ON
+// CONFLICT that touches the same row twice. Like the upsert, it keeps the row with the
+// latest datestamp, and of equal datestamps the last one written.
+func latestRows(rows []recordRow) []recordRow {
+	index := make(map[string]int, len(rows))
+	unique := make([]recordRow, 0, len(rows))
+	for _, row := range rows {
+		if i, ok := index[row.Identifier]; ok {
+			if unique[i].Datestamp <= row.Datestamp {
+				unique[i] = row
+			}
+			continue
+		}
+		index[row.Identifier] = len(unique)
+		unique = append(unique, row)
+	}
+	return unique
+}
@@ -0,0 +1,100 @@
+package sink
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jiharal/goharvest"
+)
+
+func TestPostgresSinkBatches(t *testing.T) {
+	db, driver := openRecordingDB()
+	defer db.Close()
+
+	s, err := NewPostgresSink(context.Background(), db, PostgresOptions{Table: "harvest.records", BatchSize: 2})
+	if err != nil {
+		t.Fatalf("NewPostgresSink failed: %v", err)
+	}
+
+	records := testRecords()
+	for _, record := range records {
+		if err := s.Write(record); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if got := len(driver.Statements()); got != 3 {
+		t.Fatalf("Expected schema statements and one full batch, got %d statements", got)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	statements := driver.Statements()
+	if len(statements) != 4 || driver.commits != 2 {
+		t.Fatalf("Expected 2 batches, got %d statements and %d commits", len(statements), driver.commits)
+	}
+	if !strings.Contains(statements[0].Query, "metadata JSONB") || !strings.Contains(statements[1].Query, "harvest_records_datestamp ON harvest.records") {
+		t.Errorf("Unexpected schema %q / %q", statements[0].Query, statements[1].Query)
+	}
+
+	batch := statements[2]
+	if !strings.Contains(batch.Query, "($1, $2, $3::jsonb, $4, $5, $6::jsonb, $7, $8), ($9, $10,") {
+		t.Errorf("Unexpected placeholders in %q", batch.Query)
+	}
+	if !strings.Contains(batch.Query, "WHERE harvest.records.datestamp <= EXCLUDED.datestamp") {
+		t.Errorf("Unexpected conflict clause in %q", batch.Query)
+	}
+	if len(batch.Args) != 16 || batch.Args[0] != "oai:example.org:1" || batch.Args[4] != false {
+		t.Errorf("Unexpected batch args %v", batch.Args)
+	}
+
+	tombstone := statements[3].Args
+	if len(tombstone) != 8 || tombstone[4] != true || tombstone[5] != nil {
+		t.Errorf("Unexpected tombstone args %v", tombstone)
+	}
+}
+
+func TestPostgresSinkDeduplicatesBatch(t *testing.T) {
+	db, driver := openRecordingDB()
+	defer db.Close()
+
+	s, err := NewPostgresSink(context.Background(), db, PostgresOptions{})
+	if err != nil {
+		t.Fatalf("NewPostgresSink failed: %v", err)
+	}
+
+	first := goharvest.HarvestedRecord{Header: goharvest.Header{Identifier: "oai:x:1", DateStamp: "2024-01-01"}}
+	second := goharvest.HarvestedRecord{Header: goharvest.Header{Identifier: "oai:x:1", DateStamp: "2024-02-01"}}
+	s.Write(first)
+	s.Write(second)
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	batch := driver.Statements()[2]
+	if len(batch.Args) != 8 || batch.Args[1] != "2024-02-01" {
+		t.Errorf("Expected one deduplicated row with the later datestamp, got %v", batch.Args)
+	}
+
+	// An older datestamp written later does not replace the newer row
+	s.Write(second)
+	s.Write(first)
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	statements := driver.Statements()
+	batch = statements[len(statements)-1]
+	if len(batch.Args) != 8 || batch.Args[1] != "2024-02-01" {
+		t.Errorf("Expected the row with the later datestamp, got %v", batch.Args)
+	}
+
+	driver.failOn = "INSERT"
+	s.Write(first)
+	if err := s.Flush(); err == nil || driver.rollbacks != 1 {
+		t.Errorf("Expected failed batch to roll back, got %v (%d rollbacks)", err, driver.rollbacks)
+	}
+	if _, err := NewPostgresSink(context.Background(), db, PostgresOptions{Table: "bad-name"}); err == nil {
+		t.Error("Expected invalid table name error")
+	}
+}
@@ -0,0 +1,103 @@
+package sink
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jiharal/goharvest"
+)
+
+// Sink receives the per-record harvest stream. Every sink in this package implements it.
+type Sink interface {
+	// Write stores one harvested record, including deleted records
+	Write(record goharvest.HarvestedRecord) error
+	// Delete records that the record with this OAI identifier was removed
+	Delete(identifier string) error
+	// Flush writes any buffered records
+	Flush() error
+	// Close flushes and releases the sink
+	Close() error
+}
+
+var (
+	_ Sink = (*JSONLWriter)(nil)
+	_ Sink = (*CSVWriter)(nil)
+	_ Sink = (*SQLiteSink)(nil)
+	_ Sink = (*PostgresSink)(nil)
+	_ Sink = (*MessageSink)(nil)
+	_ Sink = (*MultiSink)(nil)
+)
+
+// Callback returns a goharvest.RecordCallback writing each record to s
+func Callback(s Sink) goharvest.RecordCallback {
+	return s.Write
+}
+
+// Tombstone returns a deleted record for identifier, datestamped now, as the sinks write
+// it for Delete
+func Tombstone(identifier string) goharvest.HarvestedRecord {
+	return goharvest.HarvestedRecord{
+		Header: goharvest.Header{
+			Identifier: identifier,
+			DateStamp:  time.Now().UTC().Format(time.RFC3339),
+			Status:     goharvest.HeaderStatusDeleted,
+		},
+	}
+}
+
+// MultiSink fans the harvest out to several sinks concurrently, e.g. a JSONL archive and
+// a database mirror. Each call waits for every sink and joins their errors.
+type MultiSink struct {
+	sinks []Sink
+}
+
+// NewMultiSink returns a sink writing to all of sinks
+func NewMultiSink(sinks ...Sink) *MultiSink {
+	return &MultiSink{sinks: sinks}
+}
+
+// Write writes the record to every sink
+func (m *MultiSink) Write(record goharvest.HarvestedRecord) error {
+	return m.each(func(s Sink) error { return s.Write(record) })
+}
+
+// Delete deletes the record from every sink
+func (m *MultiSink) Delete(identifier string) error {
+	return m.each(func(s Sink) error { return s.Delete(identifier) })
+}
+
+// Callback returns a goharvest.RecordCallback writing each record to every sink
+func (m *MultiSink) Callback() goharvest.RecordCallback {
+	return m.Write
+}
+
+// Flush flushes every sink
+func (m *MultiSink) Flush() error {
+	return m.each(Sink.Flush)
+}
+
+// Close closes every sink, even when some fail
+func (m *MultiSink) Close() error {
+	return m.each(Sink.Close)
+}
+
+// each runs fn on all sinks in parallel and joins the errors
+func (m *MultiSink) each(fn func(Sink) error) error {
+	if len(m.sinks) == 1 {
+		return fn(m.sinks[0])
+	}
+
+	errs := make([]error, len(m.sinks))
+	var wg sync.WaitGroup
+	for i, s := range m.sinks {
+		wg.Add(1)
+		go func(i int, s Sink) {
+			defer wg.Done()
+			errs[i] = fn(s)
+		}(i, s)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,98 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jiharal/goharvest"
+)
+
+type failingSink struct {
+	mu     sync.Mutex
+	writes int
+	closed bool
+}
+
+func (s *failingSink) Write(goharvest.HarvestedRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes++
+	return errors.New("disk full")
+}
+
+func (s *failingSink) Delete(identifier string) error { return s.Write(Tombstone(identifier)) }
+func (s *failingSink) Flush() error                   { return nil }
+func (s *failingSink) Close() error                   { s.closed = true; return nil }
+
+func TestMultiSink(t *testing.T) {
+	var buf bytes.Buffer
+	jsonl := NewJSONLWriter(&buf, JSONLOptions{})
+	pub := &recordingPublisher{}
+	m := NewMultiSink(jsonl, NewMessageSink(pub, MessageOptions{}))
+
+	callback := Callback(m)
+	for _, record := range testRecords() {
+		if err := callback(record); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := m.Delete("oai:example.org:1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || len(pub.messages) != 4 || !pub.closed {
+		t.Fatalf("Expected 4 records in each sink, got %d lines and %d messages", len(lines), len(pub.messages))
+	}
+
+	var tombstone JSONLRecord
+	if err := json.Unmarshal([]byte(lines[3]), &tombstone); err != nil {
+		t.Fatal(err)
+	}
+	if tombstone.Identifier != "oai:example.org:1" || !tombstone.Deleted || tombstone.Datestamp == "" {
+		t.Errorf("Unexpected tombstone %+v", tombstone)
+	}
+}
+
+func TestMultiSinkErrors(t *testing.T) {
+	bad := &failingSink{}
+	var buf bytes.Buffer
+	m := NewMultiSink(bad, NewJSONLWriter(&buf, JSONLOptions{}))
+
+	err := m.Write(testRecords()[0])
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Expected joined error, got %v", err)
+	}
+	m.Flush()
+	if buf.Len() == 0 {
+		t.Error("Expected the healthy sink to receive the record")
+	}
+	if err := m.Close(); err != nil || !bad.closed {
+		t.Errorf("Expected every sink to be closed, got %v", err)
+	}
+}
+
+func TestSQLiteSinkDelete(t *testing.T) {
+	db, driver := openRecordingDB()
+	defer db.Close()
+
+	s, err := NewSQLiteSink(t.Context(), db, SQLiteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("oai:example.org:9"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	statements := driver.Statements()
+	last := statements[len(statements)-1]
+	if last.Args[0] != "oai:example.org:9" || last.Args[4] != int64(1) || last.Args[5] != nil {
+		t.Errorf("Unexpected tombstone args %v", last.Args)
+	}
+}
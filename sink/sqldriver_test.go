@@ -0,0 +1,110 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// recordingDriver is a database/sql driver that records executed statements
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []recordedStatement
+	failOn     string
+	commits    int
+	rollbacks  int
+}
+
+// recordedStatement is one executed statement with its arguments
+type recordedStatement struct {
+	Query string
+	Args  []driver.Value
+}
+
+var recordingDriverCount atomic.Int64
+
+// openRecordingDB registers a fresh recording driver and opens a database on it
+func openRecordingDB() (*sql.DB, *recordingDriver) {
+	d := &recordingDriver{}
+	name := fmt.Sprintf("recording-%d", recordingDriverCount.Add(1))
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		panic(err)
+	}
+	return db, d
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return &recordingConn{d: d}, nil }
+
+func (d *recordingDriver) Statements() []recordedStatement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]recordedStatement(nil), d.statements...)
+}
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{c: c, query: query}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return &recordingTx{d: c.d}, nil }
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	if c.d.failOn != "" && strings.Contains(query, c.d.failOn) {
+		return nil, fmt.Errorf("forced failure")
+	}
+	c.d.statements = append(c.d.statements, recordedStatement{Query: query, Args: values})
+	return driver.RowsAffected(1), nil
+}
+
+type recordingTx struct{ d *recordingDriver }
+
+func (t *recordingTx) Commit() error {
+	t.d.mu.Lock()
+	t.d.commits++
+	t.d.mu.Unlock()
+	return nil
+}
+
+func (t *recordingTx) Rollback() error {
+	t.d.mu.Lock()
+	t.d.rollbacks++
+	t.d.mu.Unlock()
+	return nil
+}
+
+type recordingStmt struct {
+	c     *recordingConn
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return s.c.ExecContext(context.Background(), s.query, named)
+}
+func (s *recordingStmt) Query([]driver.Value) (driver.Rows, error) { return emptyRows{}, nil }
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return nil }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
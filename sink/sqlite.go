@@ -0,0 +1,158 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jiharal/goharvest"
+)
+
+// tableNamePattern restricts table names, which cannot be passed as query parameters
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLiteOptions configures a SQLiteSink
+type SQLiteOptions struct {
+	// Table is the records table name (default "records")
+	Table string
+	// IncludeRaw also stores the raw metadata XML of typed records
+	IncludeRaw bool
+}
+
+// SQLiteSink mirrors harvested records into a SQLite database. It works with any
+// database/sql SQLite driver (e.g. modernc.org/sqlite or github.com/mattn/go-sqlite3),
+// which the caller registers and opens. Records are upserted by OAI identifier; an older
+// datestamp never overwrites a newer one, and deleted records are kept as tombstones.
+type SQLiteSink struct {
+	db     *sql.DB
+	opts   SQLiteOptions
+	upsert string
+}
+
+// NewSQLiteSink creates the records table if needed and returns the sink
+func NewSQLiteSink(ctx context.Context, db *sql.DB, opts SQLiteOptions) (*SQLiteSink, error) {
+	if opts.Table == "" {
+		opts.Table = "records"
+	}
+	if !tableNamePattern.MatchString(opts.Table) {
+		return nil, fmt.Errorf("invalid table name %q", opts.Table)
+	}
+
+	schema := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	identifier TEXT PRIMARY KEY,
+	datestamp TEXT NOT NULL,
+	set_specs TEXT NOT NULL DEFAULT '[]',
+	format TEXT NOT NULL DEFAULT '',
+	deleted INTEGER NOT NULL DEFAULT 0,
+	metadata TEXT,
+	raw TEXT,
+	harvested_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS %[1]s_datestamp ON %[1]s (datestamp);`, opts.Table)
+
+	for _, statement := range strings.Split(schema, ";\n") {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return nil, fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+
+	upsert := fmt.Sprintf(`INSERT INTO %s (identifier, datestamp, set_specs, format, deleted, metadata, raw, harvested_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (identifier) DO UPDATE SET
+	datestamp = excluded.datestamp,
+	set_specs = excluded.set_specs,
+	format = excluded.format,
+	deleted = excluded.deleted,
+	metadata = excluded.metadata,
+	raw = excluded.raw,
+	harvested_at = excluded.harvested_at
+WHERE excluded.datestamp >= datestamp`, opts.Table)
+
+	return &SQLiteSink{db: db, opts: opts, upsert: upsert}, nil
+}
+
+// Write upserts one record
+func (s *SQLiteSink) Write(record goharvest.HarvestedRecord) error {
+	row, err := newRecordRow(record, s.opts.IncludeRaw)
+	if err != nil {
+		return err
+	}
+
+	deleted := 0
+	if row.Deleted {
+		deleted = 1
+	}
+	_, err = s.db.Exec(s.upsert, row.Identifier, row.Datestamp, row.SetSpecs, row.Format, deleted,
+		row.Metadata, row.Raw, row.HarvestedAt.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to store record %s: %w", row.Identifier, err)
+	}
+	return nil
+}
+
+// Delete marks identifier as deleted, upserting a tombstone datestamped now
+func (s *SQLiteSink) Delete(identifier string) error {
+	return s.Write(Tombstone(identifier))
+}
+
+// Callback returns a goharvest.RecordCallback writing each record
+func (s *SQLiteSink) Callback() goharvest.RecordCallback {
+	return s.Write
+}
+
+// Flush is a no-op; every Write is committed immediately
+func (s *SQLiteSink) Flush() error {
+	return nil
+}
+
+// Close is a no-op; the caller owns the *sql.DB
+func (s *SQLiteSink) Close() error {
+	return nil
+}
+
+// recordRow is a record prepared for SQL storage
+type recordRow struct {
+	Identifier  string
+	Datestamp   string
+	SetSpecs    string
+	Format      string
+	Deleted     bool
+	Metadata    sql.NullString
+	Raw         sql.NullString
+	HarvestedAt time.Time
+}
+
+// newRecordRow converts a harvested record to column values, encoding metadata as JSON
+func newRecordRow(record goharvest.HarvestedRecord, includeRaw bool) (recordRow, error) {
+	line := NewJSONLRecord(record, includeRaw)
+
+	setSpecs, err := json.Marshal(append([]string{}, line.SetSpecs...))
+	if err != nil {
+		return recordRow{}, err
+	}
+
+	row := recordRow{
+		Identifier:  line.Identifier,
+		Datestamp:   line.Datestamp,
+		SetSpecs:    string(setSpecs),
+		Format:      string(line.Format),
+		Deleted:     line.Deleted,
+		HarvestedAt: time.Now().UTC(),
+	}
+	if line.Metadata != nil && !line.Deleted {
+		metadata, err := json.Marshal(line.Metadata)
+		if err != nil {
+			return recordRow{}, fmt.Errorf("failed to encode record %s: %w", line.Identifier, err)
+		}
+		row.Metadata = sql.NullString{String: string(metadata), Valid: true}
+	}
+	if line.Raw != "" && !line.Deleted {
+		row.Raw = sql.NullString{String: line.Raw, Valid: true}
+	}
+
+	return row, nil
+}
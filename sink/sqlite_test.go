@@ -0,0 +1,71 @@
+package sink
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSQLiteSink(t *testing.T) {
+	db, driver := openRecordingDB()
+	defer db.Close()
+
+	s, err := NewSQLiteSink(context.Background(), db, SQLiteOptions{Table: "mirror"})
+	if err != nil {
+		t.Fatalf("NewSQLiteSink failed: %v", err)
+	}
+
+	callback := s.Callback()
+	for _, record := range testRecords() {
+		if err := callback(record); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	statements := driver.Statements()
+	if len(statements) != 5 {
+		t.Fatalf("Expected 2 schema and 3 upsert statements, got %d", len(statements))
+	}
+	if !strings.HasPrefix(statements[0].Query, "CREATE TABLE IF NOT EXISTS mirror (") || !strings.Contains(statements[1].Query, "CREATE INDEX IF NOT EXISTS mirror_datestamp") {
+		t.Errorf("Unexpected schema statements %q / %q", statements[0].Query, statements[1].Query)
+	}
+
+	upsert := statements[2]
+	if !strings.Contains(upsert.Query, "ON CONFLICT (identifier) DO UPDATE") || !strings.Contains(upsert.Query, "WHERE excluded.datestamp >= datestamp") {
+		t.Errorf("Unexpected upsert %q", upsert.Query)
+	}
+	if upsert.Args[0] != "oai:example.org:1" || upsert.Args[2] != `["buku"]` || upsert.Args[3] != "oai_dc" || upsert.Args[4] != int64(0) {
+		t.Errorf("Unexpected upsert args %v", upsert.Args)
+	}
+	if metadata, ok := upsert.Args[5].(string); !ok || !strings.Contains(metadata, `"title":["Bumi manusia"]`) {
+		t.Errorf("Unexpected metadata %v", upsert.Args[5])
+	}
+
+	if raw := statements[3].Args[6]; raw != "<ead/>" {
+		t.Errorf("Expected raw XML for raw record, got %v", raw)
+	}
+	tombstone := statements[4].Args
+	if tombstone[4] != int64(1) || tombstone[5] != nil || tombstone[6] != nil {
+		t.Errorf("Unexpected tombstone args %v", tombstone)
+	}
+}
+
+func TestSQLiteSinkErrors(t *testing.T) {
+	db, driver := openRecordingDB()
+	defer db.Close()
+
+	if _, err := NewSQLiteSink(context.Background(), db, SQLiteOptions{Table: "records; DROP TABLE x"}); err == nil {
+		t.Error("Expected invalid table name error")
+	}
+
+	driver.failOn = "CREATE TABLE"
+	if _, err := NewSQLiteSink(context.Background(), db, SQLiteOptions{}); err == nil {
+		t.Error("Expected schema error")
+	}
+
+	driver.failOn = "INSERT"
+	s := &SQLiteSink{db: db, upsert: "INSERT INTO records VALUES (?)"}
+	if err := s.Write(testRecords()[0]); err == nil || !strings.Contains(err.Error(), "oai:example.org:1") {
+		t.Errorf("Expected write error naming the record, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package goharvest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// NotXMLError is returned when a repository answers with something other than XML, such
+// as an HTML login page, a bot challenge or a PHP warning printed before the document
+type NotXMLError struct {
+	// URL is the request URL
+	URL string
+	// ContentType is the Content-Type header of the response
+	ContentType string
+	// Snippet is the beginning of the body as text, with markup removed
+	Snippet string
+}
+
+// Error implements the error interface
+func (e *NotXMLError) Error() string {
+	return fmt.Sprintf("response from %s is not XML (Content-Type %q): %q", e.URL, e.ContentType, e.Snippet)
+}
+
+// sniffLength is the number of bytes inspected to recognize a non-XML response
+const sniffLength = 1024
+
+// htmlPrefixes start documents or fragments that are HTML rather than XML
+var htmlPrefixes = [][]byte{
+	[]byte("<!doctype html"), []byte("<html"), []byte("<head"), []byte("<body"),
+	[]byte("<br"), []byte("<b>"), []byte("<p>"), []byte("<script"), []byte("<meta"), []byte("<title"),
+}
+
+// sniffResponse inspects the start of a successful response. A body that does not look
+// like an XML document is replaced by one failing with a *NotXMLError; this happens before
+// the limits are applied, which would reject an HTML DOCTYPE without saying why.
+func sniffResponse(resp *http.Response) {
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	br := bufio.NewReaderSize(resp.Body, sniffLength)
+	head, err := br.Peek(sniffLength)
+	contentType := resp.Header.Get("Content-Type")
+
+	// Read errors are left to the decoder to report
+	if (err == nil || err == io.EOF) && !looksLikeXML(head, contentType) {
+		notXML := &NotXMLError{URL: resp.Request.URL.String(), ContentType: contentType, Snippet: snippet(head, 200)}
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{failingReader{notXML}, resp.Body}
+		return
+	}
+
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{br, resp.Body}
+}
+
+// failingReader fails every read with err
+type failingReader struct {
+	err error
+}
+
+// Read implements io.Reader
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// rejectNotXML returns the *NotXMLError of a body replaced by sniffResponse. Otherwise it
+// returns a body that still yields every byte.
+func rejectNotXML(body io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(body)
+	var notXML *NotXMLError
+	if _, err := br.Peek(1); errors.As(err, &notXML) {
+		body.Close()
+		return nil, notXML
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{br, body}, nil
+}
+
+// looksLikeXML reports whether the start of a body can be an OAI-PMH document
+func looksLikeXML(head []byte, contentType string) bool {
+	head = bytes.TrimPrefix(head, []byte("\xEF\xBB\xBF"))
+	head = bytes.TrimLeft(head, " \t\r\n")
+	if len(head) == 0 || head[0] != '<' {
+		return false
+	}
+
+	lower := bytes.ToLower(head[:min(len(head), 16)])
+	for _, prefix := range htmlPrefixes {
+		if bytes.HasPrefix(lower, prefix) {
+			return false
+		}
+	}
+
+	// An HTML page behind an XML-looking start still names its type
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "text/html" {
+		return bytes.HasPrefix(head, []byte("<?xml")) || bytes.Contains(head, []byte("<OAI-PMH"))
+	}
+	return true
+}
+
+var (
+	markup     = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]*>`)
+	whitespace = regexp.MustCompile(`\s+`)
+)
+
+// snippet returns the text of data with markup removed and whitespace collapsed, cut to
+// about n bytes
+func snippet(data []byte, n int) string {
+	text := markup.ReplaceAllString(string(data), " ")
+	text = strings.TrimSpace(whitespace.ReplaceAllString(text, " "))
+	return strings.ToValidUTF8(truncate(text, n), "")
+}
@@ -0,0 +1,87 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLooksLikeXML(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        bool
+	}{
+		{"declaration", `<?xml version="1.0"?><OAI-PMH/>`, "text/xml", true},
+		{"no declaration", "\n  <OAI-PMH/>", "application/xml", true},
+		{"byte order mark", "\xEF\xBB\xBF<?xml version=\"1.0\"?><OAI-PMH/>", "text/xml", true},
+		{"XML labelled as HTML", `<?xml version="1.0"?><OAI-PMH/>`, "text/html; charset=utf-8", true},
+		{"HTML page", "<!DOCTYPE html>\n<html><head><title>Login</title></head></html>", "text/xml", false},
+		{"HTML fragment labelled as HTML", `<div>Maintenance</div>`, "text/html", false},
+		{"PHP warning", "<br />\n<b>Warning</b>:  Undefined variable $set", "text/xml", false},
+		{"plain text", "Service Unavailable", "text/plain", false},
+		{"JSON", `{"error": "not found"}`, "application/json", false},
+		{"empty", "", "text/xml", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := looksLikeXML([]byte(tt.body), tt.contentType); got != tt.want {
+				t.Errorf("looksLikeXML(%q, %q) = %v, want %v", tt.body, tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotXMLResponse(t *testing.T) {
+	challenge := `<!DOCTYPE html>
+<html lang="en-US">
+<head><title>Just a moment...</title>
+<script>window._cf_chl_opt = {cType: 'managed'};</script></head>
+<body><h1>Checking if the site connection is secure</h1></body>
+</html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.Write([]byte(challenge))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	err := client.HarvestRecords("oai_dc", nil, func(HarvestedRecord) error { return nil })
+	var notXML *NotXMLError
+	if !errors.As(err, &notXML) {
+		t.Fatalf("Expected a *NotXMLError, got %v", err)
+	}
+	if notXML.Snippet != "Just a moment... Checking if the site connection is secure" {
+		t.Errorf("Unexpected snippet %q", notXML.Snippet)
+	}
+	if notXML.ContentType != "text/html; charset=UTF-8" || !strings.Contains(notXML.URL, "verb=ListRecords") {
+		t.Errorf("Unexpected error %+v", notXML)
+	}
+
+	if _, err := client.Identify(context.Background()); !errors.As(err, &notXML) {
+		t.Errorf("Expected a *NotXMLError from Identify, got %v", err)
+	}
+}
+
+func TestXMLLabelledAsHTML(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "identify_response.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(server.URL).Identify(context.Background()); err != nil {
+		t.Errorf("Expected an XML response labelled as HTML to parse, got %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package goharvest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrObjectNotFound is returned by Storage.Get for missing keys
+var ErrObjectNotFound = errors.New("object not found")
+
+// Storage is a minimal object store, such as a local directory (DirStorage) or an
+// S3-compatible bucket (S3Storage). Keys are slash-separated paths.
+type Storage interface {
+	// Put stores data under key, replacing any existing object
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the object stored under key, or ErrObjectNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns the keys starting with prefix, sorted
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Delete removes the object stored under key; missing keys are not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// DirStorage stores objects as files below a directory
+type DirStorage struct {
+	Dir string
+}
+
+// NewDirStorage returns a Storage keeping objects below dir
+func NewDirStorage(dir string) *DirStorage {
+	return &DirStorage{Dir: dir}
+}
+
+// Put writes the object atomically, creating parent directories
+func (d *DirStorage) Put(_ context.Context, key string, data []byte) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get reads the object's file
+func (d *DirStorage) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	return data, err
+}
+
+// List walks the directory for files whose key starts with prefix
+func (d *DirStorage) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(d.Dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if entry.IsDir() || strings.Contains(entry.Name(), ".tmp") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(d.Dir, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete removes the object's file
+func (d *DirStorage) Delete(_ context.Context, key string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path maps a key to a file below the directory, rejecting keys that escape it
+func (d *DirStorage) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(d.Dir, filepath.FromSlash(key)), nil
+}
+
+// StorageArchive is a PageArchive and ArchiveReader on top of a Storage, for harvests
+// that should not depend on local disks. Pages are stored gzip-compressed under
+// prefix + "pages/" and described by one JSON object each under prefix + "manifest/".
+type StorageArchive struct {
+	Storage Storage
+	Prefix  string
+
+	mu       sync.Mutex
+	sequence int
+}
+
+// NewStorageArchive returns an archive below prefix (e.g. "harvests/2025-10-02/"),
+// continuing the sequence of pages already stored there
+func NewStorageArchive(ctx context.Context, storage Storage, prefix string) (*StorageArchive, error) {
+	a := &StorageArchive{Storage: storage, Prefix: prefix}
+
+	keys, err := storage.List(ctx, a.manifestPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive: %w", err)
+	}
+	a.sequence = len(keys)
+
+	return a, nil
+}
+
+// Store puts the page and its manifest entry
+func (a *StorageArchive) Store(page *ArchivedPage, body []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ctx := context.Background()
+	compressed, err := preparePage(page, a.sequence+1, body)
+	if err != nil {
+		return err
+	}
+	if err := a.Storage.Put(ctx, a.Prefix+"pages/"+page.File, compressed); err != nil {
+		return fmt.Errorf("failed to store archived page: %w", err)
+	}
+
+	entry, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+	if err := a.Storage.Put(ctx, fmt.Sprintf("%s%06d.json", a.manifestPrefix(), page.Sequence), entry); err != nil {
+		return fmt.Errorf("failed to store archive manifest entry: %w", err)
+	}
+
+	a.sequence = page.Sequence
+	return nil
+}
+
+// Pages reads the manifest entries, in archive order
+func (a *StorageArchive) Pages() ([]ArchivedPage, error) {
+	ctx := context.Background()
+	keys, err := a.Storage.List(ctx, a.manifestPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]ArchivedPage, 0, len(keys))
+	for _, key := range keys {
+		data, err := a.Storage.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		var page ArchivedPage
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("invalid archive manifest entry %s: %w", key, err)
+		}
+		pages = append(pages, page)
+	}
+
+	sort.SliceStable(pages, func(i, j int) bool { return pages[i].Sequence < pages[j].Sequence })
+	return pages, nil
+}
+
+// Open returns the decompressed response of an archived page
+func (a *StorageArchive) Open(page ArchivedPage) (io.ReadCloser, error) {
+	data, err := a.Storage.Get(context.Background(), a.Prefix+"pages/"+page.File)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archived page %s: %w", page.File, err)
+	}
+	return zr, nil
+}
+
+// manifestPrefix is the key prefix of the manifest entries
+func (a *StorageArchive) manifestPrefix() string {
+	return a.Prefix + "manifest/"
+}
+
+// StorageCheckpointer stores harvest state as a JSON object in a Storage
+type StorageCheckpointer struct {
+	Storage Storage
+	Key     string
+}
+
+// NewStorageCheckpointer creates a checkpointer that stores state under key
+func NewStorageCheckpointer(storage Storage, key string) *StorageCheckpointer {
+	return &StorageCheckpointer{Storage: storage, Key: key}
+}
+
+// Save puts the state object
+func (s *StorageCheckpointer) Save(state *HarvestState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.Storage.Put(context.Background(), s.Key, data)
+}
+
+// Load gets the state object
+func (s *StorageCheckpointer) Load() (*HarvestState, error) {
+	data, err := s.Storage.Get(context.Background(), s.Key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state HarvestState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// Clear deletes the state object
+func (s *StorageCheckpointer) Clear() error {
+	return s.Storage.Delete(context.Background(), s.Key)
+}
@@ -0,0 +1,115 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDirStorage(t *testing.T) {
+	ctx := context.Background()
+	s := NewDirStorage(t.TempDir())
+
+	for _, key := range []string{"pages/2", "pages/1", "state.json"} {
+		if err := s.Put(ctx, key, []byte(key)); err != nil {
+			t.Fatalf("Put %s failed: %v", key, err)
+		}
+	}
+	keys, err := s.List(ctx, "pages/")
+	if err != nil || strings.Join(keys, ",") != "pages/1,pages/2" {
+		t.Errorf("List returned %v, %v", keys, err)
+	}
+	if data, err := s.Get(ctx, "state.json"); err != nil || string(data) != "state.json" {
+		t.Errorf("Get returned %q, %v", data, err)
+	}
+	if err := s.Delete(ctx, "state.json"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Get(ctx, "state.json"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Expected ErrObjectNotFound, got %v", err)
+	}
+	if err := s.Delete(ctx, "state.json"); err != nil {
+		t.Errorf("Deleting a missing key should succeed, got %v", err)
+	}
+	if err := s.Put(ctx, "../escape", nil); err == nil {
+		t.Error("Expected invalid key error")
+	}
+	if keys, err := NewDirStorage(t.TempDir()+"/missing").List(ctx, ""); err != nil || len(keys) != 0 {
+		t.Errorf("Expected empty listing of a missing directory, got %v, %v", keys, err)
+	}
+}
+
+func TestStorageArchive(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	ctx := context.Background()
+	storage := NewDirStorage(t.TempDir())
+	archive, err := NewStorageArchive(ctx, storage, "harvests/run-1/")
+	if err != nil {
+		t.Fatalf("NewStorageArchive failed: %v", err)
+	}
+
+	client := NewClient(server.URL, WithArchive(archive))
+	if err := client.HarvestRecords("oai_dc", nil, func(HarvestedRecord) error { return nil }); err != nil {
+		t.Fatalf("HarvestRecords failed: %v", err)
+	}
+
+	keys, _ := storage.List(ctx, "harvests/run-1/")
+	if len(keys) != 4 || keys[0] != "harvests/run-1/manifest/000001.json" {
+		t.Errorf("Unexpected archive objects %v", keys)
+	}
+
+	pages, err := archive.Pages()
+	if err != nil || len(pages) != 2 || pages[1].Args["resumptionToken"] != "dc-page-2" {
+		t.Fatalf("Unexpected pages %+v, %v", pages, err)
+	}
+	r, err := archive.Open(pages[1])
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	if len(data) != pages[1].Size {
+		t.Errorf("Expected %d bytes, got %d", pages[1].Size, len(data))
+	}
+
+	replay, err := NewReplayClient(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	replay.HarvestRecords("oai_dc", nil, func(HarvestedRecord) error { count++; return nil })
+	if count != 4 {
+		t.Errorf("Expected 4 replayed records, got %d", count)
+	}
+
+	reopened, _ := NewStorageArchive(ctx, storage, "harvests/run-1/")
+	page := &ArchivedPage{Verb: "Identify"}
+	if err := reopened.Store(page, []byte("<OAI-PMH/>")); err != nil || page.Sequence != 3 {
+		t.Errorf("Expected sequence 3, got %d (%v)", page.Sequence, err)
+	}
+}
+
+func TestStorageCheckpointer(t *testing.T) {
+	c := NewStorageCheckpointer(NewDirStorage(t.TempDir()), "checkpoints/repo.json")
+
+	if state, err := c.Load(); err != nil || state != nil {
+		t.Fatalf("Expected no state, got %+v, %v", state, err)
+	}
+	if err := c.Save(&HarvestState{MetadataPrefix: "oai_dc", ResumptionToken: "dc-page-2"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	state, err := c.Load()
+	if err != nil || state.ResumptionToken != "dc-page-2" {
+		t.Errorf("Unexpected state %+v, %v", state, err)
+	}
+	if err := c.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if state, _ := c.Load(); state != nil {
+		t.Errorf("Expected cleared state, got %+v", state)
+	}
+}
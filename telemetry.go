@@ -0,0 +1,141 @@
+package goharvest
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Metric names reported to Telemetry. Counters are reported with Count, histograms with
+// Record.
+const (
+	// MetricHarvestsActive counts running harvests, +1 when one starts and -1 when it ends
+	MetricHarvestsActive = "oai.harvests.active"
+	// MetricPages counts ListRecords pages fetched, by format
+	MetricPages = "oai.pages"
+	// MetricRecords counts records harvested, including deleted ones, by format
+	MetricRecords = "oai.records"
+	// MetricDeletedRecords counts records with status="deleted", by format
+	MetricDeletedRecords = "oai.records.deleted"
+	// MetricOAIErrors counts OAI-PMH error responses, by verb and error code
+	MetricOAIErrors = "oai.errors"
+	// MetricHTTPFailures counts failed requests and non-200 responses, by verb and status
+	// (0 when no response was received)
+	MetricHTTPFailures = "oai.http.failures"
+	// MetricRetries counts retried requests, by verb
+	MetricRetries = "oai.retries"
+	// MetricPageDuration records the seconds taken to fetch and parse a page, by format
+	MetricPageDuration = "oai.page.duration"
+)
+
+// Attribute names attached to spans and metrics
+const (
+	AttrBaseURL         = "oai.base_url"
+	AttrVerb            = "oai.verb"
+	AttrFormat          = "oai.metadata_prefix"
+	AttrSet             = "oai.set"
+	AttrResumptionToken = "oai.resumption_token"
+	AttrErrorCode       = "oai.error.code"
+	AttrStatus          = "http.response.status_code"
+	AttrRecords         = "oai.records"
+	AttrPages           = "oai.pages"
+)
+
+// Attribute is a key/value pair describing a span or measurement
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr creates an Attribute
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is an operation being traced
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Telemetry receives spans and metrics from the client. It is small enough to be adapted
+// to OpenTelemetry (a trace.Tracer and metric.Meter) or any other backend without
+// goharvest depending on it. Implementations must be safe for concurrent use.
+type Telemetry interface {
+	// StartSpan starts a span as a child of any span in ctx and returns a context
+	// carrying it
+	StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+	// Count adds delta to a counter
+	Count(ctx context.Context, name string, delta int64, attrs ...Attribute)
+	// Record adds a value to a histogram
+	Record(ctx context.Context, name string, value float64, attrs ...Attribute)
+}
+
+// WithTelemetry reports spans per harvest, page and request, and harvest metrics, to t
+func WithTelemetry(t Telemetry) ClientOption {
+	return func(c *OAIClient) {
+		c.Telemetry = t
+	}
+}
+
+// noopTelemetry discards everything
+type noopTelemetry struct{}
+
+func (noopTelemetry) StartSpan(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+func (noopTelemetry) Count(context.Context, string, int64, ...Attribute)    {}
+func (noopTelemetry) Record(context.Context, string, float64, ...Attribute) {}
+
+// noopSpan is the span of noopTelemetry
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+// telemetry returns the client's telemetry, or one that discards everything
+func (c *OAIClient) telemetry() Telemetry {
+	if c.Telemetry == nil {
+		return noopTelemetry{}
+	}
+	return c.Telemetry
+}
+
+// tracedPages wraps a page parser with a span and metrics per page
+func (c *OAIClient) tracedPages(parser pageParser) pageParser {
+	if c.Telemetry == nil {
+		return parser
+	}
+
+	return func(metadataPrefix string, resumptionToken string, opts *HarvestOptions) (OAIResponse, error) {
+		telemetry := c.Telemetry
+		ctx := opts.context()
+		format := Attr(AttrFormat, metadataPrefix)
+
+		_, span := telemetry.StartSpan(ctx, "oai.page", format, Attr(AttrResumptionToken, resumptionToken))
+		defer span.End()
+
+		start := time.Now()
+		resp, err := parser(metadataPrefix, resumptionToken, opts)
+		if err != nil {
+			span.RecordError(err)
+			var oaiErr *OAIError
+			if errors.As(err, &oaiErr) {
+				telemetry.Count(ctx, MetricOAIErrors, 1, Attr(AttrVerb, "ListRecords"), Attr(AttrErrorCode, oaiErr.Code))
+			}
+			return nil, err
+		}
+
+		records, deleted := countRecords(resp), countDeletedRecords(resp)
+		span.SetAttributes(Attr(AttrRecords, records))
+		telemetry.Record(ctx, MetricPageDuration, time.Since(start).Seconds(), format)
+		telemetry.Count(ctx, MetricPages, 1, format)
+		telemetry.Count(ctx, MetricRecords, int64(records), format)
+		if deleted > 0 {
+			telemetry.Count(ctx, MetricDeletedRecords, int64(deleted), format)
+		}
+		return resp, nil
+	}
+}
@@ -0,0 +1,155 @@
+package goharvest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// recordedSpan is a span captured by recordingTelemetry
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+func (s *recordedSpan) End()                  { s.ended = true }
+
+type spanKey struct{}
+
+// recordingTelemetry records spans and metrics in memory
+type recordingTelemetry struct {
+	mu       sync.Mutex
+	spans    []*recordedSpan
+	counters map[string]int64
+	values   map[string][]float64
+	errors   map[string]int64
+}
+
+func newRecordingTelemetry() *recordingTelemetry {
+	return &recordingTelemetry{
+		counters: map[string]int64{},
+		values:   map[string][]float64{},
+		errors:   map[string]int64{},
+	}
+}
+
+func (r *recordingTelemetry) StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	span := &recordedSpan{name: name, parent: parent, attrs: map[string]interface{}{}}
+	span.SetAttributes(attrs...)
+	r.spans = append(r.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (r *recordingTelemetry) Count(_ context.Context, name string, delta int64, attrs ...Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[name] += delta
+	for _, attr := range attrs {
+		if attr.Key == AttrErrorCode {
+			r.errors[attr.Value.(string)] += delta
+		}
+	}
+}
+
+func (r *recordingTelemetry) Record(_ context.Context, name string, value float64, _ ...Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] = append(r.values[name], value)
+}
+
+// named returns the recorded spans called name
+func (r *recordingTelemetry) named(name string) []*recordedSpan {
+	var spans []*recordedSpan
+	for _, span := range r.spans {
+		if span.name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func TestTelemetryHarvest(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"ListRecords":           "listrecords_dc_page1.xml",
+		"ListRecords:dc-page-2": "listrecords_dc_page2.xml",
+	})
+	telemetry := newRecordingTelemetry()
+
+	err := NewClient(server.URL, WithTelemetry(telemetry)).HarvestRecords("oai_dc", nil, func(HarvestedRecord) error { return nil })
+	if err != nil {
+		t.Fatalf("Harvest failed: %v", err)
+	}
+
+	harvests := telemetry.named("oai.harvest")
+	if len(harvests) != 1 {
+		t.Fatalf("Expected 1 harvest span, got %d", len(harvests))
+	}
+	harvest := harvests[0]
+	if !harvest.ended || harvest.err != nil || harvest.attrs[AttrFormat] != "oai_dc" ||
+		harvest.attrs[AttrPages] != 2 || harvest.attrs[AttrRecords] != 4 {
+		t.Errorf("Unexpected harvest span %+v", harvest)
+	}
+
+	if pages := telemetry.named("oai.page"); len(pages) != 2 || pages[1].attrs[AttrResumptionToken] != "dc-page-2" {
+		t.Errorf("Expected 2 page spans, got %v", pages)
+	}
+	requests := telemetry.named("OAI-PMH ListRecords")
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 request spans, got %d", len(requests))
+	}
+	for _, request := range requests {
+		if request.parent != harvest || !request.ended || request.attrs[AttrStatus] != http.StatusOK {
+			t.Errorf("Unexpected request span %+v", request)
+		}
+	}
+
+	if telemetry.counters[MetricPages] != 2 || telemetry.counters[MetricRecords] != 4 ||
+		telemetry.counters[MetricDeletedRecords] != 1 || telemetry.counters[MetricHarvestsActive] != 0 {
+		t.Errorf("Unexpected counters %v", telemetry.counters)
+	}
+	if len(telemetry.values[MetricPageDuration]) != 2 {
+		t.Errorf("Expected 2 page durations, got %v", telemetry.values[MetricPageDuration])
+	}
+}
+
+func TestTelemetryFailures(t *testing.T) {
+	server := serveDocument(t, `<OAI-PMH><error code="badArgument">Illegal argument</error></OAI-PMH>`)
+	telemetry := newRecordingTelemetry()
+	client := NewClient(server.URL, WithTelemetry(telemetry))
+
+	if err := client.HarvestRecords("oai_dc", nil, func(HarvestedRecord) error { return nil }); err == nil {
+		t.Fatal("Expected badArgument to fail the harvest")
+	}
+	if telemetry.counters[MetricOAIErrors] != 1 || telemetry.errors["badArgument"] != 1 {
+		t.Errorf("Expected 1 badArgument error, got %v", telemetry.errors)
+	}
+	if harvest := telemetry.named("oai.harvest"); len(harvest) != 1 || harvest[0].err == nil {
+		t.Errorf("Expected the harvest span to record the error, got %v", harvest)
+	}
+
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	client.BaseURL = unavailable.URL
+	if err := client.HarvestRecords("oai_dc", nil, func(HarvestedRecord) error { return nil }); err == nil {
+		t.Fatal("Expected a 503 to fail the harvest")
+	}
+	if telemetry.counters[MetricHTTPFailures] != 1 || telemetry.counters[MetricHarvestsActive] != 0 {
+		t.Errorf("Unexpected counters %v", telemetry.counters)
+	}
+}
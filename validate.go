@@ -0,0 +1,508 @@
+package goharvest
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// OAINamespace is the XML namespace of OAI-PMH 2.0 responses
+const OAINamespace = "http://www.openarchives.org/OAI/2.0/"
+
+// ComplianceSeverity tells how serious a failed compliance check is
+type ComplianceSeverity string
+
+// Compliance check severities
+const (
+	// SeverityError marks a violation of the OAI-PMH 2.0 specification
+	SeverityError ComplianceSeverity = "error"
+	// SeverityWarning marks behavior that is allowed but likely to trouble harvesters
+	SeverityWarning ComplianceSeverity = "warning"
+)
+
+// ComplianceCheck is the outcome of one check of a compliance run
+type ComplianceCheck struct {
+	// ID names the check, e.g. "identify.granularity" or "errors.badVerb"
+	ID       string             `json:"id"`
+	Verb     string             `json:"verb"`
+	Severity ComplianceSeverity `json:"severity"`
+	Passed   bool               `json:"passed"`
+	Message  string             `json:"message,omitempty"`
+}
+
+// ComplianceReport is the result of validating a repository
+type ComplianceReport struct {
+	BaseURL         string               `json:"base_url"`
+	Started         time.Time            `json:"started"`
+	Duration        time.Duration        `json:"duration"`
+	Identify        *Identify            `json:"identify,omitempty"`
+	MetadataFormats []MetadataFormatInfo `json:"metadata_formats,omitempty"`
+	Sets            int                  `json:"sets"`
+	PagesChecked    int                  `json:"pages_checked"`
+	Checks          []ComplianceCheck    `json:"checks"`
+}
+
+// Compliant returns true when no error-severity check failed
+func (r *ComplianceReport) Compliant() bool {
+	for _, check := range r.Checks {
+		if !check.Passed && check.Severity == SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+// Failures returns the failed checks, errors and warnings
+func (r *ComplianceReport) Failures() []ComplianceCheck {
+	var failed []ComplianceCheck
+	for _, check := range r.Checks {
+		if !check.Passed {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// Check returns the check with the given ID, if it was run
+func (r *ComplianceReport) Check(id string) (ComplianceCheck, bool) {
+	for _, check := range r.Checks {
+		if check.ID == id {
+			return check, true
+		}
+	}
+	return ComplianceCheck{}, false
+}
+
+// String formats the report with one line per check
+func (r *ComplianceReport) String() string {
+	var b strings.Builder
+	status := "compliant"
+	if !r.Compliant() {
+		status = "NOT compliant"
+	}
+	fmt.Fprintf(&b, "%s: %s (%d checks, %d failed)\n", r.BaseURL, status, len(r.Checks), len(r.Failures()))
+	for _, check := range r.Checks {
+		result := "PASS"
+		if !check.Passed {
+			result = strings.ToUpper(string(check.Severity))
+		}
+		fmt.Fprintf(&b, "[%s] %s", result, check.ID)
+		if check.Message != "" {
+			fmt.Fprintf(&b, ": %s", check.Message)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ValidateOptions configures a compliance run
+type ValidateOptions struct {
+	// MetadataPrefix is used for the list requests (default "oai_dc", which every
+	// repository must support)
+	MetadataPrefix string
+	// MaxPages is the number of ListIdentifiers pages followed to check resumption
+	// tokens (default 3)
+	MaxPages int
+}
+
+// Validate checks the repository at baseURL for OAI-PMH 2.0 compliance
+func Validate(ctx context.Context, baseURL string, opts ...ClientOption) (*ComplianceReport, error) {
+	return NewClient(baseURL, opts...).Validate(ctx, nil)
+}
+
+// Validate exercises Identify, ListMetadataFormats, ListSets, ListIdentifiers, ListRecords
+// and the protocol error conditions of the client's repository, checking datestamp
+// granularity, resumption token behavior, error codes and UTF-8 validity. Failed checks
+// are reported in the returned report; an error is only returned if ctx ends the run.
+func (c *OAIClient) Validate(ctx context.Context, opts *ValidateOptions) (*ComplianceReport, error) {
+	v := &validator{
+		client:         c,
+		metadataPrefix: string(FormatOAIDC),
+		maxPages:       3,
+		report:         &ComplianceReport{BaseURL: c.BaseURL, Started: time.Now().UTC()},
+	}
+	if opts != nil {
+		if opts.MetadataPrefix != "" {
+			v.metadataPrefix = opts.MetadataPrefix
+		}
+		if opts.MaxPages > 0 {
+			v.maxPages = opts.MaxPages
+		}
+	}
+
+	if v.identify(ctx) {
+		v.listMetadataFormats(ctx)
+		v.listSets(ctx)
+		v.listIdentifiers(ctx)
+		v.listRecords(ctx)
+		v.errorConditions(ctx)
+	}
+
+	v.report.Duration = time.Since(v.report.Started)
+	if err := ctx.Err(); err != nil {
+		return v.report, err
+	}
+	return v.report, nil
+}
+
+// complianceResponse is a generic OAI-PMH response for compliance checks
+type complianceResponse struct {
+	XMLName             xml.Name             `xml:"OAI-PMH"`
+	ResponseDate        string               `xml:"responseDate"`
+	Request             OAIRequest           `xml:"request"`
+	Errors              []OAIError           `xml:"error"`
+	Identify            *Identify            `xml:"Identify"`
+	ListMetadataFormats *ListMetadataFormats `xml:"ListMetadataFormats"`
+	ListSets            *ListSets            `xml:"ListSets"`
+	ListIdentifiers     *complianceList      `xml:"ListIdentifiers"`
+	ListRecords         *complianceList      `xml:"ListRecords"`
+}
+
+// complianceList is the content of a ListIdentifiers or ListRecords response
+type complianceList struct {
+	Headers         []Header           `xml:"header"`
+	Records         []complianceRecord `xml:"record"`
+	ResumptionToken *ResumptionToken   `xml:"resumptionToken"`
+}
+
+// complianceRecord is a record of a ListRecords response
+type complianceRecord struct {
+	Header   Header `xml:"header"`
+	Metadata *struct {
+		Raw []byte `xml:",innerxml"`
+	} `xml:"metadata"`
+}
+
+// errorCode returns the code of the first error in the response, if any
+func (r *complianceResponse) errorCode() string {
+	if len(r.Errors) == 0 {
+		return ""
+	}
+	return r.Errors[0].Code
+}
+
+// setSpecPattern is the syntax of setSpec values
+var setSpecPattern = regexp.MustCompile(`^[A-Za-z0-9\-_.!~*'()]+(:[A-Za-z0-9\-_.!~*'()]+)*$`)
+
+// validator runs the checks of one compliance run
+type validator struct {
+	client         *OAIClient
+	metadataPrefix string
+	maxPages       int
+	granularity    string
+	earliest       string
+	report         *ComplianceReport
+}
+
+// check records the outcome of a check; message explains failures
+func (v *validator) check(id, verb string, severity ComplianceSeverity, passed bool, format string, args ...interface{}) bool {
+	check := ComplianceCheck{ID: id, Verb: verb, Severity: severity, Passed: passed}
+	if !passed {
+		check.Message = fmt.Sprintf(format, args...)
+	}
+	v.report.Checks = append(v.report.Checks, check)
+	return passed
+}
+
+// fetch performs a request and runs the checks common to all responses, prefixed by id.
+// It returns nil when the response cannot be used for further checks.
+func (v *validator) fetch(ctx context.Context, id, verb string, args ...string) *complianceResponse {
+	resp, err := v.client.sendRequest(ctx, verb, args...)
+	if err != nil {
+		v.check(id+".reachable", verb, SeverityError, false, "request failed: %v", err)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		v.check(id+".reachable", verb, SeverityError, false, "failed to read response: %v", err)
+		return nil
+	}
+	v.report.PagesChecked++
+
+	v.check(id+".http_status", verb, SeverityWarning, resp.StatusCode == http.StatusOK,
+		"HTTP status %d; OAI-PMH responses, including protocol errors, should use 200", resp.StatusCode)
+	if !v.check(id+".utf8", verb, SeverityError, utf8.Valid(body), "response is not valid UTF-8") {
+		return nil
+	}
+
+	var parsed complianceResponse
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		v.check(id+".xml", verb, SeverityError, false, "response is not a well-formed OAI-PMH document: %v", err)
+		return nil
+	}
+	v.check(id+".xml", verb, SeverityError, true, "")
+	v.check(id+".namespace", verb, SeverityError, parsed.XMLName.Space == OAINamespace,
+		"root element namespace is %q, want %q", parsed.XMLName.Space, OAINamespace)
+	v.check(id+".response_date", verb, SeverityError, datestampGranularity(parsed.ResponseDate) == GranularitySecond,
+		"responseDate %q is not in YYYY-MM-DDThh:mm:ssZ form", parsed.ResponseDate)
+
+	return &parsed
+}
+
+// identify checks the Identify response; the other checks depend on it
+func (v *validator) identify(ctx context.Context) bool {
+	resp := v.fetch(ctx, "identify", "Identify")
+	if resp == nil {
+		return false
+	}
+	if !v.check("identify.present", "Identify", SeverityError, resp.Identify != nil, "no Identify element (error %q)", resp.errorCode()) {
+		return false
+	}
+
+	identify := resp.Identify
+	v.report.Identify = identify
+	v.granularity = identify.Granularity
+	v.earliest = identify.EarliestDatestamp
+
+	v.check("identify.repository_name", "Identify", SeverityError, identify.RepositoryName != "", "repositoryName is empty")
+	v.check("identify.base_url", "Identify", SeverityError, identify.BaseURL != "", "baseURL is empty")
+	v.check("identify.base_url_match", "Identify", SeverityWarning, identify.BaseURL == "" || strings.TrimSuffix(identify.BaseURL, "/") == strings.TrimSuffix(v.client.BaseURL, "/"),
+		"baseURL %q differs from the requested %q", identify.BaseURL, v.client.BaseURL)
+	v.check("identify.protocol_version", "Identify", SeverityError, identify.ProtocolVersion == "2.0",
+		"protocolVersion is %q, want 2.0", identify.ProtocolVersion)
+	v.check("identify.admin_email", "Identify", SeverityError, len(identify.AdminEmail) > 0 && strings.Contains(identify.AdminEmail[0], "@"),
+		"no valid adminEmail")
+
+	switch identify.DeletedRecord {
+	case DeletedRecordNo, DeletedRecordPersistent, DeletedRecordTransient:
+		v.check("identify.deleted_record", "Identify", SeverityError, true, "")
+	default:
+		v.check("identify.deleted_record", "Identify", SeverityError, false, "deletedRecord %q is not no, persistent or transient", identify.DeletedRecord)
+	}
+
+	validGranularity := identify.Granularity == GranularityDay || identify.Granularity == GranularitySecond
+	v.check("identify.granularity", "Identify", SeverityError, validGranularity, "granularity %q is not %s or %s",
+		identify.Granularity, GranularityDay, GranularitySecond)
+	v.check("identify.earliest_datestamp", "Identify", SeverityError,
+		validGranularity && datestampGranularity(identify.EarliestDatestamp) == identify.Granularity,
+		"earliestDatestamp %q does not match granularity %s", identify.EarliestDatestamp, identify.Granularity)
+
+	return true
+}
+
+// listMetadataFormats checks that oai_dc and the validated prefix are supported
+func (v *validator) listMetadataFormats(ctx context.Context) {
+	resp := v.fetch(ctx, "formats", "ListMetadataFormats")
+	if resp == nil {
+		return
+	}
+	if !v.check("formats.present", "ListMetadataFormats", SeverityError, resp.ListMetadataFormats != nil,
+		"no ListMetadataFormats element (error %q)", resp.errorCode()) {
+		return
+	}
+
+	formats := resp.ListMetadataFormats.MetadataFormats
+	v.report.MetadataFormats = formats
+	v.check("formats.oai_dc", "ListMetadataFormats", SeverityError, SupportsMetadataPrefix(formats, string(FormatOAIDC)),
+		"oai_dc is not offered, but every repository must support it")
+	if v.metadataPrefix != string(FormatOAIDC) {
+		v.check("formats.requested", "ListMetadataFormats", SeverityError, SupportsMetadataPrefix(formats, v.metadataPrefix),
+			"%s is not offered", v.metadataPrefix)
+	}
+	for _, format := range formats {
+		if format.Schema == "" || format.MetadataNamespace == "" {
+			v.check("formats.complete", "ListMetadataFormats", SeverityError, false,
+				"format %s lacks schema or metadataNamespace", format.MetadataPrefix)
+			return
+		}
+	}
+	v.check("formats.complete", "ListMetadataFormats", SeverityError, true, "")
+}
+
+// listSets checks that sets are listed with valid setSpecs, or noSetHierarchy is reported
+func (v *validator) listSets(ctx context.Context) {
+	resp := v.fetch(ctx, "sets", "ListSets")
+	if resp == nil {
+		return
+	}
+	if resp.ListSets == nil {
+		v.check("sets.present", "ListSets", SeverityError, resp.errorCode() == ErrorCodeNoSetHierarchy,
+			"neither sets nor a noSetHierarchy error (error %q)", resp.errorCode())
+		return
+	}
+	v.check("sets.present", "ListSets", SeverityError, true, "")
+
+	v.report.Sets = len(resp.ListSets.Sets)
+	for _, set := range resp.ListSets.Sets {
+		if !setSpecPattern.MatchString(set.SetSpec) || set.SetName == "" {
+			v.check("sets.syntax", "ListSets", SeverityError, false, "invalid set %q (%q)", set.SetSpec, set.SetName)
+			return
+		}
+	}
+	v.check("sets.syntax", "ListSets", SeverityError, true, "")
+}
+
+// listIdentifiers follows resumption tokens, checking headers, datestamps and token cursors
+func (v *validator) listIdentifiers(ctx context.Context) {
+	seen := make(map[string]bool)
+	var (
+		token        string
+		items        int
+		listSize     int
+		badDatestamp string
+		early        string
+		duplicate    string
+		tokenProblem string
+		complete     bool
+	)
+
+	for page := 1; page <= v.maxPages; page++ {
+		args := []string{"metadataPrefix", v.metadataPrefix}
+		if token != "" {
+			args = []string{"resumptionToken", token}
+		}
+		resp := v.fetch(ctx, "identifiers", "ListIdentifiers", args...)
+		if resp == nil {
+			return
+		}
+		if resp.ListIdentifiers == nil {
+			if page == 1 && resp.errorCode() == ErrorCodeNoRecordsMatch {
+				v.check("identifiers.present", "ListIdentifiers", SeverityWarning, false, "the repository has no %s records", v.metadataPrefix)
+			} else {
+				v.check("identifiers.present", "ListIdentifiers", SeverityError, false, "no ListIdentifiers element on page %d (error %q)", page, resp.errorCode())
+			}
+			return
+		}
+
+		for _, header := range resp.ListIdentifiers.Headers {
+			if seen[header.Identifier] && duplicate == "" {
+				duplicate = header.Identifier
+			}
+			seen[header.Identifier] = true
+			if datestampGranularity(header.DateStamp) != v.granularity && badDatestamp == "" {
+				badDatestamp = header.DateStamp
+			}
+			if v.granularity == datestampGranularity(header.DateStamp) && header.DateStamp < v.earliest && early == "" {
+				early = header.DateStamp
+			}
+		}
+
+		rt := resp.ListIdentifiers.ResumptionToken
+		if rt != nil && tokenProblem == "" {
+			if listSize != 0 && rt.CompleteListSize != 0 && rt.CompleteListSize != listSize {
+				tokenProblem = fmt.Sprintf("completeListSize changed from %d to %d", listSize, rt.CompleteListSize)
+			} else if page > 1 && rt.Cursor != 0 && rt.Cursor != items {
+				tokenProblem = fmt.Sprintf("cursor is %d on page %d, but %d items were returned before", rt.Cursor, page, items)
+			}
+			if rt.CompleteListSize != 0 {
+				listSize = rt.CompleteListSize
+			}
+		}
+		items += len(resp.ListIdentifiers.Headers)
+
+		if rt == nil || rt.Token == "" {
+			complete = true
+			if page > 1 && rt == nil && tokenProblem == "" {
+				tokenProblem = "the last page of an incomplete list must carry an empty resumptionToken"
+			}
+			break
+		}
+		token = rt.Token
+	}
+
+	v.check("identifiers.present", "ListIdentifiers", SeverityError, true, "")
+	v.check("identifiers.datestamp_granularity", "ListIdentifiers", SeverityError, badDatestamp == "",
+		"datestamp %q does not match granularity %s", badDatestamp, v.granularity)
+	v.check("identifiers.earliest_datestamp", "ListIdentifiers", SeverityWarning, early == "",
+		"datestamp %q is before earliestDatestamp %s", early, v.earliest)
+	v.check("identifiers.unique", "ListIdentifiers", SeverityError, duplicate == "", "identifier %s was listed twice", duplicate)
+	v.check("identifiers.resumption_token", "ListIdentifiers", SeverityError, tokenProblem == "", "%s", tokenProblem)
+	if complete && listSize != 0 {
+		v.check("identifiers.complete_list_size", "ListIdentifiers", SeverityWarning, listSize == items,
+			"completeListSize is %d, but %d headers were listed", listSize, items)
+	}
+}
+
+// listRecords checks that live records carry metadata and deleted ones do not
+func (v *validator) listRecords(ctx context.Context) {
+	resp := v.fetch(ctx, "records", "ListRecords", "metadataPrefix", v.metadataPrefix)
+	if resp == nil || resp.ListRecords == nil {
+		return
+	}
+
+	for _, record := range resp.ListRecords.Records {
+		hasMetadata := record.Metadata != nil && len(strings.TrimSpace(string(record.Metadata.Raw))) > 0
+		if record.Header.IsDeleted() == hasMetadata {
+			v.check("records.metadata", "ListRecords", SeverityError, false,
+				"record %s (status %q) has metadata=%v", record.Header.Identifier, record.Header.Status, hasMetadata)
+			return
+		}
+	}
+	v.check("records.metadata", "ListRecords", SeverityError, true, "")
+}
+
+// errorConditions sends invalid requests and checks the reported error codes
+func (v *validator) errorConditions(ctx context.Context) {
+	tests := []struct {
+		id   string
+		verb string
+		args []string
+		code string
+	}{
+		{"errors.badVerb", "goharvestInvalidVerb", nil, ErrorCodeBadVerb},
+		{"errors.badArgument", "ListRecords", nil, ErrorCodeBadArgument},
+		{"errors.cannotDisseminateFormat", "ListRecords", []string{"metadataPrefix", "goharvest_invalid_prefix"}, ErrorCodeCannotDisseminateFormat},
+		{"errors.idDoesNotExist", "GetRecord", []string{"identifier", "oai:goharvest.invalid:does-not-exist", "metadataPrefix", v.metadataPrefix}, ErrorCodeIDDoesNotExist},
+		{"errors.badResumptionToken", "ListIdentifiers", []string{"resumptionToken", "goharvest-invalid-token"}, ErrorCodeBadResumptionToken},
+		{"errors.noRecordsMatch", "ListIdentifiers", []string{"metadataPrefix", v.metadataPrefix, "from", "9999-12-31", "until", "9999-12-31"}, ErrorCodeNoRecordsMatch},
+	}
+	if v.granularity == GranularityDay {
+		// Arguments finer than the repository granularity must be rejected
+		tests = append(tests, struct {
+			id   string
+			verb string
+			args []string
+			code string
+		}{"errors.granularity", "ListIdentifiers", []string{"metadataPrefix", v.metadataPrefix, "from", "2000-01-01T00:00:00Z"}, ErrorCodeBadArgument})
+	}
+
+	for _, tt := range tests {
+		if ctx.Err() != nil {
+			return
+		}
+
+		resp, err := v.client.sendRequest(ctx, tt.verb, tt.args...)
+		if err != nil {
+			v.check(tt.id, tt.verb, SeverityError, false, "request failed: %v", err)
+			continue
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			v.check(tt.id, tt.verb, SeverityError, false, "failed to read response: %v", err)
+			continue
+		}
+		v.report.PagesChecked++
+
+		var parsed complianceResponse
+		if err := xml.Unmarshal(body, &parsed); err != nil {
+			v.check(tt.id, tt.verb, SeverityError, false, "HTTP %d without an OAI-PMH error document", resp.StatusCode)
+			continue
+		}
+		v.check(tt.id, tt.verb, SeverityError, parsed.errorCode() == tt.code, "expected error %s, got %q", tt.code, parsed.errorCode())
+		if tt.code == ErrorCodeBadVerb || tt.code == ErrorCodeBadArgument {
+			v.check(tt.id+".request", tt.verb, SeverityWarning, parsed.Request.Verb == "",
+				"the request element of a %s response must not echo the arguments", tt.code)
+		}
+	}
+}
+
+// datestampGranularity returns GranularityDay or GranularitySecond for a valid UTC
+// datestamp, or "" when the value is neither
+func datestampGranularity(datestamp string) string {
+	if _, err := time.Parse("2006-01-02", datestamp); err == nil {
+		return GranularityDay
+	}
+	if _, err := time.Parse("2006-01-02T15:04:05Z", datestamp); err == nil {
+		return GranularitySecond
+	}
+	return ""
+}
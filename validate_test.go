@@ -0,0 +1,93 @@
+package goharvest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// brokenRepository answers with a selection of common protocol violations
+func brokenRepository(w http.ResponseWriter, r *http.Request) {
+	head := `<?xml version="1.0" encoding="UTF-8"?><OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/"><responseDate>2025-10-02</responseDate>`
+	query := r.URL.Query()
+
+	switch {
+	case query.Get("verb") == "Identify":
+		w.Write([]byte(head + `<request verb="Identify">http://example.org/oai</request><Identify>
+			<repositoryName>Broken</repositoryName><baseURL>http://example.org/oai</baseURL>
+			<protocolVersion>2.0</protocolVersion><earliestDatestamp>2020-01-01T00:00:00Z</earliestDatestamp>
+			<deletedRecord>sometimes</deletedRecord><granularity>YYYY-MM-DD</granularity></Identify></OAI-PMH>`))
+	case query.Get("verb") == "ListMetadataFormats":
+		w.Write([]byte(head + `<request/><ListMetadataFormats><metadataFormat><metadataPrefix>marcxml</metadataPrefix></metadataFormat></ListMetadataFormats></OAI-PMH>`))
+	case query.Get("verb") == "ListSets":
+		w.Write([]byte(head + "<request/><ListSets><set><setSpec>a b</setSpec><setName>Bad</setName></set></ListSets>\xff</OAI-PMH>"))
+	case query.Get("verb") == "ListIdentifiers" && query.Get("resumptionToken") == "page-2":
+		w.Write([]byte(head + `<request/><ListIdentifiers><header><identifier>oai:x:1</identifier><datestamp>2021-01-01</datestamp></header></ListIdentifiers></OAI-PMH>`))
+	case query.Get("verb") == "ListIdentifiers" && query.Get("from") == "":
+		w.Write([]byte(head + `<request/><ListIdentifiers><header><identifier>oai:x:1</identifier><datestamp>2021-01-01T10:00:00Z</datestamp></header>
+			<resumptionToken completeListSize="5">page-2</resumptionToken></ListIdentifiers></OAI-PMH>`))
+	case query.Get("verb") == "ListRecords" && query.Get("metadataPrefix") == "oai_dc":
+		w.Write([]byte(head + `<request/><ListRecords><record><header><identifier>oai:x:1</identifier><datestamp>2021-01-01</datestamp></header></record></ListRecords></OAI-PMH>`))
+	default:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+func TestValidateReportsViolations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(brokenRepository))
+	defer server.Close()
+
+	report, err := Validate(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if report.Compliant() {
+		t.Fatalf("Expected the repository to fail validation:\n%s", report)
+	}
+
+	for _, id := range []string{
+		"identify.response_date",
+		"identify.base_url_match",
+		"identify.admin_email",
+		"identify.deleted_record",
+		"identify.earliest_datestamp",
+		"formats.oai_dc",
+		"formats.complete",
+		"sets.utf8",
+		"identifiers.datestamp_granularity",
+		"identifiers.unique",
+		"identifiers.resumption_token",
+		"records.metadata",
+		"errors.badVerb",
+		"errors.granularity",
+	} {
+		check, ok := report.Check(id)
+		if !ok {
+			t.Errorf("Check %s was not run", id)
+		} else if check.Passed {
+			t.Errorf("Expected check %s to fail", id)
+		}
+	}
+
+	if check, _ := report.Check("identify.protocol_version"); !check.Passed {
+		t.Errorf("Expected protocol version check to pass: %s", check.Message)
+	}
+	if !strings.Contains(report.String(), "[ERROR] identify.deleted_record: deletedRecord \"sometimes\"") {
+		t.Errorf("Unexpected report text:\n%s", report)
+	}
+}
+
+func TestValidateUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	report, err := Validate(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Validate returned %v", err)
+	}
+	if check, ok := report.Check("identify.reachable"); !ok || check.Passed || report.Compliant() {
+		t.Errorf("Expected an unreachable repository to fail: %+v", report.Checks)
+	}
+}